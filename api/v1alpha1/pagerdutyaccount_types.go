@@ -0,0 +1,144 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// PagerDutyAccountSpec defines how a PagerDuty account is reached, and the settings
+// shared by the PagerDutyIntegrations using it
+type PagerDutyAccountSpec struct {
+	// Reference to the secret containing PAGERDUTY_API_KEY, or the
+	// PAGERDUTY_OAUTH_CLIENT_ID, PAGERDUTY_OAUTH_CLIENT_SECRET and
+	// PAGERDUTY_OAUTH_SCOPE of a scoped OAuth app.
+	APIKeySecretRef corev1.SecretReference `json:"apiKeySecretRef"`
+
+	// Where the PagerDuty APIs of the account are served. Defaults to the
+	// US service region.
+	// +optional
+	Endpoint *PagerDutyEndpoint `json:"endpoint,omitempty"`
+
+	// How many PagerDuty REST API requests per second the operator sends with
+	// the API key, shared by every PagerDutyIntegration using the account.
+	// PagerDuty allows 16 per API key. Defaults to 12.
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=16
+	// +optional
+	RequestsPerSecond int32 `json:"requestsPerSecond,omitempty"`
+
+	// Settings applied to the PagerDuty services of the PagerDutyIntegrations
+	// using the account, unless they set them themselves.
+	// +optional
+	Defaults *PagerDutyAccountDefaults `json:"defaults,omitempty"`
+}
+
+// PagerDutyAccountDefaults are the PagerDutyIntegration settings a PagerDutyAccount
+// provides defaults for
+type PagerDutyAccountDefaults struct {
+	// Time in seconds that an incident changes to the Triggered State after
+	// being Acknowledged.
+	// +kubebuilder:validation:Minimum=0
+	// +optional
+	AcknowledgeTimeout uint `json:"acknowledgeTimeout,omitempty"`
+
+	// Time in seconds that an incident is automatically resolved if left
+	// open for that long.
+	// +kubebuilder:validation:Minimum=0
+	// +optional
+	ResolveTimeout uint `json:"resolveTimeout,omitempty"`
+
+	// Configures alert grouping for PD services
+	// +optional
+	AlertGroupingParameters *AlertGroupingParametersSpec `json:"alertGroupingParameters,omitempty"`
+}
+
+// Condition types reported in PagerDutyAccountStatus.Conditions
+const (
+	// ConditionAuthenticated reports whether PagerDuty accepted the API key of the
+	// account when it was last checked.
+	ConditionAuthenticated string = "Authenticated"
+)
+
+// Condition reasons reported in PagerDutyAccountStatus.Conditions, besides
+// ReasonSecretLoadFailed and ReasonAPIKeyRejected
+const (
+	ReasonAPIKeyAccepted string = "APIKeyAccepted"
+	ReasonCheckFailed    string = "CheckFailed"
+)
+
+// PagerDutyAccountStatus defines the observed state of PagerDutyAccount
+type PagerDutyAccountStatus struct {
+	// The generation of the PagerDutyAccount that was last reconciled.
+	// +optional
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+
+	// Standard conditions describing the health of the account.
+	// The known condition type is Authenticated.
+	// +optional
+	// +listType=map
+	// +listMapKey=type
+	Conditions []metav1.Condition `json:"conditions,omitempty"`
+
+	// Time at which the API key was last checked with PagerDuty.
+	// +optional
+	LastCheckTime *metav1.Time `json:"lastCheckTime,omitempty"`
+
+	// Number of PagerDuty REST API requests left in the current rate limit
+	// window, as last reported by PagerDuty.
+	// +optional
+	RateLimitRemaining *int32 `json:"rateLimitRemaining,omitempty"`
+
+	// Time at which the current rate limit window of PagerDuty resets.
+	// +optional
+	RateLimitResetTime *metav1.Time `json:"rateLimitResetTime,omitempty"`
+
+	// Number of PagerDutyIntegrations using the account.
+	// +optional
+	PagerDutyIntegrations int32 `json:"pagerDutyIntegrations,omitempty"`
+}
+
+//+kubebuilder:object:root=true
+//+kubebuilder:subresource:status
+//+kubebuilder:resource:path=pagerdutyaccounts,shortName=pda,scope=Cluster
+//+kubebuilder:printcolumn:name="Authenticated",type="string",JSONPath=".status.conditions[?(@.type==\"Authenticated\")].status"
+//+kubebuilder:printcolumn:name="Integrations",type="integer",JSONPath=".status.pagerDutyIntegrations"
+//+kubebuilder:printcolumn:name="Remaining",type="integer",JSONPath=".status.rateLimitRemaining"
+//+kubebuilder:printcolumn:name="Age",type="date",JSONPath=".metadata.creationTimestamp"
+
+// PagerDutyAccount is the Schema for the pagerdutyaccounts API
+type PagerDutyAccount struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   PagerDutyAccountSpec   `json:"spec,omitempty"`
+	Status PagerDutyAccountStatus `json:"status,omitempty"`
+}
+
+//+kubebuilder:object:root=true
+
+// PagerDutyAccountList contains a list of PagerDutyAccount
+type PagerDutyAccountList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []PagerDutyAccount `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&PagerDutyAccount{}, &PagerDutyAccountList{})
+}
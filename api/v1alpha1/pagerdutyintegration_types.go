@@ -22,6 +22,7 @@ import (
 )
 
 // PagerDutyIntegrationSpec defines the desired state of PagerDutyIntegration
+// +kubebuilder:validation:XValidation:rule="has(self.pagerdutyApiKeySecretRef) != has(self.pagerdutyAccountRef)",message="exactly one of pagerdutyApiKeySecretRef and pagerdutyAccountRef must be set"
 type PagerDutyIntegrationSpec struct {
 	// Time in seconds that an incident changes to the Triggered State after
 	// being Acknowledged. Value must not be negative. Omitting or setting
@@ -32,6 +33,13 @@ type PagerDutyIntegrationSpec struct {
 	// ID of an existing Escalation Policy in PagerDuty.
 	EscalationPolicy string `json:"escalationPolicy"`
 
+	// Ordered rules routing ClusterDeployments to other escalation policies.
+	// The first rule whose clusterDeploymentSelector matches the labels of a
+	// ClusterDeployment picks the escalation policy of its PD service,
+	// ClusterDeployments no rule matches use escalationPolicy.
+	// +optional
+	EscalationPolicyRules []EscalationPolicyRule `json:"escalationPolicyRules,omitempty"`
+
 	// Time in seconds that an incident is automatically resolved if left
 	// open for that long. Value must not be negative. Omitting or setting
 	// this field to 0 will disable the feature.
@@ -41,8 +49,38 @@ type PagerDutyIntegrationSpec struct {
 	// Prefix to set on the PagerDuty Service name.
 	ServicePrefix string `json:"servicePrefix"`
 
-	// Reference to the secret containing PAGERDUTY_API_KEY.
-	PagerdutyApiKeySecretRef corev1.SecretReference `json:"pagerdutyApiKeySecretRef"`
+	// Go template of the PagerDuty service name. Defaults to
+	// {{.ServicePrefix}}-{{.ClusterID}}.{{.BaseDomain}}-hive-cluster, or
+	// {{.ServicePrefix}}-{{.ClusterID}} in FedRAMP. The template can use
+	// .ServicePrefix, .ClusterID, .ClusterName, .BaseDomain, .Namespace,
+	// .Platform, .Region, .Labels and .Annotations, and must render a
+	// different name for every ClusterDeployment: it has to use .ClusterID,
+	// or .Namespace together with .ClusterName.
+	// +kubebuilder:validation:MaxLength=1024
+	// +kubebuilder:validation:XValidation:rule="!self.contains('{{') || self.substring(self.indexOf('{{') + 2).split('{{').all(action, action.contains('}}'))",message="every {{ must be closed by }} before the next {{"
+	// +kubebuilder:validation:XValidation:rule="self.contains('.ClusterID') || (self.contains('.Namespace') && self.contains('.ClusterName'))",message="must use .ClusterID, or .Namespace and .ClusterName, to be unique per ClusterDeployment"
+	// +optional
+	ServiceNameTemplate string `json:"serviceNameTemplate,omitempty"`
+
+	// Go template of the PagerDuty service description. Defaults to
+	// {{.ClusterID}} - A managed hive created cluster, or no description in
+	// FedRAMP. The template can use the same fields as serviceNameTemplate.
+	// +kubebuilder:validation:MaxLength=1024
+	// +kubebuilder:validation:XValidation:rule="!self.contains('{{') || self.substring(self.indexOf('{{') + 2).split('{{').all(action, action.contains('}}'))",message="every {{ must be closed by }} before the next {{"
+	// +optional
+	ServiceDescriptionTemplate string `json:"serviceDescriptionTemplate,omitempty"`
+
+	// Reference to the secret containing PAGERDUTY_API_KEY, or the
+	// PAGERDUTY_OAUTH_CLIENT_ID, PAGERDUTY_OAUTH_CLIENT_SECRET and
+	// PAGERDUTY_OAUTH_SCOPE of a scoped OAuth app. Exactly one of
+	// pagerdutyApiKeySecretRef and pagerdutyAccountRef must be set.
+	// +optional
+	PagerdutyApiKeySecretRef corev1.SecretReference `json:"pagerdutyApiKeySecretRef,omitzero"`
+
+	// The cluster-scoped PagerDutyAccount whose credentials, endpoint and
+	// default settings are used. Takes precedence over endpoint.
+	// +optional
+	PagerDutyAccountRef *corev1.LocalObjectReference `json:"pagerdutyAccountRef,omitempty"`
 
 	// A label selector used to find which clusterdeployment CRs receive a
 	// PD integration based on this configuration.
@@ -56,8 +94,86 @@ type PagerDutyIntegrationSpec struct {
 
 	// Configures alert grouping for PD services
 	AlertGroupingParameters *AlertGroupingParametersSpec `json:"alertGroupingParameters,omitempty"`
+
+	// The urgency of incidents created on PD services. Defaults to a
+	// constant severity_based urgency.
+	// +optional
+	IncidentUrgencyRule *IncidentUrgencyRuleSpec `json:"incidentUrgencyRule,omitempty"`
+
+	// What to do when a PD service was changed outside of the operator.
+	// Enforce (the default) re-applies the desired settings, Report only
+	// emits metrics and Events.
+	// +kubebuilder:validation:Enum=Enforce;Report
+	// +kubebuilder:default=Enforce
+	// +optional
+	DriftPolicy DriftPolicy `json:"driftPolicy,omitempty"`
+
+	// Where the PagerDuty APIs of the account are served. Defaults to the
+	// US service region. Ignored when pagerdutyAccountRef is set.
+	// +optional
+	Endpoint *PagerDutyEndpoint `json:"endpoint,omitempty"`
+}
+
+// EscalationPolicyRule routes the ClusterDeployments matching a label selector to an
+// escalation policy
+type EscalationPolicyRule struct {
+	// A label selector matching the ClusterDeployments routed by this rule.
+	ClusterDeploymentSelector metav1.LabelSelector `json:"clusterDeploymentSelector"`
+
+	// ID of an existing Escalation Policy in PagerDuty.
+	// +kubebuilder:validation:MinLength=1
+	EscalationPolicy string `json:"escalationPolicy"`
+}
+
+// PagerDutyRegion is a PagerDuty service region
+type PagerDutyRegion string
+
+const (
+	// PagerDutyRegionUS is the US service region, api.pagerduty.com
+	PagerDutyRegionUS PagerDutyRegion = "US"
+
+	// PagerDutyRegionEU is the EU service region, api.eu.pagerduty.com
+	PagerDutyRegionEU PagerDutyRegion = "EU"
+)
+
+// PagerDutyEndpoint selects the PagerDuty service region of an account, or the
+// URLs of the PagerDuty APIs, e.g. for a local stand-in
+type PagerDutyEndpoint struct {
+	// The PagerDuty service region of the account.
+	// +kubebuilder:validation:Enum=US;EU
+	// +optional
+	Region PagerDutyRegion `json:"region,omitempty"`
+
+	// Base URL of the PagerDuty REST API. Overrides the region.
+	// +kubebuilder:validation:Pattern=`^https?://`
+	// +optional
+	APIURL string `json:"apiURL,omitempty"`
+
+	// Base URL of the PagerDuty Events API. Overrides the region, defaults
+	// to apiURL when only apiURL is set.
+	// +kubebuilder:validation:Pattern=`^https?://`
+	// +optional
+	EventsURL string `json:"eventsURL,omitempty"`
+
+	// URL where OAuth client credentials are exchanged for access tokens.
+	// Overrides the region, defaults to apiURL + "/oauth/token" when apiURL
+	// is set. Only used with OAuth credentials.
+	// +kubebuilder:validation:Pattern=`^https?://`
+	// +optional
+	TokenURL string `json:"tokenURL,omitempty"`
 }
 
+// DriftPolicy defines how drift of a PD service from its desired settings is handled
+type DriftPolicy string
+
+const (
+	// DriftPolicyEnforce re-applies the desired settings to a drifted PD service
+	DriftPolicyEnforce DriftPolicy = "Enforce"
+
+	// DriftPolicyReport only reports drift of a PD service
+	DriftPolicyReport DriftPolicy = "Report"
+)
+
 // ServiceOrchestration defines if the service orchestration is enabled
 // and the referenced configmap resource for the rules
 type ServiceOrchestration struct {
@@ -77,12 +193,191 @@ type AlertGroupingParametersConfigSpec struct {
 	Timeout uint `json:"timeout,omitempty"`
 }
 
+// IncidentUrgencyRuleType is the type of an incident urgency rule
+type IncidentUrgencyRuleType string
+
+const (
+	// IncidentUrgencyRuleConstant gives all incidents the same urgency
+	IncidentUrgencyRuleConstant IncidentUrgencyRuleType = "constant"
+
+	// IncidentUrgencyRuleUseSupportHours gives incidents a different urgency
+	// during and outside of support hours
+	IncidentUrgencyRuleUseSupportHours IncidentUrgencyRuleType = "use_support_hours"
+)
+
+// IncidentUrgencyRuleSpec defines the urgency of incidents created on a PD service
+type IncidentUrgencyRuleSpec struct {
+	// The type of the rule.
+	// +kubebuilder:validation:Enum=constant;use_support_hours
+	Type IncidentUrgencyRuleType `json:"type"`
+
+	// The urgency of all incidents. Required when type is constant.
+	// +kubebuilder:validation:Enum=high;low;severity_based
+	// +optional
+	Urgency string `json:"urgency,omitempty"`
+
+	// The urgency of incidents during support hours. Required when type
+	// is use_support_hours.
+	// +kubebuilder:validation:Enum=high;low;severity_based
+	// +optional
+	DuringSupportHours string `json:"duringSupportHours,omitempty"`
+
+	// The urgency of incidents outside of support hours. Required when type
+	// is use_support_hours.
+	// +kubebuilder:validation:Enum=high;low;severity_based
+	// +optional
+	OutsideSupportHours string `json:"outsideSupportHours,omitempty"`
+
+	// The support hours of the PD service. Required when type is
+	// use_support_hours.
+	// +optional
+	SupportHours *SupportHoursSpec `json:"supportHours,omitempty"`
+
+	// Actions PD takes on open incidents when support hours start. Only
+	// used when type is use_support_hours.
+	// +optional
+	ScheduledActions []ScheduledActionSpec `json:"scheduledActions,omitempty"`
+}
+
+// SupportHoursSpec defines the support hours of a PD service
+type SupportHoursSpec struct {
+	// The IANA time zone of the support hours, e.g. America/New_York.
+	TimeZone string `json:"timeZone"`
+
+	// The days of the week with support hours, 1 is Monday and 7 is Sunday.
+	// +kubebuilder:validation:MinItems=1
+	// +kubebuilder:validation:items:Minimum=1
+	// +kubebuilder:validation:items:Maximum=7
+	DaysOfWeek []uint `json:"daysOfWeek"`
+
+	// The time support hours start, formatted as HH:MM:SS.
+	// +kubebuilder:validation:Pattern=`^([01][0-9]|2[0-3]):[0-5][0-9]:[0-5][0-9]$`
+	StartTime string `json:"startTime"`
+
+	// The time support hours end, formatted as HH:MM:SS.
+	// +kubebuilder:validation:Pattern=`^([01][0-9]|2[0-3]):[0-5][0-9]:[0-5][0-9]$`
+	EndTime string `json:"endTime"`
+}
+
+// ScheduledActionSpec defines an action PD takes on open incidents of a PD service
+type ScheduledActionSpec struct {
+	// When the action is taken. PD only supports support_hours_start.
+	// +kubebuilder:validation:Enum=support_hours_start
+	At string `json:"at"`
+
+	// The urgency open incidents are changed to. PD only supports high.
+	// +kubebuilder:validation:Enum=high
+	ToUrgency string `json:"toUrgency"`
+}
+
+// Condition types reported in PagerDutyIntegrationStatus.Conditions
+const (
+	// ConditionReady is True when the PagerDuty API key could be loaded and every
+	// matching ClusterDeployment was reconciled without error.
+	ConditionReady string = "Ready"
+
+	// ConditionSecretLoaded reports whether the PagerDuty API key could be loaded
+	// from the Secret referenced by spec.pagerdutyApiKeySecretRef, or by the
+	// PagerDutyAccount referenced by spec.pagerdutyAccountRef.
+	ConditionSecretLoaded string = "SecretLoaded"
+
+	// ConditionDegraded is True when at least one ClusterDeployment failed to
+	// reconcile during the last reconcile, or a service template doesn't parse.
+	ConditionDegraded string = "Degraded"
+)
+
+// Condition reasons reported in PagerDutyIntegrationStatus.Conditions
+const (
+	ReasonReconcileSucceeded      string = "ReconcileSucceeded"
+	ReasonSecretLoaded            string = "SecretLoaded"
+	ReasonSecretLoadFailed        string = "SecretLoadFailed"
+	ReasonAPIKeyRejected          string = "APIKeyRejected"
+	ReasonAccountNotFound         string = "AccountNotFound"
+	ReasonClusterDeploymentErrors string = "ClusterDeploymentErrors"
+	ReasonInvalidServiceTemplate  string = "InvalidServiceTemplate"
+	ReasonAsExpected              string = "AsExpected"
+)
+
+// MaxRecentErrors is the maximum number of entries kept in PagerDutyIntegrationStatus.RecentErrors
+const MaxRecentErrors = 10
+
 // PagerDutyIntegrationStatus defines the observed state of PagerDutyIntegration
-type PagerDutyIntegrationStatus struct{}
+type PagerDutyIntegrationStatus struct {
+	// The generation of the PagerDutyIntegration that was last reconciled.
+	// +optional
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+
+	// Standard conditions describing the health of the integration.
+	// Known condition types are Ready, SecretLoaded and Degraded.
+	// +optional
+	// +listType=map
+	// +listMapKey=type
+	Conditions []metav1.Condition `json:"conditions,omitempty"`
+
+	// Number of ClusterDeployments matching the clusterDeploymentSelector.
+	// +optional
+	MatchedClusterDeployments int32 `json:"matchedClusterDeployments,omitempty"`
+
+	// Number of matching ClusterDeployments that have a PagerDuty service.
+	// +optional
+	ProvisionedClusterDeployments int32 `json:"provisionedClusterDeployments,omitempty"`
+
+	// Number of ClusterDeployments that failed to reconcile during the last reconcile.
+	// +optional
+	FailedClusterDeployments int32 `json:"failedClusterDeployments,omitempty"`
+
+	// Number of failed ClusterDeployments that are quarantined: they failed too many
+	// times in a row and are not reconciled again until their backoff expires.
+	// +optional
+	QuarantinedClusterDeployments int32 `json:"quarantinedClusterDeployments,omitempty"`
+
+	// Number of matching ClusterDeployments whose PagerDuty service is disabled
+	// because the cluster is in limited support.
+	// +optional
+	LimitedSupportClusterDeployments int32 `json:"limitedSupportClusterDeployments,omitempty"`
+
+	// Time at which the PagerDutyIntegration was last reconciled.
+	// +optional
+	LastReconcileTime *metav1.Time `json:"lastReconcileTime,omitempty"`
+
+	// Errors hit while reconciling individual ClusterDeployments during the last
+	// reconcile. At most MaxRecentErrors entries are kept.
+	// +optional
+	RecentErrors []ClusterDeploymentError `json:"recentErrors,omitempty"`
+}
+
+// ClusterDeploymentError records a failure to reconcile a single ClusterDeployment
+type ClusterDeploymentError struct {
+	// Namespace of the ClusterDeployment.
+	Namespace string `json:"namespace"`
+
+	// Name of the ClusterDeployment.
+	Name string `json:"name"`
+
+	// The error returned while reconciling the ClusterDeployment.
+	Message string `json:"message"`
+
+	// Time at which the error occurred.
+	Time metav1.Time `json:"time"`
+
+	// Number of times in a row the ClusterDeployment failed to reconcile.
+	// +optional
+	ConsecutiveFailures int32 `json:"consecutiveFailures,omitempty"`
+
+	// Set while the ClusterDeployment is quarantined, it is not reconciled again
+	// before this time unless the PagerDutyIntegration or ClusterDeployment changes.
+	// +optional
+	QuarantinedUntil *metav1.Time `json:"quarantinedUntil,omitempty"`
+}
 
 //+kubebuilder:object:root=true
 //+kubebuilder:subresource:status
 //+kubebuilder:resource:path=pagerdutyintegrations,shortName=pdi,scope=Namespaced
+//+kubebuilder:printcolumn:name="Ready",type="string",JSONPath=".status.conditions[?(@.type==\"Ready\")].status"
+//+kubebuilder:printcolumn:name="Matched",type="integer",JSONPath=".status.matchedClusterDeployments"
+//+kubebuilder:printcolumn:name="Provisioned",type="integer",JSONPath=".status.provisionedClusterDeployments"
+//+kubebuilder:printcolumn:name="Failed",type="integer",JSONPath=".status.failedClusterDeployments"
+//+kubebuilder:printcolumn:name="Age",type="date",JSONPath=".metadata.creationTimestamp"
 
 // PagerDutyIntegration is the Schema for the pagerdutyintegrations API
 type PagerDutyIntegration struct {
@@ -0,0 +1,242 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// PagerDutyServiceSpec records the PagerDuty service created for a
+// ClusterDeployment by a PagerDutyIntegration, and the settings that were
+// applied to it
+type PagerDutyServiceSpec struct {
+	// The ClusterDeployment, in the same namespace, that the PagerDuty service belongs to.
+	ClusterDeploymentRef corev1.LocalObjectReference `json:"clusterDeploymentRef"`
+
+	// The PagerDutyIntegration that manages the PagerDuty service.
+	PagerDutyIntegrationRef PagerDutyIntegrationReference `json:"pagerDutyIntegrationRef"`
+
+	// ID of the service in PagerDuty.
+	// +kubebuilder:validation:MinLength=1
+	ServiceID string `json:"serviceID"`
+
+	// ID of the Events API v2 integration on the PagerDuty service.
+	// +kubebuilder:validation:MinLength=1
+	IntegrationID string `json:"integrationID"`
+
+	// ID of the Escalation Policy assigned to the PagerDuty service.
+	// +optional
+	EscalationPolicyID string `json:"escalationPolicyID,omitempty"`
+
+	// Whether the PagerDuty service was disabled because the cluster is in limited support.
+	// +optional
+	LimitedSupport bool `json:"limitedSupport,omitempty"`
+
+	// Whether service orchestration is active on the PagerDuty service.
+	// +optional
+	ServiceOrchestrationEnabled bool `json:"serviceOrchestrationEnabled,omitempty"`
+
+	// The service orchestration rules last applied to the PagerDuty service.
+	// +optional
+	ServiceOrchestrationRuleApplied string `json:"serviceOrchestrationRuleApplied,omitempty"`
+
+	// The auto-resolve timeout, in seconds, last applied to the PagerDuty service.
+	// +optional
+	ResolveTimeout uint `json:"resolveTimeout,omitempty"`
+
+	// The acknowledgement timeout, in seconds, last applied to the PagerDuty service.
+	// +optional
+	AcknowledgeTimeout uint `json:"acknowledgeTimeout,omitempty"`
+
+	// The alert grouping type last applied to the PagerDuty service.
+	// +optional
+	AlertGroupingType string `json:"alertGroupingType,omitempty"`
+
+	// The alert grouping timeout last applied to the PagerDuty service.
+	// +optional
+	AlertGroupingTimeout uint `json:"alertGroupingTimeout,omitempty"`
+
+	// The name rendered from serviceNameTemplate last applied to the
+	// PagerDuty service. Unset when the default name was applied.
+	// +optional
+	ServiceName string `json:"serviceName,omitempty"`
+
+	// The description rendered from serviceDescriptionTemplate last applied
+	// to the PagerDuty service. Unset when the default description was applied.
+	// +optional
+	ServiceDescription string `json:"serviceDescription,omitempty"`
+
+	// The incident urgency rule last applied to the PagerDuty service. Unset
+	// when the default rule was applied.
+	// +optional
+	IncidentUrgencyRule *IncidentUrgencyRuleSpec `json:"incidentUrgencyRule,omitempty"`
+}
+
+// PagerDutyIntegrationReference identifies a PagerDutyIntegration
+type PagerDutyIntegrationReference struct {
+	Name      string `json:"name"`
+	Namespace string `json:"namespace"`
+}
+
+// PagerDutyServiceStatus defines the observed state of PagerDutyService
+type PagerDutyServiceStatus struct {
+	// Time at which the PagerDuty service was last compared with its desired settings.
+	// +optional
+	LastDriftCheckTime *metav1.Time `json:"lastDriftCheckTime,omitempty"`
+
+	// Settings of the PagerDuty service that differed from the desired ones
+	// during the last drift check.
+	// +optional
+	// +listType=set
+	DriftedFields []string `json:"driftedFields,omitempty"`
+
+	// The deletion or limited support disablement of the PagerDuty service that is in
+	// progress. Pending incidents are resolved before the service is deleted or
+	// disabled, which can take several reconciles.
+	// +optional
+	Operation *ServiceOperation `json:"operation,omitempty"`
+
+	// The pd.managed.openshift.io/* annotations of the ClusterDeployment that
+	// override settings of the PagerDutyIntegration, and whether they were accepted.
+	// +optional
+	// +listType=map
+	// +listMapKey=annotation
+	Overrides []ClusterOverride `json:"overrides,omitempty"`
+}
+
+// ClusterOverride is a ClusterDeployment annotation overriding a setting of the
+// PagerDutyIntegration for its PagerDuty service
+type ClusterOverride struct {
+	// The annotation, e.g. pd.managed.openshift.io/resolve-timeout.
+	Annotation string `json:"annotation"`
+
+	// The value of the annotation.
+	Value string `json:"value"`
+
+	// Whether the override is applied. Rejected overrides are ignored and the
+	// setting of the PagerDutyIntegration is used.
+	Accepted bool `json:"accepted"`
+
+	// Why the override was rejected.
+	// +optional
+	Message string `json:"message,omitempty"`
+}
+
+// ServiceOperationType is the kind of operation applied to a PagerDuty service
+// +kubebuilder:validation:Enum=Delete;Disable;Handoff
+type ServiceOperationType string
+
+const (
+	// ServiceOperationDelete deletes the PagerDuty service
+	ServiceOperationDelete ServiceOperationType = "Delete"
+
+	// ServiceOperationDisable disables the PagerDuty service when the cluster enters limited support
+	ServiceOperationDisable ServiceOperationType = "Disable"
+
+	// ServiceOperationHandoff hands the PagerDuty service over to the PagerDutyIntegration
+	// that selects the ClusterDeployment instead of deleting it
+	ServiceOperationHandoff ServiceOperationType = "Handoff"
+)
+
+// ServiceOperationPhase is the phase of a ServiceOperation
+// +kubebuilder:validation:Enum=ResolvingIncidents;AwaitingResolution;Deleting;Disabling;AwaitingAdoption;AwaitingSync;Done
+type ServiceOperationPhase string
+
+const (
+	// ServiceOperationResolvingIncidents sends resolve events for the alerts of the service
+	ServiceOperationResolvingIncidents ServiceOperationPhase = "ResolvingIncidents"
+
+	// ServiceOperationAwaitingResolution waits for PagerDuty to resolve the incidents of the service
+	ServiceOperationAwaitingResolution ServiceOperationPhase = "AwaitingResolution"
+
+	// ServiceOperationDeleting deletes the PagerDuty service
+	ServiceOperationDeleting ServiceOperationPhase = "Deleting"
+
+	// ServiceOperationDisabling disables the PagerDuty service
+	ServiceOperationDisabling ServiceOperationPhase = "Disabling"
+
+	// ServiceOperationAwaitingAdoption waits for the new PagerDutyIntegration to record the
+	// service in its own PagerDutyService
+	ServiceOperationAwaitingAdoption ServiceOperationPhase = "AwaitingAdoption"
+
+	// ServiceOperationAwaitingSync waits for hive to deploy the integration key with the SyncSet
+	// of the new PagerDutyIntegration before the old SyncSet is deleted
+	ServiceOperationAwaitingSync ServiceOperationPhase = "AwaitingSync"
+
+	// ServiceOperationDone means the service was deleted, disabled or handed over
+	ServiceOperationDone ServiceOperationPhase = "Done"
+)
+
+// ServiceOperation tracks the progress of a PagerDuty service deletion, disablement or handoff
+type ServiceOperation struct {
+	// The operation applied to the PagerDuty service.
+	Type ServiceOperationType `json:"type"`
+
+	// The current phase of the operation.
+	Phase ServiceOperationPhase `json:"phase"`
+
+	// Time at which the operation started.
+	StartTime metav1.Time `json:"startTime"`
+
+	// Time at which the incidents of the service were last resolved.
+	// +optional
+	LastResolveTime *metav1.Time `json:"lastResolveTime,omitempty"`
+
+	// Number of incidents of the service that were unresolved at the last check.
+	// +optional
+	UnresolvedIncidents int `json:"unresolvedIncidents,omitempty"`
+
+	// The PagerDutyIntegration the service is handed over to.
+	// +optional
+	HandoffTo *PagerDutyIntegrationReference `json:"handoffTo,omitempty"`
+}
+
+//+kubebuilder:object:root=true
+//+kubebuilder:subresource:status
+//+kubebuilder:resource:path=pagerdutyservices,shortName=pds,scope=Namespaced
+//+kubebuilder:printcolumn:name="ClusterDeployment",type="string",JSONPath=".spec.clusterDeploymentRef.name"
+//+kubebuilder:printcolumn:name="Integration",type="string",JSONPath=".spec.pagerDutyIntegrationRef.name"
+//+kubebuilder:printcolumn:name="Service ID",type="string",JSONPath=".spec.serviceID"
+//+kubebuilder:printcolumn:name="Escalation Policy",type="string",JSONPath=".spec.escalationPolicyID"
+//+kubebuilder:printcolumn:name="Limited Support",type="boolean",JSONPath=".spec.limitedSupport"
+//+kubebuilder:printcolumn:name="Drifted",type="string",JSONPath=".status.driftedFields",priority=1
+//+kubebuilder:printcolumn:name="Operation",type="string",JSONPath=".status.operation.type",priority=1
+//+kubebuilder:printcolumn:name="Phase",type="string",JSONPath=".status.operation.phase",priority=1
+//+kubebuilder:printcolumn:name="Age",type="date",JSONPath=".metadata.creationTimestamp"
+
+// PagerDutyService is the Schema for the pagerdutyservices API
+type PagerDutyService struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   PagerDutyServiceSpec   `json:"spec,omitempty"`
+	Status PagerDutyServiceStatus `json:"status,omitempty"`
+}
+
+//+kubebuilder:object:root=true
+
+// PagerDutyServiceList contains a list of PagerDutyService
+type PagerDutyServiceList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []PagerDutyService `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&PagerDutyService{}, &PagerDutyServiceList{})
+}
@@ -5,7 +5,8 @@
 package v1alpha1
 
 import (
-	"k8s.io/api/core/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1"
 	runtime "k8s.io/apimachinery/pkg/runtime"
 )
 
@@ -44,13 +45,244 @@ func (in *AlertGroupingParametersSpec) DeepCopy() *AlertGroupingParametersSpec {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ClusterDeploymentError) DeepCopyInto(out *ClusterDeploymentError) {
+	*out = *in
+	in.Time.DeepCopyInto(&out.Time)
+	if in.QuarantinedUntil != nil {
+		in, out := &in.QuarantinedUntil, &out.QuarantinedUntil
+		*out = (*in).DeepCopy()
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ClusterDeploymentError.
+func (in *ClusterDeploymentError) DeepCopy() *ClusterDeploymentError {
+	if in == nil {
+		return nil
+	}
+	out := new(ClusterDeploymentError)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ClusterOverride) DeepCopyInto(out *ClusterOverride) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ClusterOverride.
+func (in *ClusterOverride) DeepCopy() *ClusterOverride {
+	if in == nil {
+		return nil
+	}
+	out := new(ClusterOverride)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *EscalationPolicyRule) DeepCopyInto(out *EscalationPolicyRule) {
+	*out = *in
+	in.ClusterDeploymentSelector.DeepCopyInto(&out.ClusterDeploymentSelector)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new EscalationPolicyRule.
+func (in *EscalationPolicyRule) DeepCopy() *EscalationPolicyRule {
+	if in == nil {
+		return nil
+	}
+	out := new(EscalationPolicyRule)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *IncidentUrgencyRuleSpec) DeepCopyInto(out *IncidentUrgencyRuleSpec) {
+	*out = *in
+	if in.SupportHours != nil {
+		in, out := &in.SupportHours, &out.SupportHours
+		*out = new(SupportHoursSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.ScheduledActions != nil {
+		in, out := &in.ScheduledActions, &out.ScheduledActions
+		*out = make([]ScheduledActionSpec, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new IncidentUrgencyRuleSpec.
+func (in *IncidentUrgencyRuleSpec) DeepCopy() *IncidentUrgencyRuleSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(IncidentUrgencyRuleSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PagerDutyAccount) DeepCopyInto(out *PagerDutyAccount) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PagerDutyAccount.
+func (in *PagerDutyAccount) DeepCopy() *PagerDutyAccount {
+	if in == nil {
+		return nil
+	}
+	out := new(PagerDutyAccount)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *PagerDutyAccount) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PagerDutyAccountDefaults) DeepCopyInto(out *PagerDutyAccountDefaults) {
+	*out = *in
+	if in.AlertGroupingParameters != nil {
+		in, out := &in.AlertGroupingParameters, &out.AlertGroupingParameters
+		*out = new(AlertGroupingParametersSpec)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PagerDutyAccountDefaults.
+func (in *PagerDutyAccountDefaults) DeepCopy() *PagerDutyAccountDefaults {
+	if in == nil {
+		return nil
+	}
+	out := new(PagerDutyAccountDefaults)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PagerDutyAccountList) DeepCopyInto(out *PagerDutyAccountList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]PagerDutyAccount, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PagerDutyAccountList.
+func (in *PagerDutyAccountList) DeepCopy() *PagerDutyAccountList {
+	if in == nil {
+		return nil
+	}
+	out := new(PagerDutyAccountList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *PagerDutyAccountList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PagerDutyAccountSpec) DeepCopyInto(out *PagerDutyAccountSpec) {
+	*out = *in
+	out.APIKeySecretRef = in.APIKeySecretRef
+	if in.Endpoint != nil {
+		in, out := &in.Endpoint, &out.Endpoint
+		*out = new(PagerDutyEndpoint)
+		**out = **in
+	}
+	if in.Defaults != nil {
+		in, out := &in.Defaults, &out.Defaults
+		*out = new(PagerDutyAccountDefaults)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PagerDutyAccountSpec.
+func (in *PagerDutyAccountSpec) DeepCopy() *PagerDutyAccountSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(PagerDutyAccountSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PagerDutyAccountStatus) DeepCopyInto(out *PagerDutyAccountStatus) {
+	*out = *in
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]v1.Condition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.LastCheckTime != nil {
+		in, out := &in.LastCheckTime, &out.LastCheckTime
+		*out = (*in).DeepCopy()
+	}
+	if in.RateLimitRemaining != nil {
+		in, out := &in.RateLimitRemaining, &out.RateLimitRemaining
+		*out = new(int32)
+		**out = **in
+	}
+	if in.RateLimitResetTime != nil {
+		in, out := &in.RateLimitResetTime, &out.RateLimitResetTime
+		*out = (*in).DeepCopy()
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PagerDutyAccountStatus.
+func (in *PagerDutyAccountStatus) DeepCopy() *PagerDutyAccountStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(PagerDutyAccountStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PagerDutyEndpoint) DeepCopyInto(out *PagerDutyEndpoint) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PagerDutyEndpoint.
+func (in *PagerDutyEndpoint) DeepCopy() *PagerDutyEndpoint {
+	if in == nil {
+		return nil
+	}
+	out := new(PagerDutyEndpoint)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *PagerDutyIntegration) DeepCopyInto(out *PagerDutyIntegration) {
 	*out = *in
 	out.TypeMeta = in.TypeMeta
 	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
 	in.Spec.DeepCopyInto(&out.Spec)
-	out.Status = in.Status
+	in.Status.DeepCopyInto(&out.Status)
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PagerDutyIntegration.
@@ -103,10 +335,37 @@ func (in *PagerDutyIntegrationList) DeepCopyObject() runtime.Object {
 	return nil
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PagerDutyIntegrationReference) DeepCopyInto(out *PagerDutyIntegrationReference) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PagerDutyIntegrationReference.
+func (in *PagerDutyIntegrationReference) DeepCopy() *PagerDutyIntegrationReference {
+	if in == nil {
+		return nil
+	}
+	out := new(PagerDutyIntegrationReference)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *PagerDutyIntegrationSpec) DeepCopyInto(out *PagerDutyIntegrationSpec) {
 	*out = *in
+	if in.EscalationPolicyRules != nil {
+		in, out := &in.EscalationPolicyRules, &out.EscalationPolicyRules
+		*out = make([]EscalationPolicyRule, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 	out.PagerdutyApiKeySecretRef = in.PagerdutyApiKeySecretRef
+	if in.PagerDutyAccountRef != nil {
+		in, out := &in.PagerDutyAccountRef, &out.PagerDutyAccountRef
+		*out = new(corev1.LocalObjectReference)
+		**out = **in
+	}
 	in.ClusterDeploymentSelector.DeepCopyInto(&out.ClusterDeploymentSelector)
 	out.TargetSecretRef = in.TargetSecretRef
 	in.ServiceOrchestration.DeepCopyInto(&out.ServiceOrchestration)
@@ -115,6 +374,16 @@ func (in *PagerDutyIntegrationSpec) DeepCopyInto(out *PagerDutyIntegrationSpec)
 		*out = new(AlertGroupingParametersSpec)
 		(*in).DeepCopyInto(*out)
 	}
+	if in.IncidentUrgencyRule != nil {
+		in, out := &in.IncidentUrgencyRule, &out.IncidentUrgencyRule
+		*out = new(IncidentUrgencyRuleSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Endpoint != nil {
+		in, out := &in.Endpoint, &out.Endpoint
+		*out = new(PagerDutyEndpoint)
+		**out = **in
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PagerDutyIntegrationSpec.
@@ -130,6 +399,24 @@ func (in *PagerDutyIntegrationSpec) DeepCopy() *PagerDutyIntegrationSpec {
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *PagerDutyIntegrationStatus) DeepCopyInto(out *PagerDutyIntegrationStatus) {
 	*out = *in
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]v1.Condition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.LastReconcileTime != nil {
+		in, out := &in.LastReconcileTime, &out.LastReconcileTime
+		*out = (*in).DeepCopy()
+	}
+	if in.RecentErrors != nil {
+		in, out := &in.RecentErrors, &out.RecentErrors
+		*out = make([]ClusterDeploymentError, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PagerDutyIntegrationStatus.
@@ -142,12 +429,167 @@ func (in *PagerDutyIntegrationStatus) DeepCopy() *PagerDutyIntegrationStatus {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PagerDutyService) DeepCopyInto(out *PagerDutyService) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PagerDutyService.
+func (in *PagerDutyService) DeepCopy() *PagerDutyService {
+	if in == nil {
+		return nil
+	}
+	out := new(PagerDutyService)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *PagerDutyService) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PagerDutyServiceList) DeepCopyInto(out *PagerDutyServiceList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]PagerDutyService, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PagerDutyServiceList.
+func (in *PagerDutyServiceList) DeepCopy() *PagerDutyServiceList {
+	if in == nil {
+		return nil
+	}
+	out := new(PagerDutyServiceList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *PagerDutyServiceList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PagerDutyServiceSpec) DeepCopyInto(out *PagerDutyServiceSpec) {
+	*out = *in
+	out.ClusterDeploymentRef = in.ClusterDeploymentRef
+	out.PagerDutyIntegrationRef = in.PagerDutyIntegrationRef
+	if in.IncidentUrgencyRule != nil {
+		in, out := &in.IncidentUrgencyRule, &out.IncidentUrgencyRule
+		*out = new(IncidentUrgencyRuleSpec)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PagerDutyServiceSpec.
+func (in *PagerDutyServiceSpec) DeepCopy() *PagerDutyServiceSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(PagerDutyServiceSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PagerDutyServiceStatus) DeepCopyInto(out *PagerDutyServiceStatus) {
+	*out = *in
+	if in.LastDriftCheckTime != nil {
+		in, out := &in.LastDriftCheckTime, &out.LastDriftCheckTime
+		*out = (*in).DeepCopy()
+	}
+	if in.DriftedFields != nil {
+		in, out := &in.DriftedFields, &out.DriftedFields
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Operation != nil {
+		in, out := &in.Operation, &out.Operation
+		*out = new(ServiceOperation)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Overrides != nil {
+		in, out := &in.Overrides, &out.Overrides
+		*out = make([]ClusterOverride, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PagerDutyServiceStatus.
+func (in *PagerDutyServiceStatus) DeepCopy() *PagerDutyServiceStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(PagerDutyServiceStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ScheduledActionSpec) DeepCopyInto(out *ScheduledActionSpec) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ScheduledActionSpec.
+func (in *ScheduledActionSpec) DeepCopy() *ScheduledActionSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(ScheduledActionSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ServiceOperation) DeepCopyInto(out *ServiceOperation) {
+	*out = *in
+	in.StartTime.DeepCopyInto(&out.StartTime)
+	if in.LastResolveTime != nil {
+		in, out := &in.LastResolveTime, &out.LastResolveTime
+		*out = (*in).DeepCopy()
+	}
+	if in.HandoffTo != nil {
+		in, out := &in.HandoffTo, &out.HandoffTo
+		*out = new(PagerDutyIntegrationReference)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ServiceOperation.
+func (in *ServiceOperation) DeepCopy() *ServiceOperation {
+	if in == nil {
+		return nil
+	}
+	out := new(ServiceOperation)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ServiceOrchestration) DeepCopyInto(out *ServiceOrchestration) {
 	*out = *in
 	if in.RuleConfigConfigMapRef != nil {
 		in, out := &in.RuleConfigConfigMapRef, &out.RuleConfigConfigMapRef
-		*out = new(v1.ObjectReference)
+		*out = new(corev1.ObjectReference)
 		**out = **in
 	}
 }
@@ -161,3 +603,23 @@ func (in *ServiceOrchestration) DeepCopy() *ServiceOrchestration {
 	in.DeepCopyInto(out)
 	return out
 }
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SupportHoursSpec) DeepCopyInto(out *SupportHoursSpec) {
+	*out = *in
+	if in.DaysOfWeek != nil {
+		in, out := &in.DaysOfWeek, &out.DaysOfWeek
+		*out = make([]uint, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SupportHoursSpec.
+func (in *SupportHoursSpec) DeepCopy() *SupportHoursSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(SupportHoursSpec)
+	in.DeepCopyInto(out)
+	return out
+}
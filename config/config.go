@@ -28,6 +28,12 @@ const (
 	PagerDutyAPISecretName string = "pagerduty-api-key" // #nosec G101 -- This is a false positive
 	PagerDutyAPISecretKey  string = "PAGERDUTY_API_KEY" // #nosec G101 -- This is a false positive
 	PagerDutySecretKey     string = "PAGERDUTY_KEY"     // #nosec G101 -- This is a false positive
+	// PagerDutyOAuthClientIDKey, PagerDutyOAuthClientSecretKey and PagerDutyOAuthScopeKey
+	// hold the client credentials of a PagerDuty scoped OAuth app. A credential Secret
+	// setting them is used instead of PagerDutyAPISecretKey.
+	PagerDutyOAuthClientIDKey     string = "PAGERDUTY_OAUTH_CLIENT_ID"
+	PagerDutyOAuthClientSecretKey string = "PAGERDUTY_OAUTH_CLIENT_SECRET" // #nosec G101 -- This is a false positive
+	PagerDutyOAuthScopeKey        string = "PAGERDUTY_OAUTH_SCOPE"
 	// PagerDutyFinalizerPrefix prefix used for finalizers on resources other than PDI
 	PagerDutyFinalizerPrefix string = "pd.managed.openshift.io/"
 	// PagerDutyIntegrationFinalizer name of finalizer used for PDI
@@ -35,10 +41,13 @@ const (
 	// LegacyPagerDutyFinalizer name of legacy finalizer, always to be deleted
 	LegacyPagerDutyFinalizer string = "pd.managed.openshift.io/pagerduty"
 	SecretSuffix             string = "-pd-secret"
-	ConfigMapSuffix          string = "-pd-config"
+	// ConfigMapSuffix is the suffix of the legacy per-cluster ConfigMap that was
+	// replaced by the PagerDutyService CR. It is only read to migrate existing clusters.
+	ConfigMapSuffix        string = "-pd-config"
+	PagerDutyServiceSuffix string = "-pd-service"
 
-	// PagerDutyUrgencyRule is the type of IncidentUrgencyRule for new incidents
-	// coming into the Service. This is for the creation of NEW SERVICES ONLY
+	// PagerDutyUrgencyRule is the constant urgency of new incidents on PD
+	// services whose PagerDutyIntegration doesn't set spec.incidentUrgencyRule.
 	// Supported values (by this operator) are:
 	// * high - Treat all incidents as high urgency
 	// * severity_based - Look to the severity on the PagerDuty Incident to map
@@ -56,10 +65,23 @@ const (
 	// ClusterDeploymentSupportExceptionLabel is the label indicating the cluster is under a support
 	// exception and the PagerDuty service should be enabled even if the cluster is in limited support
 	ClusterDeploymentSupportExceptionLabel string = "ext-managed.openshift.io/support-exception"
+
+	// OverrideAnnotationPrefix is the prefix of the ClusterDeployment annotations that override
+	// settings of the PagerDutyIntegration for the PD service of a single cluster
+	OverrideAnnotationPrefix string = "pd.managed.openshift.io/"
+	// ResolveTimeoutOverrideAnnotation overrides spec.resolveTimeout, in seconds
+	ResolveTimeoutOverrideAnnotation string = OverrideAnnotationPrefix + "resolve-timeout"
+	// AcknowledgeTimeoutOverrideAnnotation overrides spec.acknowledgeTimeout, in seconds
+	AcknowledgeTimeoutOverrideAnnotation string = OverrideAnnotationPrefix + "acknowledge-timeout"
+	// AlertGroupingTimeoutOverrideAnnotation overrides the time based alert grouping window of
+	// spec.alertGroupingParameters, in minutes
+	AlertGroupingTimeoutOverrideAnnotation string = OverrideAnnotationPrefix + "alert-grouping-timeout"
+	// EscalationPolicyOverrideAnnotation overrides spec.escalationPolicy and spec.escalationPolicyRules
+	EscalationPolicyOverrideAnnotation string = OverrideAnnotationPrefix + "escalation-policy"
 )
 
 // Name is used to generate the name of secondary resources (SyncSets,
-// Secrets, ConfigMaps) for a ClusterDeployment that are created by
+// Secrets, PagerDutyServices) for a ClusterDeployment that are created by
 // the PagerDutyIntegration controller.
 func Name(servicePrefix, clusterDeploymentName, suffix string) string {
 	return servicePrefix + "-" + clusterDeploymentName + suffix
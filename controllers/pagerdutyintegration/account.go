@@ -0,0 +1,154 @@
+// Copyright 2019 RedHat
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pagerdutyintegration
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	pagerdutyv1alpha1 "github.com/openshift/pagerduty-operator/api/v1alpha1"
+	"github.com/openshift/pagerduty-operator/config"
+	pd "github.com/openshift/pagerduty-operator/pkg/pagerduty"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// errPagerDutyAccountNotFound is returned when the PagerDutyAccount referenced by a
+// PagerDutyIntegration doesn't exist
+var errPagerDutyAccountNotFound = errors.New("PagerDutyAccount not found")
+
+// loadAccount returns the credentials, endpoint and rate limit budget used for the PD
+// services of pdi, along with the PagerDutyAccount providing them if pdi references one
+func loadAccount(ctx context.Context, c client.Client, pdi *pagerdutyv1alpha1.PagerDutyIntegration) (pd.Account, *pagerdutyv1alpha1.PagerDutyAccount, error) {
+	if pdi.Spec.PagerDutyAccountRef == nil {
+		account, err := loadCredentials(ctx, c, pdi.Spec.PagerdutyApiKeySecretRef)
+		if err != nil {
+			return pd.Account{}, nil, err
+		}
+		account.Endpoint = pd.EndpointFor(pdi.Spec.Endpoint)
+		return account, nil, nil
+	}
+
+	pdAccount := &pagerdutyv1alpha1.PagerDutyAccount{}
+	if err := c.Get(ctx, types.NamespacedName{Name: pdi.Spec.PagerDutyAccountRef.Name}, pdAccount); err != nil {
+		if apierrors.IsNotFound(err) {
+			return pd.Account{}, nil, fmt.Errorf("%w: %s", errPagerDutyAccountNotFound, pdi.Spec.PagerDutyAccountRef.Name)
+		}
+		return pd.Account{}, nil, err
+	}
+	account, err := loadPagerDutyAccount(ctx, c, pdAccount)
+	if err != nil {
+		return pd.Account{}, nil, err
+	}
+	return account, pdAccount, nil
+}
+
+// LoadHeartbeatAccount returns the account checked by the pagerduty_heartbeat metric: the
+// PagerDutyAccount called name, or the credentials of the default Secret reached at apiURL
+// when name is empty
+func LoadHeartbeatAccount(ctx context.Context, c client.Client, name string, apiURL string) (pd.Account, error) {
+	if name != "" {
+		pdAccount := &pagerdutyv1alpha1.PagerDutyAccount{}
+		if err := c.Get(ctx, types.NamespacedName{Name: name}, pdAccount); err != nil {
+			if apierrors.IsNotFound(err) {
+				return pd.Account{}, fmt.Errorf("%w: %s", errPagerDutyAccountNotFound, name)
+			}
+			return pd.Account{}, err
+		}
+		return loadPagerDutyAccount(ctx, c, pdAccount)
+	}
+
+	account, err := loadCredentials(ctx, c, corev1.SecretReference{Namespace: config.OperatorNamespace, Name: config.PagerDutyAPISecretName})
+	if err != nil {
+		return pd.Account{}, err
+	}
+	account.Endpoint = pd.USEndpoint
+	account.Endpoint.APIURL = apiURL
+	return account, nil
+}
+
+// loadPagerDutyAccount returns the credentials, endpoint and rate limit budget of pdAccount
+func loadPagerDutyAccount(ctx context.Context, c client.Client, pdAccount *pagerdutyv1alpha1.PagerDutyAccount) (pd.Account, error) {
+	account, err := loadCredentials(ctx, c, pdAccount.Spec.APIKeySecretRef)
+	if err != nil {
+		return pd.Account{}, err
+	}
+	account.Endpoint = pd.EndpointFor(pdAccount.Spec.Endpoint)
+	account.RequestsPerSecond = int(pdAccount.Spec.RequestsPerSecond)
+	return account, nil
+}
+
+// loadCredentials loads the PD credentials from the given Secret: the client credentials
+// of a scoped OAuth app when it has a client ID, the API key otherwise
+func loadCredentials(ctx context.Context, c client.Client, ref corev1.SecretReference) (pd.Account, error) {
+	secret := &corev1.Secret{}
+	if err := c.Get(ctx, types.NamespacedName{Namespace: ref.Namespace, Name: ref.Name}, secret); err != nil {
+		return pd.Account{}, err
+	}
+
+	if _, ok := secret.Data[config.PagerDutyOAuthClientIDKey]; ok {
+		oauth := &pd.OAuthCredentials{
+			ClientID:     string(secret.Data[config.PagerDutyOAuthClientIDKey]),
+			ClientSecret: string(secret.Data[config.PagerDutyOAuthClientSecretKey]),
+			Scope:        string(secret.Data[config.PagerDutyOAuthScopeKey]),
+		}
+		if oauth.ClientID == "" || oauth.ClientSecret == "" || oauth.Scope == "" {
+			return pd.Account{}, fmt.Errorf("secret %s has to set %s, %s and %s", ref.Name,
+				config.PagerDutyOAuthClientIDKey, config.PagerDutyOAuthClientSecretKey, config.PagerDutyOAuthScopeKey)
+		}
+		return pd.Account{OAuth: oauth}, nil
+	}
+
+	apiKey, ok := secret.Data[config.PagerDutyAPISecretKey]
+	if !ok {
+		return pd.Account{}, fmt.Errorf("secret %s did not contain key %s", ref.Name, config.PagerDutyAPISecretKey)
+	}
+	if len(apiKey) == 0 {
+		return pd.Account{}, fmt.Errorf("%s is empty", config.PagerDutyAPISecretKey)
+	}
+	return pd.Account{APIKey: string(apiKey)}, nil
+}
+
+// apiKeySource describes where the API key of pdi comes from, for status messages
+func apiKeySource(pdi *pagerdutyv1alpha1.PagerDutyIntegration) string {
+	if pdi.Spec.PagerDutyAccountRef != nil {
+		return "PagerDutyAccount " + pdi.Spec.PagerDutyAccountRef.Name
+	}
+	return fmt.Sprintf("Secret %s/%s", pdi.Spec.PagerdutyApiKeySecretRef.Namespace, pdi.Spec.PagerdutyApiKeySecretRef.Name)
+}
+
+// withAccountDefaults returns a copy of pdi whose unset service settings are taken from
+// the defaults of account, or pdi itself when account has none
+func withAccountDefaults(pdi *pagerdutyv1alpha1.PagerDutyIntegration, account *pagerdutyv1alpha1.PagerDutyAccount) *pagerdutyv1alpha1.PagerDutyIntegration {
+	if account == nil || account.Spec.Defaults == nil {
+		return pdi
+	}
+
+	defaults := account.Spec.Defaults
+	pdi = pdi.DeepCopy()
+	if pdi.Spec.AcknowledgeTimeout == 0 {
+		pdi.Spec.AcknowledgeTimeout = defaults.AcknowledgeTimeout
+	}
+	if pdi.Spec.ResolveTimeout == 0 {
+		pdi.Spec.ResolveTimeout = defaults.ResolveTimeout
+	}
+	if pdi.Spec.AlertGroupingParameters == nil && defaults.AlertGroupingParameters != nil {
+		pdi.Spec.AlertGroupingParameters = defaults.AlertGroupingParameters.DeepCopy()
+	}
+	return pdi
+}
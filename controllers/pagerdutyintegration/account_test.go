@@ -0,0 +1,282 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pagerdutyintegration
+
+import (
+	"context"
+	"testing"
+
+	pagerdutyv1alpha1 "github.com/openshift/pagerduty-operator/api/v1alpha1"
+	"github.com/openshift/pagerduty-operator/config"
+	pd "github.com/openshift/pagerduty-operator/pkg/pagerduty"
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	testPagerDutyAccountName = "test-account"
+	testAccountSecretName    = "test-account-api-key"
+	testAccountAPIKey        = "test-account-pd-api-key" //#nosec G101 -- This is a false positive
+)
+
+// testPagerDutyAccount returns a PagerDutyAccount in the EU region with its own API key
+func testPagerDutyAccount() *pagerdutyv1alpha1.PagerDutyAccount {
+	return &pagerdutyv1alpha1.PagerDutyAccount{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:       testPagerDutyAccountName,
+			Generation: 1,
+		},
+		Spec: pagerdutyv1alpha1.PagerDutyAccountSpec{
+			APIKeySecretRef: corev1.SecretReference{
+				Name:      testAccountSecretName,
+				Namespace: config.OperatorNamespace,
+			},
+			Endpoint:          &pagerdutyv1alpha1.PagerDutyEndpoint{Region: pagerdutyv1alpha1.PagerDutyRegionEU},
+			RequestsPerSecond: 4,
+		},
+	}
+}
+
+func testAccountSecret() *corev1.Secret {
+	return &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: config.OperatorNamespace,
+			Name:      testAccountSecretName,
+		},
+		Data: map[string][]byte{
+			config.PagerDutyAPISecretKey: []byte(testAccountAPIKey),
+		},
+	}
+}
+
+// testPagerDutyIntegrationWithAccount returns the test PagerDutyIntegration using the
+// test PagerDutyAccount rather than its own Secret
+func testPagerDutyIntegrationWithAccount() *pagerdutyv1alpha1.PagerDutyIntegration {
+	pdi := testPagerDutyIntegration()
+	pdi.Spec.PagerdutyApiKeySecretRef = corev1.SecretReference{}
+	pdi.Spec.PagerDutyAccountRef = &corev1.LocalObjectReference{Name: testPagerDutyAccountName}
+	return pdi
+}
+
+func TestLoadHeartbeatAccount(t *testing.T) {
+	oauthSecret := testAccountSecret()
+	oauthSecret.Data = map[string][]byte{
+		config.PagerDutyOAuthClientIDKey:     []byte("client-id"),
+		config.PagerDutyOAuthClientSecretKey: []byte("client-secret"),
+		config.PagerDutyOAuthScopeKey:        []byte("as_account-us.example services.read"),
+	}
+	defaultSecret := testAccountSecret()
+	defaultSecret.Name = config.PagerDutyAPISecretName
+	const apiURL = "https://pagerduty.example.com"
+
+	tests := []struct {
+		name          string
+		localObjects  []client.Object
+		account       string
+		expectAccount pd.Account
+		expectErr     bool
+	}{
+		{
+			name:          "Default Secret at the heartbeat API URL",
+			localObjects:  []client.Object{defaultSecret},
+			expectAccount: pd.Account{APIKey: testAccountAPIKey, Endpoint: pd.Endpoint{APIURL: apiURL, EventsURL: pd.USEndpoint.EventsURL, TokenURL: pd.USEndpoint.TokenURL}},
+		},
+		{
+			name:         "Account with OAuth client credentials",
+			localObjects: []client.Object{defaultSecret, oauthSecret, testPagerDutyAccount()},
+			account:      testPagerDutyAccountName,
+			expectAccount: pd.Account{
+				OAuth:             &pd.OAuthCredentials{ClientID: "client-id", ClientSecret: "client-secret", Scope: "as_account-us.example services.read"},
+				Endpoint:          pd.EUEndpoint,
+				RequestsPerSecond: 4,
+			},
+		},
+		{
+			name:         "Account not found",
+			localObjects: []client.Object{defaultSecret},
+			account:      testPagerDutyAccountName,
+			expectErr:    true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			mocks := setupDefaultMocks(t, test.localObjects)
+
+			account, err := LoadHeartbeatAccount(context.TODO(), mocks.fakeKubeClient, test.account, apiURL)
+			if test.expectErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, test.expectAccount, account)
+		})
+	}
+}
+
+func TestLoadAccount(t *testing.T) {
+	euPDI := testPagerDutyIntegration()
+	euPDI.Spec.Endpoint = &pagerdutyv1alpha1.PagerDutyEndpoint{Region: pagerdutyv1alpha1.PagerDutyRegionEU}
+
+	tests := []struct {
+		name            string
+		localObjects    []client.Object
+		pdi             *pagerdutyv1alpha1.PagerDutyIntegration
+		expectAccount   pd.Account
+		expectPDAccount bool
+		expectErr       error
+	}{
+		{
+			name:          "PDI without account uses its own Secret and endpoint",
+			localObjects:  []client.Object{testPDISecret(), testAccountSecret(), testPagerDutyAccount()},
+			pdi:           euPDI,
+			expectAccount: pd.Account{APIKey: testAPIKey, Endpoint: pd.EUEndpoint},
+		},
+		{
+			name:            "PDI with account uses the account",
+			localObjects:    []client.Object{testPDISecret(), testAccountSecret(), testPagerDutyAccount()},
+			pdi:             testPagerDutyIntegrationWithAccount(),
+			expectAccount:   pd.Account{APIKey: testAccountAPIKey, Endpoint: pd.EUEndpoint, RequestsPerSecond: 4},
+			expectPDAccount: true,
+		},
+		{
+			name:         "Account not found",
+			localObjects: []client.Object{testPDISecret(), testAccountSecret()},
+			pdi:          testPagerDutyIntegrationWithAccount(),
+			expectErr:    errPagerDutyAccountNotFound,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			mocks := setupDefaultMocks(t, test.localObjects)
+
+			account, pdAccount, err := loadAccount(context.TODO(), mocks.fakeKubeClient, test.pdi)
+			if test.expectErr != nil {
+				assert.ErrorIs(t, err, test.expectErr)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, test.expectAccount, account)
+			assert.Equal(t, test.expectPDAccount, pdAccount != nil)
+		})
+	}
+}
+
+func TestLoadAccount_SecretMissing(t *testing.T) {
+	mocks := setupDefaultMocks(t, []client.Object{testPDISecret(), testPagerDutyAccount()})
+
+	_, _, err := loadAccount(context.TODO(), mocks.fakeKubeClient, testPagerDutyIntegrationWithAccount())
+	assert.Error(t, err)
+	assert.NotErrorIs(t, err, errPagerDutyAccountNotFound)
+}
+
+func TestLoadCredentials(t *testing.T) {
+	tests := []struct {
+		name          string
+		data          map[string][]byte
+		expectAccount pd.Account
+		expectErr     bool
+	}{
+		{
+			name:          "API key",
+			data:          map[string][]byte{config.PagerDutyAPISecretKey: []byte(testAPIKey)},
+			expectAccount: pd.Account{APIKey: testAPIKey},
+		},
+		{
+			name: "OAuth client credentials",
+			data: map[string][]byte{
+				config.PagerDutyOAuthClientIDKey:     []byte("client-id"),
+				config.PagerDutyOAuthClientSecretKey: []byte("client-secret"),
+				config.PagerDutyOAuthScopeKey:        []byte("as_account-us.example services.write"),
+				// the client credentials take precedence
+				config.PagerDutyAPISecretKey: []byte(testAPIKey),
+			},
+			expectAccount: pd.Account{OAuth: &pd.OAuthCredentials{
+				ClientID:     "client-id",
+				ClientSecret: "client-secret",
+				Scope:        "as_account-us.example services.write",
+			}},
+		},
+		{
+			name: "OAuth client secret missing",
+			data: map[string][]byte{
+				config.PagerDutyOAuthClientIDKey: []byte("client-id"),
+				config.PagerDutyOAuthScopeKey:    []byte("as_account-us.example services.write"),
+			},
+			expectErr: true,
+		},
+		{
+			name:      "API key empty",
+			data:      map[string][]byte{config.PagerDutyAPISecretKey: {}},
+			expectErr: true,
+		},
+		{
+			name:      "No credentials",
+			data:      map[string][]byte{},
+			expectErr: true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			secret := testPDISecret()
+			secret.Data = test.data
+			mocks := setupDefaultMocks(t, []client.Object{secret})
+
+			account, err := loadCredentials(context.TODO(), mocks.fakeKubeClient, testPagerDutyIntegration().Spec.PagerdutyApiKeySecretRef)
+			if test.expectErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, test.expectAccount, account)
+		})
+	}
+}
+
+func TestWithAccountDefaults(t *testing.T) {
+	account := testPagerDutyAccount()
+	account.Spec.Defaults = &pagerdutyv1alpha1.PagerDutyAccountDefaults{
+		AcknowledgeTimeout: 60,
+		ResolveTimeout:     120,
+		AlertGroupingParameters: &pagerdutyv1alpha1.AlertGroupingParametersSpec{
+			Type: "intelligent",
+		},
+	}
+
+	// the PDI's own settings win
+	pdi := testPagerDutyIntegrationWithAccount()
+	assert.Equal(t, pdi, withAccountDefaults(pdi, account))
+
+	unset := testPagerDutyIntegrationWithAccount()
+	unset.Spec.AcknowledgeTimeout = 0
+	unset.Spec.ResolveTimeout = 0
+	unset.Spec.AlertGroupingParameters = nil
+	defaulted := withAccountDefaults(unset, account)
+	assert.Equal(t, uint(60), defaulted.Spec.AcknowledgeTimeout)
+	assert.Equal(t, uint(120), defaulted.Spec.ResolveTimeout)
+	assert.Equal(t, account.Spec.Defaults.AlertGroupingParameters, defaulted.Spec.AlertGroupingParameters)
+	// the cached PDI isn't changed
+	assert.Zero(t, unset.Spec.AcknowledgeTimeout)
+	assert.Nil(t, unset.Spec.AlertGroupingParameters)
+
+	// nothing to default without an account
+	assert.Same(t, unset, withAccountDefaults(unset, nil))
+}
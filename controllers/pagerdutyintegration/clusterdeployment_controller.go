@@ -0,0 +1,358 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pagerdutyintegration
+
+import (
+	"context"
+	"time"
+
+	"github.com/go-logr/logr"
+	hivev1 "github.com/openshift/hive/apis/hive/v1"
+	pagerdutyv1alpha1 "github.com/openshift/pagerduty-operator/api/v1alpha1"
+	"github.com/openshift/pagerduty-operator/config"
+	"github.com/openshift/pagerduty-operator/pkg/localmetrics"
+	pd "github.com/openshift/pagerduty-operator/pkg/pagerduty"
+	"github.com/openshift/pagerduty-operator/pkg/utils"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/events"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+const clusterDeploymentControllerName = "clusterdeployment"
+
+// ClusterDeploymentReconciler reconciles a single ClusterDeployment against each
+// PagerDutyIntegration that selects it, or that still has a finalizer on it
+type ClusterDeploymentReconciler struct {
+	client.Client
+	Scheme    *runtime.Scheme
+	IsFedramp bool
+	Recorder  events.EventRecorder
+
+	// DriftCheckInterval is how often each PD service is compared with its desired
+	// settings. Drift checks are disabled when it is zero.
+	DriftCheckInterval time.Duration
+
+	// Results receives the outcome of each (PagerDutyIntegration, ClusterDeployment)
+	// pair, for the PagerDutyIntegration status
+	Results *ClusterDeploymentResults
+
+	// MaxConcurrentReconciles is how many ClusterDeployments are reconciled in parallel.
+	// Their PD API calls share the rate limit of each API key.
+	MaxConcurrentReconciles int
+
+	// KeyValidator checks each API key with PD before it is used, shared with the
+	// PagerDutyIntegrationReconciler
+	KeyValidator *pd.APIKeyValidator
+
+	// Selectors caches the ClusterDeployment selector of each PagerDutyIntegration, shared
+	// with the PagerDutyIntegrationReconciler which forgets deleted PagerDutyIntegrations
+	Selectors *SelectorCache
+
+	reqLogger logr.Logger
+	pdclient  func(account pd.Account, controllerName string) pd.Client
+}
+
+//+kubebuilder:rbac:groups=hive.openshift.io,resources=clusterdeployments;clusterdeployments/finalizers;clusterdeployments/status,verbs=get;list;watch;update;patch
+//+kubebuilder:rbac:groups=hive.openshift.io,resources=syncsets,verbs=get;list;watch;create;update;patch;delete
+//+kubebuilder:rbac:groups=hiveinternal.openshift.io,resources=clustersyncs,verbs=get;list;watch
+//+kubebuilder:rbac:groups="",resources=configmaps;secrets,verbs=*
+
+// Reconcile creates, updates and deletes the PD services of a ClusterDeployment, one
+// PagerDutyIntegration at a time. PagerDutyIntegrations that are being deleted are
+// cleaned up here as well, the PagerDutyIntegration controller only waits for it.
+func (r *ClusterDeploymentReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	start := time.Now()
+
+	r = r.forRequest(req)
+	r.reqLogger.Info("Reconciling ClusterDeployment")
+
+	defer func() {
+		dur := time.Since(start)
+		localmetrics.SetReconcileDuration(clusterDeploymentControllerName, dur.Seconds())
+		r.reqLogger.WithValues("Duration", dur).Info("Reconcile complete")
+	}()
+
+	cd := &hivev1.ClusterDeployment{}
+	if err := r.Get(ctx, req.NamespacedName, cd); err != nil {
+		if errors.IsNotFound(err) {
+			r.Results.forgetClusterDeployment(req.NamespacedName)
+			return r.doNotRequeue()
+		}
+		return r.requeueOnErr(err)
+	}
+
+	pdiList := &pagerdutyv1alpha1.PagerDutyIntegrationList{}
+	if err := r.List(ctx, pdiList); err != nil {
+		return r.requeueOnErr(err)
+	}
+
+	var (
+		reconcileErrors pdiReconcileErrors
+		// requeue is set when a PD service operation is waiting for PD, or for the next drift check
+		requeue time.Duration
+	)
+	for i := range pdiList.Items {
+		pdi := &pdiList.Items[i]
+
+		isMatching := r.Selectors.matches(pdi, cd, r.reqLogger)
+		if !isMatching && !utils.HasFinalizer(cd, config.PagerDutyFinalizerPrefix+pdi.Name) {
+			continue
+		}
+
+		// The PDI controller adds its finalizer first, so that PD services are never
+		// created for a PDI that could disappear without cleaning them up
+		if !utils.HasFinalizer(pdi, config.PagerDutyIntegrationFinalizer) {
+			continue
+		}
+
+		// A quarantined ClusterDeployment failed too often, leave it alone until its
+		// backoff expires rather than retrying it on every event
+		if remaining, quarantined := r.Results.quarantined(pdi, cd); quarantined {
+			r.reqLogger.Info("Skipping quarantined ClusterDeployment", "PagerDutyIntegration", pdi.Name, "RetryIn", remaining)
+			requeue = minRequeue(requeue, remaining)
+			continue
+		}
+
+		err := r.reconcilePagerDutyIntegration(ctx, pdi, cd, isMatching)
+		if inProgress, ok := asOperationInProgress(err); ok {
+			requeue = minRequeue(requeue, inProgress.requeueAfter)
+			err = nil
+		}
+		quarantine := r.Results.record(pdi, cd, err)
+		if err != nil {
+			localmetrics.AddMetricPagerDutyReconcileError(pdi.Name, pd.ErrorReason(err))
+			if quarantine > 0 {
+				// the backoff of the quarantine replaces the rate limited requeue of the error
+				r.reqLogger.Error(err, "ClusterDeployment keeps failing, quarantining it", "PagerDutyIntegration", pdi.Name, "RetryIn", quarantine)
+				requeue = minRequeue(requeue, quarantine)
+				continue
+			}
+			reconcileErrors = append(reconcileErrors, err)
+			continue
+		}
+
+		if isMatching && cd.DeletionTimestamp == nil && pdi.DeletionTimestamp == nil && r.DriftCheckInterval > 0 {
+			// come back for the next drift check
+			requeue = minRequeue(requeue, r.DriftCheckInterval)
+		}
+	}
+
+	if len(reconcileErrors) > 0 {
+		return r.requeueOnErr(reconcileErrors)
+	}
+	if requeue > 0 {
+		return r.requeueAfter(requeue)
+	}
+
+	return r.doNotRequeue()
+}
+
+// forRequest returns a copy of the reconciler for a single request, so that concurrent
+// reconciles don't share the request logger
+func (r *ClusterDeploymentReconciler) forRequest(req ctrl.Request) *ClusterDeploymentReconciler {
+	rr := *r
+	if rr.pdclient == nil {
+		rr.pdclient = pd.NewClient
+	}
+	rr.reqLogger = log.WithValues("Request.Namespace", req.Namespace, "Request.Name", req.Name)
+	return &rr
+}
+
+// reconcilePagerDutyIntegration brings the PD service of cd for pdi to its desired
+// state, deleting it when either of them is being deleted or pdi no longer selects cd.
+// The service is handed over instead when another PagerDutyIntegration selects cd.
+func (r *ClusterDeploymentReconciler) reconcilePagerDutyIntegration(ctx context.Context, pdi *pagerdutyv1alpha1.PagerDutyIntegration, cd *hivev1.ClusterDeployment, isMatching bool) error {
+	account, pdAccount, err := loadAccount(ctx, r.Client, pdi)
+	if err != nil {
+		r.reqLogger.Error(err, "Failed to load PagerDuty API key of PagerDutyIntegration CR", "PagerDutyIntegration", pdi.Name)
+		return err
+	}
+	pdClient := r.pdclient(account, clusterDeploymentControllerName)
+	if err := r.KeyValidator.Validate(ctx, pdClient, account); err != nil {
+		r.reqLogger.Error(err, "PagerDuty API key of PagerDutyIntegration CR can't be used", "PagerDutyIntegration", pdi.Name)
+		return err
+	}
+	// the PagerDutyAccount fills in the service settings the PDI leaves unset
+	pdi = withAccountDefaults(pdi, pdAccount)
+
+	// The PagerDutyIntegration controller reports service templates that don't parse. The
+	// PD service is still cleaned up and handed over, which doesn't need its name.
+	templateErr := pd.ValidateServiceTemplates(pdi.Spec.ServiceNameTemplate, pdi.Spec.ServiceDescriptionTemplate)
+	if templateErr != nil {
+		pdi = pdi.DeepCopy()
+		pdi.Spec.ServiceNameTemplate, pdi.Spec.ServiceDescriptionTemplate = "", ""
+	}
+
+	if pdi.DeletionTimestamp != nil || cd.DeletionTimestamp != nil {
+		return r.handleDelete(ctx, pdClient, pdi, cd)
+	}
+	if !isMatching {
+		// Another PagerDutyIntegration selecting the ClusterDeployment takes the PagerDuty service over
+		if handedOff, err := r.handleHandoff(ctx, pdi, cd); handedOff || err != nil {
+			return err
+		}
+		// It's not a matched ClusterDeployment, delete the PagerDuty service because it shouldn't exist
+		r.reqLogger.Info("cleaning up as the ClusterDeployment has a finalizer but no matching label", "PagerDutyIntegration", pdi.Name)
+		return r.handleDelete(ctx, pdClient, pdi, cd)
+	}
+	if templateErr != nil {
+		r.reqLogger.Info("Skipping ClusterDeployment until the service templates of the PagerDutyIntegration are fixed", "PagerDutyIntegration", pdi.Name, "Reason", templateErr.Error())
+		return nil
+	}
+
+	var reconcileErrors pdiReconcileErrors
+	if err := r.handleCreate(ctx, pdClient, pdi, cd); err != nil {
+		reconcileErrors = append(reconcileErrors, err)
+	}
+
+	// update alert grouping if necessary
+	if err := r.handleUpdate(ctx, pdClient, pdi, cd); err != nil {
+		reconcileErrors = append(reconcileErrors, err)
+	}
+
+	// Do nothing if the orchestration is not enabled and leave it as default for now
+	if pdi.Spec.ServiceOrchestration.Enabled {
+		if err := r.handleServiceOrchestration(ctx, pdClient, pdi, cd); err != nil {
+			reconcileErrors = append(reconcileErrors, err)
+		}
+	}
+
+	// inProgress is set while the PD service waits to be disabled, which isn't a failure
+	var inProgress error
+	if err := r.handleLimitedSupport(ctx, pdClient, pdi, cd); err != nil {
+		if _, ok := asOperationInProgress(err); ok {
+			inProgress = err
+		} else {
+			reconcileErrors = append(reconcileErrors, err)
+		}
+	}
+
+	if err := r.handleDriftCheck(ctx, pdClient, pdi, cd); err != nil {
+		reconcileErrors = append(reconcileErrors, err)
+	}
+
+	if len(reconcileErrors) > 0 {
+		return reconcileErrors
+	}
+	return inProgress
+}
+
+func (r *ClusterDeploymentReconciler) doNotRequeue() (reconcile.Result, error) {
+	return reconcile.Result{}, nil
+}
+
+func (r *ClusterDeploymentReconciler) requeueOnErr(err error) (reconcile.Result, error) {
+	// PD told us when to come back, retrying any earlier only hits the rate limit again
+	if retryAfter, ok := pd.RetryAfter(err); ok {
+		r.reqLogger.Info("PD API rate limit hit, requeuing", "RetryAfter", retryAfter, "Error", err.Error())
+		return r.requeueAfter(retryAfter)
+	}
+	return reconcile.Result{}, err
+}
+
+func (r *ClusterDeploymentReconciler) requeueAfter(t time.Duration) (reconcile.Result, error) {
+	return reconcile.Result{RequeueAfter: t}, nil
+}
+
+// SetupWithManager sets up the controller with the Manager.
+// Changes to a PagerDutyIntegration are fanned out to the ClusterDeployments it selects or
+// still has a finalizer on, and SyncSets, PagerDutyServices and Secrets owned by a
+// ClusterDeployment only reconcile that ClusterDeployment.
+func (r *ClusterDeploymentReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewControllerManagedBy(mgr).
+		Named(clusterDeploymentControllerName).
+		WithOptions(controller.Options{MaxConcurrentReconciles: r.MaxConcurrentReconciles}).
+		For(&hivev1.ClusterDeployment{}, builder.WithPredicates(clusterDeploymentChangedPredicate(clusterDeploymentControllerName))).
+		Watches(&pagerdutyv1alpha1.PagerDutyIntegration{}, &enqueueRequestForPagerDutyIntegration{
+			Client:    mgr.GetClient(),
+			selectors: r.Selectors,
+		}, builder.WithPredicates(predicate.Or[client.Object](predicate.GenerationChangedPredicate{}, finalizersChangedPredicate))).
+		Watches(&hivev1.SyncSet{}, handler.EnqueueRequestForOwner(mgr.GetScheme(), mgr.GetRESTMapper(), &hivev1.ClusterDeployment{})).
+		// Drift checks only update the PagerDutyService status, which shouldn't trigger another reconcile
+		Watches(&pagerdutyv1alpha1.PagerDutyService{}, handler.EnqueueRequestForOwner(mgr.GetScheme(), mgr.GetRESTMapper(), &hivev1.ClusterDeployment{}),
+			builder.WithPredicates(predicate.GenerationChangedPredicate{})).
+		Watches(&corev1.Secret{}, handler.EnqueueRequestForOwner(mgr.GetScheme(), mgr.GetRESTMapper(), &hivev1.ClusterDeployment{})).
+		Watches(&corev1.ConfigMap{}, handler.EnqueueRequestsFromMapFunc(r.clusterDeploymentsForConfigMap)).
+		Watches(&corev1.Secret{}, handler.EnqueueRequestsFromMapFunc(r.clusterDeploymentsForAPIKeySecret)).
+		Watches(&pagerdutyv1alpha1.PagerDutyAccount{}, handler.EnqueueRequestsFromMapFunc(r.clusterDeploymentsForPagerDutyAccount),
+			builder.WithPredicates(predicate.GenerationChangedPredicate{})).
+		Complete(r)
+}
+
+// clusterDeploymentsForPagerDutyAccount fans a PagerDutyAccount change out to the
+// ClusterDeployments of the PagerDutyIntegrations using it, quarantined or not, as their
+// credentials, endpoint or default settings may have changed
+func (r *ClusterDeploymentReconciler) clusterDeploymentsForPagerDutyAccount(ctx context.Context, obj client.Object) []reconcile.Request {
+	pdiList, err := listPagerDutyIntegrationsForAccount(ctx, r.Client, obj.GetName())
+	if err != nil {
+		log.Error(err, "could not list PagerDutyIntegrations")
+		return nil
+	}
+
+	pdiHandler := &enqueueRequestForPagerDutyIntegration{Client: r.Client, selectors: r.Selectors}
+	reqs := []reconcile.Request{}
+	for i := range pdiList.Items {
+		pdi := &pdiList.Items[i]
+		r.Results.liftQuarantine(types.NamespacedName{Namespace: pdi.Namespace, Name: pdi.Name})
+		reqs = append(reqs, pdiHandler.toRequests(ctx, pdi)...)
+	}
+	return reqs
+}
+
+// clusterDeploymentsForConfigMap fans a ConfigMap event out to the ClusterDeployments
+// of the PagerDutyIntegrations it concerns
+func (r *ClusterDeploymentReconciler) clusterDeploymentsForConfigMap(ctx context.Context, obj client.Object) []reconcile.Request {
+	pdiHandler := &enqueueRequestForPagerDutyIntegration{Client: r.Client, selectors: r.Selectors}
+
+	reqs := []reconcile.Request{}
+	for _, pdiReq := range (&enqueueRequestForConfigMap{Client: r.Client}).toRequests(ctx, obj) {
+		pdi := &pagerdutyv1alpha1.PagerDutyIntegration{}
+		if err := r.Get(ctx, pdiReq.NamespacedName, pdi); err != nil {
+			continue
+		}
+		reqs = append(reqs, pdiHandler.toRequests(ctx, pdi)...)
+	}
+	return reqs
+}
+
+// clusterDeploymentsForAPIKeySecret retries the ClusterDeployments that failed against the
+// PagerDutyIntegrations using the Secret as API key, quarantined or not, as a fixed or
+// rotated key may well be what they were waiting for
+func (r *ClusterDeploymentReconciler) clusterDeploymentsForAPIKeySecret(ctx context.Context, obj client.Object) []reconcile.Request {
+	pdiList, err := listPagerDutyIntegrationsForSecret(ctx, r.Client, types.NamespacedName{Namespace: obj.GetNamespace(), Name: obj.GetName()})
+	if err != nil {
+		log.Error(err, "could not list PagerDutyIntegrations")
+		return nil
+	}
+
+	reqs := []reconcile.Request{}
+	for _, pdi := range pdiList.Items {
+		for _, cdKey := range r.Results.liftQuarantine(types.NamespacedName{Namespace: pdi.Namespace, Name: pdi.Name}) {
+			reqs = append(reqs, reconcile.Request{NamespacedName: cdKey})
+		}
+	}
+	return reqs
+}
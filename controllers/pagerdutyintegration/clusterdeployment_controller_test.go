@@ -0,0 +1,576 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pagerdutyintegration
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	hiveapis "github.com/openshift/hive/apis"
+	pagerdutyapi "github.com/openshift/pagerduty-operator/api"
+	pagerdutyv1alpha1 "github.com/openshift/pagerduty-operator/api/v1alpha1"
+	"github.com/openshift/pagerduty-operator/config"
+	pd "github.com/openshift/pagerduty-operator/pkg/pagerduty"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/util/workqueue"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+// testFinalizedPagerDutyIntegration returns the test PagerDutyIntegration once the
+// PagerDutyIntegration controller added its finalizer
+func testFinalizedPagerDutyIntegration(isDeleting bool) *pagerdutyv1alpha1.PagerDutyIntegration {
+	pdi := testPagerDutyIntegration()
+	pdi.SetFinalizers([]string{config.PagerDutyIntegrationFinalizer})
+	if isDeleting {
+		now := metav1.Now()
+		pdi.DeletionTimestamp = &now
+	}
+	return pdi
+}
+
+func TestReconcileClusterDeployment(t *testing.T) {
+	assert.Nil(t, hiveapis.AddToScheme(scheme.Scheme))
+	assert.Nil(t, pagerdutyapi.AddToScheme(scheme.Scheme))
+
+	otherClusterDeployment := testClusterDeployment(true, true, false, false, false, false, false)
+	otherClusterDeployment.Name = "otherCluster"
+
+	tests := []struct {
+		name            string
+		localObjects    []client.Object
+		setupPDMock     func(*pd.MockClientMockRecorder)
+		expectErr       bool
+		expectFinalizer bool
+		expectFailures  int
+	}{
+		{
+			name: "Test Only The Requested ClusterDeployment Is Reconciled",
+			localObjects: []client.Object{
+				testClusterDeployment(true, true, true, false, false, false, false),
+				otherClusterDeployment,
+				testPDISecret(),
+				testFinalizedPagerDutyIntegration(false),
+			},
+			setupPDMock: func(r *pd.MockClientMockRecorder) {
+				r.CreateService(gomock.Any(), gomock.Any()).Return(testIntegrationID, nil).Times(1)
+				r.GetIntegrationKey(gomock.Any(), gomock.Any()).Return(testIntegrationID, nil).Times(1)
+			},
+			expectFinalizer: true,
+		},
+		{
+			name: "Test PagerDutyIntegration Without Finalizer Is Skipped",
+			localObjects: []client.Object{
+				testClusterDeployment(true, true, false, false, false, false, false),
+				testPDISecret(),
+				testPagerDutyIntegration(),
+			},
+			setupPDMock: func(r *pd.MockClientMockRecorder) {
+				r.CreateService(gomock.Any(), gomock.Any()).Times(0)
+			},
+		},
+		{
+			name: "Test PagerDutyIntegration Being Deleted",
+			localObjects: []client.Object{
+				testClusterDeployment(true, true, true, false, false, false, false),
+				testPDISecret(),
+				testFinalizedPagerDutyIntegration(true),
+				testCDPagerDutyService(false, false, false, true),
+				testCDSyncSet(),
+				testCDSecret(),
+			},
+			setupPDMock: func(r *pd.MockClientMockRecorder) {
+				r.GetService(gomock.Any(), gomock.Any()).Return(nil, nil).Times(1)
+				r.ResolvePendingIncidents(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil).Times(1)
+				r.CountUnresolvedIncidents(gomock.Any(), gomock.Any()).Return(0, nil).Times(1)
+				r.DeleteService(gomock.Any(), gomock.Any()).Return(nil).Times(1)
+			},
+		},
+		{
+			name: "Test ClusterDeployment No Longer Selected",
+			localObjects: []client.Object{
+				testClusterDeployment(true, false, true, false, false, false, false),
+				testPDISecret(),
+				testFinalizedPagerDutyIntegration(false),
+				testCDPagerDutyService(false, false, false, true),
+				testCDSyncSet(),
+				testCDSecret(),
+			},
+			setupPDMock: func(r *pd.MockClientMockRecorder) {
+				r.GetService(gomock.Any(), gomock.Any()).Return(nil, nil).Times(1)
+				r.ResolvePendingIncidents(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil).Times(1)
+				r.CountUnresolvedIncidents(gomock.Any(), gomock.Any()).Return(0, nil).Times(1)
+				r.DeleteService(gomock.Any(), gomock.Any()).Return(nil).Times(1)
+			},
+		},
+		{
+			name: "Test Missing API Key Is Recorded",
+			localObjects: []client.Object{
+				testClusterDeployment(true, true, false, false, false, false, false),
+				testFinalizedPagerDutyIntegration(false),
+			},
+			setupPDMock: func(r *pd.MockClientMockRecorder) {
+				r.CreateService(gomock.Any(), gomock.Any()).Times(0)
+			},
+			expectErr:      true,
+			expectFailures: 1,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			mocks := setupDefaultMocks(t, test.localObjects)
+			test.setupPDMock(mocks.mockPDClient.EXPECT())
+			defer mocks.mockCtrl.Finish()
+
+			rcd := newTestReconciler(mocks).cd
+			_, err := rcd.Reconcile(context.TODO(), reconcile.Request{
+				NamespacedName: types.NamespacedName{Namespace: testNamespace, Name: testClusterName},
+			})
+			if test.expectErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+			assert.Equal(t, test.expectFinalizer, verifyFinalizer(mocks.fakeKubeClient, &ClusterDeploymentEntry{name: testClusterName}))
+			assert.True(t, verifyNoFinalizer(mocks.fakeKubeClient, &ClusterDeploymentEntry{name: otherClusterDeployment.Name}))
+			assert.Len(t, rcd.Results.failures(testPagerDutyIntegration()), test.expectFailures)
+		})
+	}
+}
+
+func TestReconcileClusterDeploymentEndpoint(t *testing.T) {
+	assert.Nil(t, hiveapis.AddToScheme(scheme.Scheme))
+	assert.Nil(t, pagerdutyapi.AddToScheme(scheme.Scheme))
+
+	pdi := testFinalizedPagerDutyIntegration(false)
+	pdi.Spec.Endpoint = &pagerdutyv1alpha1.PagerDutyEndpoint{Region: pagerdutyv1alpha1.PagerDutyRegionEU}
+
+	mocks := setupDefaultMocks(t, []client.Object{
+		testClusterDeployment(true, true, true, false, false, false, false),
+		testPDISecret(),
+		pdi,
+	})
+	r := mocks.mockPDClient.EXPECT()
+	r.CreateService(gomock.Any(), gomock.Any()).Return(testIntegrationID, nil).Times(1)
+	r.GetIntegrationKey(gomock.Any(), gomock.Any()).Return(testIntegrationID, nil).Times(1)
+	defer mocks.mockCtrl.Finish()
+
+	var endpoints []pd.Endpoint
+	var controllers []string
+	rcd := newTestReconciler(mocks).cd
+	rcd.pdclient = func(account pd.Account, controller string) pd.Client {
+		endpoints = append(endpoints, account.Endpoint)
+		controllers = append(controllers, controller)
+		return mocks.mockPDClient
+	}
+	_, err := rcd.Reconcile(context.TODO(), reconcile.Request{
+		NamespacedName: types.NamespacedName{Namespace: testNamespace, Name: testClusterName},
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, []pd.Endpoint{pd.EUEndpoint}, endpoints)
+	// the PD API calls are labelled with the controller making them
+	assert.Equal(t, []string{clusterDeploymentControllerName}, controllers)
+}
+
+func TestReconcileClusterDeploymentOverrides(t *testing.T) {
+	assert.Nil(t, hiveapis.AddToScheme(scheme.Scheme))
+	assert.Nil(t, pagerdutyapi.AddToScheme(scheme.Scheme))
+
+	cd := testClusterDeployment(true, true, true, false, false, false, false)
+	cd.Annotations[config.ResolveTimeoutOverrideAnnotation] = "600"
+	cd.Annotations[config.AcknowledgeTimeoutOverrideAnnotation] = "soon"
+
+	mocks := setupDefaultMocks(t, []client.Object{
+		cd,
+		testPDISecret(),
+		testFinalizedPagerDutyIntegration(false),
+		testCDPagerDutyService(false, false, false, true),
+		testCDSyncSet(),
+		testCDSecret(),
+	})
+	mocks.mockPDClient.EXPECT().UpdateServiceSettings(gomock.Any(), gomock.Any()).Times(1).DoAndReturn(
+		func(_ context.Context, data *pd.Data) error {
+			assert.Equal(t, uint(600), data.ResolveTimeout)
+			assert.Equal(t, uint(testAcknowledgeTimeout), data.AcknowledgeTimeOut)
+			return nil
+		})
+	defer mocks.mockCtrl.Finish()
+
+	recorder := &objectEventRecorder{}
+	rcd := newTestReconciler(mocks).cd
+	rcd.Recorder = recorder
+	// the second reconcile finds the overrides applied and the rejection already reported
+	for range 2 {
+		_, err := rcd.Reconcile(context.TODO(), reconcile.Request{
+			NamespacedName: types.NamespacedName{Namespace: testNamespace, Name: testClusterName},
+		})
+		assert.NoError(t, err)
+	}
+
+	var rejections []string
+	for _, event := range recorder.events {
+		if strings.HasSuffix(event, reasonOverrideRejected) {
+			rejections = append(rejections, event)
+		}
+	}
+	assert.Equal(t, []string{
+		"ClusterDeployment Warning " + reasonOverrideRejected,
+		"PagerDutyIntegration Warning " + reasonOverrideRejected,
+	}, rejections)
+
+	pdService := &pagerdutyv1alpha1.PagerDutyService{}
+	err := mocks.fakeKubeClient.Get(context.TODO(), types.NamespacedName{Name: config.Name(testServicePrefix, testClusterName, config.PagerDutyServiceSuffix), Namespace: testNamespace}, pdService)
+	assert.NoError(t, err)
+	assert.Equal(t, uint(600), pdService.Spec.ResolveTimeout)
+	assert.Equal(t, []pagerdutyv1alpha1.ClusterOverride{
+		{
+			Annotation: config.AcknowledgeTimeoutOverrideAnnotation,
+			Value:      "soon",
+			Message:    `"soon" is not a timeout, it must be a whole number`,
+		},
+		{
+			Annotation: config.ResolveTimeoutOverrideAnnotation,
+			Value:      "600",
+			Accepted:   true,
+		},
+	}, pdService.Status.Overrides)
+}
+
+func TestReconcileClusterDeploymentAccount(t *testing.T) {
+	assert.Nil(t, hiveapis.AddToScheme(scheme.Scheme))
+	assert.Nil(t, pagerdutyapi.AddToScheme(scheme.Scheme))
+
+	account := testPagerDutyAccount()
+	account.Spec.Defaults = &pagerdutyv1alpha1.PagerDutyAccountDefaults{ResolveTimeout: 600}
+	pdi := testPagerDutyIntegrationWithAccount()
+	pdi.SetFinalizers([]string{config.PagerDutyIntegrationFinalizer})
+	pdi.Spec.ResolveTimeout = 0
+
+	mocks := setupDefaultMocks(t, []client.Object{
+		testClusterDeployment(true, true, true, false, false, false, false),
+		testAccountSecret(),
+		account,
+		pdi,
+	})
+	r := mocks.mockPDClient.EXPECT()
+	var created *pd.Data
+	r.CreateService(gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, data *pd.Data) (string, error) {
+		created = data
+		return testIntegrationID, nil
+	}).Times(1)
+	r.GetIntegrationKey(gomock.Any(), gomock.Any()).Return(testIntegrationID, nil).Times(1)
+	defer mocks.mockCtrl.Finish()
+
+	var accounts []pd.Account
+	rcd := newTestReconciler(mocks).cd
+	rcd.pdclient = func(account pd.Account, _ string) pd.Client {
+		accounts = append(accounts, account)
+		return mocks.mockPDClient
+	}
+	_, err := rcd.Reconcile(context.TODO(), reconcile.Request{
+		NamespacedName: types.NamespacedName{Namespace: testNamespace, Name: testClusterName},
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, []pd.Account{{APIKey: testAccountAPIKey, Endpoint: pd.EUEndpoint, RequestsPerSecond: 4}}, accounts)
+	if assert.NotNil(t, created) {
+		// the account default fills in the setting the PDI leaves unset
+		assert.Equal(t, uint(600), created.ResolveTimeout)
+		assert.Equal(t, uint(testAcknowledgeTimeout), created.AcknowledgeTimeOut)
+	}
+}
+
+func TestClusterDeploymentsForPagerDutyAccount(t *testing.T) {
+	assert.Nil(t, hiveapis.AddToScheme(scheme.Scheme))
+	assert.Nil(t, pagerdutyapi.AddToScheme(scheme.Scheme))
+
+	pdi := testPagerDutyIntegrationWithAccount()
+	pdi.SetFinalizers([]string{config.PagerDutyIntegrationFinalizer})
+	mocks := setupDefaultMocks(t, []client.Object{
+		testClusterDeployment(true, true, true, false, false, false, false),
+		testAccountSecret(),
+		testPagerDutyAccount(),
+		pdi,
+	})
+	defer mocks.mockCtrl.Finish()
+
+	rcd := newTestReconciler(mocks).cd
+	reqs := rcd.clusterDeploymentsForPagerDutyAccount(context.TODO(), testPagerDutyAccount())
+	assert.Equal(t, []reconcile.Request{{NamespacedName: types.NamespacedName{Namespace: testNamespace, Name: testClusterName}}}, reqs)
+
+	unused := testPagerDutyAccount()
+	unused.Name = "unused"
+	assert.Empty(t, rcd.clusterDeploymentsForPagerDutyAccount(context.TODO(), unused))
+}
+
+func TestReconcilePagerDutyIntegrationDeletion(t *testing.T) {
+	assert.Nil(t, hiveapis.AddToScheme(scheme.Scheme))
+	assert.Nil(t, pagerdutyapi.AddToScheme(scheme.Scheme))
+
+	mocks := setupDefaultMocks(t, []client.Object{
+		testClusterDeployment(true, true, true, false, false, false, false),
+		testPDISecret(),
+		testFinalizedPagerDutyIntegration(true),
+		testCDPagerDutyService(false, false, false, true),
+		testCDSyncSet(),
+		testCDSecret(),
+	})
+	r := mocks.mockPDClient.EXPECT()
+	r.GetService(gomock.Any(), gomock.Any()).Return(nil, nil).Times(1)
+	r.ResolvePendingIncidents(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil).Times(1)
+	r.CountUnresolvedIncidents(gomock.Any(), gomock.Any()).Return(0, nil).Times(1)
+	r.DeleteService(gomock.Any(), gomock.Any()).Return(nil).Times(1)
+	defer mocks.mockCtrl.Finish()
+
+	rpdi := newTestReconciler(mocks)
+	pdiRequest := reconcile.Request{
+		NamespacedName: types.NamespacedName{Name: testPagerDutyIntegrationName, Namespace: config.OperatorNamespace},
+	}
+
+	// The PDI waits for the ClusterDeployment controller to delete the PD service
+	_, err := rpdi.pdi.Reconcile(context.TODO(), pdiRequest)
+	assert.NoError(t, err)
+	pdi := &pagerdutyv1alpha1.PagerDutyIntegration{}
+	assert.NoError(t, mocks.fakeKubeClient.Get(context.TODO(), pdiRequest.NamespacedName, pdi))
+
+	// Deleting the PD service releases the ClusterDeployment, then the PDI
+	_, err = rpdi.Reconcile(context.TODO(), pdiRequest)
+	assert.NoError(t, err)
+	assert.True(t, verifyNoFinalizer(mocks.fakeKubeClient, &ClusterDeploymentEntry{name: testClusterName}))
+	err = mocks.fakeKubeClient.Get(context.TODO(), pdiRequest.NamespacedName, pdi)
+	assert.True(t, errors.IsNotFound(err), "PagerDutyIntegration should be gone, got %v", err)
+}
+
+func TestReconcileClusterDeploymentConcurrently(t *testing.T) {
+	assert.Nil(t, hiveapis.AddToScheme(scheme.Scheme))
+	assert.Nil(t, pagerdutyapi.AddToScheme(scheme.Scheme))
+
+	const clusterCount = 8
+	localObjects := []client.Object{testPDISecret(), testFinalizedPagerDutyIntegration(false)}
+	for i := 0; i < clusterCount; i++ {
+		cd := testClusterDeployment(true, true, true, false, false, false, false)
+		cd.Name = fmt.Sprintf("%s-%d", testClusterName, i)
+		localObjects = append(localObjects, cd)
+	}
+
+	mocks := setupDefaultMocks(t, localObjects)
+	r := mocks.mockPDClient.EXPECT()
+	r.CreateService(gomock.Any(), gomock.Any()).Return(testIntegrationID, nil).Times(clusterCount)
+	r.GetIntegrationKey(gomock.Any(), gomock.Any()).Return(testIntegrationID, nil).Times(clusterCount)
+	defer mocks.mockCtrl.Finish()
+
+	// the workers of the controller share a single reconciler
+	rcd := newTestReconciler(mocks).cd
+	var wg sync.WaitGroup
+	errs := make(chan error, clusterCount)
+	for i := 0; i < clusterCount; i++ {
+		wg.Add(1)
+		go func(name string) {
+			defer wg.Done()
+			_, err := rcd.Reconcile(context.TODO(), reconcile.Request{
+				NamespacedName: types.NamespacedName{Namespace: testNamespace, Name: name},
+			})
+			errs <- err
+		}(fmt.Sprintf("%s-%d", testClusterName, i))
+	}
+	wg.Wait()
+	close(errs)
+
+	for err := range errs {
+		assert.NoError(t, err)
+	}
+	for i := 0; i < clusterCount; i++ {
+		pdServiceName := config.Name(testServicePrefix, fmt.Sprintf("%s-%d", testClusterName, i), config.PagerDutyServiceSuffix)
+		err := mocks.fakeKubeClient.Get(context.TODO(), types.NamespacedName{Namespace: testNamespace, Name: pdServiceName}, &pagerdutyv1alpha1.PagerDutyService{})
+		assert.NoError(t, err)
+	}
+}
+
+func TestReconcileClusterDeploymentQuarantine(t *testing.T) {
+	assert.Nil(t, hiveapis.AddToScheme(scheme.Scheme))
+	assert.Nil(t, pagerdutyapi.AddToScheme(scheme.Scheme))
+
+	mocks := setupDefaultMocks(t, []client.Object{
+		testClusterDeployment(true, true, true, false, false, false, false),
+		testPDISecret(),
+		testFinalizedPagerDutyIntegration(false),
+	})
+	r := mocks.mockPDClient.EXPECT()
+	// one call per failure before the quarantine, and one once its backoff expired
+	r.CreateService(gomock.Any(), gomock.Any()).Return("", fmt.Errorf("escalation policy not found")).Times(quarantineThreshold + 1)
+	defer mocks.mockCtrl.Finish()
+
+	rpdi := newTestReconciler(mocks)
+	now := time.Now()
+	rpdi.cd.Results.now = func() time.Time { return now }
+	cdRequest := reconcile.Request{NamespacedName: types.NamespacedName{Namespace: testNamespace, Name: testClusterName}}
+	pdiRequest := reconcile.Request{NamespacedName: types.NamespacedName{Name: testPagerDutyIntegrationName, Namespace: config.OperatorNamespace}}
+
+	// the errors are retried by the controller until the ClusterDeployment is quarantined
+	for i := 1; i < quarantineThreshold; i++ {
+		_, err := rpdi.cd.Reconcile(context.TODO(), cdRequest)
+		assert.Error(t, err)
+	}
+	result, err := rpdi.cd.Reconcile(context.TODO(), cdRequest)
+	assert.NoError(t, err)
+	assert.Equal(t, quarantineBaseBackoff, result.RequeueAfter)
+
+	_, err = rpdi.pdi.Reconcile(context.TODO(), pdiRequest)
+	assert.NoError(t, err)
+	pdi := &pagerdutyv1alpha1.PagerDutyIntegration{}
+	assert.NoError(t, mocks.fakeKubeClient.Get(context.TODO(), pdiRequest.NamespacedName, pdi))
+	assert.Equal(t, int32(1), pdi.Status.FailedClusterDeployments)
+	assert.Equal(t, int32(1), pdi.Status.QuarantinedClusterDeployments)
+	if assert.Len(t, pdi.Status.RecentErrors, 1) {
+		assert.Equal(t, int32(quarantineThreshold), pdi.Status.RecentErrors[0].ConsecutiveFailures)
+		assert.NotNil(t, pdi.Status.RecentErrors[0].QuarantinedUntil)
+	}
+
+	// events for the quarantined ClusterDeployment don't reach PD
+	now = now.Add(quarantineBaseBackoff / 2)
+	result, err = rpdi.cd.Reconcile(context.TODO(), cdRequest)
+	assert.NoError(t, err)
+	assert.Equal(t, quarantineBaseBackoff/2, result.RequeueAfter)
+
+	// once the backoff expired it is retried, and quarantined for longer
+	now = now.Add(quarantineBaseBackoff / 2)
+	result, err = rpdi.cd.Reconcile(context.TODO(), cdRequest)
+	assert.NoError(t, err)
+	assert.Equal(t, 2*quarantineBaseBackoff, result.RequeueAfter)
+}
+
+func TestClusterDeploymentResultsQuarantine(t *testing.T) {
+	pdi := testFinalizedPagerDutyIntegration(false)
+	cd := testClusterDeployment(true, true, true, false, false, false, false)
+
+	tests := []struct {
+		name              string
+		err               error
+		changeGeneration  bool
+		expectQuarantined bool
+	}{
+		{
+			name:              "Test Repeated Failures Quarantine",
+			err:               fmt.Errorf("escalation policy not found"),
+			expectQuarantined: true,
+		},
+		{
+			name:              "Test Changed PagerDutyIntegration Lifts Quarantine",
+			err:               fmt.Errorf("escalation policy not found"),
+			changeGeneration:  true,
+			expectQuarantined: false,
+		},
+		{
+			name:              "Test Rate Limited Errors Don't Quarantine",
+			err:               fmt.Errorf("unable to create service: %w", pd.ErrRateLimited),
+			expectQuarantined: false,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			results := NewClusterDeploymentResults()
+			for i := 0; i < quarantineThreshold; i++ {
+				results.record(pdi, cd, test.err)
+			}
+
+			current := pdi.DeepCopy()
+			if test.changeGeneration {
+				current.Generation++
+			}
+			_, quarantined := results.quarantined(current, cd)
+			assert.Equal(t, test.expectQuarantined, quarantined)
+
+			// a success clears the failure
+			results.record(current, cd, nil)
+			_, quarantined = results.quarantined(current, cd)
+			assert.False(t, quarantined)
+			assert.Empty(t, results.failures(current))
+		})
+	}
+}
+
+func TestClusterDeploymentResultsNotify(t *testing.T) {
+	results := NewClusterDeploymentResults()
+
+	// more PagerDutyIntegrations than any buffer would hold, each notified twice
+	const pdiCount = 2000
+	for i := 0; i < 2*pdiCount; i++ {
+		results.notify(types.NamespacedName{Namespace: config.OperatorNamespace, Name: fmt.Sprintf("pdi-%d", i%pdiCount)})
+	}
+
+	queue := workqueue.NewTypedRateLimitingQueue(workqueue.DefaultTypedControllerRateLimiter[reconcile.Request]())
+	defer queue.ShutDown()
+	assert.NoError(t, results.source().Start(context.TODO(), queue))
+	assert.Equal(t, pdiCount, queue.Len())
+
+	// once the controller started the PagerDutyIntegrations are enqueued right away
+	results.notify(types.NamespacedName{Namespace: config.OperatorNamespace, Name: "pdi-0"})
+	results.notify(types.NamespacedName{Namespace: config.OperatorNamespace, Name: "pdi-new"})
+	assert.Equal(t, pdiCount+1, queue.Len())
+}
+
+func TestClusterDeploymentsForAPIKeySecret(t *testing.T) {
+	pdi := testFinalizedPagerDutyIntegration(false)
+	cd := testClusterDeployment(true, true, true, false, false, false, false)
+
+	mocks := setupDefaultMocks(t, []client.Object{testPDISecret(), pdi, cd})
+	defer mocks.mockCtrl.Finish()
+
+	rpdi := newTestReconciler(mocks)
+	for i := 0; i < quarantineThreshold; i++ {
+		rpdi.cd.Results.record(pdi, cd, pd.ErrUnauthorized)
+	}
+	_, quarantined := rpdi.cd.Results.quarantined(pdi, cd)
+	assert.True(t, quarantined)
+
+	// a change to the API key retries the failed ClusterDeployment right away
+	reqs := rpdi.cd.clusterDeploymentsForAPIKeySecret(context.TODO(), testPDISecret())
+	assert.Equal(t, []reconcile.Request{{NamespacedName: types.NamespacedName{Namespace: cd.Namespace, Name: cd.Name}}}, reqs)
+	_, quarantined = rpdi.cd.Results.quarantined(pdi, cd)
+	assert.False(t, quarantined)
+	if failures := rpdi.cd.Results.failures(pdi); assert.Len(t, failures, 1) {
+		assert.Equal(t, int32(0), failures[0].ConsecutiveFailures)
+	}
+}
+
+func TestQuarantineBackoff(t *testing.T) {
+	tests := []struct {
+		consecutiveFailures int32
+		expectBackoff       time.Duration
+	}{
+		{consecutiveFailures: quarantineThreshold, expectBackoff: quarantineBaseBackoff},
+		{consecutiveFailures: quarantineThreshold + 1, expectBackoff: 2 * quarantineBaseBackoff},
+		{consecutiveFailures: quarantineThreshold + 3, expectBackoff: 8 * quarantineBaseBackoff},
+		{consecutiveFailures: quarantineThreshold + 100, expectBackoff: quarantineMaxBackoff},
+	}
+
+	for _, test := range tests {
+		t.Run(fmt.Sprintf("%d failures", test.consecutiveFailures), func(t *testing.T) {
+			assert.Equal(t, test.expectBackoff, quarantineBackoff(test.consecutiveFailures))
+		})
+	}
+}
@@ -20,6 +20,7 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/client"
 
 	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
 	"k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/types"
 
@@ -34,16 +35,16 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
 )
 
-func (r *PagerDutyIntegrationReconciler) handleCreate(pdclient pd.Client, pdi *pagerdutyv1alpha1.PagerDutyIntegration, cd *hivev1.ClusterDeployment) error {
+func (r *ClusterDeploymentReconciler) handleCreate(ctx context.Context, pdclient pd.Client, pdi *pagerdutyv1alpha1.PagerDutyIntegration, cd *hivev1.ClusterDeployment) error {
 	var (
 		// secretName is the name of the Secret deployed to the target
 		// cluster, and also the name of the SyncSet that causes it to
 		// be deployed.
 		secretName = config.Name(pdi.Spec.ServicePrefix, cd.Name, config.SecretSuffix)
 
-		// configMapName is the name of the ConfigMap containing the
-		// SERVICE_ID and INTEGRATION_ID
-		configMapName = config.Name(pdi.Spec.ServicePrefix, cd.Name, config.ConfigMapSuffix)
+		// pdServiceName is the name of the PagerDutyService containing the
+		// service ID and integration ID
+		pdServiceName = config.Name(pdi.Spec.ServicePrefix, cd.Name, config.PagerDutyServiceSuffix)
 
 		// There can be more than one PagerDutyIntegration that causes
 		// creation of resources for a ClusterDeployment, and each one
@@ -69,74 +70,103 @@ func (r *PagerDutyIntegrationReconciler) handleCreate(pdclient pd.Client, pdi *p
 	if !utils.HasFinalizer(cd, finalizer) {
 		baseToPatch := client.MergeFrom(cd.DeepCopy())
 		utils.AddFinalizer(cd, finalizer)
-		return r.Patch(context.TODO(), cd, baseToPatch)
+		return r.Patch(ctx, cd, baseToPatch)
+	}
+
+	if err := r.migrateLegacyClusterConfig(ctx, pdi, cd); err != nil {
+		r.reqLogger.Error(err, "Error migrating PagerDuty cluster config", "ClusterDeployment.Namespace", cd.Namespace)
+		return err
+	}
+
+	// take the PD service over from the PagerDutyIntegration that selected the cluster before
+	if err := r.adoptPagerDutyService(ctx, pdi, cd, pdServiceName); err != nil {
+		r.reqLogger.Error(err, "Error adopting PagerDuty service", "ClusterDeployment.Namespace", cd.Namespace)
+		return err
 	}
 
 	clusterID := utils.GetClusterID(cd, r.IsFedramp)
-	pdData, err := pd.NewData(pdi, clusterID, cd.Spec.BaseDomain, r.IsFedramp)
+	pdData, err := pd.NewData(pdi, cd, clusterID, r.IsFedramp)
 	if err != nil {
 		return err
 	}
+	// the escalation policy the PDI routes the cluster to, ParseClusterConfig loads the one last applied
+	escalationPolicyID := pdData.EscalationPolicyID
 
 	// load configuration
-	err = pdData.ParseClusterConfig(r.Client, cd.Namespace, configMapName)
+	err = pdData.ParseClusterConfig(ctx, r.Client, cd.Namespace, pdServiceName)
 
 	if err != nil || pdData.ServiceID == "" {
 		// unable to load configuration, therefore create the PD service
 		var createErr error
+		pdData.EscalationPolicyID = escalationPolicyID
 		r.reqLogger.Info("Creating PD service", "ClusterID", pdData.ClusterID, "BaseDomain", pdData.BaseDomain, "ClusterDeployment.Namespace", cd.Namespace)
-		_, createErr = pdclient.CreateService(pdData)
+		_, createErr = pdclient.CreateService(ctx, pdData)
 		if createErr != nil {
 			localmetrics.UpdateMetricPagerDutyCreateFailure(1, clusterID, pdi.Name)
+			r.recordPagerDutyFailure(pdi, cd, reasonServiceCreateFailed, "CreateService", "Failed to create PagerDuty service", createErr)
 			return createErr
 		}
 		localmetrics.UpdateMetricPagerDutyCreateFailure(0, clusterID, pdi.Name)
+		r.recordPagerDutyEvent(pdi, cd, corev1.EventTypeNormal, reasonServiceCreated, "CreateService",
+			"Created PagerDuty service %s with escalation policy %s", pdData.ServiceID, pdData.EscalationPolicyID)
 
-		r.reqLogger.Info("Creating configmap")
+		r.reqLogger.Info("Creating PagerDutyService")
 
-		// save config map
-		newCM := kube.GenerateConfigMap(cd.Namespace, configMapName, pdData.ServiceID, pdData.IntegrationID, pdData.EscalationPolicyID, false, pdData.ServiceOrchestrationEnabled, pdData.ServiceOrchestrationRuleApplied, pdData.AlertGroupingType, pdData.AlertGroupingTimeout)
-		if err = controllerutil.SetControllerReference(cd, newCM, r.Scheme); err != nil {
-			r.reqLogger.Error(err, "Error setting controller reference on configmap")
+		// save PagerDutyService, the new service was created with the settings of the PDI
+		newPDService := kube.GeneratePagerDutyService(cd.Namespace, pdServiceName, cd.Name, pdi)
+		pdData.UpdatePagerDutyServiceSpec(&newPDService.Spec)
+		pdData.RecordAppliedSettings(&newPDService.Spec)
+		if err = controllerutil.SetControllerReference(cd, newPDService, r.Scheme); err != nil {
+			r.reqLogger.Error(err, "Error setting controller reference on PagerDutyService")
 			return err
 		}
-		if err := r.Create(context.TODO(), newCM); err != nil {
+		if err := r.Create(ctx, newPDService); err != nil {
 			if errors.IsAlreadyExists(err) {
-				if updateErr := r.Update(context.TODO(), newCM); updateErr != nil {
-					r.reqLogger.Error(err, "Error updating existing configmap", "Name", configMapName)
-					return err
+				if updateErr := r.recordCreatedService(ctx, pdData, cd.Namespace, pdServiceName); updateErr != nil {
+					r.reqLogger.Error(updateErr, "Error updating existing PagerDutyService", "Name", pdServiceName)
+					return updateErr
 				}
 				return nil
 			}
-			r.reqLogger.Error(err, "Error creating configmap", "Name", configMapName)
+			r.reqLogger.Error(err, "Error creating PagerDutyService", "Name", pdServiceName)
 			return err
 		}
 	}
 
-	// If no value in ConfigMap for EscalationPolicyID set it from pdi.EscalationPolicyID
+	// If no value in PagerDutyService for EscalationPolicyID set it from the PDI
 	if pdData.EscalationPolicyID == "" {
 		// update policy ID from PDI, it is used in next set call
-		pdData.EscalationPolicyID = pdi.Spec.EscalationPolicy
-		if err = pdData.SetClusterConfig(r.Client, cd.Namespace, configMapName); err != nil {
-			r.reqLogger.Error(err, "Error updating PagerDuty cluster config", "Name", configMapName)
+		pdData.EscalationPolicyID = escalationPolicyID
+		if err = pdData.SetClusterConfig(ctx, r.Client, cd.Namespace, pdServiceName); err != nil {
+			r.reqLogger.Error(err, "Error updating PagerDuty cluster config", "Name", pdServiceName)
 			return err
 		}
 	} else {
-		// ConfigMap has a value for EscalationPolicyID
-		// Check if the value is the same EscalationPolicyID as from PDI
-		if pdData.EscalationPolicyID != pdi.Spec.EscalationPolicy {
+		// PagerDutyService has a value for EscalationPolicyID
+		// Check if the value is the same EscalationPolicyID as from PDI. It changes when the
+		// PDI escalation policy or rules change, or when the labels of the CD match another rule
+		if pdData.EscalationPolicyID != escalationPolicyID {
 			r.reqLogger.Info("PDI EscalationPolicy changed, updating service", "ClusterID", pdData.ClusterID, "ServiceID", pdData.ServiceID, "ClusterDeployment.Namespace", cd.Namespace)
 			// update policy ID from PDI, it is used in next update call
-			pdData.EscalationPolicyID = pdi.Spec.EscalationPolicy
-			err := pdclient.UpdateEscalationPolicy(pdData)
+			oldEscalationPolicyID := pdData.EscalationPolicyID
+			pdData.EscalationPolicyID = escalationPolicyID
+			err := pdclient.UpdateEscalationPolicy(ctx, pdData)
 			if err != nil {
+				if pd.IsNotFound(err) {
+					if healed, healErr := r.healPagerDutyService(ctx, pdclient, pdi, cd, pdData); healed || healErr != nil {
+						return healErr
+					}
+				}
 				r.reqLogger.Error(err, "Error updating PagerDuty service", "ClusterID", pdData.ClusterID, "ServiceID", pdData.ServiceID, "ClusterDeployment.Namespace", cd.Namespace)
+				r.recordPagerDutyFailure(pdi, cd, reasonEscalationPolicyUpdateFailed, "UpdateEscalationPolicy", "Failed to update the escalation policy of PagerDuty service "+pdData.ServiceID, err)
 				return err
 			}
+			r.recordPagerDutyEvent(pdi, cd, corev1.EventTypeNormal, reasonEscalationPolicyUpdated, "UpdateEscalationPolicy",
+				"Changed the escalation policy of PagerDuty service %s from %s to %s", pdData.ServiceID, oldEscalationPolicyID, pdData.EscalationPolicyID)
 
-			// Update ConfigMap to reflect the new escalation policy changes
-			if err := pdData.SetClusterConfig(r.Client, cd.Namespace, configMapName); err != nil {
-				r.reqLogger.Error(err, "Error updating PagerDuty cluster config", "Name", configMapName)
+			// Update PagerDutyService to reflect the new escalation policy changes
+			if err := pdData.SetClusterConfig(ctx, r.Client, cd.Namespace, pdServiceName); err != nil {
+				r.reqLogger.Error(err, "Error updating PagerDuty cluster config", "Name", pdServiceName)
 				return err
 			}
 
@@ -149,7 +179,7 @@ func (r *PagerDutyIntegrationReconciler) handleCreate(pdclient pd.Client, pdi *p
 
 	// try to load integration key (secret)
 	sc := &corev1.Secret{}
-	err = r.Get(context.TODO(), types.NamespacedName{Name: secretName, Namespace: cd.Namespace}, sc)
+	err = r.Get(ctx, types.NamespacedName{Name: secretName, Namespace: cd.Namespace}, sc)
 
 	if err == nil {
 		// successfully loaded secret, snag the integration key
@@ -158,13 +188,40 @@ func (r *PagerDutyIntegrationReconciler) handleCreate(pdclient pd.Client, pdi *p
 	} else {
 		// unable to load an integration key, create one.
 		r.reqLogger.Info("pdIntegrationKey not found, creating one", "ClusterID", pdData.ClusterID, "BaseDomain", pdData.BaseDomain, "ClusterDeployment.Namespace", cd.Namespace)
-		pdIntegrationKey, err = pdclient.GetIntegrationKey(pdData)
+		pdIntegrationKey, err = pdclient.GetIntegrationKey(ctx, pdData)
 		if err != nil {
+			if pd.IsNotFound(err) {
+				// the service or the integration was deleted in PagerDuty
+				if healed, healErr := r.healPagerDutyService(ctx, pdclient, pdi, cd, pdData); healed || healErr != nil {
+					return healErr
+				}
+			}
 			// unable to get an integration key
 			return err
 		}
 	}
 
+	return r.ensureSecretAndSyncSet(ctx, pdi, cd, secretName, pdIntegrationKey)
+}
+
+// recordCreatedService records a PD service that was just created in the existing PagerDutyService
+// name, together with the settings it was created with
+func (r *ClusterDeploymentReconciler) recordCreatedService(ctx context.Context, pdData *pd.Data, namespace string, name string) error {
+	pdService := &pagerdutyv1alpha1.PagerDutyService{}
+	if err := r.Get(ctx, types.NamespacedName{Namespace: namespace, Name: name}, pdService); err != nil {
+		return err
+	}
+
+	pdData.UpdatePagerDutyServiceSpec(&pdService.Spec)
+	pdData.RecordAppliedSettings(&pdService.Spec)
+	return r.Update(ctx, pdService)
+}
+
+// ensureSecretAndSyncSet makes sure the Secret holding the integration key and the SyncSet
+// deploying it to the cluster exist and are up to date
+func (r *ClusterDeploymentReconciler) ensureSecretAndSyncSet(ctx context.Context, pdi *pagerdutyv1alpha1.PagerDutyIntegration, cd *hivev1.ClusterDeployment, secretName string, pdIntegrationKey string) error {
+	var err error
+
 	//add secret part
 	secret := kube.GeneratePdSecret(cd.Namespace, secretName, pdIntegrationKey)
 	r.reqLogger.Info("creating pd secret", "ClusterDeployment.Namespace", cd.Namespace)
@@ -173,25 +230,25 @@ func (r *PagerDutyIntegrationReconciler) handleCreate(pdclient pd.Client, pdi *p
 		r.reqLogger.Error(err, "Error setting controller reference on secret", "ClusterDeployment.Namespace", cd.Namespace)
 		return err
 	}
-	if err = r.Create(context.TODO(), secret); err != nil {
+	if err = r.Create(ctx, secret); err != nil {
 		if !errors.IsAlreadyExists(err) {
 			return err
 		}
 
 		r.reqLogger.Info("the pd secret exist, check if pdIntegrationKey is changed or not", "ClusterDeployment.Namespace", cd.Namespace)
 		sc := &corev1.Secret{}
-		err = r.Get(context.TODO(), types.NamespacedName{Name: secret.Name, Namespace: cd.Namespace}, sc)
+		err = r.Get(ctx, types.NamespacedName{Name: secret.Name, Namespace: cd.Namespace}, sc)
 		if err != nil {
 			return nil
 		}
 		if string(sc.Data[config.PagerDutySecretKey]) != pdIntegrationKey {
 			r.reqLogger.Info("pdIntegrationKey is changed, delete the secret first")
-			if err = r.Delete(context.TODO(), secret); err != nil {
+			if err = r.Delete(ctx, secret); err != nil {
 				log.Info("failed to delete existing pd secret")
 				return err
 			}
 			r.reqLogger.Info("creating pd secret", "ClusterDeployment.Namespace", cd.Namespace)
-			if err = r.Create(context.TODO(), secret); err != nil {
+			if err = r.Create(ctx, secret); err != nil {
 				return err
 			}
 		}
@@ -199,7 +256,7 @@ func (r *PagerDutyIntegrationReconciler) handleCreate(pdclient pd.Client, pdi *p
 
 	r.reqLogger.Info("Creating syncset", "ClusterDeployment.Namespace", cd.Namespace)
 	ss := &hivev1.SyncSet{}
-	err = r.Get(context.TODO(), types.NamespacedName{Name: secretName, Namespace: cd.Namespace}, ss)
+	err = r.Get(ctx, types.NamespacedName{Name: secretName, Namespace: cd.Namespace}, ss)
 	if err != nil {
 		r.reqLogger.Info("error finding the old syncset")
 		if !errors.IsNotFound(err) {
@@ -211,7 +268,18 @@ func (r *PagerDutyIntegrationReconciler) handleCreate(pdclient pd.Client, pdi *p
 			r.reqLogger.Error(err, "Error setting controller reference on syncset", "ClusterDeployment.Namespace", cd.Namespace)
 			return err
 		}
-		if err := r.Create(context.TODO(), ss); err != nil {
+		if err := r.Create(ctx, ss); err != nil {
+			return err
+		}
+		return nil
+	}
+
+	expected := kube.GenerateSyncSet(cd.Namespace, cd.Name, secret, pdi)
+	if !equality.Semantic.DeepEqual(ss.Spec.ClusterDeploymentRefs, expected.Spec.ClusterDeploymentRefs) || !equality.Semantic.DeepEqual(ss.Spec.Secrets, expected.Spec.Secrets) {
+		r.reqLogger.Info("Updating syncset", "ClusterDeployment.Namespace", cd.Namespace)
+		ss.Spec.ClusterDeploymentRefs = expected.Spec.ClusterDeploymentRefs
+		ss.Spec.Secrets = expected.Spec.Secrets
+		if err := r.Update(ctx, ss); err != nil {
 			return err
 		}
 	}
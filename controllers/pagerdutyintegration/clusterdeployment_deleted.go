@@ -17,7 +17,6 @@ package pagerdutyintegration
 import (
 	"context"
 	"fmt"
-	"strings"
 
 	hivev1 "github.com/openshift/hive/apis/hive/v1"
 	pagerdutyv1alpha1 "github.com/openshift/pagerduty-operator/api/v1alpha1"
@@ -25,11 +24,13 @@ import (
 	metrics "github.com/openshift/pagerduty-operator/pkg/localmetrics"
 	pd "github.com/openshift/pagerduty-operator/pkg/pagerduty"
 	"github.com/openshift/pagerduty-operator/pkg/utils"
+	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 )
 
-func (r *PagerDutyIntegrationReconciler) handleDelete(pdclient pd.Client, pdi *pagerdutyv1alpha1.PagerDutyIntegration, cd *hivev1.ClusterDeployment) error {
+func (r *ClusterDeploymentReconciler) handleDelete(ctx context.Context, pdclient pd.Client, pdi *pagerdutyv1alpha1.PagerDutyIntegration, cd *hivev1.ClusterDeployment) error {
 	if cd == nil {
 		// nothing to do, bail early
 		return nil
@@ -41,9 +42,9 @@ func (r *PagerDutyIntegrationReconciler) handleDelete(pdclient pd.Client, pdi *p
 		// be deployed.
 		secretName = config.Name(pdi.Spec.ServicePrefix, cd.Name, config.SecretSuffix)
 
-		// configMapName is the name of the ConfigMap containing the
-		// SERVICE_ID and INTEGRATION_ID
-		configMapName = config.Name(pdi.Spec.ServicePrefix, cd.Name, config.ConfigMapSuffix)
+		// pdServiceName is the name of the PagerDutyService containing the
+		// service ID and integration ID
+		pdServiceName = config.Name(pdi.Spec.ServicePrefix, cd.Name, config.PagerDutyServiceSuffix)
 
 		// There can be more than one PagerDutyIntegration that causes
 		// creation of resources for a ClusterDeployment, and each one
@@ -56,8 +57,22 @@ func (r *PagerDutyIntegrationReconciler) handleDelete(pdclient pd.Client, pdi *p
 		return nil
 	}
 
+	// The PagerDuty service was handed over to another PagerDutyIntegration, which deletes it
+	handedOver, err := r.serviceHandedOver(ctx, pdi, cd)
+	if err != nil {
+		return err
+	}
+	if handedOver != nil {
+		return r.releaseClusterDeployment(ctx, pdi, cd, handedOver.Spec.ServiceID, handedOver.Spec.PagerDutyIntegrationRef.Name, handedOver.Name != pdServiceName)
+	}
+
+	// the PagerDutyService records the progress of the deletion
+	if err := r.migrateLegacyClusterConfig(ctx, pdi, cd); err != nil {
+		return err
+	}
+
 	clusterID := utils.GetClusterID(cd, r.IsFedramp)
-	pdData, err := pd.NewData(pdi, clusterID, cd.Spec.BaseDomain, r.IsFedramp)
+	pdData, err := pd.NewData(pdi, cd, clusterID, r.IsFedramp)
 	if err != nil {
 		return err
 	}
@@ -65,9 +80,9 @@ func (r *PagerDutyIntegrationReconciler) handleDelete(pdclient pd.Client, pdi *p
 	// Evaluate edge-cases where the PagerDuty service no longer needs to be deleted
 	deletePDService := true
 
-	// If the Configmap containing the PagerDuty service parameters is missing, the controller has no hope
-	// of deleting the service, so just cleanup the rest of the Kubernetes resources
-	if err := pdData.ParseClusterConfig(r.Client, cd.Namespace, configMapName); err != nil {
+	// If the PagerDutyService containing the PagerDuty service parameters is missing, the controller
+	// has no hope of deleting the service, so just cleanup the rest of the Kubernetes resources
+	if err := pdData.ParseClusterConfig(ctx, r.Client, cd.Namespace, pdServiceName); err != nil {
 		if !errors.IsNotFound(err) {
 			// some error other than not found, requeue
 			return err
@@ -77,10 +92,10 @@ func (r *PagerDutyIntegrationReconciler) handleDelete(pdclient pd.Client, pdi *p
 
 	// Check if the PD Service still exists, if not DeleteService returns errors
 	if deletePDService {
-		_, err = pdclient.GetService(pdData)
+		_, err = pdclient.GetService(ctx, pdData)
 
 		if err != nil {
-			if !strings.Contains(err.Error(), "Not Found") {
+			if !pd.IsNotFound(err) {
 				return err
 			}
 			r.reqLogger.Info(fmt.Sprintf("PD service %s-%s.%s not found...skipping PD service deletion", pdData.ServicePrefix, pdData.ClusterID, pdData.BaseDomain))
@@ -90,17 +105,29 @@ func (r *PagerDutyIntegrationReconciler) handleDelete(pdclient pd.Client, pdi *p
 
 	// None of the edge cases apply, delete the PagerDuty service
 	if deletePDService {
+		pdService := &pagerdutyv1alpha1.PagerDutyService{}
+		if err := r.Get(ctx, types.NamespacedName{Namespace: cd.Namespace, Name: pdServiceName}, pdService); err != nil {
+			return err
+		}
+
 		r.reqLogger.Info(fmt.Sprintf("Deleting PD service %s-%s.%s", pdData.ServicePrefix, pdData.ClusterID, pdData.BaseDomain))
-		if err := pdclient.DeleteService(pdData); err != nil {
-			r.reqLogger.Error(err, "Failed cleaning up pagerduty.", "ClusterDeployment.Namespace", cd.Namespace, "ClusterID", pdData.ClusterID)
+		if err := r.advanceServiceOperation(ctx, pdclient, pdService, pdData, pagerdutyv1alpha1.ServiceOperationDelete); err != nil {
+			if inProgress, ok := asOperationInProgress(err); ok {
+				r.reqLogger.Info("Waiting for PD incidents to resolve before deleting the PD service", "ClusterDeployment.Namespace", cd.Namespace, "ClusterID", pdData.ClusterID, "UnresolvedIncidents", inProgress.operation.UnresolvedIncidents)
+			} else {
+				r.reqLogger.Error(err, "Failed cleaning up pagerduty.", "ClusterDeployment.Namespace", cd.Namespace, "ClusterID", pdData.ClusterID)
+			}
+			r.recordPagerDutyFailure(pdi, cd, reasonServiceDeleteFailed, "DeleteService", "Failed to delete PagerDuty service "+pdData.ServiceID, err)
 			return err
 		}
+		r.recordPagerDutyEvent(pdi, cd, corev1.EventTypeNormal, reasonServiceDeleted, "DeleteService",
+			"Deleted PagerDuty service %s", pdData.ServiceID)
 
-		// Only delete the configmap if the PagerDuty service was successfully deleted because
+		// Only delete the PagerDutyService if the PagerDuty service was successfully deleted because
 		// it contains the service ID which can be used to find and delete the service next time.
-		r.reqLogger.Info("Deleting PD ConfigMap", "ClusterDeployment.Namespace", cd.Namespace, "Name", configMapName)
-		if err := utils.DeleteConfigMap(configMapName, cd.Namespace, r.Client, r.reqLogger); err != nil {
-			r.reqLogger.Error(err, "Error deleting ConfigMap", "ClusterDeployment.Namespace", cd.Namespace, "Name", configMapName)
+		r.reqLogger.Info("Deleting PagerDutyService", "ClusterDeployment.Namespace", cd.Namespace, "Name", pdServiceName)
+		if err := utils.DeletePagerDutyService(pdServiceName, cd.Namespace, r.Client, r.reqLogger); err != nil {
+			r.reqLogger.Error(err, "Error deleting PagerDutyService", "ClusterDeployment.Namespace", cd.Namespace, "Name", pdServiceName)
 		}
 	}
 
@@ -123,7 +150,7 @@ func (r *PagerDutyIntegrationReconciler) handleDelete(pdclient pd.Client, pdi *p
 		r.reqLogger.Info("Deleting PD finalizer from ClusterDeployment", "ClusterDeployment.Namespace", cd.Namespace, "ClusterDeployment Name", cd.Name)
 		baseToPatch := client.MergeFrom(cd.DeepCopy())
 		utils.DeleteFinalizer(cd, finalizer)
-		err = r.Patch(context.TODO(), cd, baseToPatch)
+		err = r.Patch(ctx, cd, baseToPatch)
 		if err != nil {
 			r.reqLogger.Error(err, "Error deleting Finalizer from cluster deployment", "ClusterDeployment.Namespace", cd.Namespace, "ClusterDeployment Name", cd.Name)
 			metrics.UpdateMetricPagerDutyDeleteFailure(1, clusterID, pdi.Name)
@@ -134,7 +161,7 @@ func (r *PagerDutyIntegrationReconciler) handleDelete(pdclient pd.Client, pdi *p
 	if utils.HasFinalizer(cd, config.LegacyPagerDutyFinalizer) {
 		r.reqLogger.Info("Deleting old PD finalizer from ClusterDeployment", "ClusterDeployment.Namespace", cd.Namespace, "ClusterDeployment Name", cd.Name)
 		utils.DeleteFinalizer(cd, config.LegacyPagerDutyFinalizer)
-		err = r.Update(context.TODO(), cd)
+		err = r.Update(ctx, cd)
 		if err != nil {
 			metrics.UpdateMetricPagerDutyDeleteFailure(1, clusterID, pdi.Name)
 			return err
@@ -1,6 +1,7 @@
 package pagerdutyintegration
 
 import (
+	"context"
 	"strconv"
 
 	hivev1 "github.com/openshift/hive/apis/hive/v1"
@@ -8,11 +9,13 @@ import (
 	"github.com/openshift/pagerduty-operator/config"
 	pd "github.com/openshift/pagerduty-operator/pkg/pagerduty"
 	"github.com/openshift/pagerduty-operator/pkg/utils"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
 )
 
-func (r *PagerDutyIntegrationReconciler) handleLimitedSupport(pdclient pd.Client, pdi *pagerdutyv1alpha1.PagerDutyIntegration, cd *hivev1.ClusterDeployment) error {
-	// configMapName is the name of the ConfigMap of the relevant service
-	var configMapName = config.Name(pdi.Spec.ServicePrefix, cd.Name, config.ConfigMapSuffix)
+func (r *ClusterDeploymentReconciler) handleLimitedSupport(ctx context.Context, pdclient pd.Client, pdi *pagerdutyv1alpha1.PagerDutyIntegration, cd *hivev1.ClusterDeployment) error {
+	// pdServiceName is the name of the PagerDutyService of the relevant service
+	var pdServiceName = config.Name(pdi.Spec.ServicePrefix, cd.Name, config.PagerDutyServiceSuffix)
 
 	// check if the cluster isn't installed yet
 	if !cd.Spec.Installed {
@@ -22,17 +25,22 @@ func (r *PagerDutyIntegrationReconciler) handleLimitedSupport(pdclient pd.Client
 
 	// PagerDuty data
 	clusterID := utils.GetClusterID(cd, r.IsFedramp)
-	pdData, err := pd.NewData(pdi, clusterID, cd.Spec.BaseDomain, r.IsFedramp)
+	pdData, err := pd.NewData(pdi, cd, clusterID, r.IsFedramp)
 	if err != nil {
 		return err
 	}
 
-	err = pdData.ParseClusterConfig(r.Client, cd.Namespace, configMapName)
+	err = pdData.ParseClusterConfig(ctx, r.Client, cd.Namespace, pdServiceName)
 	if err != nil || pdData.ServiceID == "" {
 		// pagerduty service isn't created yet, return
 		return nil
 	}
 
+	pdService := &pagerdutyv1alpha1.PagerDutyService{}
+	if err := r.Get(ctx, types.NamespacedName{Namespace: cd.Namespace, Name: pdServiceName}, pdService); err != nil {
+		return err
+	}
+
 	// Check if limited-support label exists in CD
 	hasLimitedSupport := false
 	if val, err := strconv.ParseBool(cd.Labels[config.ClusterDeploymentLimitedSupportLabel]); err == nil {
@@ -45,13 +53,25 @@ func (r *PagerDutyIntegrationReconciler) handleLimitedSupport(pdclient pd.Client
 		hasSupportException = supportExValue
 	}
 
+	// The cluster left limited support before its PD service was disabled
+	if op := pdService.Status.Operation; op != nil && op.Type == pagerdutyv1alpha1.ServiceOperationDisable &&
+		(!hasLimitedSupport || hasSupportException) {
+		r.reqLogger.Info("The cluster is not in limited-support anymore, cancelling the PagerDuty service disablement", "ClusterID", pdData.ClusterID, "Phase", op.Phase)
+		if err := r.setServiceOperation(ctx, pdService, nil); err != nil {
+			return err
+		}
+	}
+
 	if hasSupportException && pdData.LimitedSupport {
 		// Enable PagerDuty service if the cluster is in limited support
 		r.reqLogger.Info("The cluster has a support exception, re-enabling PagerDuty service", "ClusterID", pdData.ClusterID, "BaseDomain", pdData.BaseDomain)
-		if err := pdclient.EnableService(pdData); err != nil {
+		if err := pdclient.EnableService(ctx, pdData); err != nil {
 			r.reqLogger.Error(err, "Error re-enabling PagerDuty service")
+			r.recordPagerDutyFailure(pdi, cd, reasonServiceEnableFailed, "EnableService", "Failed to re-enable PagerDuty service "+pdData.ServiceID, err)
 			return err
 		}
+		r.recordPagerDutyEvent(pdi, cd, corev1.EventTypeNormal, reasonServiceEnabled, "EnableService",
+			"Re-enabled PagerDuty service %s, the cluster has a support exception", pdData.ServiceID)
 	}
 
 	if hasLimitedSupport && !pdData.LimitedSupport {
@@ -62,29 +82,40 @@ func (r *PagerDutyIntegrationReconciler) handleLimitedSupport(pdclient pd.Client
 		}
 		// Disable PD service and resolve existing service alerts if limited-support label set to true
 		r.reqLogger.Info("The cluster is in limited-support, disabling PagerDuty service", "ClusterID", pdData.ClusterID, "BaseDomain", pdData.BaseDomain)
-		if err := pdclient.DisableService(pdData); err != nil {
-			r.reqLogger.Error(err, "Error disabling PagerDuty service")
+		if err := r.advanceServiceOperation(ctx, pdclient, pdService, pdData, pagerdutyv1alpha1.ServiceOperationDisable); err != nil {
+			if _, ok := asOperationInProgress(err); !ok {
+				r.reqLogger.Error(err, "Error disabling PagerDuty service")
+			}
+			r.recordPagerDutyFailure(pdi, cd, reasonServiceDisableFailed, "DisableService", "Failed to disable PagerDuty service "+pdData.ServiceID, err)
 			return err
 		}
+		r.recordPagerDutyEvent(pdi, cd, corev1.EventTypeNormal, reasonServiceDisabled, "DisableService",
+			"Disabled PagerDuty service %s, the cluster is in limited support", pdData.ServiceID)
 
 		pdData.LimitedSupport = true
 
-		if err := pdData.SetClusterConfig(r.Client, cd.Namespace, configMapName); err != nil {
-			r.reqLogger.Error(err, "Error updating PagerDuty cluster config", "Name", configMapName)
+		if err := pdData.SetClusterConfig(ctx, r.Client, cd.Namespace, pdServiceName); err != nil {
+			r.reqLogger.Error(err, "Error updating PagerDuty cluster config", "Name", pdServiceName)
 			return err
 		}
+
+		// the disablement is recorded in the spec now
+		return r.setServiceOperation(ctx, pdService, nil)
 	} else if !hasLimitedSupport && pdData.LimitedSupport {
 		// Enable PagerDuty service if limited-support label is-not-true/does-not-exist
 		r.reqLogger.Info("The cluster is not in limited-support, enabling PagerDuty service", "ClusterID", pdData.ClusterID, "BaseDomain", pdData.BaseDomain)
-		if err := pdclient.EnableService(pdData); err != nil {
+		if err := pdclient.EnableService(ctx, pdData); err != nil {
 			r.reqLogger.Error(err, "Error enabling PagerDuty service")
+			r.recordPagerDutyFailure(pdi, cd, reasonServiceEnableFailed, "EnableService", "Failed to enable PagerDuty service "+pdData.ServiceID, err)
 			return err
 		}
+		r.recordPagerDutyEvent(pdi, cd, corev1.EventTypeNormal, reasonServiceEnabled, "EnableService",
+			"Enabled PagerDuty service %s, the cluster left limited support", pdData.ServiceID)
 
 		pdData.LimitedSupport = false
 
-		if err := pdData.SetClusterConfig(r.Client, cd.Namespace, configMapName); err != nil {
-			r.reqLogger.Error(err, "Error updating PagerDuty cluster config", "Name", configMapName)
+		if err := pdData.SetClusterConfig(ctx, r.Client, cd.Namespace, pdServiceName); err != nil {
+			r.reqLogger.Error(err, "Error updating PagerDuty cluster config", "Name", pdServiceName)
 			return err
 		}
 	}
@@ -0,0 +1,117 @@
+// Copyright 2019 RedHat
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pagerdutyintegration
+
+import (
+	"context"
+	"strconv"
+	"time"
+
+	hivev1 "github.com/openshift/hive/apis/hive/v1"
+	pagerdutyv1alpha1 "github.com/openshift/pagerduty-operator/api/v1alpha1"
+	"github.com/openshift/pagerduty-operator/config"
+	"github.com/openshift/pagerduty-operator/pkg/localmetrics"
+	pd "github.com/openshift/pagerduty-operator/pkg/pagerduty"
+	"github.com/openshift/pagerduty-operator/pkg/utils"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// Event reasons emitted by the drift check
+const (
+	reasonServiceDriftDetected  = "ServiceDriftDetected"
+	reasonServiceDriftCorrected = "ServiceDriftCorrected"
+)
+
+// handleDriftCheck compares the live PD service of a ClusterDeployment with the settings
+// derived from the PagerDutyIntegration, at most once every DriftCheckInterval. Every
+// drifted setting is counted and reported as an Event on the PagerDutyService, then the
+// desired settings are re-applied unless the PagerDutyIntegration only reports drift.
+// A service or integration that was deleted in PagerDuty is recreated. handleCreate only
+// notices that when the integration key Secret is missing, so this is the only place a
+// deleted service is found while its Secret exists, and it is off with a zero interval.
+func (r *ClusterDeploymentReconciler) handleDriftCheck(ctx context.Context, pdclient pd.Client, pdi *pagerdutyv1alpha1.PagerDutyIntegration, cd *hivev1.ClusterDeployment) error {
+	if r.DriftCheckInterval <= 0 {
+		return nil
+	}
+
+	pdServiceName := config.Name(pdi.Spec.ServicePrefix, cd.Name, config.PagerDutyServiceSuffix)
+	pdService := &pagerdutyv1alpha1.PagerDutyService{}
+	if err := r.Get(ctx, types.NamespacedName{Namespace: cd.Namespace, Name: pdServiceName}, pdService); err != nil {
+		return nil // the PD service isn't created yet
+	}
+
+	lastCheck := pdService.Status.LastDriftCheckTime
+	if lastCheck != nil && time.Since(lastCheck.Time) < r.DriftCheckInterval {
+		return nil
+	}
+
+	pdData, err := pd.NewData(pdi, cd, utils.GetClusterID(cd, r.IsFedramp), r.IsFedramp)
+	if err != nil {
+		return err
+	}
+	if err := pdData.ParseClusterConfig(ctx, r.Client, cd.Namespace, pdServiceName); err != nil {
+		return err
+	}
+	// the escalation policy recorded in the PagerDutyService may be outdated, the desired one is in the PDI
+	pdData.EscalationPolicyID, err = pd.EscalationPolicyFor(pdi, cd)
+	if err != nil {
+		return err
+	}
+	// a support exception keeps the PD service enabled even though the cluster is in limited support
+	if supportException, err := strconv.ParseBool(cd.Labels[config.ClusterDeploymentSupportExceptionLabel]); err == nil && supportException {
+		pdData.LimitedSupport = false
+	}
+
+	service, err := pdclient.GetService(ctx, pdData)
+	if err != nil && !pd.IsNotFound(err) {
+		return err
+	}
+	if err != nil || !pd.HasIntegration(service, pdData.IntegrationID) {
+		// The service or its integration was deleted in PagerDuty. The recreated
+		// objects have the desired settings, so they are checked next time.
+		_, err := r.healPagerDutyService(ctx, pdclient, pdi, cd, pdData)
+		return err
+	}
+
+	drifts := pd.DetectServiceDrift(service, pdData)
+	var driftedFields []string
+	for _, drift := range drifts {
+		driftedFields = append(driftedFields, drift.Field)
+		localmetrics.AddMetricPagerDutyServiceDrift(pdi.Name, drift.Field)
+		r.Recorder.Eventf(pdService, cd, corev1.EventTypeWarning, reasonServiceDriftDetected, "DetectDrift",
+			"PagerDuty service %s setting %s drifted: desired %q, actual %q", pdData.ServiceID, drift.Field, drift.Desired, drift.Actual)
+	}
+
+	if len(drifts) > 0 {
+		r.reqLogger.Info("PD service drifted from its desired settings", "ClusterDeployment.Namespace", cd.Namespace, "ServiceID", pdData.ServiceID, "Settings", driftedFields, "DriftPolicy", pdi.Spec.DriftPolicy)
+		if pdi.Spec.DriftPolicy != pagerdutyv1alpha1.DriftPolicyReport {
+			if err := pdclient.RestoreService(ctx, pdData); err != nil {
+				return err
+			}
+			r.Recorder.Eventf(pdService, cd, corev1.EventTypeNormal, reasonServiceDriftCorrected, "CorrectDrift",
+				"PagerDuty service %s settings restored: %v", pdData.ServiceID, driftedFields)
+			driftedFields = nil
+		}
+	}
+
+	base := pdService.DeepCopy()
+	now := metav1.Now()
+	pdService.Status.LastDriftCheckTime = &now
+	pdService.Status.DriftedFields = driftedFields
+	return r.Status().Patch(ctx, pdService, client.MergeFrom(base))
+}
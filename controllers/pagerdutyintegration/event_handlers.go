@@ -21,8 +21,8 @@ import (
 	hivev1 "github.com/openshift/hive/apis/hive/v1"
 	pagerdutyv1alpha1 "github.com/openshift/pagerduty-operator/api/v1alpha1"
 	"github.com/openshift/pagerduty-operator/config"
+	"github.com/openshift/pagerduty-operator/pkg/utils"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
-	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/apimachinery/pkg/types"
@@ -38,51 +38,53 @@ var _ handler.EventHandler = &enqueueRequestForClusterDeployment{}
 // enqueueRequestForClusterDeployment implements the handler.EventHandler interface.
 // Heavily inspired by https://github.com/kubernetes-sigs/controller-runtime/blob/v0.22.5/pkg/handler/enqueue_mapped.go
 type enqueueRequestForClusterDeployment struct {
-	Client client.Client
+	Client    client.Client
+	selectors *SelectorCache
 }
 
 func (e *enqueueRequestForClusterDeployment) Create(ctx context.Context, evt event.TypedCreateEvent[client.Object], q workqueue.TypedRateLimitingInterface[reconcile.Request]) {
 	reqs := map[reconcile.Request]struct{}{}
-	e.mapAndEnqueue(q, evt.Object, reqs)
+	e.mapAndEnqueue(ctx, q, evt.Object, reqs)
 }
 
 func (e *enqueueRequestForClusterDeployment) Update(ctx context.Context, evt event.TypedUpdateEvent[client.Object], q workqueue.TypedRateLimitingInterface[reconcile.Request]) {
 	reqs := map[reconcile.Request]struct{}{}
-	e.mapAndEnqueue(q, evt.ObjectOld, reqs)
-	e.mapAndEnqueue(q, evt.ObjectNew, reqs)
+	e.mapAndEnqueue(ctx, q, evt.ObjectOld, reqs)
+	e.mapAndEnqueue(ctx, q, evt.ObjectNew, reqs)
 }
 
 func (e *enqueueRequestForClusterDeployment) Delete(ctx context.Context, evt event.TypedDeleteEvent[client.Object], q workqueue.TypedRateLimitingInterface[reconcile.Request]) {
 	reqs := map[reconcile.Request]struct{}{}
-	e.mapAndEnqueue(q, evt.Object, reqs)
+	e.mapAndEnqueue(ctx, q, evt.Object, reqs)
 }
 
 func (e *enqueueRequestForClusterDeployment) Generic(ctx context.Context, evt event.TypedGenericEvent[client.Object], q workqueue.TypedRateLimitingInterface[reconcile.Request]) {
 	reqs := map[reconcile.Request]struct{}{}
-	e.mapAndEnqueue(q, evt.Object, reqs)
+	e.mapAndEnqueue(ctx, q, evt.Object, reqs)
 }
 
 // toRequests receives a ClusterDeployment objects that have fired an event and checks if it can find an associated
-// PagerDutyIntegration object that has a matching label selector, if so it creates a request for the reconciler to
-// take a look at that PagerDutyIntegration object.
-func (e *enqueueRequestForClusterDeployment) toRequests(obj client.Object) []reconcile.Request {
+// PagerDutyIntegration object that has a matching label selector or a finalizer on the ClusterDeployment, if so it
+// creates a request for the reconciler to take a look at that PagerDutyIntegration object.
+func (e *enqueueRequestForClusterDeployment) toRequests(ctx context.Context, obj client.Object) []reconcile.Request {
 	reqs := []reconcile.Request{}
 	pdiList := &pagerdutyv1alpha1.PagerDutyIntegrationList{}
-	if err := e.Client.List(context.TODO(), pdiList, &client.ListOptions{}); err != nil {
+	if err := e.Client.List(ctx, pdiList, &client.ListOptions{}); err != nil {
 		return reqs
 	}
 
 	for _, pdi := range pdiList.Items {
-
-		sanitized, matchesNothing := sanitizeLabelSelector(&pdi.Spec.ClusterDeploymentSelector, log)
-		if matchesNothing {
-			continue
-		}
-		selector, err := metav1.LabelSelectorAsSelector(sanitized)
-		if err != nil {
+		if utils.HasFinalizer(obj, config.PagerDutyFinalizerPrefix+pdi.Name) {
+			reqs = append(reqs, reconcile.Request{
+				NamespacedName: types.NamespacedName{
+					Name:      pdi.Name,
+					Namespace: pdi.Namespace,
+				},
+			})
 			continue
 		}
-		if selector.Matches(labels.Set(obj.GetLabels())) {
+
+		if e.selectors.matches(&pdi, obj, log) {
 			reqs = append(reqs, reconcile.Request{
 				NamespacedName: types.NamespacedName{
 					Name:      pdi.Name,
@@ -94,8 +96,94 @@ func (e *enqueueRequestForClusterDeployment) toRequests(obj client.Object) []rec
 	return reqs
 }
 
-func (e *enqueueRequestForClusterDeployment) mapAndEnqueue(q workqueue.TypedRateLimitingInterface[reconcile.Request], obj client.Object, reqs map[reconcile.Request]struct{}) {
-	for _, req := range e.toRequests(obj) {
+func (e *enqueueRequestForClusterDeployment) mapAndEnqueue(ctx context.Context, q workqueue.TypedRateLimitingInterface[reconcile.Request], obj client.Object, reqs map[reconcile.Request]struct{}) {
+	for _, req := range e.toRequests(ctx, obj) {
+		_, ok := reqs[req]
+		if !ok {
+			q.Add(req)
+			// Used for de-duping requests
+			reqs[req] = struct{}{}
+		}
+	}
+}
+
+var _ handler.EventHandler = &enqueueRequestForPagerDutyIntegration{}
+
+// enqueueRequestForPagerDutyIntegration implements the handler.EventHandler interface.
+// It fans a PagerDutyIntegration event out to the ClusterDeployment controller.
+// Heavily inspired by https://github.com/kubernetes-sigs/controller-runtime/blob/v0.22.5/pkg/handler/enqueue_mapped.go
+type enqueueRequestForPagerDutyIntegration struct {
+	Client    client.Client
+	selectors *SelectorCache
+}
+
+func (e *enqueueRequestForPagerDutyIntegration) Create(ctx context.Context, evt event.TypedCreateEvent[client.Object], q workqueue.TypedRateLimitingInterface[reconcile.Request]) {
+	reqs := map[reconcile.Request]struct{}{}
+	e.mapAndEnqueue(ctx, q, evt.Object, reqs)
+}
+
+func (e *enqueueRequestForPagerDutyIntegration) Update(ctx context.Context, evt event.TypedUpdateEvent[client.Object], q workqueue.TypedRateLimitingInterface[reconcile.Request]) {
+	reqs := map[reconcile.Request]struct{}{}
+	// ClusterDeployments no longer selected by the new selector have to be cleaned up
+	e.mapAndEnqueue(ctx, q, evt.ObjectOld, reqs)
+	e.mapAndEnqueue(ctx, q, evt.ObjectNew, reqs)
+}
+
+func (e *enqueueRequestForPagerDutyIntegration) Delete(ctx context.Context, evt event.TypedDeleteEvent[client.Object], q workqueue.TypedRateLimitingInterface[reconcile.Request]) {
+	reqs := map[reconcile.Request]struct{}{}
+	e.mapAndEnqueue(ctx, q, evt.Object, reqs)
+	e.selectors.forget(types.NamespacedName{Namespace: evt.Object.GetNamespace(), Name: evt.Object.GetName()})
+}
+
+func (e *enqueueRequestForPagerDutyIntegration) Generic(ctx context.Context, evt event.TypedGenericEvent[client.Object], q workqueue.TypedRateLimitingInterface[reconcile.Request]) {
+	reqs := map[reconcile.Request]struct{}{}
+	e.mapAndEnqueue(ctx, q, evt.Object, reqs)
+}
+
+// toRequests receives a PagerDutyIntegration object that has fired an event and creates a request for every
+// ClusterDeployment that its label selector matches, or that has its finalizer. Both are looked up through the
+// cache indexes rather than by walking every ClusterDeployment.
+func (e *enqueueRequestForPagerDutyIntegration) toRequests(ctx context.Context, obj client.Object) []reconcile.Request {
+	reqs := []reconcile.Request{}
+	pdi, ok := obj.(*pagerdutyv1alpha1.PagerDutyIntegration)
+	if !ok {
+		return reqs
+	}
+
+	var clusterDeployments []hivev1.ClusterDeployment
+	selector, err := e.selectors.selectorFor(pdi, log)
+	if err != nil {
+		log.Error(err, "could not build ClusterDeployment label selector", "PagerDutyIntegration", pdi.Name)
+	} else if selector != nil {
+		matching := &hivev1.ClusterDeploymentList{}
+		if err := e.Client.List(ctx, matching, &client.ListOptions{LabelSelector: selector}); err != nil {
+			log.Error(err, "could not list ClusterDeployments")
+			return reqs
+		}
+		clusterDeployments = matching.Items
+	}
+
+	finalized, err := listFinalizedClusterDeployments(ctx, e.Client, pdi)
+	if err != nil {
+		log.Error(err, "could not list ClusterDeployments")
+		return reqs
+	}
+	clusterDeployments = append(clusterDeployments, finalized.Items...)
+
+	seen := map[types.NamespacedName]struct{}{}
+	for _, cd := range clusterDeployments {
+		key := types.NamespacedName{Name: cd.Name, Namespace: cd.Namespace}
+		if _, ok := seen[key]; ok {
+			continue
+		}
+		seen[key] = struct{}{}
+		reqs = append(reqs, reconcile.Request{NamespacedName: key})
+	}
+	return reqs
+}
+
+func (e *enqueueRequestForPagerDutyIntegration) mapAndEnqueue(ctx context.Context, q workqueue.TypedRateLimitingInterface[reconcile.Request], obj client.Object, reqs map[reconcile.Request]struct{}) {
+	for _, req := range e.toRequests(ctx, obj) {
 		_, ok := reqs[req]
 		if !ok {
 			q.Add(req)
@@ -113,23 +201,24 @@ type enqueueRequestForClusterDeploymentOwner struct {
 	Client    client.Client
 	Scheme    *runtime.Scheme
 	groupKind schema.GroupKind
+	selectors *SelectorCache
 }
 
 func (e *enqueueRequestForClusterDeploymentOwner) Create(ctx context.Context, evt event.TypedCreateEvent[client.Object], q workqueue.TypedRateLimitingInterface[reconcile.Request]) {
-	e.mapAndEnqueue(q, evt.Object)
+	e.mapAndEnqueue(ctx, q, evt.Object)
 }
 
 func (e *enqueueRequestForClusterDeploymentOwner) Update(ctx context.Context, evt event.TypedUpdateEvent[client.Object], q workqueue.TypedRateLimitingInterface[reconcile.Request]) {
-	e.mapAndEnqueue(q, evt.ObjectOld)
-	e.mapAndEnqueue(q, evt.ObjectNew)
+	e.mapAndEnqueue(ctx, q, evt.ObjectOld)
+	e.mapAndEnqueue(ctx, q, evt.ObjectNew)
 }
 
 func (e *enqueueRequestForClusterDeploymentOwner) Delete(ctx context.Context, evt event.TypedDeleteEvent[client.Object], q workqueue.TypedRateLimitingInterface[reconcile.Request]) {
-	e.mapAndEnqueue(q, evt.Object)
+	e.mapAndEnqueue(ctx, q, evt.Object)
 }
 
 func (e *enqueueRequestForClusterDeploymentOwner) Generic(ctx context.Context, evt event.TypedGenericEvent[client.Object], q workqueue.TypedRateLimitingInterface[reconcile.Request]) {
-	e.mapAndEnqueue(q, evt.Object)
+	e.mapAndEnqueue(ctx, q, evt.Object)
 }
 
 func (e *enqueueRequestForClusterDeploymentOwner) getClusterDeploymentGroupKind() {
@@ -141,7 +230,7 @@ func (e *enqueueRequestForClusterDeploymentOwner) getClusterDeploymentGroupKind(
 
 // getAssociatedPagerDutyIntegrations receives objects and checks if they're owned by a ClusterDeployment. If so, it then
 // collects associated PagerDutyIntegration CRs and creates requests for the reconciler to consider.
-func (e *enqueueRequestForClusterDeploymentOwner) getAssociatedPagerDutyIntegrations(obj metav1.Object) map[reconcile.Request]struct{} {
+func (e *enqueueRequestForClusterDeploymentOwner) getAssociatedPagerDutyIntegrations(ctx context.Context, obj metav1.Object) map[reconcile.Request]struct{} {
 	e.getClusterDeploymentGroupKind()
 
 	cds := []*hivev1.ClusterDeployment{}
@@ -154,7 +243,7 @@ func (e *enqueueRequestForClusterDeploymentOwner) getAssociatedPagerDutyIntegrat
 
 		if ref.Kind == e.groupKind.Kind && refGV.Group == e.groupKind.Group {
 			cd := &hivev1.ClusterDeployment{}
-			if err := e.Client.Get(context.TODO(), client.ObjectKey{Namespace: obj.GetNamespace(), Name: ref.Name}, cd); err != nil {
+			if err := e.Client.Get(ctx, client.ObjectKey{Namespace: obj.GetNamespace(), Name: ref.Name}, cd); err != nil {
 				log.Error(err, "could not get ClusterDeployment", "namespace", obj.GetNamespace(), "name", ref.Name)
 				continue
 			}
@@ -168,24 +257,14 @@ func (e *enqueueRequestForClusterDeploymentOwner) getAssociatedPagerDutyIntegrat
 
 	reqs := map[reconcile.Request]struct{}{}
 	pdiList := &pagerdutyv1alpha1.PagerDutyIntegrationList{}
-	if err := e.Client.List(context.TODO(), pdiList, &client.ListOptions{}); err != nil {
+	if err := e.Client.List(ctx, pdiList, &client.ListOptions{}); err != nil {
 		log.Error(err, "could not list PagerDutyIntegrations")
 		return reqs
 	}
 
 	for _, pdi := range pdiList.Items {
-
-		sanitized, matchesNothing := sanitizeLabelSelector(&pdi.Spec.ClusterDeploymentSelector, log)
-		if matchesNothing {
-			continue
-		}
-		selector, err := metav1.LabelSelectorAsSelector(sanitized)
-		if err != nil {
-			log.Error(err, "could not build ClusterDeployment label selector")
-			continue
-		}
 		for _, cd := range cds {
-			if selector.Matches(labels.Set(cd.GetLabels())) {
+			if e.selectors.matches(&pdi, cd, log) {
 				request := reconcile.Request{
 					NamespacedName: types.NamespacedName{
 						Name:      pdi.Name,
@@ -200,8 +279,8 @@ func (e *enqueueRequestForClusterDeploymentOwner) getAssociatedPagerDutyIntegrat
 	return reqs
 }
 
-func (e *enqueueRequestForClusterDeploymentOwner) mapAndEnqueue(q workqueue.TypedRateLimitingInterface[reconcile.Request], obj client.Object) {
-	for req := range e.getAssociatedPagerDutyIntegrations(obj) {
+func (e *enqueueRequestForClusterDeploymentOwner) mapAndEnqueue(ctx context.Context, q workqueue.TypedRateLimitingInterface[reconcile.Request], obj client.Object) {
+	for req := range e.getAssociatedPagerDutyIntegrations(ctx, obj) {
 		q.Add(req)
 	}
 }
@@ -216,65 +295,50 @@ type enqueueRequestForConfigMap struct {
 
 func (e *enqueueRequestForConfigMap) Create(ctx context.Context, evt event.TypedCreateEvent[client.Object], q workqueue.TypedRateLimitingInterface[reconcile.Request]) {
 	reqs := map[reconcile.Request]struct{}{}
-	e.mapAndEnqueue(q, evt.Object, reqs)
+	e.mapAndEnqueue(ctx, q, evt.Object, reqs)
 }
 
 func (e *enqueueRequestForConfigMap) Update(ctx context.Context, evt event.TypedUpdateEvent[client.Object], q workqueue.TypedRateLimitingInterface[reconcile.Request]) {
 	reqs := map[reconcile.Request]struct{}{}
-	e.mapAndEnqueue(q, evt.ObjectOld, reqs)
-	e.mapAndEnqueue(q, evt.ObjectNew, reqs)
+	e.mapAndEnqueue(ctx, q, evt.ObjectOld, reqs)
+	e.mapAndEnqueue(ctx, q, evt.ObjectNew, reqs)
 }
 
 func (e *enqueueRequestForConfigMap) Delete(ctx context.Context, evt event.TypedDeleteEvent[client.Object], q workqueue.TypedRateLimitingInterface[reconcile.Request]) {
 	reqs := map[reconcile.Request]struct{}{}
-	e.mapAndEnqueue(q, evt.Object, reqs)
+	e.mapAndEnqueue(ctx, q, evt.Object, reqs)
 }
 
 func (e *enqueueRequestForConfigMap) Generic(ctx context.Context, evt event.TypedGenericEvent[client.Object], q workqueue.TypedRateLimitingInterface[reconcile.Request]) {
 	reqs := map[reconcile.Request]struct{}{}
-	e.mapAndEnqueue(q, evt.Object, reqs)
+	e.mapAndEnqueue(ctx, q, evt.Object, reqs)
 }
 
-// toRequests receives a Configmap objects that have fired an event and checks if it can find an associated
-// PagerDutyIntegration object that has a matching label selector, if so it creates a request for the reconciler to
-// take a look at that PagerDutyIntegration object.
-func (e *enqueueRequestForConfigMap) toRequests(obj client.Object) []reconcile.Request {
+// toRequests receives a ConfigMap object that has fired an event and creates a request for every
+// PagerDutyIntegration with service orchestration enabled that references it in
+// spec.serviceOrchestration.ruleConfigConfigMapRef.
+func (e *enqueueRequestForConfigMap) toRequests(ctx context.Context, obj client.Object) []reconcile.Request {
 	reqs := []reconcile.Request{}
 
-	// enqueue for configmap in the operator namespace only
-	if obj.GetNamespace() != config.OperatorNamespace {
-		return reqs
-	}
-
-	pdiList := &pagerdutyv1alpha1.PagerDutyIntegrationList{}
-	if err := e.Client.List(context.TODO(), pdiList, &client.ListOptions{}); err != nil {
+	pdiList, err := listPagerDutyIntegrationsForConfigMap(ctx, e.Client, types.NamespacedName{Namespace: obj.GetNamespace(), Name: obj.GetName()})
+	if err != nil {
+		log.Error(err, "could not list PagerDutyIntegrations")
 		return reqs
 	}
 
 	for _, pdi := range pdiList.Items {
-
-		sanitized, matchesNothing := sanitizeLabelSelector(&pdi.Spec.ClusterDeploymentSelector, log)
-		if matchesNothing {
-			continue
-		}
-		selector, err := metav1.LabelSelectorAsSelector(sanitized)
-		if err != nil {
-			continue
-		}
-		if selector.Matches(labels.Set(obj.GetLabels())) {
-			reqs = append(reqs, reconcile.Request{
-				NamespacedName: types.NamespacedName{
-					Name:      pdi.Name,
-					Namespace: pdi.Namespace,
-				},
-			})
-		}
+		reqs = append(reqs, reconcile.Request{
+			NamespacedName: types.NamespacedName{
+				Name:      pdi.Name,
+				Namespace: pdi.Namespace,
+			},
+		})
 	}
 	return reqs
 }
 
-func (e *enqueueRequestForConfigMap) mapAndEnqueue(q workqueue.TypedRateLimitingInterface[reconcile.Request], obj client.Object, reqs map[reconcile.Request]struct{}) {
-	for _, req := range e.toRequests(obj) {
+func (e *enqueueRequestForConfigMap) mapAndEnqueue(ctx context.Context, q workqueue.TypedRateLimitingInterface[reconcile.Request], obj client.Object, reqs map[reconcile.Request]struct{}) {
+	for _, req := range e.toRequests(ctx, obj) {
 		_, ok := reqs[req]
 		if !ok {
 			q.Add(req)
@@ -111,7 +111,7 @@ func Test_enqueueRequestForClusterDeployment_toRequests(t *testing.T) {
 			e := &enqueueRequestForClusterDeployment{
 				Client: fake.NewClientBuilder().WithScheme(scheme).WithObjects(test.obj).WithObjects(test.pdiObjs...).Build(),
 			}
-			reqs := e.toRequests(test.obj)
+			reqs := e.toRequests(context.TODO(), test.obj)
 			assert.Equal(t, test.expectedRequests, len(reqs))
 		})
 	}
@@ -274,7 +274,7 @@ func Test_enqueueRequestForClusterDeploymentOwner_getAssociatedPagerDutyIntegrat
 					WithObjects(test.cdObjs...).
 					Build(),
 			}
-			reqs := e.getAssociatedPagerDutyIntegrations(test.obj)
+			reqs := e.getAssociatedPagerDutyIntegrations(context.TODO(), test.obj)
 			assert.Equal(t, test.expectedRequests, len(reqs))
 		})
 	}
@@ -288,62 +288,77 @@ func Test_enqueueRequestForConfigmap_toRequests(t *testing.T) {
 	}
 	assert.Nil(t, s.AddToScheme(scheme))
 
+	referencedCM := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "orchestration-cm",
+			Namespace: config.OperatorNamespace,
+			Labels: map[string]string{
+				"key1": "val1",
+			},
+		},
+	}
+
 	tests := []struct {
 		name             string
 		obj              client.Object
 		pdiObjs          []client.Object
-		expectedRequests int
+		expectedRequests []string
 	}{
 		{
 			name:             "empty configmap",
 			obj:              &corev1.ConfigMap{},
 			pdiObjs:          []client.Object{},
-			expectedRequests: 0,
+			expectedRequests: []string{},
 		},
 		{
-			name: "PDI with In empty values matches nothing via configmap",
-			obj: &corev1.ConfigMap{
-				ObjectMeta: metav1.ObjectMeta{
-					Name:      "test-cm",
-					Namespace: config.OperatorNamespace,
-					Labels: map[string]string{
-						"key1": "val1",
-					},
-				},
+			name: "PDIs referencing the configmap are enqueued",
+			obj:  referencedCM,
+			pdiObjs: []client.Object{
+				mockPagerDutyIntegrationWithConfigMapRef("pdi1", config.OperatorNamespace, "orchestration-cm", true),
+				mockPagerDutyIntegrationWithConfigMapRef("pdi2", config.OperatorNamespace, "orchestration-cm", true),
 			},
+			expectedRequests: []string{"pdi1", "pdi2"},
+		},
+		{
+			name: "PDI with service orchestration disabled is not enqueued",
+			obj:  referencedCM,
 			pdiObjs: []client.Object{
-				mockPagerDutyIntegrationWithExpressions("pdi-in-empty", []metav1.LabelSelectorRequirement{
-					{Key: "key1", Operator: metav1.LabelSelectorOpIn, Values: []string{}},
-				}),
+				mockPagerDutyIntegrationWithConfigMapRef("pdi1", config.OperatorNamespace, "orchestration-cm", false),
 			},
-			expectedRequests: 0,
+			expectedRequests: []string{},
 		},
 		{
-			name: "PDI with NotIn empty values drops expression via configmap",
-			obj: &corev1.ConfigMap{
-				ObjectMeta: metav1.ObjectMeta{
-					Name:      "test-cm-notin",
-					Namespace: config.OperatorNamespace,
-					Labels: map[string]string{
-						"key1": "val1",
-					},
-				},
+			name: "PDI referencing a configmap of the same name in another namespace is not enqueued",
+			obj:  referencedCM,
+			pdiObjs: []client.Object{
+				mockPagerDutyIntegrationWithConfigMapRef("pdi1", "other-namespace", "orchestration-cm", true),
 			},
+			expectedRequests: []string{},
+		},
+		{
+			name: "PDI whose selector matches the configmap labels is not enqueued",
+			obj:  referencedCM,
 			pdiObjs: []client.Object{
-				mockPagerDutyIntegrationWithExpressions("pdi-notin-empty", []metav1.LabelSelectorRequirement{
-					{Key: "key1", Operator: metav1.LabelSelectorOpNotIn, Values: []string{}},
-				}),
+				mockPagerDutyIntegration("pdi1", map[string]string{"key1": "val1"}),
 			},
-			expectedRequests: 1,
+			expectedRequests: []string{},
 		},
 	}
 	for _, test := range tests {
 		t.Run(test.name, func(t *testing.T) {
 			e := &enqueueRequestForConfigMap{
-				Client: fake.NewClientBuilder().WithScheme(scheme).WithObjects(test.obj).WithObjects(test.pdiObjs...).Build(),
+				Client: fake.NewClientBuilder().
+					WithScheme(scheme).
+					WithObjects(test.obj).
+					WithObjects(test.pdiObjs...).
+					WithIndex(&pagerdutyv1alpha1.PagerDutyIntegration{}, pagerDutyIntegrationConfigMapIndex, orchestrationConfigMap).
+					Build(),
 			}
-			reqs := e.toRequests(test.obj)
-			assert.Equal(t, test.expectedRequests, len(reqs))
+			names := []string{}
+			for _, req := range e.toRequests(context.TODO(), test.obj) {
+				names = append(names, req.Name)
+			}
+			assert.ElementsMatch(t, test.expectedRequests, names)
 		})
 	}
 }
@@ -474,6 +489,132 @@ func Test_enqueueRequestForClusterDeployment_Update_Deduplication(t *testing.T)
 	assert.Equal(t, 1, q.Len(), "same PDI from ObjectOld and ObjectNew should be de-duplicated")
 }
 
+func Test_enqueueRequestForClusterDeployment_Finalizer(t *testing.T) {
+	scheme := newTestScheme()
+	ctx := context.TODO()
+
+	// the CD is no longer selected by pdi1, but still has its finalizer
+	cd := &hivev1.ClusterDeployment{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:       "cd1",
+			Namespace:  "ns1",
+			Labels:     map[string]string{"pdiWatching": "none"},
+			Finalizers: []string{config.PagerDutyFinalizerPrefix + "pdi1"},
+		},
+	}
+
+	fakeClient := fake.NewClientBuilder().
+		WithScheme(scheme).
+		WithObjects(cd).
+		WithObjects(mockPagerDutyIntegration("pdi1", map[string]string{"pdiWatching": "cd1"})).
+		Build()
+
+	handler := &enqueueRequestForClusterDeployment{Client: fakeClient}
+	q := workqueue.NewTypedRateLimitingQueue(workqueue.DefaultTypedControllerRateLimiter[reconcile.Request]())
+	defer q.ShutDown()
+
+	handler.Update(ctx, event.UpdateEvent{ObjectOld: cd, ObjectNew: cd}, q)
+
+	assert.Equal(t, 1, q.Len())
+	req, _ := q.Get()
+	assert.Equal(t, "pdi1", req.Name)
+	q.Done(req)
+}
+
+func Test_enqueueRequestForPagerDutyIntegration_QueueMethods(t *testing.T) {
+	scheme := newTestScheme()
+	ctx := context.TODO()
+
+	selected := &hivev1.ClusterDeployment{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "cd-selected",
+			Namespace: "ns1",
+			Labels:    map[string]string{"pdiWatching": "cd1"},
+		},
+	}
+	finalized := &hivev1.ClusterDeployment{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:       "cd-finalized",
+			Namespace:  "ns2",
+			Finalizers: []string{config.PagerDutyFinalizerPrefix + "pdi1"},
+		},
+	}
+	unrelated := &hivev1.ClusterDeployment{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "cd-unrelated",
+			Namespace: "ns3",
+			Labels:    map[string]string{"pdiWatching": "cd2"},
+		},
+	}
+
+	pdi := mockPagerDutyIntegration("pdi1", map[string]string{"pdiWatching": "cd1"})
+	pdi.Generation = 2
+	oldPDI := mockPagerDutyIntegration("pdi1", map[string]string{"pdiWatching": "cd2"})
+	oldPDI.Generation = 1
+
+	tests := []struct {
+		name             string
+		fire             func(handler *enqueueRequestForPagerDutyIntegration, q workqueue.TypedRateLimitingInterface[reconcile.Request])
+		expectedRequests []types.NamespacedName
+	}{
+		{
+			name: "Create enqueues selected and finalized ClusterDeployments",
+			fire: func(h *enqueueRequestForPagerDutyIntegration, q workqueue.TypedRateLimitingInterface[reconcile.Request]) {
+				h.Create(ctx, event.CreateEvent{Object: pdi}, q)
+			},
+			expectedRequests: []types.NamespacedName{
+				{Namespace: "ns1", Name: "cd-selected"},
+				{Namespace: "ns2", Name: "cd-finalized"},
+			},
+		},
+		{
+			name: "Update enqueues ClusterDeployments of the old and new selector once",
+			fire: func(h *enqueueRequestForPagerDutyIntegration, q workqueue.TypedRateLimitingInterface[reconcile.Request]) {
+				h.Update(ctx, event.UpdateEvent{ObjectOld: oldPDI, ObjectNew: pdi}, q)
+			},
+			expectedRequests: []types.NamespacedName{
+				{Namespace: "ns1", Name: "cd-selected"},
+				{Namespace: "ns2", Name: "cd-finalized"},
+				{Namespace: "ns3", Name: "cd-unrelated"},
+			},
+		},
+		{
+			name: "Delete enqueues selected and finalized ClusterDeployments",
+			fire: func(h *enqueueRequestForPagerDutyIntegration, q workqueue.TypedRateLimitingInterface[reconcile.Request]) {
+				h.Delete(ctx, event.DeleteEvent{Object: pdi}, q)
+			},
+			expectedRequests: []types.NamespacedName{
+				{Namespace: "ns1", Name: "cd-selected"},
+				{Namespace: "ns2", Name: "cd-finalized"},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fakeClient := fake.NewClientBuilder().
+				WithScheme(scheme).
+				WithObjects(selected, finalized, unrelated).
+				WithIndex(&hivev1.ClusterDeployment{}, clusterDeploymentFinalizerIndex, pagerDutyFinalizers).
+				Build()
+
+			handler := &enqueueRequestForPagerDutyIntegration{Client: fakeClient, selectors: NewSelectorCache()}
+			q := workqueue.NewTypedRateLimitingQueue(workqueue.DefaultTypedControllerRateLimiter[reconcile.Request]())
+			defer q.ShutDown()
+
+			tt.fire(handler, q)
+
+			requests := []types.NamespacedName{}
+			for q.Len() > 0 {
+				req, _ := q.Get()
+				requests = append(requests, req.NamespacedName)
+				q.Done(req)
+			}
+			assert.ElementsMatch(t, tt.expectedRequests, requests)
+		})
+	}
+}
+
 func Test_enqueueRequestForClusterDeploymentOwner_QueueMethods(t *testing.T) {
 	scheme := newTestScheme()
 	ctx := context.TODO()
@@ -556,23 +697,23 @@ func Test_enqueueRequestForConfigMap_QueueMethods(t *testing.T) {
 	scheme := newTestScheme()
 	ctx := context.TODO()
 
-	cmInOperatorNS := &corev1.ConfigMap{
+	referencedCM := &corev1.ConfigMap{
 		ObjectMeta: metav1.ObjectMeta{
 			Name:      "orchestration-cm",
 			Namespace: config.OperatorNamespace,
-			Labels:    map[string]string{"pdiWatching": "cd1"},
 		},
 	}
 
-	cmWrongNS := &corev1.ConfigMap{
+	unrelatedCM := &corev1.ConfigMap{
 		ObjectMeta: metav1.ObjectMeta{
-			Name:      "orchestration-cm-wrong",
-			Namespace: "other-namespace",
+			Name:      "unrelated-cm",
+			Namespace: config.OperatorNamespace,
 			Labels:    map[string]string{"pdiWatching": "cd1"},
 		},
 	}
 
-	pdi := mockPagerDutyIntegration("pdi1", map[string]string{"pdiWatching": "cd1"})
+	pdi := mockPagerDutyIntegrationWithConfigMapRef("pdi1", config.OperatorNamespace, "orchestration-cm", true)
+	pdi.Spec.ClusterDeploymentSelector.MatchLabels = map[string]string{"pdiWatching": "cd1"}
 
 	tests := []struct {
 		name          string
@@ -582,27 +723,36 @@ func Test_enqueueRequestForConfigMap_QueueMethods(t *testing.T) {
 		expectedName  string
 	}{
 		{
-			name: "Create in operator namespace enqueues matching PDI",
-			obj:  cmInOperatorNS,
+			name: "Create of the referenced ConfigMap enqueues the PDI",
+			obj:  referencedCM,
 			fire: func(h *enqueueRequestForConfigMap, q workqueue.TypedRateLimitingInterface[reconcile.Request]) {
-				h.Create(ctx, event.CreateEvent{Object: cmInOperatorNS}, q)
+				h.Create(ctx, event.CreateEvent{Object: referencedCM}, q)
 			},
 			expectedCount: 1,
 			expectedName:  "pdi1",
 		},
 		{
-			name: "Create in wrong namespace enqueues nothing",
-			obj:  cmWrongNS,
+			name: "Update of the referenced ConfigMap enqueues the PDI once",
+			obj:  referencedCM,
 			fire: func(h *enqueueRequestForConfigMap, q workqueue.TypedRateLimitingInterface[reconcile.Request]) {
-				h.Create(ctx, event.CreateEvent{Object: cmWrongNS}, q)
+				h.Update(ctx, event.UpdateEvent{ObjectOld: referencedCM, ObjectNew: referencedCM}, q)
+			},
+			expectedCount: 1,
+			expectedName:  "pdi1",
+		},
+		{
+			name: "Create of an unrelated ConfigMap enqueues nothing",
+			obj:  unrelatedCM,
+			fire: func(h *enqueueRequestForConfigMap, q workqueue.TypedRateLimitingInterface[reconcile.Request]) {
+				h.Create(ctx, event.CreateEvent{Object: unrelatedCM}, q)
 			},
 			expectedCount: 0,
 		},
 		{
-			name: "Delete in operator namespace enqueues matching PDI",
-			obj:  cmInOperatorNS,
+			name: "Delete of the referenced ConfigMap enqueues the PDI",
+			obj:  referencedCM,
 			fire: func(h *enqueueRequestForConfigMap, q workqueue.TypedRateLimitingInterface[reconcile.Request]) {
-				h.Delete(ctx, event.DeleteEvent{Object: cmInOperatorNS}, q)
+				h.Delete(ctx, event.DeleteEvent{Object: referencedCM}, q)
 			},
 			expectedCount: 1,
 			expectedName:  "pdi1",
@@ -615,6 +765,7 @@ func Test_enqueueRequestForConfigMap_QueueMethods(t *testing.T) {
 				WithScheme(scheme).
 				WithObjects(tt.obj).
 				WithObjects(pdi).
+				WithIndex(&pagerdutyv1alpha1.PagerDutyIntegration{}, pagerDutyIntegrationConfigMapIndex, orchestrationConfigMap).
 				Build()
 
 			handler := &enqueueRequestForConfigMap{Client: fakeClient}
@@ -633,6 +784,19 @@ func Test_enqueueRequestForConfigMap_QueueMethods(t *testing.T) {
 	}
 }
 
+// mockPagerDutyIntegrationWithConfigMapRef returns a PDI whose service orchestration rules are in the given ConfigMap
+func mockPagerDutyIntegrationWithConfigMapRef(name string, cmNamespace string, cmName string, enabled bool) *pagerdutyv1alpha1.PagerDutyIntegration {
+	pdi := mockPagerDutyIntegration(name, map[string]string{})
+	pdi.Spec.ServiceOrchestration = pagerdutyv1alpha1.ServiceOrchestration{
+		Enabled: enabled,
+		RuleConfigConfigMapRef: &corev1.ObjectReference{
+			Namespace: cmNamespace,
+			Name:      cmName,
+		},
+	}
+	return pdi
+}
+
 func mockPagerDutyIntegrationWithExpressions(name string, exprs []metav1.LabelSelectorRequirement) *pagerdutyv1alpha1.PagerDutyIntegration {
 	return &pagerdutyv1alpha1.PagerDutyIntegration{
 		ObjectMeta: metav1.ObjectMeta{
@@ -0,0 +1,63 @@
+// Copyright 2019 RedHat
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pagerdutyintegration
+
+import (
+	"fmt"
+
+	hivev1 "github.com/openshift/hive/apis/hive/v1"
+	pagerdutyv1alpha1 "github.com/openshift/pagerduty-operator/api/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+)
+
+// Event reasons emitted when the PD service of a ClusterDeployment is changed in PagerDuty
+const (
+	reasonServiceCreated               = "ServiceCreated"
+	reasonServiceCreateFailed          = "ServiceCreateFailed"
+	reasonEscalationPolicyUpdated      = "EscalationPolicyUpdated"
+	reasonEscalationPolicyUpdateFailed = "EscalationPolicyUpdateFailed"
+	reasonServiceSettingsUpdated       = "ServiceSettingsUpdated"
+	reasonServiceSettingsUpdateFailed  = "ServiceSettingsUpdateFailed"
+	reasonServiceDisabled              = "ServiceDisabled"
+	reasonServiceDisableFailed         = "ServiceDisableFailed"
+	reasonServiceEnabled               = "ServiceEnabled"
+	reasonServiceEnableFailed          = "ServiceEnableFailed"
+	reasonOrchestrationEnabled         = "OrchestrationEnabled"
+	reasonOrchestrationEnableFailed    = "OrchestrationEnableFailed"
+	reasonOrchestrationRuleApplied     = "OrchestrationRuleApplied"
+	reasonOrchestrationRuleApplyFailed = "OrchestrationRuleApplyFailed"
+	reasonServiceDeleted               = "ServiceDeleted"
+	reasonServiceDeleteFailed          = "ServiceDeleteFailed"
+	reasonServiceHandedOff             = "ServiceHandedOff"
+	reasonServiceAdopted               = "ServiceAdopted"
+)
+
+// recordPagerDutyEvent records an event on the ClusterDeployment, so that its PD history
+// shows up when describing it, and the same event on the PagerDutyIntegration that
+// caused the change
+func (r *ClusterDeploymentReconciler) recordPagerDutyEvent(pdi *pagerdutyv1alpha1.PagerDutyIntegration, cd *hivev1.ClusterDeployment, eventtype, reason, action, note string, args ...any) {
+	note = fmt.Sprintf(note, args...)
+	r.Recorder.Eventf(cd, pdi, eventtype, reason, action, "%s (PagerDutyIntegration %s)", note, pdi.Name)
+	r.Recorder.Eventf(pdi, cd, eventtype, reason, action, "%s (ClusterDeployment %s/%s)", note, cd.Namespace, cd.Name)
+}
+
+// recordPagerDutyFailure records a Warning event for a failed change of the PD service,
+// unless err only reports that PD is still resolving incidents
+func (r *ClusterDeploymentReconciler) recordPagerDutyFailure(pdi *pagerdutyv1alpha1.PagerDutyIntegration, cd *hivev1.ClusterDeployment, reason, action, message string, err error) {
+	if _, ok := asOperationInProgress(err); ok {
+		return
+	}
+	r.recordPagerDutyEvent(pdi, cd, corev1.EventTypeWarning, reason, action, "%s: %v", message, err)
+}
@@ -0,0 +1,173 @@
+package pagerdutyintegration
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	hiveapis "github.com/openshift/hive/apis"
+	hivev1 "github.com/openshift/hive/apis/hive/v1"
+	pagerdutyapi "github.com/openshift/pagerduty-operator/api"
+	pagerdutyv1alpha1 "github.com/openshift/pagerduty-operator/api/v1alpha1"
+	pd "github.com/openshift/pagerduty-operator/pkg/pagerduty"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+// objectEventRecorder records the kind of the object each event is about with its type and reason
+type objectEventRecorder struct {
+	events []string
+}
+
+func (o *objectEventRecorder) Eventf(regarding runtime.Object, related runtime.Object, eventtype, reason, action, note string, args ...interface{}) {
+	kind := fmt.Sprintf("%T", regarding)
+	switch regarding.(type) {
+	case *hivev1.ClusterDeployment:
+		kind = "ClusterDeployment"
+	case *pagerdutyv1alpha1.PagerDutyIntegration:
+		kind = "PagerDutyIntegration"
+	case *pagerdutyv1alpha1.PagerDutyService:
+		kind = "PagerDutyService"
+	}
+	o.events = append(o.events, kind+" "+eventtype+" "+reason)
+}
+
+func TestPagerDutyEvents(t *testing.T) {
+	assert.Nil(t, hiveapis.AddToScheme(scheme.Scheme))
+	assert.Nil(t, pagerdutyapi.AddToScheme(scheme.Scheme))
+
+	tests := []struct {
+		name         string
+		localObjects []client.Object
+		setupPDMock  func(*pd.MockClientMockRecorder)
+		expectEvents []string
+	}{
+		{
+			name: "Test Service Created",
+			localObjects: []client.Object{
+				testClusterDeployment(true, true, true, false, false, false, false),
+				testPDISecret(),
+				testFinalizedPagerDutyIntegration(false),
+			},
+			setupPDMock: func(r *pd.MockClientMockRecorder) {
+				r.CreateService(gomock.Any(), gomock.Any()).Return(testIntegrationID, nil).Times(1)
+				r.GetIntegrationKey(gomock.Any(), gomock.Any()).Return(testIntegrationID, nil).Times(1)
+			},
+			expectEvents: []string{
+				"ClusterDeployment Normal " + reasonServiceCreated,
+				"PagerDutyIntegration Normal " + reasonServiceCreated,
+			},
+		},
+		{
+			name: "Test Service Creation Failed",
+			localObjects: []client.Object{
+				testClusterDeployment(true, true, true, false, false, false, false),
+				testPDISecret(),
+				testFinalizedPagerDutyIntegration(false),
+			},
+			setupPDMock: func(r *pd.MockClientMockRecorder) {
+				r.CreateService(gomock.Any(), gomock.Any()).Return("", fmt.Errorf("pagerduty unavailable")).Times(1)
+			},
+			expectEvents: []string{
+				"ClusterDeployment Warning " + reasonServiceCreateFailed,
+				"PagerDutyIntegration Warning " + reasonServiceCreateFailed,
+			},
+		},
+		{
+			name: "Test Service Enabled After Limited Support",
+			localObjects: []client.Object{
+				testClusterDeployment(true, true, true, false, false, false, false),
+				testPDISecret(),
+				testFinalizedPagerDutyIntegration(false),
+				testCDPagerDutyService(true, false, false, true),
+				testCDSyncSet(),
+				testCDSecret(),
+			},
+			setupPDMock: func(r *pd.MockClientMockRecorder) {
+				r.EnableService(gomock.Any(), gomock.Any()).Return(nil).Times(1)
+			},
+			expectEvents: []string{
+				"ClusterDeployment Normal " + reasonServiceEnabled,
+				"PagerDutyIntegration Normal " + reasonServiceEnabled,
+			},
+		},
+		{
+			name: "Test Service Deleted",
+			localObjects: []client.Object{
+				testClusterDeployment(true, true, true, false, false, false, false),
+				testPDISecret(),
+				testFinalizedPagerDutyIntegration(true),
+				testCDPagerDutyService(false, false, false, true),
+				testCDSyncSet(),
+				testCDSecret(),
+			},
+			setupPDMock: func(r *pd.MockClientMockRecorder) {
+				r.GetService(gomock.Any(), gomock.Any()).Return(nil, nil).Times(1)
+				r.ResolvePendingIncidents(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil).Times(1)
+				r.CountUnresolvedIncidents(gomock.Any(), gomock.Any()).Return(0, nil).Times(1)
+				r.DeleteService(gomock.Any(), gomock.Any()).Return(nil).Times(1)
+			},
+			expectEvents: []string{
+				"ClusterDeployment Normal " + reasonServiceDeleted,
+				"PagerDutyIntegration Normal " + reasonServiceDeleted,
+			},
+		},
+		{
+			name: "Test No Event While Incidents Are Resolving",
+			localObjects: []client.Object{
+				testClusterDeployment(true, true, true, false, false, false, false),
+				testPDISecret(),
+				testFinalizedPagerDutyIntegration(true),
+				testCDPagerDutyService(false, false, false, true),
+				testCDSyncSet(),
+				testCDSecret(),
+			},
+			setupPDMock: func(r *pd.MockClientMockRecorder) {
+				r.GetService(gomock.Any(), gomock.Any()).Return(nil, nil).Times(1)
+				r.ResolvePendingIncidents(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil).Times(1)
+				r.CountUnresolvedIncidents(gomock.Any(), gomock.Any()).Return(1, nil).Times(1)
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			mocks := setupDefaultMocks(t, test.localObjects)
+			test.setupPDMock(mocks.mockPDClient.EXPECT())
+			defer mocks.mockCtrl.Finish()
+
+			recorder := &objectEventRecorder{}
+			rcd := newTestReconciler(mocks).cd
+			rcd.Recorder = recorder
+			_, _ = rcd.Reconcile(context.TODO(), reconcile.Request{
+				NamespacedName: types.NamespacedName{Namespace: testNamespace, Name: testClusterName},
+			})
+			assert.Equal(t, test.expectEvents, recorder.events)
+		})
+	}
+}
+
+func TestRecordPagerDutyEvent(t *testing.T) {
+	recorder := &objectEventRecorder{}
+	r := &ClusterDeploymentReconciler{Recorder: recorder}
+	pdi := testPagerDutyIntegration()
+	cd := testClusterDeployment(true, true, true, false, false, false, false)
+
+	r.recordPagerDutyFailure(pdi, cd, reasonServiceDeleteFailed, "DeleteService", "Failed to delete PagerDuty service", &operationInProgressError{
+		serviceID: testServiceID,
+		operation: &pagerdutyv1alpha1.ServiceOperation{},
+	})
+	assert.Empty(t, recorder.events, "waiting for PD is not a failure")
+
+	r.recordPagerDutyFailure(pdi, cd, reasonServiceDeleteFailed, "DeleteService", "Failed to delete PagerDuty service", fmt.Errorf("pagerduty unavailable"))
+	assert.Equal(t, []string{
+		"ClusterDeployment " + corev1.EventTypeWarning + " " + reasonServiceDeleteFailed,
+		"PagerDutyIntegration " + corev1.EventTypeWarning + " " + reasonServiceDeleteFailed,
+	}, recorder.events)
+}
@@ -16,56 +16,119 @@ package pagerdutyintegration
 
 import (
 	"context"
-	"fmt"
+	"slices"
 
 	"github.com/openshift/pagerduty-operator/config"
 	pd "github.com/openshift/pagerduty-operator/pkg/pagerduty"
+	"github.com/openshift/pagerduty-operator/pkg/utils"
 	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
 	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 
 	hivev1 "github.com/openshift/hive/apis/hive/v1"
 	pagerdutyv1alpha1 "github.com/openshift/pagerduty-operator/api/v1alpha1"
 )
 
-func (r *PagerDutyIntegrationReconciler) handleUpdate(pdclient pd.Client, pdi *pagerdutyv1alpha1.PagerDutyIntegration, cd *hivev1.ClusterDeployment) error {
-	if pdi.Spec.AlertGroupingParameters == nil {
-		return nil
-	}
+// reasonOverrideRejected is emitted when an override annotation of a ClusterDeployment is ignored
+const reasonOverrideRejected = "OverrideRejected"
+
+// handleUpdate brings the settings of an existing PD service in line with the
+// PagerDutyIntegration. The settings last applied are recorded in the PagerDutyService,
+// so the PD API is only called when one of them changed.
+func (r *ClusterDeploymentReconciler) handleUpdate(ctx context.Context, pdclient pd.Client, pdi *pagerdutyv1alpha1.PagerDutyIntegration, cd *hivev1.ClusterDeployment) error {
 	var (
-		// configMapName is the name of the ConfigMap containing the
-		// SERVICE_ID and INTEGRATION_ID
-		configMapName = config.Name(pdi.Spec.ServicePrefix, cd.Name, config.ConfigMapSuffix)
+		// pdServiceName is the name of the PagerDutyService containing the
+		// service ID and integration ID
+		pdServiceName = config.Name(pdi.Spec.ServicePrefix, cd.Name, config.PagerDutyServiceSuffix)
 	)
-	cm := &corev1.ConfigMap{}
-	err := r.Get(context.TODO(), types.NamespacedName{Namespace: cd.Namespace, Name: configMapName}, cm)
+	pdService := &pagerdutyv1alpha1.PagerDutyService{}
+	err := r.Get(ctx, types.NamespacedName{Namespace: cd.Namespace, Name: pdServiceName}, pdService)
 	if err != nil {
-		return nil // requeue and wait for the configmap to be created
+		return nil // requeue and wait for the PagerDutyService to be created
 	}
 
-	alertGroupingType := cm.Data["ALERT_GROUPING_TYPE"]
-	alertGroupingTimeout := cm.Data["ALERT_GROUPING_TIMEOUT"]
+	pdData, err := pd.NewData(pdi, cd, utils.GetClusterID(cd, r.IsFedramp), r.IsFedramp)
+	if err != nil {
+		return err
+	}
 
-	if alertGroupingType != pdi.Spec.AlertGroupingParameters.Type || alertGroupingTimeout != fmt.Sprintf("%d", pdi.Spec.AlertGroupingParameters.Config.Timeout) {
-		pdData, err := pd.NewData(pdi, cd.Spec.ClusterMetadata.ClusterID, cd.Spec.BaseDomain, r.IsFedramp)
-		if err != nil {
-			return err
-		}
-		err = pdData.ParseClusterConfig(r.Client, cd.Namespace, configMapName)
-		if err != nil {
-			return err
-		}
+	if err := r.recordClusterOverrides(ctx, pdi, cd, pdService, pdData.Overrides); err != nil {
+		return err
+	}
 
-		err = pdclient.UpdateAlertGrouping(pdData)
-		if err != nil {
-			return err
-		}
+	changed := changedServiceSettings(&pdService.Spec, pdData)
+	if len(changed) == 0 {
+		return nil
+	}
+
+	r.reqLogger.Info("Updating PD service settings", "ClusterDeployment.Namespace", cd.Namespace, "ClusterDeployment.Name", cd.Name, "Settings", changed)
+
+	err = pdData.ParseClusterConfig(ctx, r.Client, cd.Namespace, pdServiceName)
+	if err != nil {
+		return err
+	}
+
+	err = pdclient.UpdateServiceSettings(ctx, pdData)
+	if err != nil {
+		r.recordPagerDutyFailure(pdi, cd, reasonServiceSettingsUpdateFailed, "UpdateServiceSettings", "Failed to update the settings of PagerDuty service "+pdData.ServiceID, err)
+		return err
+	}
+	r.recordPagerDutyEvent(pdi, cd, corev1.EventTypeNormal, reasonServiceSettingsUpdated, "UpdateServiceSettings",
+		"Updated PagerDuty service %s settings: %v", pdData.ServiceID, changed)
+
+	pdData.RecordAppliedSettings(&pdService.Spec)
+	return r.Update(ctx, pdService)
+}
 
-		cm.Data["ALERT_GROUPING_TYPE"] = pdi.Spec.AlertGroupingParameters.Type
-		cm.Data["ALERT_GROUPING_TIMEOUT"] = fmt.Sprintf("%d", pdi.Spec.AlertGroupingParameters.Config.Timeout)
-		err = r.Update(context.TODO(), cm)
-		if err != nil {
-			return err
+// changedServiceSettings returns the names of the service settings in data that differ
+// from the ones last applied to the PD service. data holds the settings of the
+// PagerDutyIntegration with the overrides of the cluster applied. Alert grouping is only
+// compared when it is configured. The name and description are compared as rendered for
+// the cluster, so label changes rename the service too.
+func changedServiceSettings(spec *pagerdutyv1alpha1.PagerDutyServiceSpec, data *pd.Data) []string {
+	var changed []string
+
+	if spec.ServiceName != data.ServiceName {
+		changed = append(changed, "name")
+	}
+	if spec.ServiceDescription != data.ServiceDescription {
+		changed = append(changed, "description")
+	}
+	if spec.ResolveTimeout != data.ResolveTimeout {
+		changed = append(changed, "resolveTimeout")
+	}
+	if spec.AcknowledgeTimeout != data.AcknowledgeTimeOut {
+		changed = append(changed, "acknowledgeTimeout")
+	}
+	if data.AlertGroupingType != "" &&
+		(spec.AlertGroupingType != data.AlertGroupingType || spec.AlertGroupingTimeout != data.AlertGroupingTimeout) {
+		changed = append(changed, "alertGroupingParameters")
+	}
+	if !equality.Semantic.DeepEqual(spec.IncidentUrgencyRule, data.IncidentUrgencyRule) {
+		changed = append(changed, "incidentUrgencyRule")
+	}
+
+	return changed
+}
+
+// recordClusterOverrides records the override annotations of the cluster in the
+// PagerDutyService status. Overrides that are newly rejected are reported with a
+// Warning event.
+func (r *ClusterDeploymentReconciler) recordClusterOverrides(ctx context.Context, pdi *pagerdutyv1alpha1.PagerDutyIntegration, cd *hivev1.ClusterDeployment, pdService *pagerdutyv1alpha1.PagerDutyService, overrides []pagerdutyv1alpha1.ClusterOverride) error {
+	if equality.Semantic.DeepEqual(pdService.Status.Overrides, overrides) {
+		return nil
+	}
+
+	for _, override := range overrides {
+		if override.Accepted || slices.Contains(pdService.Status.Overrides, override) {
+			continue
 		}
+		r.recordPagerDutyEvent(pdi, cd, corev1.EventTypeWarning, reasonOverrideRejected, "ValidateOverride",
+			"Ignoring annotation %s=%q: %s", override.Annotation, override.Value, override.Message)
 	}
-	return nil
+
+	base := pdService.DeepCopy()
+	pdService.Status.Overrides = overrides
+	return r.Status().Patch(ctx, pdService, client.MergeFrom(base))
 }
@@ -0,0 +1,393 @@
+// Copyright 2019 RedHat
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pagerdutyintegration
+
+import (
+	"context"
+	"slices"
+	"strings"
+	"time"
+
+	hivev1 "github.com/openshift/hive/apis/hive/v1"
+	hiveintv1alpha1 "github.com/openshift/hive/apis/hiveinternal/v1alpha1"
+	pagerdutyv1alpha1 "github.com/openshift/pagerduty-operator/api/v1alpha1"
+	"github.com/openshift/pagerduty-operator/config"
+	"github.com/openshift/pagerduty-operator/pkg/kube"
+	"github.com/openshift/pagerduty-operator/pkg/utils"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+)
+
+// handoffPollInterval is how often a PD service handoff checks whether the new
+// PagerDutyIntegration adopted the service and hive deployed its integration key
+const handoffPollInterval = 15 * time.Second
+
+// handleHandoff hands the PD service of cd over to the PagerDutyIntegration that selects cd
+// now that pdi doesn't, instead of deleting it. The service is kept, and the Secret and
+// SyncSet of pdi keep deploying its integration key, until the successor recorded the
+// service in its own PagerDutyService and hive deployed the key with the successor's
+// SyncSet. Only then are the objects and the finalizer of pdi removed, so the cluster
+// keeps paging throughout. It returns false when no PagerDutyIntegration can take the
+// service over, handleDelete deletes it then.
+func (r *ClusterDeploymentReconciler) handleHandoff(ctx context.Context, pdi *pagerdutyv1alpha1.PagerDutyIntegration, cd *hivev1.ClusterDeployment) (bool, error) {
+	var (
+		pdServiceName = config.Name(pdi.Spec.ServicePrefix, cd.Name, config.PagerDutyServiceSuffix)
+		finalizer     = config.PagerDutyFinalizerPrefix + pdi.Name
+	)
+
+	if !utils.HasFinalizer(cd, finalizer) {
+		return false, nil
+	}
+
+	pdService := &pagerdutyv1alpha1.PagerDutyService{}
+	if err := r.Get(ctx, types.NamespacedName{Namespace: cd.Namespace, Name: pdServiceName}, pdService); err != nil {
+		if errors.IsNotFound(err) {
+			// nothing to hand over
+			return false, nil
+		}
+		return false, err
+	}
+
+	if !isManagedBy(pdService, pdi) {
+		// a PagerDutyIntegration with the same service prefix took the PagerDutyService over
+		return true, r.releaseClusterDeployment(ctx, pdi, cd, pdService.Spec.ServiceID, pdService.Spec.PagerDutyIntegrationRef.Name, false)
+	}
+	if op := pdService.Status.Operation; op != nil && op.Type == pagerdutyv1alpha1.ServiceOperationDelete {
+		// the deletion started already, finish it
+		return false, nil
+	}
+
+	successor, err := r.findSuccessor(ctx, pdi, cd)
+	if err != nil || successor == nil {
+		return false, err
+	}
+
+	op := pdService.Status.Operation
+	if op == nil || op.Type != pagerdutyv1alpha1.ServiceOperationHandoff || op.HandoffTo == nil || op.HandoffTo.Name != successor.Name || op.HandoffTo.Namespace != successor.Namespace {
+		op = &pagerdutyv1alpha1.ServiceOperation{
+			Type:      pagerdutyv1alpha1.ServiceOperationHandoff,
+			StartTime: metav1.Now(),
+			HandoffTo: &pagerdutyv1alpha1.PagerDutyIntegrationReference{Name: successor.Name, Namespace: successor.Namespace},
+		}
+		r.reqLogger.Info("Handing PD service over", "PagerDutyIntegration", pdi.Name, "Successor", successor.Name, "ServiceID", pdService.Spec.ServiceID)
+	} else {
+		op = op.DeepCopy()
+	}
+
+	adopted := &pagerdutyv1alpha1.PagerDutyService{}
+	err = r.Get(ctx, types.NamespacedName{Namespace: cd.Namespace, Name: config.Name(successor.Spec.ServicePrefix, cd.Name, config.PagerDutyServiceSuffix)}, adopted)
+	if err != nil && !errors.IsNotFound(err) {
+		return false, err
+	}
+
+	switch {
+	case errors.IsNotFound(err) || !isManagedBy(adopted, successor):
+		op.Phase = pagerdutyv1alpha1.ServiceOperationAwaitingAdoption
+	case adopted.Spec.ServiceID != pdService.Spec.ServiceID:
+		// the successor has a PD service of its own already, so this one is deleted
+		return false, nil
+	default:
+		synced, err := r.handoffSynced(ctx, pdi, successor, cd)
+		if err != nil {
+			return false, err
+		}
+		if synced {
+			return true, r.releaseClusterDeployment(ctx, pdi, cd, pdService.Spec.ServiceID, successor.Name, true)
+		}
+		op.Phase = pagerdutyv1alpha1.ServiceOperationAwaitingSync
+	}
+
+	if err := r.setServiceOperation(ctx, pdService, op); err != nil {
+		return false, err
+	}
+	return true, &operationInProgressError{serviceID: pdService.Spec.ServiceID, operation: op, requeueAfter: handoffPollInterval}
+}
+
+// findSuccessor returns the PagerDutyIntegration that takes the PD service of cd over from
+// pdi: the first one by name that selects cd and reaches the same PD account with the same
+// credentials, or nil if there is none
+func (r *ClusterDeploymentReconciler) findSuccessor(ctx context.Context, pdi *pagerdutyv1alpha1.PagerDutyIntegration, cd *hivev1.ClusterDeployment) (*pagerdutyv1alpha1.PagerDutyIntegration, error) {
+	account, _, err := loadAccount(ctx, r.Client, pdi)
+	if err != nil {
+		return nil, err
+	}
+
+	pdiList := &pagerdutyv1alpha1.PagerDutyIntegrationList{}
+	if err := r.List(ctx, pdiList); err != nil {
+		return nil, err
+	}
+	slices.SortFunc(pdiList.Items, func(a, b pagerdutyv1alpha1.PagerDutyIntegration) int {
+		return strings.Compare(a.Namespace+"/"+a.Name, b.Namespace+"/"+b.Name)
+	})
+
+	for i := range pdiList.Items {
+		candidate := &pdiList.Items[i]
+		if candidate.Name == pdi.Name && candidate.Namespace == pdi.Namespace {
+			continue
+		}
+		if candidate.DeletionTimestamp != nil || !utils.HasFinalizer(candidate, config.PagerDutyIntegrationFinalizer) {
+			continue
+		}
+		if !r.Selectors.matches(candidate, cd, r.reqLogger) {
+			continue
+		}
+
+		candidateAccount, _, err := loadAccount(ctx, r.Client, candidate)
+		if err != nil {
+			return nil, err
+		}
+		if account.SameAccount(candidateAccount) {
+			return candidate, nil
+		}
+	}
+	return nil, nil
+}
+
+// handoffSynced returns whether the SyncSet of the successor deployed the integration key
+// to the cluster, so the SyncSet of pdi can be deleted. When both deploy the same Secret,
+// the SyncSet of pdi is switched to Upsert first, otherwise hive deletes the Secret from
+// the cluster along with it.
+func (r *ClusterDeploymentReconciler) handoffSynced(ctx context.Context, pdi, successor *pagerdutyv1alpha1.PagerDutyIntegration, cd *hivev1.ClusterDeployment) (bool, error) {
+	ss := &hivev1.SyncSet{}
+	if err := r.Get(ctx, types.NamespacedName{Namespace: cd.Namespace, Name: config.Name(successor.Spec.ServicePrefix, cd.Name, config.SecretSuffix)}, ss); err != nil {
+		if errors.IsNotFound(err) {
+			return false, nil
+		}
+		return false, err
+	}
+	status, err := r.syncSetStatus(ctx, cd, ss)
+	if err != nil || status == nil || status.Result != hiveintv1alpha1.SuccessSyncSetResult {
+		return false, err
+	}
+
+	if pdi.Spec.TargetSecretRef != successor.Spec.TargetSecretRef {
+		return true, nil
+	}
+
+	old := &hivev1.SyncSet{}
+	if err := r.Get(ctx, types.NamespacedName{Namespace: cd.Namespace, Name: config.Name(pdi.Spec.ServicePrefix, cd.Name, config.SecretSuffix)}, old); err != nil {
+		if errors.IsNotFound(err) {
+			return true, nil
+		}
+		return false, err
+	}
+	if old.Spec.ResourceApplyMode != hivev1.UpsertResourceApplyMode {
+		r.reqLogger.Info("Switching PD SyncSet to Upsert before deleting it", "ClusterDeployment.Namespace", cd.Namespace, "Name", old.Name)
+		base := client.MergeFrom(old.DeepCopy())
+		old.Spec.ResourceApplyMode = hivev1.UpsertResourceApplyMode
+		return false, r.Patch(ctx, old, base)
+	}
+	status, err = r.syncSetStatus(ctx, cd, old)
+	if err != nil || status == nil {
+		return false, err
+	}
+	return len(status.ResourcesToDelete) == 0, nil
+}
+
+// syncSetStatus returns the sync status of ss in the ClusterSync of cd, or nil if hive
+// hasn't applied the current generation of ss yet
+func (r *ClusterDeploymentReconciler) syncSetStatus(ctx context.Context, cd *hivev1.ClusterDeployment, ss *hivev1.SyncSet) (*hiveintv1alpha1.SyncStatus, error) {
+	clusterSync := &hiveintv1alpha1.ClusterSync{}
+	if err := r.Get(ctx, types.NamespacedName{Namespace: cd.Namespace, Name: cd.Name}, clusterSync); err != nil {
+		if errors.IsNotFound(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	for i, status := range clusterSync.Status.SyncSets {
+		if status.Name == ss.Name && status.ObservedGeneration == ss.Generation {
+			return &clusterSync.Status.SyncSets[i], nil
+		}
+	}
+	return nil, nil
+}
+
+// releaseClusterDeployment removes the finalizer of pdi from cd once its PD service was
+// handed over to successor. The PagerDutyService, legacy ConfigMap, Secret and SyncSet of pdi are
+// deleted too, unless they are shared with the successor because they have the same names.
+func (r *ClusterDeploymentReconciler) releaseClusterDeployment(ctx context.Context, pdi *pagerdutyv1alpha1.PagerDutyIntegration, cd *hivev1.ClusterDeployment, serviceID, successor string, deleteObjects bool) error {
+	var (
+		secretName    = config.Name(pdi.Spec.ServicePrefix, cd.Name, config.SecretSuffix)
+		pdServiceName = config.Name(pdi.Spec.ServicePrefix, cd.Name, config.PagerDutyServiceSuffix)
+		configMapName = config.Name(pdi.Spec.ServicePrefix, cd.Name, config.ConfigMapSuffix)
+		finalizer     = config.PagerDutyFinalizerPrefix + pdi.Name
+	)
+
+	if deleteObjects {
+		if err := utils.DeletePagerDutyService(pdServiceName, cd.Namespace, r.Client, r.reqLogger); err != nil {
+			r.reqLogger.Error(err, "Error deleting PagerDutyService", "ClusterDeployment.Namespace", cd.Namespace, "Name", pdServiceName)
+		}
+		if err := utils.DeleteConfigMap(configMapName, cd.Namespace, r.Client, r.reqLogger); err != nil {
+			r.reqLogger.Error(err, "Error deleting ConfigMap", "ClusterDeployment.Namespace", cd.Namespace, "Name", configMapName)
+		}
+		if err := utils.DeleteSecret(secretName, cd.Namespace, r.Client, r.reqLogger); err != nil {
+			r.reqLogger.Error(err, "Error deleting Secret", "ClusterDeployment.Namespace", cd.Namespace, "Name", secretName)
+		}
+		if err := utils.DeleteSyncSet(secretName, cd.Namespace, r.Client, r.reqLogger); err != nil {
+			r.reqLogger.Error(err, "Error deleting SyncSet", "ClusterDeployment.Namespace", cd.Namespace, "Name", secretName)
+		}
+	}
+
+	if utils.HasFinalizer(cd, finalizer) {
+		r.reqLogger.Info("Deleting PD finalizer from ClusterDeployment after handoff", "ClusterDeployment.Namespace", cd.Namespace, "ClusterDeployment Name", cd.Name)
+		baseToPatch := client.MergeFrom(cd.DeepCopy())
+		utils.DeleteFinalizer(cd, finalizer)
+		if err := r.Patch(ctx, cd, baseToPatch); err != nil {
+			return err
+		}
+	}
+
+	r.recordPagerDutyEvent(pdi, cd, corev1.EventTypeNormal, reasonServiceHandedOff, "HandoffService",
+		"Handed PagerDuty service %s over to PagerDutyIntegration %s", serviceID, successor)
+	return nil
+}
+
+// adoptPagerDutyService takes the PD service of cd over from the PagerDutyIntegration that
+// no longer selects cd and hands it over to pdi, by recording it in the PagerDutyService
+// of pdi. The settings last applied are kept, so handleCreate and handleUpdate bring the
+// escalation policy, name and settings of the service in line with pdi afterwards.
+func (r *ClusterDeploymentReconciler) adoptPagerDutyService(ctx context.Context, pdi *pagerdutyv1alpha1.PagerDutyIntegration, cd *hivev1.ClusterDeployment, pdServiceName string) error {
+	existing := &pagerdutyv1alpha1.PagerDutyService{}
+	err := r.Get(ctx, types.NamespacedName{Namespace: cd.Namespace, Name: pdServiceName}, existing)
+	if err != nil && !errors.IsNotFound(err) {
+		return err
+	}
+
+	if err == nil {
+		if isManagedBy(existing, pdi) {
+			return nil
+		}
+		// the PagerDutyIntegration handing the service over uses the same service prefix
+		handsOver, err := r.handsOverTo(ctx, existing, pdi, cd)
+		if err != nil || !handsOver {
+			return err
+		}
+		from := existing.Spec.PagerDutyIntegrationRef.Name
+		base := client.MergeFrom(existing.DeepCopy())
+		existing.Spec.PagerDutyIntegrationRef = pagerdutyv1alpha1.PagerDutyIntegrationReference{Name: pdi.Name, Namespace: pdi.Namespace}
+		if err := r.Patch(ctx, existing, base); err != nil {
+			return err
+		}
+		// clear the handoff recorded by the previous PagerDutyIntegration
+		if err := r.setServiceOperation(ctx, existing, nil); err != nil {
+			return err
+		}
+		r.recordPagerDutyEvent(pdi, cd, corev1.EventTypeNormal, reasonServiceAdopted, "AdoptService",
+			"Took over PagerDuty service %s from PagerDutyIntegration %s", existing.Spec.ServiceID, from)
+		return nil
+	}
+
+	pdServiceList := &pagerdutyv1alpha1.PagerDutyServiceList{}
+	if err := r.List(ctx, pdServiceList, client.InNamespace(cd.Namespace)); err != nil {
+		return err
+	}
+	for i := range pdServiceList.Items {
+		previous := &pdServiceList.Items[i]
+		if previous.Spec.ClusterDeploymentRef.Name != cd.Name || isManagedBy(previous, pdi) {
+			continue
+		}
+		handsOver, err := r.handsOverTo(ctx, previous, pdi, cd)
+		if err != nil {
+			return err
+		}
+		if !handsOver {
+			continue
+		}
+
+		r.reqLogger.Info("Adopting PD service", "PagerDutyIntegration", pdi.Name, "ServiceID", previous.Spec.ServiceID, "From", previous.Spec.PagerDutyIntegrationRef.Name)
+		pdService := kube.GeneratePagerDutyService(cd.Namespace, pdServiceName, cd.Name, pdi)
+		ref := pdService.Spec.PagerDutyIntegrationRef
+		pdService.Spec = *previous.Spec.DeepCopy()
+		pdService.Spec.PagerDutyIntegrationRef = ref
+		if err := controllerutil.SetControllerReference(cd, pdService, r.Scheme); err != nil {
+			r.reqLogger.Error(err, "Error setting controller reference on PagerDutyService")
+			return err
+		}
+		if err := r.Create(ctx, pdService); err != nil {
+			r.reqLogger.Error(err, "Error creating PagerDutyService", "Name", pdServiceName)
+			return err
+		}
+		r.recordPagerDutyEvent(pdi, cd, corev1.EventTypeNormal, reasonServiceAdopted, "AdoptService",
+			"Took over PagerDuty service %s from PagerDutyIntegration %s", previous.Spec.ServiceID, previous.Spec.PagerDutyIntegrationRef.Name)
+		return nil
+	}
+	return nil
+}
+
+// handsOverTo returns whether the PagerDutyIntegration managing pdService is handing its PD
+// service over to pdi: it no longer selects cd, still has its finalizer on cd, and pdi is
+// its successor
+func (r *ClusterDeploymentReconciler) handsOverTo(ctx context.Context, pdService *pagerdutyv1alpha1.PagerDutyService, pdi *pagerdutyv1alpha1.PagerDutyIntegration, cd *hivev1.ClusterDeployment) (bool, error) {
+	if op := pdService.Status.Operation; op != nil && op.Type == pagerdutyv1alpha1.ServiceOperationDelete {
+		return false, nil
+	}
+
+	ref := pdService.Spec.PagerDutyIntegrationRef
+	previous := &pagerdutyv1alpha1.PagerDutyIntegration{}
+	if err := r.Get(ctx, types.NamespacedName{Namespace: ref.Namespace, Name: ref.Name}, previous); err != nil {
+		if errors.IsNotFound(err) {
+			return false, nil
+		}
+		return false, err
+	}
+	if previous.DeletionTimestamp != nil || !utils.HasFinalizer(cd, config.PagerDutyFinalizerPrefix+previous.Name) || r.Selectors.matches(previous, cd, r.reqLogger) {
+		return false, nil
+	}
+
+	successor, err := r.findSuccessor(ctx, previous, cd)
+	if err != nil {
+		// the previous PagerDutyIntegration can't be used, it won't delete the service either
+		r.reqLogger.Info("Unable to check the handoff of the PD service", "PagerDutyIntegration", previous.Name, "Reason", err.Error())
+		return false, nil
+	}
+	return successor != nil && successor.Name == pdi.Name && successor.Namespace == pdi.Namespace, nil
+}
+
+// serviceHandedOver returns the PagerDutyService in which another PagerDutyIntegration
+// recorded the PD service of cd that pdi handed over, or nil if pdi didn't hand it over
+func (r *ClusterDeploymentReconciler) serviceHandedOver(ctx context.Context, pdi *pagerdutyv1alpha1.PagerDutyIntegration, cd *hivev1.ClusterDeployment) (*pagerdutyv1alpha1.PagerDutyService, error) {
+	pdService := &pagerdutyv1alpha1.PagerDutyService{}
+	if err := r.Get(ctx, types.NamespacedName{Namespace: cd.Namespace, Name: config.Name(pdi.Spec.ServicePrefix, cd.Name, config.PagerDutyServiceSuffix)}, pdService); err != nil {
+		if errors.IsNotFound(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	if !isManagedBy(pdService, pdi) {
+		return pdService, nil
+	}
+
+	pdServiceList := &pagerdutyv1alpha1.PagerDutyServiceList{}
+	if err := r.List(ctx, pdServiceList, client.InNamespace(cd.Namespace)); err != nil {
+		return nil, err
+	}
+	for i := range pdServiceList.Items {
+		other := &pdServiceList.Items[i]
+		if other.Spec.ClusterDeploymentRef.Name == cd.Name && !isManagedBy(other, pdi) && other.Spec.ServiceID == pdService.Spec.ServiceID {
+			return other, nil
+		}
+	}
+	return nil, nil
+}
+
+// isManagedBy returns whether pdService records the PD service of pdi
+func isManagedBy(pdService *pagerdutyv1alpha1.PagerDutyService, pdi *pagerdutyv1alpha1.PagerDutyIntegration) bool {
+	ref := pdService.Spec.PagerDutyIntegrationRef
+	return ref.Name == pdi.Name && ref.Namespace == pdi.Namespace
+}
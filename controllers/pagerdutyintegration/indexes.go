@@ -0,0 +1,171 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pagerdutyintegration
+
+import (
+	"context"
+	"strings"
+
+	hivev1 "github.com/openshift/hive/apis/hive/v1"
+	pagerdutyv1alpha1 "github.com/openshift/pagerduty-operator/api/v1alpha1"
+	"github.com/openshift/pagerduty-operator/config"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	// clusterDeploymentFinalizerIndex indexes ClusterDeployments by the PagerDutyIntegration
+	// finalizers they carry
+	clusterDeploymentFinalizerIndex = "metadata.finalizers.pagerduty"
+
+	// pagerDutyIntegrationConfigMapIndex indexes PagerDutyIntegrations with service
+	// orchestration enabled by the namespace/name of their rule ConfigMap
+	pagerDutyIntegrationConfigMapIndex = "spec.serviceOrchestration.ruleConfigConfigMapRef"
+
+	// pagerDutyIntegrationAPIKeySecretIndex indexes PagerDutyIntegrations by the
+	// namespace/name of their API key Secret
+	pagerDutyIntegrationAPIKeySecretIndex = "spec.pagerdutyApiKeySecretRef"
+
+	// pagerDutyIntegrationAccountIndex indexes PagerDutyIntegrations by the name of their
+	// PagerDutyAccount
+	pagerDutyIntegrationAccountIndex = "spec.pagerdutyAccountRef"
+
+	// pagerDutyAccountAPIKeySecretIndex indexes PagerDutyAccounts by the namespace/name of
+	// their API key Secret
+	pagerDutyAccountAPIKeySecretIndex = "spec.apiKeySecretRef"
+)
+
+// SetupIndexes registers the field indexes used by the controllers of this package.
+// It has to be called once, before the controllers are set up.
+func SetupIndexes(ctx context.Context, indexer client.FieldIndexer) error {
+	if err := indexer.IndexField(ctx, &hivev1.ClusterDeployment{}, clusterDeploymentFinalizerIndex, pagerDutyFinalizers); err != nil {
+		return err
+	}
+	if err := indexer.IndexField(ctx, &pagerdutyv1alpha1.PagerDutyIntegration{}, pagerDutyIntegrationConfigMapIndex, orchestrationConfigMap); err != nil {
+		return err
+	}
+	if err := indexer.IndexField(ctx, &pagerdutyv1alpha1.PagerDutyIntegration{}, pagerDutyIntegrationAPIKeySecretIndex, apiKeySecret); err != nil {
+		return err
+	}
+	if err := indexer.IndexField(ctx, &pagerdutyv1alpha1.PagerDutyIntegration{}, pagerDutyIntegrationAccountIndex, pagerDutyAccount); err != nil {
+		return err
+	}
+	return indexer.IndexField(ctx, &pagerdutyv1alpha1.PagerDutyAccount{}, pagerDutyAccountAPIKeySecretIndex, apiKeySecret)
+}
+
+// pagerDutyFinalizers returns the PagerDutyIntegration finalizers of obj
+func pagerDutyFinalizers(obj client.Object) []string {
+	var finalizers []string
+	for _, finalizer := range obj.GetFinalizers() {
+		if strings.HasPrefix(finalizer, config.PagerDutyFinalizerPrefix) {
+			finalizers = append(finalizers, finalizer)
+		}
+	}
+	return finalizers
+}
+
+// listFinalizedClusterDeployments lists the ClusterDeployments that carry the finalizer of pdi
+func listFinalizedClusterDeployments(ctx context.Context, c client.Reader, pdi *pagerdutyv1alpha1.PagerDutyIntegration) (*hivev1.ClusterDeploymentList, error) {
+	cdList := &hivev1.ClusterDeploymentList{}
+	err := c.List(ctx, cdList, client.MatchingFields{clusterDeploymentFinalizerIndex: config.PagerDutyFinalizerPrefix + pdi.Name})
+	return cdList, err
+}
+
+// orchestrationConfigMap returns the namespace/name of the service orchestration rule
+// ConfigMap of obj, if service orchestration is enabled
+func orchestrationConfigMap(obj client.Object) []string {
+	pdi, ok := obj.(*pagerdutyv1alpha1.PagerDutyIntegration)
+	if !ok || !pdi.Spec.ServiceOrchestration.Enabled || pdi.Spec.ServiceOrchestration.RuleConfigConfigMapRef == nil {
+		return nil
+	}
+	ref := pdi.Spec.ServiceOrchestration.RuleConfigConfigMapRef
+	return []string{types.NamespacedName{Namespace: ref.Namespace, Name: ref.Name}.String()}
+}
+
+// listPagerDutyIntegrationsForConfigMap lists the PagerDutyIntegrations with service
+// orchestration enabled whose rules are in the given ConfigMap
+func listPagerDutyIntegrationsForConfigMap(ctx context.Context, c client.Reader, configMap types.NamespacedName) (*pagerdutyv1alpha1.PagerDutyIntegrationList, error) {
+	pdiList := &pagerdutyv1alpha1.PagerDutyIntegrationList{}
+	err := c.List(ctx, pdiList, client.MatchingFields{pagerDutyIntegrationConfigMapIndex: configMap.String()})
+	return pdiList, err
+}
+
+// apiKeySecret returns the namespace/name of the API key Secret of obj. The Secret of a
+// PagerDutyIntegration using a PagerDutyAccount isn't used.
+func apiKeySecret(obj client.Object) []string {
+	var ref corev1.SecretReference
+	switch o := obj.(type) {
+	case *pagerdutyv1alpha1.PagerDutyIntegration:
+		if o.Spec.PagerDutyAccountRef != nil {
+			return nil
+		}
+		ref = o.Spec.PagerdutyApiKeySecretRef
+	case *pagerdutyv1alpha1.PagerDutyAccount:
+		ref = o.Spec.APIKeySecretRef
+	default:
+		return nil
+	}
+	return []string{types.NamespacedName{Namespace: ref.Namespace, Name: ref.Name}.String()}
+}
+
+// listPagerDutyIntegrationsForSecret lists the PagerDutyIntegrations whose API key is
+// in the given Secret, directly or through their PagerDutyAccount
+func listPagerDutyIntegrationsForSecret(ctx context.Context, c client.Reader, secret types.NamespacedName) (*pagerdutyv1alpha1.PagerDutyIntegrationList, error) {
+	pdiList := &pagerdutyv1alpha1.PagerDutyIntegrationList{}
+	if err := c.List(ctx, pdiList, client.MatchingFields{pagerDutyIntegrationAPIKeySecretIndex: secret.String()}); err != nil {
+		return nil, err
+	}
+
+	accountList, err := listPagerDutyAccountsForSecret(ctx, c, secret)
+	if err != nil {
+		return nil, err
+	}
+	for _, account := range accountList.Items {
+		accountPDIs, err := listPagerDutyIntegrationsForAccount(ctx, c, account.Name)
+		if err != nil {
+			return nil, err
+		}
+		pdiList.Items = append(pdiList.Items, accountPDIs.Items...)
+	}
+	return pdiList, nil
+}
+
+// listPagerDutyAccountsForSecret lists the PagerDutyAccounts whose API key is in the
+// given Secret
+func listPagerDutyAccountsForSecret(ctx context.Context, c client.Reader, secret types.NamespacedName) (*pagerdutyv1alpha1.PagerDutyAccountList, error) {
+	accountList := &pagerdutyv1alpha1.PagerDutyAccountList{}
+	err := c.List(ctx, accountList, client.MatchingFields{pagerDutyAccountAPIKeySecretIndex: secret.String()})
+	return accountList, err
+}
+
+// pagerDutyAccount returns the name of the PagerDutyAccount of obj, if it references one
+func pagerDutyAccount(obj client.Object) []string {
+	pdi, ok := obj.(*pagerdutyv1alpha1.PagerDutyIntegration)
+	if !ok || pdi.Spec.PagerDutyAccountRef == nil {
+		return nil
+	}
+	return []string{pdi.Spec.PagerDutyAccountRef.Name}
+}
+
+// listPagerDutyIntegrationsForAccount lists the PagerDutyIntegrations using the named
+// PagerDutyAccount
+func listPagerDutyIntegrationsForAccount(ctx context.Context, c client.Reader, name string) (*pagerdutyv1alpha1.PagerDutyIntegrationList, error) {
+	pdiList := &pagerdutyv1alpha1.PagerDutyIntegrationList{}
+	err := c.List(ctx, pdiList, client.MatchingFields{pagerDutyIntegrationAccountIndex: name})
+	return pdiList, err
+}
@@ -0,0 +1,182 @@
+// Copyright 2019 RedHat
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pagerdutyintegration
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	pagerdutyv1alpha1 "github.com/openshift/pagerduty-operator/api/v1alpha1"
+	"github.com/openshift/pagerduty-operator/pkg/localmetrics"
+	pd "github.com/openshift/pagerduty-operator/pkg/pagerduty"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+const (
+	pagerDutyAccountControllerName = "pagerdutyaccount"
+
+	// accountCheckInterval is how often the API key of each PagerDutyAccount is checked
+	// with PD, and its rate limit budget reported
+	accountCheckInterval = 5 * time.Minute
+)
+
+// PagerDutyAccountReconciler reports the health of each PagerDutyAccount: whether PD
+// accepts its API key, how much of its rate limit budget is left and how many
+// PagerDutyIntegrations use it. The accounts themselves are used by the
+// PagerDutyIntegration and ClusterDeployment reconcilers.
+type PagerDutyAccountReconciler struct {
+	client.Client
+	Scheme *runtime.Scheme
+
+	pdclient func(account pd.Account, controllerName string) pd.Client
+}
+
+//+kubebuilder:rbac:groups=pagerduty.openshift.io,resources=pagerdutyaccounts,verbs=get;list;watch;update;patch
+//+kubebuilder:rbac:groups=pagerduty.openshift.io,resources=pagerdutyaccounts/status,verbs=get;update;patch
+
+// Reconcile checks the API key of a PagerDutyAccount with PD and updates its status
+func (r *PagerDutyAccountReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	start := time.Now()
+
+	reqLogger := log.WithValues("Request.Name", req.Name)
+	reqLogger.Info("Reconciling PagerDutyAccount")
+	if r.pdclient == nil {
+		r.pdclient = pd.NewClient
+	}
+
+	defer func() {
+		dur := time.Since(start)
+		localmetrics.SetReconcileDuration(pagerDutyAccountControllerName, dur.Seconds())
+		reqLogger.WithValues("Duration", dur).Info("Reconcile complete")
+	}()
+
+	account := &pagerdutyv1alpha1.PagerDutyAccount{}
+	if err := r.Get(ctx, req.NamespacedName, account); err != nil {
+		if errors.IsNotFound(err) {
+			return reconcile.Result{}, nil
+		}
+		return reconcile.Result{}, err
+	}
+
+	pdiList, err := listPagerDutyIntegrationsForAccount(ctx, r.Client, account.Name)
+	if err != nil {
+		return reconcile.Result{}, err
+	}
+
+	base := account.DeepCopy()
+	now := metav1.Now()
+	account.Status.ObservedGeneration = account.Generation
+	account.Status.LastCheckTime = &now
+	account.Status.PagerDutyIntegrations = int32(len(pdiList.Items))
+	meta.SetStatusCondition(&account.Status.Conditions, r.checkAPIKey(ctx, account))
+	if err := r.Status().Patch(ctx, account, client.MergeFrom(base)); err != nil {
+		reqLogger.Error(err, "Failed to update PagerDutyAccount status")
+		return reconcile.Result{}, err
+	}
+
+	return reconcile.Result{RequeueAfter: accountCheckInterval}, nil
+}
+
+// checkAPIKey checks the API key or OAuth credentials of account with PD, and records the rate limit budget
+// PD reported for it in the status of account. It returns the resulting Authenticated
+// condition.
+func (r *PagerDutyAccountReconciler) checkAPIKey(ctx context.Context, account *pagerdutyv1alpha1.PagerDutyAccount) metav1.Condition {
+	condition := metav1.Condition{
+		Type:               pagerdutyv1alpha1.ConditionAuthenticated,
+		Status:             metav1.ConditionTrue,
+		Reason:             pagerdutyv1alpha1.ReasonAPIKeyAccepted,
+		Message:            "PagerDuty accepted the credentials",
+		ObservedGeneration: account.Generation,
+	}
+
+	ref := account.Spec.APIKeySecretRef
+	pdAccount, err := loadPagerDutyAccount(ctx, r.Client, account)
+	if err != nil {
+		condition.Status = metav1.ConditionFalse
+		condition.Reason = pagerdutyv1alpha1.ReasonSecretLoadFailed
+		condition.Message = fmt.Sprintf("Failed to load PagerDuty credentials from Secret %s/%s: %v", ref.Namespace, ref.Name, err)
+		return condition
+	}
+
+	err = r.pdclient(pdAccount, pagerDutyAccountControllerName).ValidateAPIKey(ctx)
+	switch {
+	case pd.IsUnauthorized(err):
+		condition.Status = metav1.ConditionFalse
+		condition.Reason = pagerdutyv1alpha1.ReasonAPIKeyRejected
+		condition.Message = fmt.Sprintf("PagerDuty rejected the credentials from Secret %s/%s: %v", ref.Namespace, ref.Name, err)
+	case err != nil:
+		condition.Status = metav1.ConditionUnknown
+		condition.Reason = pagerdutyv1alpha1.ReasonCheckFailed
+		condition.Message = fmt.Sprintf("Failed to check the credentials with PagerDuty: %v", err)
+	}
+
+	if quota, ok := pd.QuotaOf(pdAccount); ok {
+		remaining := int32(quota.Remaining)
+		resetTime := metav1.NewTime(quota.ResetTime)
+		account.Status.RateLimitRemaining = &remaining
+		account.Status.RateLimitResetTime = &resetTime
+	}
+	return condition
+}
+
+// SetupWithManager sets up the controller with the Manager.
+// Status updates don't bump the generation, so each PagerDutyAccount is checked again
+// every accountCheckInterval, or when its Secret or the PagerDutyIntegrations using it
+// change.
+func (r *PagerDutyAccountReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewControllerManagedBy(mgr).
+		Named(pagerDutyAccountControllerName).
+		For(&pagerdutyv1alpha1.PagerDutyAccount{}, builder.WithPredicates(predicate.GenerationChangedPredicate{})).
+		Watches(&corev1.Secret{}, handler.EnqueueRequestsFromMapFunc(r.pagerDutyAccountsForSecret)).
+		Watches(&pagerdutyv1alpha1.PagerDutyIntegration{}, handler.EnqueueRequestsFromMapFunc(pagerDutyAccountOf),
+			builder.WithPredicates(predicate.GenerationChangedPredicate{})).
+		Complete(r)
+}
+
+// pagerDutyAccountsForSecret maps a Secret to the PagerDutyAccounts using it as API key
+func (r *PagerDutyAccountReconciler) pagerDutyAccountsForSecret(ctx context.Context, obj client.Object) []reconcile.Request {
+	accountList, err := listPagerDutyAccountsForSecret(ctx, r.Client, types.NamespacedName{Namespace: obj.GetNamespace(), Name: obj.GetName()})
+	if err != nil {
+		log.Error(err, "could not list PagerDutyAccounts")
+		return nil
+	}
+
+	reqs := make([]reconcile.Request, 0, len(accountList.Items))
+	for _, account := range accountList.Items {
+		reqs = append(reqs, reconcile.Request{NamespacedName: types.NamespacedName{Name: account.Name}})
+	}
+	return reqs
+}
+
+// pagerDutyAccountOf maps a PagerDutyIntegration to the PagerDutyAccount it uses, if any
+func pagerDutyAccountOf(_ context.Context, obj client.Object) []reconcile.Request {
+	reqs := []reconcile.Request{}
+	for _, name := range pagerDutyAccount(obj) {
+		reqs = append(reqs, reconcile.Request{NamespacedName: types.NamespacedName{Name: name}})
+	}
+	return reqs
+}
@@ -0,0 +1,157 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pagerdutyintegration
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	pagerdutyv1alpha1 "github.com/openshift/pagerduty-operator/api/v1alpha1"
+	"github.com/openshift/pagerduty-operator/config"
+	pd "github.com/openshift/pagerduty-operator/pkg/pagerduty"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+func TestReconcilePagerDutyAccount(t *testing.T) {
+	tests := []struct {
+		name         string
+		localObjects []client.Object
+		validateErr  error
+		expectStatus metav1.ConditionStatus
+		expectReason string
+	}{
+		{
+			name:         "API key accepted",
+			localObjects: []client.Object{testAccountSecret(), testPagerDutyAccount(), testPagerDutyIntegrationWithAccount()},
+			expectStatus: metav1.ConditionTrue,
+			expectReason: pagerdutyv1alpha1.ReasonAPIKeyAccepted,
+		},
+		{
+			name:         "API key rejected",
+			localObjects: []client.Object{testAccountSecret(), testPagerDutyAccount(), testPagerDutyIntegrationWithAccount()},
+			validateErr:  fmt.Errorf("unable to validate API key: %w", pd.ErrUnauthorized),
+			expectStatus: metav1.ConditionFalse,
+			expectReason: pagerdutyv1alpha1.ReasonAPIKeyRejected,
+		},
+		{
+			name:         "PagerDuty unavailable",
+			localObjects: []client.Object{testAccountSecret(), testPagerDutyAccount(), testPagerDutyIntegrationWithAccount()},
+			validateErr:  fmt.Errorf("unable to validate API key: %w", pd.ErrRateLimited),
+			expectStatus: metav1.ConditionUnknown,
+			expectReason: pagerdutyv1alpha1.ReasonCheckFailed,
+		},
+		{
+			name:         "Secret missing",
+			localObjects: []client.Object{testPagerDutyAccount(), testPagerDutyIntegrationWithAccount()},
+			expectStatus: metav1.ConditionFalse,
+			expectReason: pagerdutyv1alpha1.ReasonSecretLoadFailed,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			mocks := setupDefaultMocks(t, test.localObjects)
+			mocks.mockPDClient.EXPECT().ValidateAPIKey(gomock.Any()).Return(test.validateErr).MaxTimes(1)
+			defer mocks.mockCtrl.Finish()
+
+			var accounts []pd.Account
+			r := &PagerDutyAccountReconciler{
+				Client: mocks.fakeKubeClient,
+				Scheme: scheme.Scheme,
+				pdclient: func(account pd.Account, _ string) pd.Client {
+					accounts = append(accounts, account)
+					return mocks.mockPDClient
+				},
+			}
+			result, err := r.Reconcile(context.TODO(), reconcile.Request{NamespacedName: types.NamespacedName{Name: testPagerDutyAccountName}})
+			assert.NoError(t, err)
+			assert.Equal(t, accountCheckInterval, result.RequeueAfter)
+
+			account := &pagerdutyv1alpha1.PagerDutyAccount{}
+			assert.NoError(t, mocks.fakeKubeClient.Get(context.TODO(), types.NamespacedName{Name: testPagerDutyAccountName}, account))
+			authenticated := meta.FindStatusCondition(account.Status.Conditions, pagerdutyv1alpha1.ConditionAuthenticated)
+			if assert.NotNil(t, authenticated) {
+				assert.Equal(t, test.expectStatus, authenticated.Status)
+				assert.Equal(t, test.expectReason, authenticated.Reason)
+			}
+			assert.Equal(t, int32(1), account.Status.PagerDutyIntegrations)
+			assert.Equal(t, account.Generation, account.Status.ObservedGeneration)
+			assert.NotNil(t, account.Status.LastCheckTime)
+			for _, a := range accounts {
+				assert.Equal(t, pd.Account{APIKey: testAccountAPIKey, Endpoint: pd.EUEndpoint, RequestsPerSecond: 4}, a)
+			}
+		})
+	}
+}
+
+func TestReconcilePagerDutyAccount_RateLimitQuota(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Ratelimit-Remaining", "700")
+		w.Header().Set("Ratelimit-Reset", "30")
+		_, _ = w.Write([]byte(`{"abilities": ["teams"]}`))
+	}))
+	defer server.Close()
+
+	account := testPagerDutyAccount()
+	account.Spec.Endpoint = &pagerdutyv1alpha1.PagerDutyEndpoint{APIURL: server.URL}
+	secret := testAccountSecret()
+	// the rate limit state is shared by every test using the same key
+	secret.Data[config.PagerDutyAPISecretKey] = []byte(t.Name())
+	mocks := setupDefaultMocks(t, []client.Object{secret, account})
+	defer mocks.mockCtrl.Finish()
+
+	// a real client, so the quota is reported by the rate limiter of the key
+	r := &PagerDutyAccountReconciler{Client: mocks.fakeKubeClient, Scheme: scheme.Scheme}
+	_, err := r.Reconcile(context.TODO(), reconcile.Request{NamespacedName: types.NamespacedName{Name: testPagerDutyAccountName}})
+	assert.NoError(t, err)
+
+	assert.NoError(t, mocks.fakeKubeClient.Get(context.TODO(), types.NamespacedName{Name: testPagerDutyAccountName}, account))
+	assert.True(t, meta.IsStatusConditionTrue(account.Status.Conditions, pagerdutyv1alpha1.ConditionAuthenticated))
+	if assert.NotNil(t, account.Status.RateLimitRemaining) {
+		assert.Equal(t, int32(700), *account.Status.RateLimitRemaining)
+	}
+	if assert.NotNil(t, account.Status.RateLimitResetTime) {
+		assert.WithinDuration(t, time.Now().Add(30*time.Second), account.Status.RateLimitResetTime.Time, 5*time.Second)
+	}
+}
+
+func TestPagerDutyAccountsForSecret(t *testing.T) {
+	mocks := setupDefaultMocks(t, []client.Object{testPDISecret(), testAccountSecret(), testPagerDutyAccount()})
+	defer mocks.mockCtrl.Finish()
+
+	r := &PagerDutyAccountReconciler{Client: mocks.fakeKubeClient}
+	assert.Equal(t, []reconcile.Request{{NamespacedName: types.NamespacedName{Name: testPagerDutyAccountName}}},
+		r.pagerDutyAccountsForSecret(context.TODO(), testAccountSecret()))
+	assert.Empty(t, r.pagerDutyAccountsForSecret(context.TODO(), testPDISecret()))
+}
+
+func TestPagerDutyAccountOf(t *testing.T) {
+	assert.Equal(t, []reconcile.Request{{NamespacedName: types.NamespacedName{Name: testPagerDutyAccountName}}},
+		pagerDutyAccountOf(context.TODO(), testPagerDutyIntegrationWithAccount()))
+	assert.Empty(t, pagerDutyAccountOf(context.TODO(), testPagerDutyIntegration()))
+}
@@ -32,9 +32,13 @@ import (
 	"k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
 	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
 	logf "sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 )
 
@@ -58,19 +62,41 @@ func (p pdiReconcileErrors) Error() string {
 	}
 }
 
-// PagerDutyIntegrationReconciler reconciles a PagerDutyIntegration object
+// Unwrap allows errors.Is and errors.As to match any of the errors
+func (p pdiReconcileErrors) Unwrap() []error {
+	return p
+}
+
+// PagerDutyIntegrationReconciler reconciles a PagerDutyIntegration object. The PD
+// services of each ClusterDeployment are managed by the ClusterDeploymentReconciler,
+// this reconciler protects the PagerDutyIntegration until they are cleaned up and
+// reports their state in its status.
 type PagerDutyIntegrationReconciler struct {
 	client.Client
-	Scheme    *runtime.Scheme
-	IsFedramp bool
+	Scheme *runtime.Scheme
+
+	// Results holds the outcome of each ClusterDeployment reconcile, shared with
+	// the ClusterDeploymentReconciler
+	Results *ClusterDeploymentResults
+
+	// KeyValidator checks the API key of each PagerDutyIntegration with PD before it
+	// is used, shared with the ClusterDeploymentReconciler
+	KeyValidator *pd.APIKeyValidator
+
+	// Selectors caches the ClusterDeployment selector of each PagerDutyIntegration, shared
+	// with the ClusterDeploymentReconciler
+	Selectors *SelectorCache
 
 	reqLogger logr.Logger
-	pdclient  func(APIKey string, controllerName string) pd.Client
+	pdclient  func(account pd.Account, controllerName string) pd.Client
 }
 
-//+kubebuilder:rbac:groups=pagerduty.pagerduty.openshift.io,resources=pagerdutyintegrations,verbs=get;list;watch;create;update;patch;delete
-//+kubebuilder:rbac:groups=pagerduty.pagerduty.openshift.io,resources=pagerdutyintegrations/status,verbs=get;update;patch
-//+kubebuilder:rbac:groups=pagerduty.pagerduty.openshift.io,resources=pagerdutyintegrations/finalizers,verbs=update
+//+kubebuilder:rbac:groups=pagerduty.openshift.io,resources=pagerdutyintegrations,verbs=get;list;watch;update;patch
+//+kubebuilder:rbac:groups=pagerduty.openshift.io,resources=pagerdutyintegrations/status,verbs=get;update;patch
+//+kubebuilder:rbac:groups=pagerduty.openshift.io,resources=pagerdutyintegrations/finalizers,verbs=update
+//+kubebuilder:rbac:groups=pagerduty.openshift.io,resources=pagerdutyservices,verbs=get;list;watch;create;update;patch;delete
+//+kubebuilder:rbac:groups=pagerduty.openshift.io,resources=pagerdutyservices/status,verbs=get;update;patch
+//+kubebuilder:rbac:groups=events.k8s.io,resources=events,verbs=create;patch
 
 // Reconcile is part of the main kubernetes reconciliation loop which aims to
 // move the current state of the cluster closer to the desired state.
@@ -80,11 +106,11 @@ type PagerDutyIntegrationReconciler struct {
 func (r *PagerDutyIntegrationReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
 	start := time.Now()
 
+	r.reqLogger = log.WithValues("Request.Namespace", req.Namespace, "Request.Name", req.Name)
+	r.reqLogger.Info("Reconciling PagerDutyIntegration")
 	if r.pdclient == nil {
 		r.pdclient = pd.NewClient
 	}
-	r.reqLogger = log.WithValues("Request.Namespace", req.Namespace, "Request.Name", req.Name)
-	r.reqLogger.Info("Reconciling PagerDutyIntegration")
 
 	defer func() {
 		dur := time.Since(start)
@@ -94,65 +120,41 @@ func (r *PagerDutyIntegrationReconciler) Reconcile(ctx context.Context, req ctrl
 
 	// Fetch the PagerDutyIntegration instance
 	pdi := &pagerdutyv1alpha1.PagerDutyIntegration{}
-	err := r.Get(context.TODO(), req.NamespacedName, pdi)
+	err := r.Get(ctx, req.NamespacedName, pdi)
 	if err != nil {
 		if errors.IsNotFound(err) {
 			// Request object not found, could have been deleted after reconcile request.
 			// Owned objects are automatically garbage collected. For additional cleanup logic use finalizers.
 			// Return and don't requeue
+			r.Results.forgetPagerDutyIntegration(req.NamespacedName)
+			r.Selectors.forget(req.NamespacedName)
 			return r.doNotRequeue()
 		}
 		// Error reading the object - requeue the request.
 		return r.requeueOnErr(err)
 	}
 
-	// fetch all CDs so we can inspect if they're dropped out of the matching CD list
-	allClusterDeployments, err := r.getAllClusterDeployments()
-	if err != nil {
-		return r.requeueOnErr(err)
-	}
-
-	// Fetch ClusterDeployments matching the PDI's ClusterDeployment label selector
-	matchingClusterDeployments, err := r.getMatchingClusterDeployments(pdi)
-	if err != nil {
-		return r.requeueOnErr(err)
-	}
-
-	// the name of the finalizer for the PDI being reconciled
-	clusterDeploymentFinalizerName := config.PagerDutyFinalizerPrefix + pdi.Name
-
-	// load PD api key
-	pdApiKey, err := utils.LoadSecretData(
-		r.Client,
-		pdi.Spec.PagerdutyApiKeySecretRef.Name,
-		pdi.Spec.PagerdutyApiKeySecretRef.Namespace,
-		config.PagerDutyAPISecretKey,
-	)
-	if err != nil {
-		r.reqLogger.Error(err, "Failed to load PagerDuty API key from Secret listed in PagerDutyIntegration CR")
-		localmetrics.UpdateMetricPagerDutyIntegrationSecretLoaded(0, pdi.Name)
-		return r.requeueAfter(10 * time.Minute)
-	}
-	localmetrics.UpdateMetricPagerDutyIntegrationSecretLoaded(1, pdi.Name)
-	pdClient := r.pdclient(pdApiKey, controllerName)
-
-	// If the PDI is being deleted, clean up all ClusterDeployments with matching finalizers
+	// If the PDI is being deleted, the ClusterDeployment controller deletes the PD
+	// services. Wait until it removed the finalizers of all ClusterDeployments.
 	if pdi.DeletionTimestamp != nil {
 		if utils.HasFinalizer(pdi, config.PagerDutyIntegrationFinalizer) {
-			for _, clusterdeployment := range allClusterDeployments.Items {
-				if utils.HasFinalizer(&clusterdeployment, clusterDeploymentFinalizerName) {
-					err = r.handleDelete(pdClient, pdi, &clusterdeployment)
-					if err != nil {
-						return r.requeueOnErr(err)
-					}
-				}
+			finalizedClusterDeployments, err := listFinalizedClusterDeployments(ctx, r.Client, pdi)
+			if err != nil {
+				return r.requeueOnErr(err)
+			}
+			if remaining := len(finalizedClusterDeployments.Items); remaining > 0 {
+				// the finalizer removals enqueue the PDI again
+				r.reqLogger.Info("Waiting for the PD services of the ClusterDeployments to be deleted", "Remaining", remaining)
+				return r.doNotRequeue()
 			}
 
 			localmetrics.DeleteMetricPagerDutyIntegrationSecretLoaded(pdi.Name)
+			localmetrics.DeleteMetricPagerDutyQuarantinedClusterDeployments(pdi.Name)
+			r.Results.forgetPagerDutyIntegration(req.NamespacedName)
 
 			// Once all ClusterDeployments have been cleaned up, delete the PDI finalizer
 			utils.DeleteFinalizer(pdi, config.PagerDutyIntegrationFinalizer)
-			err = r.Update(context.TODO(), pdi)
+			err = r.Update(ctx, pdi)
 			if err != nil {
 				return r.requeueOnErr(err)
 			}
@@ -160,100 +162,79 @@ func (r *PagerDutyIntegrationReconciler) Reconcile(ctx context.Context, req ctrl
 		return r.doNotRequeue()
 	}
 
-	// Ensure the PDI has a finalizer to protect it from deletion
-	if !utils.HasFinalizer(pdi, config.PagerDutyIntegrationFinalizer) {
-		utils.AddFinalizer(pdi, config.PagerDutyIntegrationFinalizer)
-		err := r.Update(context.TODO(), pdi)
-		if err != nil {
+	// load PD api key, the ClusterDeployment controller can't do anything without it
+	account, _, err := loadAccount(ctx, r.Client, pdi)
+	if err != nil {
+		r.reqLogger.Error(err, "Failed to load PagerDuty API key of PagerDutyIntegration CR")
+		localmetrics.UpdateMetricPagerDutyIntegrationSecretLoaded(0, pdi.Name)
+		base := pdi.DeepCopy()
+		setSecretLoadFailedStatus(pdi, err)
+		if err := r.updateStatus(ctx, pdi, base); err != nil {
 			return r.requeueOnErr(err)
 		}
+		return r.requeueAfter(10 * time.Minute)
 	}
 
-	var reconcileErrors pdiReconcileErrors
-	// Process all ClusterDeployments with the PDI finalizer for PD service deletion
-	for _, cd := range allClusterDeployments.Items {
-		if utils.HasFinalizer(&cd, clusterDeploymentFinalizerName) {
-			if cd.DeletionTimestamp != nil {
-				// The ClusterDeployment is being deleted, so delete the PD service
-				err := r.handleDelete(pdClient, pdi, &cd)
-				if err != nil {
-					reconcileErrors = append(reconcileErrors, err)
-				}
-			} else {
-				// The ClusterDeployment is NOT being deleted, is it one of our matched ClusterDeployments?
-				cdIsMatching := false
-				for _, mcd := range matchingClusterDeployments.Items {
-					if cd.Namespace == mcd.Namespace && cd.Name == mcd.Name {
-						cdIsMatching = true
-						break
-					}
-				}
-
-				// It's not a matched ClusterDeployment, delete the PagerDuty service because it shouldn't exist
-				if !cdIsMatching {
-					r.reqLogger.Info(fmt.Sprintf("cleaning up %s as it has a finalizer but no matching label", cd.Name))
-					err := r.handleDelete(pdClient, pdi, &cd)
-					if err != nil {
-						reconcileErrors = append(reconcileErrors, err)
-					}
-				}
-			}
+	// check the key with PD once, rather than failing every ClusterDeployment with it
+	if err := r.KeyValidator.Validate(ctx, r.pdclient(account, controllerName), account); err != nil {
+		if !pd.IsUnauthorized(err) {
+			return r.requeueOnErr(err)
 		}
+		r.reqLogger.Error(err, "PagerDuty rejected the API key of PagerDutyIntegration CR")
+		localmetrics.UpdateMetricPagerDutyIntegrationSecretLoaded(0, pdi.Name)
+		base := pdi.DeepCopy()
+		setAPIKeyRejectedStatus(pdi, err)
+		if err := r.updateStatus(ctx, pdi, base); err != nil {
+			return r.requeueOnErr(err)
+		}
+		return r.requeueAfter(10 * time.Minute)
 	}
+	localmetrics.UpdateMetricPagerDutyIntegrationSecretLoaded(1, pdi.Name)
 
-	// and finally, any Matching CD not being deleted
-	for _, cd := range matchingClusterDeployments.Items {
-		if cd.DeletionTimestamp == nil {
-			if err := r.handleCreate(pdClient, pdi, &cd); err != nil {
-				reconcileErrors = append(reconcileErrors, err)
-			}
-
-			// update alert grouping if necessary
-			err = r.handleUpdate(pdClient, pdi, &cd)
-			if err != nil {
-				reconcileErrors = append(reconcileErrors, err)
-			}
+	// Ensure the PDI has a finalizer to protect it from deletion. The ClusterDeployment
+	// controller waits for it before creating any PD service.
+	if !utils.HasFinalizer(pdi, config.PagerDutyIntegrationFinalizer) {
+		utils.AddFinalizer(pdi, config.PagerDutyIntegrationFinalizer)
+		err := r.Update(ctx, pdi)
+		if err != nil {
+			return r.requeueOnErr(err)
+		}
+	}
 
-			// Do nothing if the orchestration is not enabled and leave it as default for now
-			if pdi.Spec.ServiceOrchestration.Enabled {
-				if err := r.handleServiceOrchestration(pdClient, pdi, &cd); err != nil {
-					reconcileErrors = append(reconcileErrors, err)
-				}
-			}
+	// Fetch ClusterDeployments matching the PDI's ClusterDeployment label selector
+	matchingClusterDeployments, err := r.getMatchingClusterDeployments(ctx, pdi)
+	if err != nil {
+		return r.requeueOnErr(err)
+	}
 
-			if err := r.handleLimitedSupport(pdClient, pdi, &cd); err != nil {
-				reconcileErrors = append(reconcileErrors, err)
-			}
-		}
+	// a template that doesn't parse is reported here, the ClusterDeployment controller skips the PDI
+	templateErr := pd.ValidateServiceTemplates(pdi.Spec.ServiceNameTemplate, pdi.Spec.ServiceDescriptionTemplate)
+	if templateErr != nil {
+		r.reqLogger.Error(templateErr, "Invalid PagerDuty service template in PagerDutyIntegration CR")
 	}
 
-	if len(reconcileErrors) > 0 {
-		return r.requeueOnErr(reconcileErrors)
+	base := pdi.DeepCopy()
+	r.setReconciledStatus(ctx, pdi, matchingClusterDeployments, r.Results.failures(pdi), templateErr)
+	localmetrics.UpdateMetricPagerDutyQuarantinedClusterDeployments(pdi.Status.QuarantinedClusterDeployments, pdi.Name)
+	if err := r.updateStatus(ctx, pdi, base); err != nil {
+		return r.requeueOnErr(err)
 	}
 
 	return r.doNotRequeue()
 }
 
-func (r *PagerDutyIntegrationReconciler) getAllClusterDeployments() (*hivev1.ClusterDeploymentList, error) {
-	allClusterDeployments := &hivev1.ClusterDeploymentList{}
-	err := r.List(context.TODO(), allClusterDeployments, &client.ListOptions{})
-	return allClusterDeployments, err
-}
-
-func (r *PagerDutyIntegrationReconciler) getMatchingClusterDeployments(pdi *pagerdutyv1alpha1.PagerDutyIntegration) (*hivev1.ClusterDeploymentList, error) {
-	sanitized, matchesNothing := sanitizeLabelSelector(&pdi.Spec.ClusterDeploymentSelector, r.reqLogger)
-	if matchesNothing {
-		return &hivev1.ClusterDeploymentList{}, nil
-	}
-
-	selector, err := metav1.LabelSelectorAsSelector(sanitized)
+func (r *PagerDutyIntegrationReconciler) getMatchingClusterDeployments(ctx context.Context, pdi *pagerdutyv1alpha1.PagerDutyIntegration) (*hivev1.ClusterDeploymentList, error) {
+	selector, err := r.Selectors.selectorFor(pdi, r.reqLogger)
 	if err != nil {
 		return nil, err
 	}
+	if selector == nil {
+		return &hivev1.ClusterDeploymentList{}, nil
+	}
 
 	matchingClusterDeployments := &hivev1.ClusterDeploymentList{}
 	listOpts := &client.ListOptions{LabelSelector: selector}
-	err = r.List(context.TODO(), matchingClusterDeployments, listOpts)
+	err = r.List(ctx, matchingClusterDeployments, listOpts)
 	return matchingClusterDeployments, err
 }
 
@@ -314,34 +295,69 @@ func (r *PagerDutyIntegrationReconciler) requeueOnErr(err error) (reconcile.Resu
 	return reconcile.Result{}, err
 }
 
+// minRequeue returns the earliest of two requeue delays, ignoring unset ones
+func minRequeue(a, b time.Duration) time.Duration {
+	if a <= 0 || (b > 0 && b < a) {
+		return b
+	}
+	return a
+}
+
 func (r *PagerDutyIntegrationReconciler) requeueAfter(t time.Duration) (reconcile.Result, error) {
 	return reconcile.Result{RequeueAfter: t}, nil
 }
 
 // SetupWithManager sets up the controller with the Manager.
 // Custom event handlers are utilized here such that when a ClusterDeployment event is created, only associated
-// PagerDutyIntegration CRs are reconciled. Likewise, when events for SyncSets, ConfigMaps, or Secrets are created,
-// if they're owned by a ClusterDeployment, then associated PagerDutyIntegration CRs are reconciled.
+// PagerDutyIntegration CRs are reconciled. Likewise, when events for PagerDutyServices are created, if they're owned
+// by a ClusterDeployment, then associated PagerDutyIntegration CRs are reconciled. The ClusterDeployment controller
+// reports changes to its results through the source of Results.
 func (r *PagerDutyIntegrationReconciler) SetupWithManager(mgr ctrl.Manager) error {
 	return ctrl.NewControllerManagedBy(mgr).
-		For(&pagerdutyv1alpha1.PagerDutyIntegration{}).
+		// Status updates don't bump the generation, so they don't trigger another reconcile
+		For(&pagerdutyv1alpha1.PagerDutyIntegration{}, builder.WithPredicates(predicate.GenerationChangedPredicate{})).
 		Watches(&hivev1.ClusterDeployment{}, &enqueueRequestForClusterDeployment{
-			Client: mgr.GetClient(),
-		}).
-		Watches(&hivev1.SyncSet{}, &enqueueRequestForClusterDeploymentOwner{
-			Client: mgr.GetClient(),
-			Scheme: mgr.GetScheme(),
-		}).
-		Watches(&corev1.ConfigMap{}, &enqueueRequestForClusterDeploymentOwner{
-			Client: mgr.GetClient(),
-			Scheme: mgr.GetScheme(),
-		}).
-		Watches(&corev1.Secret{}, &enqueueRequestForClusterDeploymentOwner{
-			Client: mgr.GetClient(),
-			Scheme: mgr.GetScheme(),
-		}).
-		Watches(&corev1.ConfigMap{}, &enqueueRequestForConfigMap{
-			Client: mgr.GetClient(),
-		}).
+			Client:    mgr.GetClient(),
+			selectors: r.Selectors,
+		}, builder.WithPredicates(clusterDeploymentChangedPredicate(controllerName))).
+		// Drift checks only update the PagerDutyService status, which shouldn't trigger another reconcile
+		Watches(&pagerdutyv1alpha1.PagerDutyService{}, &enqueueRequestForClusterDeploymentOwner{
+			Client:    mgr.GetClient(),
+			Scheme:    mgr.GetScheme(),
+			selectors: r.Selectors,
+		}, builder.WithPredicates(predicate.GenerationChangedPredicate{})).
+		// A fixed or rotated API key is picked up right away
+		Watches(&corev1.Secret{}, handler.EnqueueRequestsFromMapFunc(r.pagerDutyIntegrationsForSecret)).
+		Watches(&pagerdutyv1alpha1.PagerDutyAccount{}, handler.EnqueueRequestsFromMapFunc(r.pagerDutyIntegrationsForAccount),
+			builder.WithPredicates(predicate.GenerationChangedPredicate{})).
+		WatchesRawSource(r.Results.source()).
 		Complete(r)
 }
+
+// pagerDutyIntegrationsForSecret maps a Secret to the PagerDutyIntegrations using it as API key
+func (r *PagerDutyIntegrationReconciler) pagerDutyIntegrationsForSecret(ctx context.Context, obj client.Object) []reconcile.Request {
+	pdiList, err := listPagerDutyIntegrationsForSecret(ctx, r.Client, types.NamespacedName{Namespace: obj.GetNamespace(), Name: obj.GetName()})
+	if err != nil {
+		log.Error(err, "could not list PagerDutyIntegrations")
+		return nil
+	}
+	return pagerDutyIntegrationRequests(pdiList)
+}
+
+// pagerDutyIntegrationsForAccount maps a PagerDutyAccount to the PagerDutyIntegrations using it
+func (r *PagerDutyIntegrationReconciler) pagerDutyIntegrationsForAccount(ctx context.Context, obj client.Object) []reconcile.Request {
+	pdiList, err := listPagerDutyIntegrationsForAccount(ctx, r.Client, obj.GetName())
+	if err != nil {
+		log.Error(err, "could not list PagerDutyIntegrations")
+		return nil
+	}
+	return pagerDutyIntegrationRequests(pdiList)
+}
+
+func pagerDutyIntegrationRequests(pdiList *pagerdutyv1alpha1.PagerDutyIntegrationList) []reconcile.Request {
+	reqs := make([]reconcile.Request, 0, len(pdiList.Items))
+	for _, pdi := range pdiList.Items {
+		reqs = append(reqs, reconcile.Request{NamespacedName: types.NamespacedName{Namespace: pdi.Namespace, Name: pdi.Name}})
+	}
+	return reqs
+}
@@ -16,13 +16,18 @@ package pagerdutyintegration
 
 import (
 	"context"
+	"fmt"
+	"net/http"
 	"strconv"
 	"strings"
 	"testing"
+	"time"
 
+	pdApi "github.com/PagerDuty/go-pagerduty"
 	routev1 "github.com/openshift/api/route/v1"
 	hiveapis "github.com/openshift/hive/apis"
 	hivev1 "github.com/openshift/hive/apis/hive/v1"
+	hiveintv1alpha1 "github.com/openshift/hive/apis/hiveinternal/v1alpha1"
 	pagerdutyapi "github.com/openshift/pagerduty-operator/api"
 	pagerdutyv1alpha1 "github.com/openshift/pagerduty-operator/api/v1alpha1"
 	"github.com/openshift/pagerduty-operator/config"
@@ -33,11 +38,13 @@ import (
 
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
 	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
 	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/events"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/client/fake"
 	logf "sigs.k8s.io/controller-runtime/pkg/log"
@@ -89,10 +96,11 @@ func setupDefaultMocks(t *testing.T, localObjects []client.Object) *mocks {
 	fakeScheme := runtime.NewScheme()
 	utilruntime.Must(routev1.Install(fakeScheme))
 	utilruntime.Must(hivev1.AddToScheme(fakeScheme))
+	utilruntime.Must(hiveintv1alpha1.AddToScheme(fakeScheme))
 	utilruntime.Must(pagerdutyv1alpha1.AddToScheme(fakeScheme))
 
 	mocks := &mocks{
-		fakeKubeClient: fake.NewClientBuilder().WithScheme(fakeScheme).WithObjects(localObjects...).Build(),
+		fakeKubeClient: fake.NewClientBuilder().WithScheme(fakeScheme).WithObjects(localObjects...).WithIndex(&hivev1.ClusterDeployment{}, clusterDeploymentFinalizerIndex, pagerDutyFinalizers).WithIndex(&pagerdutyv1alpha1.PagerDutyIntegration{}, pagerDutyIntegrationAPIKeySecretIndex, apiKeySecret).WithIndex(&pagerdutyv1alpha1.PagerDutyIntegration{}, pagerDutyIntegrationAccountIndex, pagerDutyAccount).WithIndex(&pagerdutyv1alpha1.PagerDutyAccount{}, pagerDutyAccountAPIKeySecretIndex, apiKeySecret).WithStatusSubresource(&pagerdutyv1alpha1.PagerDutyIntegration{}, &pagerdutyv1alpha1.PagerDutyService{}, &pagerdutyv1alpha1.PagerDutyAccount{}).Build(),
 		mockCtrl:       gomock.NewController(t),
 	}
 
@@ -101,6 +109,70 @@ func setupDefaultMocks(t *testing.T, localObjects []client.Object) *mocks {
 	return mocks
 }
 
+// testReconciler runs the PagerDutyIntegration and ClusterDeployment reconcilers the
+// way the manager would after a change to a PagerDutyIntegration
+type testReconciler struct {
+	pdi *PagerDutyIntegrationReconciler
+	cd  *ClusterDeploymentReconciler
+}
+
+func newTestReconciler(m *mocks) *testReconciler {
+	// the API key is accepted unless a test expects otherwise first
+	m.mockPDClient.EXPECT().ValidateAPIKey(gomock.Any()).Return(nil).AnyTimes()
+
+	results := NewClusterDeploymentResults()
+	keyValidator := pd.NewAPIKeyValidator()
+	selectors := NewSelectorCache()
+	return &testReconciler{
+		pdi: &PagerDutyIntegrationReconciler{
+			Client:       m.fakeKubeClient,
+			Scheme:       scheme.Scheme,
+			Results:      results,
+			KeyValidator: keyValidator,
+			Selectors:    selectors,
+			pdclient:     func(pd.Account, string) pd.Client { return m.mockPDClient },
+		},
+		cd: &ClusterDeploymentReconciler{
+			Client:       m.fakeKubeClient,
+			Scheme:       scheme.Scheme,
+			Results:      results,
+			KeyValidator: keyValidator,
+			Selectors:    selectors,
+			// events are dropped unless a test records them
+			Recorder: &events.FakeRecorder{},
+			pdclient: func(pd.Account, string) pd.Client { return m.mockPDClient },
+		},
+	}
+}
+
+// Reconcile reconciles the PagerDutyIntegration, then every ClusterDeployment, then the
+// PagerDutyIntegration again to report their results in its status. It returns the
+// earliest requeue and the first error.
+func (t *testReconciler) Reconcile(ctx context.Context, req reconcile.Request) (reconcile.Result, error) {
+	result, err := t.pdi.Reconcile(ctx, req)
+	if err != nil {
+		return result, err
+	}
+
+	cdList := &hivev1.ClusterDeploymentList{}
+	if err := t.cd.List(ctx, cdList); err != nil {
+		return result, err
+	}
+	var cdErr error
+	for _, cd := range cdList.Items {
+		cdResult, err := t.cd.Reconcile(ctx, reconcile.Request{NamespacedName: types.NamespacedName{Namespace: cd.Namespace, Name: cd.Name}})
+		if err != nil && cdErr == nil {
+			cdErr = err
+		}
+		result.RequeueAfter = minRequeue(result.RequeueAfter, cdResult.RequeueAfter)
+	}
+
+	if _, err := t.pdi.Reconcile(ctx, req); err != nil {
+		return result, err
+	}
+	return result, cdErr
+}
+
 // testPDISecret creates a fake secret containing pagerduty config details to use for testing.
 func testPDISecret() *corev1.Secret {
 	s := &corev1.Secret{
@@ -115,7 +187,8 @@ func testPDISecret() *corev1.Secret {
 	return s
 }
 
-// testCDConfigMap returns a fake configmap for a deployed cluster for testing.
+// testCDConfigMap returns a fake legacy configmap for a deployed cluster, from before the
+// cluster config was moved to a PagerDutyService, for testing.
 func testCDConfigMap(hasLimitedSupport, isOrchestrationEnabled, isOrchestrationApplied, isAlertGroupingConfigured bool) *corev1.ConfigMap {
 	cm := &corev1.ConfigMap{
 		ObjectMeta: metav1.ObjectMeta{
@@ -139,20 +212,31 @@ func testCDConfigMap(hasLimitedSupport, isOrchestrationEnabled, isOrchestrationA
 	return cm
 }
 
-// testCDConfigMap returns a fake configmap without ESCALATION_POLICY_ID key for a deployed cluster for testing.
-func testCDConfigMapWithoutEscalationPolicy(hasLimitedSupport bool) *corev1.ConfigMap {
-	cm := &corev1.ConfigMap{
-		ObjectMeta: metav1.ObjectMeta{
-			Namespace: testNamespace,
-			Name:      config.Name(testServicePrefix, testClusterName, config.ConfigMapSuffix),
-		},
-		Data: map[string]string{
-			"INTEGRATION_ID":  testIntegrationID,
-			"SERVICE_ID":      testServiceID,
-			"LIMITED_SUPPORT": strconv.FormatBool(hasLimitedSupport),
-		},
+// testCDPagerDutyService returns a fake PagerDutyService for a deployed cluster for testing.
+func testCDPagerDutyService(hasLimitedSupport, isOrchestrationEnabled, isOrchestrationApplied, isAlertGroupingConfigured bool) *pagerdutyv1alpha1.PagerDutyService {
+	pdService := kube.GeneratePagerDutyService(testNamespace, config.Name(testServicePrefix, testClusterName, config.PagerDutyServiceSuffix), testClusterName, testPagerDutyIntegration())
+	pdService.Spec.ServiceID = testServiceID
+	pdService.Spec.IntegrationID = testIntegrationID
+	pdService.Spec.EscalationPolicyID = testEscalationPolicy
+	pdService.Spec.LimitedSupport = hasLimitedSupport
+	pdService.Spec.ServiceOrchestrationEnabled = isOrchestrationEnabled
+	pdService.Spec.ServiceOrchestrationRuleApplied = strconv.FormatBool(isOrchestrationApplied)
+	pdService.Spec.ResolveTimeout = testResolveTimeout
+	pdService.Spec.AcknowledgeTimeout = testAcknowledgeTimeout
+
+	if isAlertGroupingConfigured {
+		pdService.Spec.AlertGroupingType = testAlertGroupingType
+		pdService.Spec.AlertGroupingTimeout = testAlertGroupingTimeout
 	}
-	return cm
+	return pdService
+}
+
+// testCDPagerDutyServiceWithoutEscalationPolicy returns a fake PagerDutyService without an escalation policy ID for a deployed cluster for testing.
+func testCDPagerDutyServiceWithoutEscalationPolicy(hasLimitedSupport bool) *pagerdutyv1alpha1.PagerDutyService {
+	pdService := testCDPagerDutyService(hasLimitedSupport, false, false, true)
+	pdService.Spec.EscalationPolicyID = ""
+	pdService.Spec.ServiceOrchestrationRuleApplied = ""
+	return pdService
 }
 
 // testCDSecret returns a Secret that will go in the SyncSet for a deployed cluster to use in testing.
@@ -403,9 +487,9 @@ func TestReconcilePagerDutyIntegration(t *testing.T) {
 			},
 			expectPDSetup: false,
 			setupPDMock: func(r *pd.MockClientMockRecorder) {
-				r.CreateService(gomock.Any()).Return(testIntegrationID, nil).Times(0)
-				r.GetIntegrationKey(gomock.Any()).Return(testIntegrationID, nil).Times(0)
-				r.DeleteService(gomock.Any()).Return(nil).Times(0)
+				r.CreateService(gomock.Any(), gomock.Any()).Return(testIntegrationID, nil).Times(0)
+				r.GetIntegrationKey(gomock.Any(), gomock.Any()).Return(testIntegrationID, nil).Times(0)
+				r.DeleteService(gomock.Any(), gomock.Any()).Return(nil).Times(0)
 			},
 		},
 		{
@@ -417,9 +501,9 @@ func TestReconcilePagerDutyIntegration(t *testing.T) {
 			},
 			expectPDSetup: false,
 			setupPDMock: func(r *pd.MockClientMockRecorder) {
-				r.CreateService(gomock.Any()).Return(testIntegrationID, nil).Times(0)
-				r.GetIntegrationKey(gomock.Any()).Return(testIntegrationID, nil).Times(0)
-				r.DeleteService(gomock.Any()).Return(nil).Times(0)
+				r.CreateService(gomock.Any(), gomock.Any()).Return(testIntegrationID, nil).Times(0)
+				r.GetIntegrationKey(gomock.Any(), gomock.Any()).Return(testIntegrationID, nil).Times(0)
+				r.DeleteService(gomock.Any(), gomock.Any()).Return(nil).Times(0)
 			},
 		},
 		{
@@ -431,17 +515,17 @@ func TestReconcilePagerDutyIntegration(t *testing.T) {
 			},
 			expectPDSetup: true,
 			setupPDMock: func(r *pd.MockClientMockRecorder) {
-				r.CreateService(gomock.Any()).Return(testIntegrationID, nil).Times(1).DoAndReturn(
-					func(data *pd.Data) (string, error) {
+				r.CreateService(gomock.Any(), gomock.Any()).Return(testIntegrationID, nil).Times(1).DoAndReturn(
+					func(_ context.Context, data *pd.Data) (string, error) {
 						data.ServiceID = "XYZ123"
 						data.IntegrationID = "LMN456"
 						data.EscalationPolicyID = testEscalationPolicy
 						return data.IntegrationID, nil
 					})
-				r.GetIntegrationKey(gomock.Any()).Return(testIntegrationID, nil).Times(1)
-				r.DeleteService(gomock.Any()).Return(nil).Times(0)
-				r.DisableService(gomock.Any()).Return(nil).Times(0)
-				r.EnableService(gomock.Any()).Return(nil).Times(0)
+				r.GetIntegrationKey(gomock.Any(), gomock.Any()).Return(testIntegrationID, nil).Times(1)
+				r.DeleteService(gomock.Any(), gomock.Any()).Return(nil).Times(0)
+				r.DisableService(gomock.Any(), gomock.Any()).Return(nil).Times(0)
+				r.EnableService(gomock.Any(), gomock.Any()).Return(nil).Times(0)
 			},
 		},
 		{
@@ -453,18 +537,18 @@ func TestReconcilePagerDutyIntegration(t *testing.T) {
 			},
 			expectPDSetup: true,
 			setupPDMock: func(r *pd.MockClientMockRecorder) {
-				r.CreateService(gomock.Any()).Return(testIntegrationID, nil).Times(1).DoAndReturn(
-					func(data *pd.Data) (string, error) {
+				r.CreateService(gomock.Any(), gomock.Any()).Return(testIntegrationID, nil).Times(1).DoAndReturn(
+					func(_ context.Context, data *pd.Data) (string, error) {
 						data.ServiceID = "XYZ123"
 						data.IntegrationID = "LMN456"
 						data.EscalationPolicyID = testEscalationPolicy
 						return data.IntegrationID, nil
 					})
-				r.GetIntegrationKey(gomock.Any()).Return(testIntegrationID, nil).Times(1)
-				r.UpdateEscalationPolicy(gomock.Any()).Return(nil).Times(0)
-				r.DeleteService(gomock.Any()).Return(nil).Times(0)
-				r.DisableService(gomock.Any()).Return(nil).Times(0)
-				r.EnableService(gomock.Any()).Return(nil).Times(0)
+				r.GetIntegrationKey(gomock.Any(), gomock.Any()).Return(testIntegrationID, nil).Times(1)
+				r.UpdateEscalationPolicy(gomock.Any(), gomock.Any()).Return(nil).Times(0)
+				r.DeleteService(gomock.Any(), gomock.Any()).Return(nil).Times(0)
+				r.DisableService(gomock.Any(), gomock.Any()).Return(nil).Times(0)
+				r.EnableService(gomock.Any(), gomock.Any()).Return(nil).Times(0)
 			},
 		},
 		{
@@ -473,19 +557,19 @@ func TestReconcilePagerDutyIntegration(t *testing.T) {
 				testClusterDeployment(true, true, true, false, false, false, false),
 				testPDISecret(),
 				testPagerDutyIntegration(),
-				testCDConfigMap(false, false, false, true),
+				testCDPagerDutyService(false, false, false, true),
 				testCDSyncSet(),
 				testCDSecret(),
 			},
 			expectPDSetup: true,
 			setupPDMock: func(r *pd.MockClientMockRecorder) {
-				r.CreateService(gomock.Any()).Return(testIntegrationID, nil).Times(0)
-				r.GetIntegrationKey(gomock.Any()).Return(testIntegrationID, nil).Times(0)
-				r.DeleteService(gomock.Any()).Return(nil).Times(0)
+				r.CreateService(gomock.Any(), gomock.Any()).Return(testIntegrationID, nil).Times(0)
+				r.GetIntegrationKey(gomock.Any(), gomock.Any()).Return(testIntegrationID, nil).Times(0)
+				r.DeleteService(gomock.Any(), gomock.Any()).Return(nil).Times(0)
 			},
 		},
 		{
-			name: "Test Managed, Finalizer, Not Deleting, PD Setup, Missing CM",
+			name: "Test Managed, Finalizer, Not Deleting, PD Setup, Missing PagerDutyService",
 			localObjects: []client.Object{
 				testClusterDeployment(true, true, true, false, false, false, false),
 				testPDISecret(),
@@ -495,18 +579,18 @@ func TestReconcilePagerDutyIntegration(t *testing.T) {
 			},
 			expectPDSetup: true,
 			setupPDMock: func(r *pd.MockClientMockRecorder) {
-				r.CreateService(gomock.Any()).Return(testIntegrationID, nil).Times(1).DoAndReturn(
-					func(data *pd.Data) (string, error) {
+				r.CreateService(gomock.Any(), gomock.Any()).Return(testIntegrationID, nil).Times(1).DoAndReturn(
+					func(_ context.Context, data *pd.Data) (string, error) {
 						data.ServiceID = "XYZ123"
 						data.IntegrationID = "LMN456"
 						data.EscalationPolicyID = testEscalationPolicy
 						return data.IntegrationID, nil
 					}) // unit test not support "lookup"
-				r.GetIntegrationKey(gomock.Any()).Return(testIntegrationID, nil).Times(0) // secret already exists, won't recreate
-				r.UpdateEscalationPolicy(gomock.Any()).Return(nil).Times(0)
-				r.DeleteService(gomock.Any()).Return(nil).Times(0)
-				r.DisableService(gomock.Any()).Return(nil).Times(0)
-				r.EnableService(gomock.Any()).Return(nil).Times(0)
+				r.GetIntegrationKey(gomock.Any(), gomock.Any()).Return(testIntegrationID, nil).Times(0) // secret already exists, won't recreate
+				r.UpdateEscalationPolicy(gomock.Any(), gomock.Any()).Return(nil).Times(0)
+				r.DeleteService(gomock.Any(), gomock.Any()).Return(nil).Times(0)
+				r.DisableService(gomock.Any(), gomock.Any()).Return(nil).Times(0)
+				r.EnableService(gomock.Any(), gomock.Any()).Return(nil).Times(0)
 			},
 		},
 		{
@@ -515,16 +599,16 @@ func TestReconcilePagerDutyIntegration(t *testing.T) {
 				testClusterDeployment(true, true, true, false, false, false, false),
 				testPDISecret(),
 				testPagerDutyIntegration(),
-				testCDConfigMap(false, false, false, true),
+				testCDPagerDutyService(false, false, false, true),
 				testCDSecret(),
 			},
 			expectPDSetup: true,
 			setupPDMock: func(r *pd.MockClientMockRecorder) {
-				r.CreateService(gomock.Any()).Return(testIntegrationID, nil).Times(0)
-				r.GetIntegrationKey(gomock.Any()).Return(testIntegrationID, nil).Times(0)
-				r.DeleteService(gomock.Any()).Return(nil).Times(0)
-				r.DisableService(gomock.Any()).Return(nil).Times(0)
-				r.EnableService(gomock.Any()).Return(nil).Times(0)
+				r.CreateService(gomock.Any(), gomock.Any()).Return(testIntegrationID, nil).Times(0)
+				r.GetIntegrationKey(gomock.Any(), gomock.Any()).Return(testIntegrationID, nil).Times(0)
+				r.DeleteService(gomock.Any(), gomock.Any()).Return(nil).Times(0)
+				r.DisableService(gomock.Any(), gomock.Any()).Return(nil).Times(0)
+				r.EnableService(gomock.Any(), gomock.Any()).Return(nil).Times(0)
 			},
 		},
 		{
@@ -533,17 +617,17 @@ func TestReconcilePagerDutyIntegration(t *testing.T) {
 				testClusterDeployment(true, true, true, false, false, false, false),
 				testPDISecret(),
 				testPagerDutyIntegration(),
-				testCDConfigMap(false, false, false, true),
+				testCDPagerDutyService(false, false, false, true),
 				testCDSyncSet(),
 				testCDSecret(),
 			},
 			expectPDSetup: true,
 			setupPDMock: func(r *pd.MockClientMockRecorder) {
-				r.CreateService(gomock.Any()).Return(testIntegrationID, nil).Times(0)
-				r.GetIntegrationKey(gomock.Any()).Return(testIntegrationID, nil).Times(0)
-				r.DeleteService(gomock.Any()).Return(nil).Times(0)
-				r.DisableService(gomock.Any()).Return(nil).Times(0)
-				r.EnableService(gomock.Any()).Return(nil).Times(0)
+				r.CreateService(gomock.Any(), gomock.Any()).Return(testIntegrationID, nil).Times(0)
+				r.GetIntegrationKey(gomock.Any(), gomock.Any()).Return(testIntegrationID, nil).Times(0)
+				r.DeleteService(gomock.Any(), gomock.Any()).Return(nil).Times(0)
+				r.DisableService(gomock.Any(), gomock.Any()).Return(nil).Times(0)
+				r.EnableService(gomock.Any(), gomock.Any()).Return(nil).Times(0)
 			},
 		},
 		{
@@ -555,9 +639,9 @@ func TestReconcilePagerDutyIntegration(t *testing.T) {
 			},
 			expectPDSetup: false,
 			setupPDMock: func(r *pd.MockClientMockRecorder) {
-				r.CreateService(gomock.Any()).Return(testIntegrationID, nil).Times(0)
-				r.GetIntegrationKey(gomock.Any()).Return(testIntegrationID, nil).Times(0)
-				r.DeleteService(gomock.Any()).Return(nil).Times(0)
+				r.CreateService(gomock.Any(), gomock.Any()).Return(testIntegrationID, nil).Times(0)
+				r.GetIntegrationKey(gomock.Any(), gomock.Any()).Return(testIntegrationID, nil).Times(0)
+				r.DeleteService(gomock.Any(), gomock.Any()).Return(nil).Times(0)
 			},
 		},
 		{
@@ -566,16 +650,18 @@ func TestReconcilePagerDutyIntegration(t *testing.T) {
 				testClusterDeployment(true, true, true, true, false, false, false),
 				testPDISecret(),
 				testPagerDutyIntegration(),
-				testCDConfigMap(false, false, false, true),
+				testCDPagerDutyService(false, false, false, true),
 				testCDSyncSet(),
 				testCDSecret(),
 			},
 			expectPDSetup: false,
 			setupPDMock: func(r *pd.MockClientMockRecorder) {
-				r.CreateService(gomock.Any()).Return(testIntegrationID, nil).Times(0)
-				r.GetIntegrationKey(gomock.Any()).Return(testIntegrationID, nil).Times(0)
-				r.GetService(gomock.Any()).Return(nil, nil).Times(1)
-				r.DeleteService(gomock.Any()).Return(nil).Times(1)
+				r.CreateService(gomock.Any(), gomock.Any()).Return(testIntegrationID, nil).Times(0)
+				r.GetIntegrationKey(gomock.Any(), gomock.Any()).Return(testIntegrationID, nil).Times(0)
+				r.GetService(gomock.Any(), gomock.Any()).Return(nil, nil).Times(1)
+				r.ResolvePendingIncidents(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil).Times(1)
+				r.CountUnresolvedIncidents(gomock.Any(), gomock.Any()).Return(0, nil).Times(1)
+				r.DeleteService(gomock.Any(), gomock.Any()).Return(nil).Times(1)
 			},
 		},
 		{
@@ -587,9 +673,9 @@ func TestReconcilePagerDutyIntegration(t *testing.T) {
 			},
 			expectPDSetup: false,
 			setupPDMock: func(r *pd.MockClientMockRecorder) {
-				r.CreateService(gomock.Any()).Return(testIntegrationID, nil).Times(0)
-				r.GetIntegrationKey(gomock.Any()).Return(testIntegrationID, nil).Times(0)
-				r.DeleteService(gomock.Any()).Return(nil).Times(0)
+				r.CreateService(gomock.Any(), gomock.Any()).Return(testIntegrationID, nil).Times(0)
+				r.GetIntegrationKey(gomock.Any(), gomock.Any()).Return(testIntegrationID, nil).Times(0)
+				r.DeleteService(gomock.Any(), gomock.Any()).Return(nil).Times(0)
 			},
 		},
 		{
@@ -601,9 +687,9 @@ func TestReconcilePagerDutyIntegration(t *testing.T) {
 			},
 			expectPDSetup: false,
 			setupPDMock: func(r *pd.MockClientMockRecorder) {
-				r.CreateService(gomock.Any()).Return(testIntegrationID, nil).Times(0)
-				r.GetIntegrationKey(gomock.Any()).Return(testIntegrationID, nil).Times(0)
-				r.DeleteService(gomock.Any()).Return(nil).Times(0)
+				r.CreateService(gomock.Any(), gomock.Any()).Return(testIntegrationID, nil).Times(0)
+				r.GetIntegrationKey(gomock.Any(), gomock.Any()).Return(testIntegrationID, nil).Times(0)
+				r.DeleteService(gomock.Any(), gomock.Any()).Return(nil).Times(0)
 			},
 		},
 		{
@@ -615,9 +701,9 @@ func TestReconcilePagerDutyIntegration(t *testing.T) {
 			},
 			expectPDSetup: false,
 			setupPDMock: func(r *pd.MockClientMockRecorder) {
-				r.CreateService(gomock.Any()).Return(testIntegrationID, nil).Times(0)
-				r.GetIntegrationKey(gomock.Any()).Return(testIntegrationID, nil).Times(0)
-				r.DeleteService(gomock.Any()).Return(nil).Times(0)
+				r.CreateService(gomock.Any(), gomock.Any()).Return(testIntegrationID, nil).Times(0)
+				r.GetIntegrationKey(gomock.Any(), gomock.Any()).Return(testIntegrationID, nil).Times(0)
+				r.DeleteService(gomock.Any(), gomock.Any()).Return(nil).Times(0)
 			},
 		},
 		{
@@ -626,16 +712,18 @@ func TestReconcilePagerDutyIntegration(t *testing.T) {
 				testClusterDeployment(true, false, true, false, false, false, false),
 				testPDISecret(),
 				testPagerDutyIntegration(),
-				testCDConfigMap(false, false, false, true),
+				testCDPagerDutyService(false, false, false, true),
 				testCDSyncSet(),
 				testCDSecret(),
 			},
 			expectPDSetup: false,
 			setupPDMock: func(r *pd.MockClientMockRecorder) {
-				r.CreateService(gomock.Any()).Return(testIntegrationID, nil).Times(0)
-				r.GetIntegrationKey(gomock.Any()).Return(testIntegrationID, nil).Times(0)
-				r.GetService(gomock.Any()).Return(nil, nil).Times(1)
-				r.DeleteService(gomock.Any()).Return(nil).Times(1)
+				r.CreateService(gomock.Any(), gomock.Any()).Return(testIntegrationID, nil).Times(0)
+				r.GetIntegrationKey(gomock.Any(), gomock.Any()).Return(testIntegrationID, nil).Times(0)
+				r.GetService(gomock.Any(), gomock.Any()).Return(nil, nil).Times(1)
+				r.ResolvePendingIncidents(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil).Times(1)
+				r.CountUnresolvedIncidents(gomock.Any(), gomock.Any()).Return(0, nil).Times(1)
+				r.DeleteService(gomock.Any(), gomock.Any()).Return(nil).Times(1)
 			},
 		},
 		{
@@ -644,16 +732,16 @@ func TestReconcilePagerDutyIntegration(t *testing.T) {
 				testClusterDeployment(true, true, true, false, false, false, false),
 				testPDISecret(),
 				testPagerDutyIntegration(),
-				testCDConfigMap(false, false, false, true),
+				testCDPagerDutyService(false, false, false, true),
 				testCDSyncSet(),
 				testCDSecret(),
 			},
 			expectPDSetup: true,
 			setupPDMock: func(r *pd.MockClientMockRecorder) {
-				r.CreateService(gomock.Any()).Return(testIntegrationID, nil).Times(0)
-				r.GetIntegrationKey(gomock.Any()).Return(testIntegrationID, nil).Times(0)
-				r.EnableService(gomock.Any()).Return(nil).Times(0)
-				r.DisableService(gomock.Any()).Return(nil).Times(0)
+				r.CreateService(gomock.Any(), gomock.Any()).Return(testIntegrationID, nil).Times(0)
+				r.GetIntegrationKey(gomock.Any(), gomock.Any()).Return(testIntegrationID, nil).Times(0)
+				r.EnableService(gomock.Any(), gomock.Any()).Return(nil).Times(0)
+				r.DisableService(gomock.Any(), gomock.Any()).Return(nil).Times(0)
 			},
 		},
 		{
@@ -662,16 +750,18 @@ func TestReconcilePagerDutyIntegration(t *testing.T) {
 				testClusterDeployment(true, false, true, false, false, false, false),
 				testPDISecret(),
 				testPagerDutyIntegration(),
-				testCDConfigMap(false, false, false, true),
+				testCDPagerDutyService(false, false, false, true),
 				testCDSyncSet(),
 				testCDSecret(),
 			},
 			expectPDSetup: false,
 			setupPDMock: func(r *pd.MockClientMockRecorder) {
-				r.CreateService(gomock.Any()).Return(testIntegrationID, nil).Times(0)
-				r.GetIntegrationKey(gomock.Any()).Return(testIntegrationID, nil).Times(0)
-				r.GetService(gomock.Any()).Return(nil, nil).Times(1)
-				r.DeleteService(gomock.Any()).Return(nil).Times(1)
+				r.CreateService(gomock.Any(), gomock.Any()).Return(testIntegrationID, nil).Times(0)
+				r.GetIntegrationKey(gomock.Any(), gomock.Any()).Return(testIntegrationID, nil).Times(0)
+				r.GetService(gomock.Any(), gomock.Any()).Return(nil, nil).Times(1)
+				r.ResolvePendingIncidents(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil).Times(1)
+				r.CountUnresolvedIncidents(gomock.Any(), gomock.Any()).Return(0, nil).Times(1)
+				r.DeleteService(gomock.Any(), gomock.Any()).Return(nil).Times(1)
 			},
 		},
 		{
@@ -683,9 +773,9 @@ func TestReconcilePagerDutyIntegration(t *testing.T) {
 			},
 			expectPDSetup: false,
 			setupPDMock: func(r *pd.MockClientMockRecorder) {
-				r.CreateService(gomock.Any()).Return(testIntegrationID, nil).Times(0)
-				r.GetIntegrationKey(gomock.Any()).Return(testIntegrationID, nil).Times(0)
-				r.DeleteService(gomock.Any()).Return(nil).Times(0)
+				r.CreateService(gomock.Any(), gomock.Any()).Return(testIntegrationID, nil).Times(0)
+				r.GetIntegrationKey(gomock.Any(), gomock.Any()).Return(testIntegrationID, nil).Times(0)
+				r.DeleteService(gomock.Any(), gomock.Any()).Return(nil).Times(0)
 			},
 		},
 		{
@@ -694,16 +784,18 @@ func TestReconcilePagerDutyIntegration(t *testing.T) {
 				testClusterDeployment(true, false, true, true, false, false, false),
 				testPDISecret(),
 				testPagerDutyIntegration(),
-				testCDConfigMap(false, false, false, true),
+				testCDPagerDutyService(false, false, false, true),
 				testCDSyncSet(),
 				testCDSecret(),
 			},
 			expectPDSetup: false,
 			setupPDMock: func(r *pd.MockClientMockRecorder) {
-				r.CreateService(gomock.Any()).Return(testIntegrationID, nil).Times(0)
-				r.GetIntegrationKey(gomock.Any()).Return(testIntegrationID, nil).Times(0)
-				r.GetService(gomock.Any()).Return(nil, nil).Times(1)
-				r.DeleteService(gomock.Any()).Return(nil).Times(1)
+				r.CreateService(gomock.Any(), gomock.Any()).Return(testIntegrationID, nil).Times(0)
+				r.GetIntegrationKey(gomock.Any(), gomock.Any()).Return(testIntegrationID, nil).Times(0)
+				r.GetService(gomock.Any(), gomock.Any()).Return(nil, nil).Times(1)
+				r.ResolvePendingIncidents(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil).Times(1)
+				r.CountUnresolvedIncidents(gomock.Any(), gomock.Any()).Return(0, nil).Times(1)
+				r.DeleteService(gomock.Any(), gomock.Any()).Return(nil).Times(1)
 			},
 		},
 		{
@@ -715,9 +807,9 @@ func TestReconcilePagerDutyIntegration(t *testing.T) {
 			},
 			expectPDSetup: false,
 			setupPDMock: func(r *pd.MockClientMockRecorder) {
-				r.CreateService(gomock.Any()).Return(testIntegrationID, nil).Times(0)
-				r.GetIntegrationKey(gomock.Any()).Return(testIntegrationID, nil).Times(0)
-				r.DeleteService(gomock.Any()).Return(nil).Times(0)
+				r.CreateService(gomock.Any(), gomock.Any()).Return(testIntegrationID, nil).Times(0)
+				r.GetIntegrationKey(gomock.Any(), gomock.Any()).Return(testIntegrationID, nil).Times(0)
+				r.DeleteService(gomock.Any(), gomock.Any()).Return(nil).Times(0)
 			},
 		},
 		{
@@ -729,17 +821,17 @@ func TestReconcilePagerDutyIntegration(t *testing.T) {
 			},
 			expectPDSetup: true,
 			setupPDMock: func(r *pd.MockClientMockRecorder) {
-				r.CreateService(gomock.Any()).Return(testIntegrationID, nil).Times(1).DoAndReturn(
-					func(data *pd.Data) (string, error) {
+				r.CreateService(gomock.Any(), gomock.Any()).Return(testIntegrationID, nil).Times(1).DoAndReturn(
+					func(_ context.Context, data *pd.Data) (string, error) {
 						data.ServiceID = "XYZ123"
 						data.IntegrationID = "LMN456"
 						data.EscalationPolicyID = testEscalationPolicy
 						return data.IntegrationID, nil
 					})
-				r.GetIntegrationKey(gomock.Any()).Return(testIntegrationID, nil).Times(1)
-				r.UpdateEscalationPolicy(gomock.Any()).Return(nil).Times(0)
-				r.DisableService(gomock.Any()).Return(nil).Times(0)
-				r.EnableService(gomock.Any()).Return(nil).Times(0)
+				r.GetIntegrationKey(gomock.Any(), gomock.Any()).Return(testIntegrationID, nil).Times(1)
+				r.UpdateEscalationPolicy(gomock.Any(), gomock.Any()).Return(nil).Times(0)
+				r.DisableService(gomock.Any(), gomock.Any()).Return(nil).Times(0)
+				r.EnableService(gomock.Any(), gomock.Any()).Return(nil).Times(0)
 			},
 		},
 		{
@@ -748,16 +840,16 @@ func TestReconcilePagerDutyIntegration(t *testing.T) {
 				testClusterDeployment(true, true, true, false, true, false, false),
 				testPDISecret(),
 				testPagerDutyIntegration(),
-				testCDConfigMap(false, false, false, true),
+				testCDPagerDutyService(false, false, false, true),
 				testCDSyncSet(),
 				testCDSecret(),
 			},
 			expectPDSetup: true,
 			setupPDMock: func(r *pd.MockClientMockRecorder) {
-				r.CreateService(gomock.Any()).Return(testIntegrationID, nil).Times(0)
-				r.GetIntegrationKey(gomock.Any()).Return(testIntegrationID, nil).Times(0)
-				r.DisableService(gomock.Any()).Return(nil).Times(0)
-				r.EnableService(gomock.Any()).Return(nil).Times(0)
+				r.CreateService(gomock.Any(), gomock.Any()).Return(testIntegrationID, nil).Times(0)
+				r.GetIntegrationKey(gomock.Any(), gomock.Any()).Return(testIntegrationID, nil).Times(0)
+				r.DisableService(gomock.Any(), gomock.Any()).Return(nil).Times(0)
+				r.EnableService(gomock.Any(), gomock.Any()).Return(nil).Times(0)
 			},
 		},
 		{
@@ -768,14 +860,16 @@ func TestReconcilePagerDutyIntegration(t *testing.T) {
 				testCDSecret(),
 				testCDSyncSet(),
 				testPagerDutyIntegration(),
-				testCDConfigMap(false, false, false, true),
+				testCDPagerDutyService(false, false, false, true),
 			},
 			expectPDSetup: true,
 			setupPDMock: func(r *pd.MockClientMockRecorder) {
-				r.CreateService(gomock.Any()).Return(testIntegrationID, nil).Times(0)
-				r.GetIntegrationKey(gomock.Any()).Return(testIntegrationID, nil).Times(0)
-				r.DisableService(gomock.Any()).Return(nil).Times(1)
-				r.EnableService(gomock.Any()).Return(nil).Times(0)
+				r.CreateService(gomock.Any(), gomock.Any()).Return(testIntegrationID, nil).Times(0)
+				r.GetIntegrationKey(gomock.Any(), gomock.Any()).Return(testIntegrationID, nil).Times(0)
+				r.ResolvePendingIncidents(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil).Times(1)
+				r.CountUnresolvedIncidents(gomock.Any(), gomock.Any()).Return(0, nil).Times(1)
+				r.DisableService(gomock.Any(), gomock.Any()).Return(nil).Times(1)
+				r.EnableService(gomock.Any(), gomock.Any()).Return(nil).Times(0)
 			},
 		},
 		{
@@ -786,66 +880,66 @@ func TestReconcilePagerDutyIntegration(t *testing.T) {
 				testCDSecret(),
 				testCDSyncSet(),
 				testPagerDutyIntegration(),
-				testCDConfigMap(true, false, false, true),
+				testCDPagerDutyService(true, false, false, true),
 			},
 			expectPDSetup: true,
 			setupPDMock: func(r *pd.MockClientMockRecorder) {
-				r.CreateService(gomock.Any()).Return(testIntegrationID, nil).Times(0)
-				r.GetIntegrationKey(gomock.Any()).Return(testIntegrationID, nil).Times(0)
-				r.EnableService(gomock.Any()).Return(nil).Times(1)
-				r.DisableService(gomock.Any()).Return(nil).Times(0)
+				r.CreateService(gomock.Any(), gomock.Any()).Return(testIntegrationID, nil).Times(0)
+				r.GetIntegrationKey(gomock.Any(), gomock.Any()).Return(testIntegrationID, nil).Times(0)
+				r.EnableService(gomock.Any(), gomock.Any()).Return(nil).Times(1)
+				r.DisableService(gomock.Any(), gomock.Any()).Return(nil).Times(0)
 			},
 		},
 		{
-			name: "Test Managed, Finalizer, Not Deleting, PD Setup, Not in Limited Support, CM PDI escalation policy missing",
+			name: "Test Managed, Finalizer, Not Deleting, PD Setup, Not in Limited Support, PagerDutyService escalation policy missing",
 			localObjects: []client.Object{
 				testClusterDeployment(true, true, true, false, false, false, false),
 				testPDISecret(),
 				testPagerDutyIntegration(),
-				testCDConfigMapWithoutEscalationPolicy(false),
+				testCDPagerDutyServiceWithoutEscalationPolicy(false),
 				testCDSyncSet(),
 				testCDSecret(),
 			},
 			expectPDSetup: true,
 			setupPDMock: func(r *pd.MockClientMockRecorder) {
-				r.UpdateEscalationPolicy(gomock.Any()).Return(nil).Times(0)
-				r.GetService(gomock.Any()).Return(nil, nil).Times(0)
+				r.UpdateEscalationPolicy(gomock.Any(), gomock.Any()).Return(nil).Times(0)
+				r.GetService(gomock.Any(), gomock.Any()).Return(nil, nil).Times(0)
 			},
 		},
 		{
-			name: "Test Managed, Finalizer, Not Deleting, PD Setup, Not Limited Support, CM PDI escalation policy exists, PDI escalation policy changed",
+			name: "Test Managed, Finalizer, Not Deleting, PD Setup, Not Limited Support, PagerDutyService escalation policy exists, PDI escalation policy changed",
 			localObjects: []client.Object{
 				testClusterDeployment(true, true, true, false, false, false, false),
 				testPDISecret(),
 				updatedTestPagerDutyIntegration(),
-				testCDConfigMap(false, false, false, true),
+				testCDPagerDutyService(false, false, false, true),
 				testCDSyncSet(),
 				testCDSecret(),
 			},
 			expectPDSetup: true,
 			setupPDMock: func(r *pd.MockClientMockRecorder) {
-				r.UpdateEscalationPolicy(gomock.Any()).Return(nil).Times(1)
-				r.GetService(gomock.Any()).Return(nil, nil).Times(0)
-				r.CreateService(gomock.Any()).Return(testIntegrationID, nil).Times(0)
-				r.GetIntegrationKey(gomock.Any()).Return(testIntegrationID, nil).Times(0)
+				r.UpdateEscalationPolicy(gomock.Any(), gomock.Any()).Return(nil).Times(1)
+				r.GetService(gomock.Any(), gomock.Any()).Return(nil, nil).Times(0)
+				r.CreateService(gomock.Any(), gomock.Any()).Return(testIntegrationID, nil).Times(0)
+				r.GetIntegrationKey(gomock.Any(), gomock.Any()).Return(testIntegrationID, nil).Times(0)
 			},
 		},
 		{
-			name: "Test Managed, Finalizer, Not Deleting, PD Setup, Not Limited Support, CM PDI escalation policy missing, PDI escalation policy changed",
+			name: "Test Managed, Finalizer, Not Deleting, PD Setup, Not Limited Support, PagerDutyService escalation policy missing, PDI escalation policy changed",
 			localObjects: []client.Object{
 				testClusterDeployment(true, true, true, false, false, false, false),
 				testPDISecret(),
 				updatedTestPagerDutyIntegration(),
-				testCDConfigMapWithoutEscalationPolicy(false),
+				testCDPagerDutyServiceWithoutEscalationPolicy(false),
 				testCDSyncSet(),
 				testCDSecret(),
 			},
 			expectPDSetup: true,
 			setupPDMock: func(r *pd.MockClientMockRecorder) {
-				r.UpdateEscalationPolicy(gomock.Any()).Return(nil).Times(0)
-				r.GetService(gomock.Any()).Return(nil, nil).Times(0)
-				r.CreateService(gomock.Any()).Return(testIntegrationID, nil).Times(0)
-				r.GetIntegrationKey(gomock.Any()).Return(testIntegrationID, nil).Times(0)
+				r.UpdateEscalationPolicy(gomock.Any(), gomock.Any()).Return(nil).Times(0)
+				r.GetService(gomock.Any(), gomock.Any()).Return(nil, nil).Times(0)
+				r.CreateService(gomock.Any(), gomock.Any()).Return(testIntegrationID, nil).Times(0)
+				r.GetIntegrationKey(gomock.Any(), gomock.Any()).Return(testIntegrationID, nil).Times(0)
 			},
 		},
 		{
@@ -858,24 +952,24 @@ func TestReconcilePagerDutyIntegration(t *testing.T) {
 			},
 			expectPDSetup: true,
 			setupPDMock: func(r *pd.MockClientMockRecorder) {
-				r.CreateService(gomock.Any()).Return(testIntegrationID, nil).Times(1).DoAndReturn(
-					func(data *pd.Data) (string, error) {
+				r.CreateService(gomock.Any(), gomock.Any()).Return(testIntegrationID, nil).Times(1).DoAndReturn(
+					func(_ context.Context, data *pd.Data) (string, error) {
 						data.ServiceID = "XYZ123"
 						data.IntegrationID = "LMN456"
 						data.EscalationPolicyID = testEscalationPolicy
 						return data.IntegrationID, nil
 					})
-				r.GetIntegrationKey(gomock.Any()).Return(testIntegrationID, nil).Times(1)
-				r.UpdateEscalationPolicy(gomock.Any()).Return(nil).Times(0)
-				r.ToggleServiceOrchestration(gomock.Any(), gomock.Any()).Return(nil).Times(1)
-				r.ApplyServiceOrchestrationRule(gomock.Any()).Return(nil).Times(1)
-				r.DeleteService(gomock.Any()).Return(nil).Times(0)
-				r.DisableService(gomock.Any()).Return(nil).Times(0)
-				r.EnableService(gomock.Any()).Return(nil).Times(0)
+				r.GetIntegrationKey(gomock.Any(), gomock.Any()).Return(testIntegrationID, nil).Times(1)
+				r.UpdateEscalationPolicy(gomock.Any(), gomock.Any()).Return(nil).Times(0)
+				r.ToggleServiceOrchestration(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil).Times(1)
+				r.ApplyServiceOrchestrationRule(gomock.Any(), gomock.Any()).Return(nil).Times(1)
+				r.DeleteService(gomock.Any(), gomock.Any()).Return(nil).Times(0)
+				r.DisableService(gomock.Any(), gomock.Any()).Return(nil).Times(0)
+				r.EnableService(gomock.Any(), gomock.Any()).Return(nil).Times(0)
 			},
 		},
 		{
-			name: "Test Managed, Finalizer, Not Deleting, PD Setup, Service Orchestration enabled, CM orchestration is enabled, CM orchestration is applied",
+			name: "Test Managed, Finalizer, Not Deleting, PD Setup, Service Orchestration enabled, PagerDutyService orchestration is enabled, PagerDutyService orchestration is applied",
 			localObjects: []client.Object{
 				testClusterDeployment(true, true, true, false, false, false, false),
 				testPDISecret(),
@@ -884,25 +978,25 @@ func TestReconcilePagerDutyIntegration(t *testing.T) {
 			},
 			expectPDSetup: true,
 			setupPDMock: func(r *pd.MockClientMockRecorder) {
-				r.CreateService(gomock.Any()).Return(testIntegrationID, nil).Times(1).DoAndReturn(
-					func(data *pd.Data) (string, error) {
+				r.CreateService(gomock.Any(), gomock.Any()).Return(testIntegrationID, nil).Times(1).DoAndReturn(
+					func(_ context.Context, data *pd.Data) (string, error) {
 						data.ServiceID = "XYZ123"
 						data.IntegrationID = "LMN456"
 						data.EscalationPolicyID = testEscalationPolicy
 						data.ServiceOrchestrationEnabled = true
 						return data.IntegrationID, nil
 					})
-				r.GetIntegrationKey(gomock.Any()).Return(testIntegrationID, nil).Times(1)
-				r.UpdateEscalationPolicy(gomock.Any()).Return(nil).Times(0)
-				r.ToggleServiceOrchestration(gomock.Any(), gomock.Any()).Return(nil).Times(0)
-				r.ApplyServiceOrchestrationRule(gomock.Any()).Return(nil).Times(1)
-				r.DeleteService(gomock.Any()).Return(nil).Times(0)
-				r.DisableService(gomock.Any()).Return(nil).Times(0)
-				r.EnableService(gomock.Any()).Return(nil).Times(0)
+				r.GetIntegrationKey(gomock.Any(), gomock.Any()).Return(testIntegrationID, nil).Times(1)
+				r.UpdateEscalationPolicy(gomock.Any(), gomock.Any()).Return(nil).Times(0)
+				r.ToggleServiceOrchestration(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil).Times(0)
+				r.ApplyServiceOrchestrationRule(gomock.Any(), gomock.Any()).Return(nil).Times(1)
+				r.DeleteService(gomock.Any(), gomock.Any()).Return(nil).Times(0)
+				r.DisableService(gomock.Any(), gomock.Any()).Return(nil).Times(0)
+				r.EnableService(gomock.Any(), gomock.Any()).Return(nil).Times(0)
 			},
 		},
 		{
-			name: "Test Managed, Finalizer, Not Deleting, PD Setup, Service Orchestration enabled, CM orchestration is enabled, CM orchestration not applied",
+			name: "Test Managed, Finalizer, Not Deleting, PD Setup, Service Orchestration enabled, PagerDutyService orchestration is enabled, PagerDutyService orchestration not applied",
 			localObjects: []client.Object{
 				testClusterDeployment(true, true, true, false, false, false, false),
 				testPDISecret(),
@@ -911,25 +1005,25 @@ func TestReconcilePagerDutyIntegration(t *testing.T) {
 			},
 			expectPDSetup: true,
 			setupPDMock: func(r *pd.MockClientMockRecorder) {
-				r.CreateService(gomock.Any()).Return(testIntegrationID, nil).Times(1).DoAndReturn(
-					func(data *pd.Data) (string, error) {
+				r.CreateService(gomock.Any(), gomock.Any()).Return(testIntegrationID, nil).Times(1).DoAndReturn(
+					func(_ context.Context, data *pd.Data) (string, error) {
 						data.ServiceID = "XYZ123"
 						data.IntegrationID = "LMN456"
 						data.EscalationPolicyID = testEscalationPolicy
 						data.ServiceOrchestrationEnabled = true
 						return data.IntegrationID, nil
 					})
-				r.GetIntegrationKey(gomock.Any()).Return(testIntegrationID, nil).Times(1)
-				r.UpdateEscalationPolicy(gomock.Any()).Return(nil).Times(0)
-				r.ToggleServiceOrchestration(gomock.Any(), gomock.Any()).Return(nil).Times(0)
-				r.ApplyServiceOrchestrationRule(gomock.Any()).Return(nil).Times(1)
-				r.DeleteService(gomock.Any()).Return(nil).Times(0)
-				r.DisableService(gomock.Any()).Return(nil).Times(0)
-				r.EnableService(gomock.Any()).Return(nil).Times(0)
+				r.GetIntegrationKey(gomock.Any(), gomock.Any()).Return(testIntegrationID, nil).Times(1)
+				r.UpdateEscalationPolicy(gomock.Any(), gomock.Any()).Return(nil).Times(0)
+				r.ToggleServiceOrchestration(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil).Times(0)
+				r.ApplyServiceOrchestrationRule(gomock.Any(), gomock.Any()).Return(nil).Times(1)
+				r.DeleteService(gomock.Any(), gomock.Any()).Return(nil).Times(0)
+				r.DisableService(gomock.Any(), gomock.Any()).Return(nil).Times(0)
+				r.EnableService(gomock.Any(), gomock.Any()).Return(nil).Times(0)
 			},
 		},
 		{
-			name: "Test Managed, Finalizer, Not Deleting, PD Setup, Service Orchestration enabled, CM orchestration not enabled, CM orchestration is applied",
+			name: "Test Managed, Finalizer, Not Deleting, PD Setup, Service Orchestration enabled, PagerDutyService orchestration not enabled, PagerDutyService orchestration is applied",
 			localObjects: []client.Object{
 				testClusterDeployment(true, true, true, false, false, false, false),
 				testPDISecret(),
@@ -938,24 +1032,24 @@ func TestReconcilePagerDutyIntegration(t *testing.T) {
 			},
 			expectPDSetup: true,
 			setupPDMock: func(r *pd.MockClientMockRecorder) {
-				r.CreateService(gomock.Any()).Return(testIntegrationID, nil).Times(1).DoAndReturn(
-					func(data *pd.Data) (string, error) {
+				r.CreateService(gomock.Any(), gomock.Any()).Return(testIntegrationID, nil).Times(1).DoAndReturn(
+					func(_ context.Context, data *pd.Data) (string, error) {
 						data.ServiceID = "XYZ123"
 						data.IntegrationID = "LMN456"
 						data.EscalationPolicyID = testEscalationPolicy
 						return data.IntegrationID, nil
 					})
-				r.GetIntegrationKey(gomock.Any()).Return(testIntegrationID, nil).Times(1)
-				r.UpdateEscalationPolicy(gomock.Any()).Return(nil).Times(0)
-				r.ToggleServiceOrchestration(gomock.Any(), gomock.Any()).Return(nil).Times(1)
-				r.ApplyServiceOrchestrationRule(gomock.Any()).Return(nil).Times(1)
-				r.DeleteService(gomock.Any()).Return(nil).Times(0)
-				r.DisableService(gomock.Any()).Return(nil).Times(0)
-				r.EnableService(gomock.Any()).Return(nil).Times(0)
+				r.GetIntegrationKey(gomock.Any(), gomock.Any()).Return(testIntegrationID, nil).Times(1)
+				r.UpdateEscalationPolicy(gomock.Any(), gomock.Any()).Return(nil).Times(0)
+				r.ToggleServiceOrchestration(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil).Times(1)
+				r.ApplyServiceOrchestrationRule(gomock.Any(), gomock.Any()).Return(nil).Times(1)
+				r.DeleteService(gomock.Any(), gomock.Any()).Return(nil).Times(0)
+				r.DisableService(gomock.Any(), gomock.Any()).Return(nil).Times(0)
+				r.EnableService(gomock.Any(), gomock.Any()).Return(nil).Times(0)
 			},
 		},
 		{
-			name: "Test Managed, Finalizer, Not Deleting, PD Setup, Service Orchestration enabled, CM orchestration not enabled, CM orchestration not applied",
+			name: "Test Managed, Finalizer, Not Deleting, PD Setup, Service Orchestration enabled, PagerDutyService orchestration not enabled, PagerDutyService orchestration not applied",
 			localObjects: []client.Object{
 				testClusterDeployment(true, true, true, false, false, false, false),
 				testPDISecret(),
@@ -964,20 +1058,20 @@ func TestReconcilePagerDutyIntegration(t *testing.T) {
 			},
 			expectPDSetup: true,
 			setupPDMock: func(r *pd.MockClientMockRecorder) {
-				r.CreateService(gomock.Any()).Return(testIntegrationID, nil).Times(1).DoAndReturn(
-					func(data *pd.Data) (string, error) {
+				r.CreateService(gomock.Any(), gomock.Any()).Return(testIntegrationID, nil).Times(1).DoAndReturn(
+					func(_ context.Context, data *pd.Data) (string, error) {
 						data.ServiceID = "XYZ123"
 						data.IntegrationID = "LMN456"
 						data.EscalationPolicyID = testEscalationPolicy
 						return data.IntegrationID, nil
 					})
-				r.GetIntegrationKey(gomock.Any()).Return(testIntegrationID, nil).Times(1)
-				r.UpdateEscalationPolicy(gomock.Any()).Return(nil).Times(0)
-				r.ToggleServiceOrchestration(gomock.Any(), gomock.Any()).Return(nil).Times(1)
-				r.ApplyServiceOrchestrationRule(gomock.Any()).Return(nil).Times(1)
-				r.DeleteService(gomock.Any()).Return(nil).Times(0)
-				r.DisableService(gomock.Any()).Return(nil).Times(0)
-				r.EnableService(gomock.Any()).Return(nil).Times(0)
+				r.GetIntegrationKey(gomock.Any(), gomock.Any()).Return(testIntegrationID, nil).Times(1)
+				r.UpdateEscalationPolicy(gomock.Any(), gomock.Any()).Return(nil).Times(0)
+				r.ToggleServiceOrchestration(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil).Times(1)
+				r.ApplyServiceOrchestrationRule(gomock.Any(), gomock.Any()).Return(nil).Times(1)
+				r.DeleteService(gomock.Any(), gomock.Any()).Return(nil).Times(0)
+				r.DisableService(gomock.Any(), gomock.Any()).Return(nil).Times(0)
+				r.EnableService(gomock.Any(), gomock.Any()).Return(nil).Times(0)
 			},
 		},
 		{
@@ -990,20 +1084,20 @@ func TestReconcilePagerDutyIntegration(t *testing.T) {
 			},
 			expectPDSetup: true,
 			setupPDMock: func(r *pd.MockClientMockRecorder) {
-				r.CreateService(gomock.Any()).Return(testIntegrationID, nil).Times(1).DoAndReturn(
-					func(data *pd.Data) (string, error) {
+				r.CreateService(gomock.Any(), gomock.Any()).Return(testIntegrationID, nil).Times(1).DoAndReturn(
+					func(_ context.Context, data *pd.Data) (string, error) {
 						data.ServiceID = "XYZ123"
 						data.IntegrationID = "LMN456"
 						data.EscalationPolicyID = testEscalationPolicy
 						return data.IntegrationID, nil
 					})
-				r.GetIntegrationKey(gomock.Any()).Return(testIntegrationID, nil).Times(1)
-				r.UpdateEscalationPolicy(gomock.Any()).Return(nil).Times(0)
-				r.ToggleServiceOrchestration(gomock.Any(), gomock.Any()).Return(nil).Times(1)
-				r.ApplyServiceOrchestrationRule(gomock.Any()).Return(nil).Times(1)
-				r.DeleteService(gomock.Any()).Return(nil).Times(0)
-				r.DisableService(gomock.Any()).Return(nil).Times(0)
-				r.EnableService(gomock.Any()).Return(nil).Times(0)
+				r.GetIntegrationKey(gomock.Any(), gomock.Any()).Return(testIntegrationID, nil).Times(1)
+				r.UpdateEscalationPolicy(gomock.Any(), gomock.Any()).Return(nil).Times(0)
+				r.ToggleServiceOrchestration(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil).Times(1)
+				r.ApplyServiceOrchestrationRule(gomock.Any(), gomock.Any()).Return(nil).Times(1)
+				r.DeleteService(gomock.Any(), gomock.Any()).Return(nil).Times(0)
+				r.DisableService(gomock.Any(), gomock.Any()).Return(nil).Times(0)
+				r.EnableService(gomock.Any(), gomock.Any()).Return(nil).Times(0)
 			},
 		},
 		{
@@ -1016,20 +1110,20 @@ func TestReconcilePagerDutyIntegration(t *testing.T) {
 			},
 			expectPDSetup: true,
 			setupPDMock: func(r *pd.MockClientMockRecorder) {
-				r.CreateService(gomock.Any()).Return(testIntegrationID, nil).Times(1).DoAndReturn(
-					func(data *pd.Data) (string, error) {
+				r.CreateService(gomock.Any(), gomock.Any()).Return(testIntegrationID, nil).Times(1).DoAndReturn(
+					func(_ context.Context, data *pd.Data) (string, error) {
 						data.ServiceID = "XYZ123"
 						data.IntegrationID = "LMN456"
 						data.EscalationPolicyID = testEscalationPolicy
 						return data.IntegrationID, nil
 					})
-				r.GetIntegrationKey(gomock.Any()).Return(testIntegrationID, nil).Times(1)
-				r.UpdateEscalationPolicy(gomock.Any()).Return(nil).Times(0)
-				r.ToggleServiceOrchestration(gomock.Any(), gomock.Any()).Return(nil).Times(0)
-				r.ApplyServiceOrchestrationRule(gomock.Any()).Return(nil).Times(0)
-				r.DeleteService(gomock.Any()).Return(nil).Times(0)
-				r.DisableService(gomock.Any()).Return(nil).Times(0)
-				r.EnableService(gomock.Any()).Return(nil).Times(0)
+				r.GetIntegrationKey(gomock.Any(), gomock.Any()).Return(testIntegrationID, nil).Times(1)
+				r.UpdateEscalationPolicy(gomock.Any(), gomock.Any()).Return(nil).Times(0)
+				r.ToggleServiceOrchestration(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil).Times(0)
+				r.ApplyServiceOrchestrationRule(gomock.Any(), gomock.Any()).Return(nil).Times(0)
+				r.DeleteService(gomock.Any(), gomock.Any()).Return(nil).Times(0)
+				r.DisableService(gomock.Any(), gomock.Any()).Return(nil).Times(0)
+				r.EnableService(gomock.Any(), gomock.Any()).Return(nil).Times(0)
 			},
 		},
 		{
@@ -1041,20 +1135,20 @@ func TestReconcilePagerDutyIntegration(t *testing.T) {
 			},
 			expectPDSetup: true,
 			setupPDMock: func(r *pd.MockClientMockRecorder) {
-				r.CreateService(gomock.Any()).Return(testIntegrationID, nil).Times(1).DoAndReturn(
-					func(data *pd.Data) (string, error) {
+				r.CreateService(gomock.Any(), gomock.Any()).Return(testIntegrationID, nil).Times(1).DoAndReturn(
+					func(_ context.Context, data *pd.Data) (string, error) {
 						data.ServiceID = "XYZ123"
 						data.IntegrationID = "LMN456"
 						data.EscalationPolicyID = testEscalationPolicy
 						return data.IntegrationID, nil
 					})
-				r.GetIntegrationKey(gomock.Any()).Return(testIntegrationID, nil).Times(1)
-				r.UpdateEscalationPolicy(gomock.Any()).Return(nil).Times(0)
-				r.ToggleServiceOrchestration(gomock.Any(), gomock.Any()).Return(nil).Times(1)
-				r.ApplyServiceOrchestrationRule(gomock.Any()).Return(nil).Times(0)
-				r.DeleteService(gomock.Any()).Return(nil).Times(0)
-				r.DisableService(gomock.Any()).Return(nil).Times(0)
-				r.EnableService(gomock.Any()).Return(nil).Times(0)
+				r.GetIntegrationKey(gomock.Any(), gomock.Any()).Return(testIntegrationID, nil).Times(1)
+				r.UpdateEscalationPolicy(gomock.Any(), gomock.Any()).Return(nil).Times(0)
+				r.ToggleServiceOrchestration(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil).Times(1)
+				r.ApplyServiceOrchestrationRule(gomock.Any(), gomock.Any()).Return(nil).Times(0)
+				r.DeleteService(gomock.Any(), gomock.Any()).Return(nil).Times(0)
+				r.DisableService(gomock.Any(), gomock.Any()).Return(nil).Times(0)
+				r.EnableService(gomock.Any(), gomock.Any()).Return(nil).Times(0)
 			},
 		},
 		{
@@ -1063,16 +1157,16 @@ func TestReconcilePagerDutyIntegration(t *testing.T) {
 				testClusterDeployment(true, true, true, false, false, false, false),
 				testPDISecret(),
 				testPagerDutyIntegration(),
-				testCDConfigMap(false, false, false, false),
+				testCDPagerDutyService(false, false, false, false),
 				testCDSyncSet(),
 				testCDSecret(),
 			},
 			expectPDSetup: true,
 			setupPDMock: func(r *pd.MockClientMockRecorder) {
-				r.CreateService(gomock.Any()).Return(testIntegrationID, nil).Times(0)
-				r.GetIntegrationKey(gomock.Any()).Return(testIntegrationID, nil).Times(0)
-				r.DeleteService(gomock.Any()).Return(nil).Times(0)
-				r.UpdateAlertGrouping(gomock.Any()).Return(nil)
+				r.CreateService(gomock.Any(), gomock.Any()).Return(testIntegrationID, nil).Times(0)
+				r.GetIntegrationKey(gomock.Any(), gomock.Any()).Return(testIntegrationID, nil).Times(0)
+				r.DeleteService(gomock.Any(), gomock.Any()).Return(nil).Times(0)
+				r.UpdateServiceSettings(gomock.Any(), gomock.Any()).Return(nil)
 			},
 		},
 	}
@@ -1085,12 +1179,7 @@ func TestReconcilePagerDutyIntegration(t *testing.T) {
 
 			defer mocks.mockCtrl.Finish()
 
-			rpdi := &PagerDutyIntegrationReconciler{
-				Client:    mocks.fakeKubeClient,
-				Scheme:    scheme.Scheme,
-				IsFedramp: false,
-				pdclient:  func(s1 string, s2 string) pd.Client { return mocks.mockPDClient },
-			}
+			rpdi := newTestReconciler(mocks)
 
 			// 1st run sets finalizer
 			_, err1 := rpdi.Reconcile(context.TODO(), reconcile.Request{
@@ -1121,17 +1210,17 @@ func TestReconcilePagerDutyIntegration(t *testing.T) {
 			assert.Nil(t, err2, "Unexpected Error with Reconcile (2 of 3)")
 			assert.Nil(t, err3, "Unexpected Error with Reconcile (3 of 3)")
 			if test.expectPDSetup {
-				// should see a syncset, secret, configmap, and finalizer on CD
+				// should see a syncset, secret, pagerdutyservice, and finalizer on CD
 				assert.True(t, verifySyncSetExists(mocks.fakeKubeClient, expectedSyncSet), "verifySyncSets: "+test.name)
 				assert.True(t, verifySecretExists(mocks.fakeKubeClient, expectedSecret), "verifySecretExists: "+test.name)
 				assert.True(t, verifyFinalizer(mocks.fakeKubeClient, expectedClusterDeployment), "verifyFinalizer: "+test.name)
-				assert.True(t, verifyConfigMapExists(mocks.fakeKubeClient), "verifyConfigMapExists: "+test.name)
+				assert.True(t, verifyPagerDutyServiceExists(mocks.fakeKubeClient), "verifyPagerDutyServiceExists: "+test.name)
 			} else {
-				// expect no syncset, secret, configmap, OR finalizer on CD
+				// expect no syncset, secret, pagerdutyservice, OR finalizer on CD
 				assert.True(t, verifyNoSyncSetExists(mocks.fakeKubeClient), "verifyNoSyncSetExists: "+test.name)
 				assert.True(t, verifyNoSecretExists(mocks.fakeKubeClient), "verifyNoSecretExists: "+test.name)
 				assert.True(t, verifyNoFinalizer(mocks.fakeKubeClient, expectedClusterDeployment), "verifyNoFinalizer: "+test.name)
-				assert.True(t, verifyNoConfigMapExists(mocks.fakeKubeClient), "verifyNoConfigMapExists: "+test.name)
+				assert.True(t, verifyNoPagerDutyServiceExists(mocks.fakeKubeClient), "verifyNoPagerDutyServiceExists: "+test.name)
 			}
 		})
 	}
@@ -1279,21 +1368,21 @@ func verifyNoSecretExists(c client.Client) bool {
 	return true
 }
 
-func verifyConfigMapExists(c client.Client) bool {
-	cmList := &corev1.ConfigMapList{}
+func verifyPagerDutyServiceExists(c client.Client) bool {
+	pdServiceList := &pagerdutyv1alpha1.PagerDutyServiceList{}
 	opts := client.ListOptions{Namespace: testNamespace}
-	err := c.List(context.TODO(), cmList, &opts)
+	err := c.List(context.TODO(), pdServiceList, &opts)
 
 	if err != nil {
 		if errors.IsNotFound(err) {
-			// no configmaps are defined, this is a failure
+			// no pagerdutyservices are defined, this is a failure
 			return false
 		}
 	}
 
-	for _, cm := range cmList.Items {
-		if strings.HasSuffix(cm.Name, config.ConfigMapSuffix) {
-			// found a configmap associated with this operator!
+	for _, pdService := range pdServiceList.Items {
+		if strings.HasSuffix(pdService.Name, config.PagerDutyServiceSuffix) {
+			// found a pagerdutyservice associated with this operator!
 			return true
 		}
 	}
@@ -1302,29 +1391,1399 @@ func verifyConfigMapExists(c client.Client) bool {
 	return false
 }
 
-func verifyNoConfigMapExists(c client.Client) bool {
-	cmList := &corev1.ConfigMapList{}
+func verifyNoPagerDutyServiceExists(c client.Client) bool {
+	pdServiceList := &pagerdutyv1alpha1.PagerDutyServiceList{}
 	opts := client.ListOptions{Namespace: testNamespace}
-	err := c.List(context.TODO(), cmList, &opts)
+	err := c.List(context.TODO(), pdServiceList, &opts)
 
 	if err != nil {
 		if errors.IsNotFound(err) {
-			// no configmaps are defined, this is OK
+			// no pagerdutyservices are defined, this is OK
 			return true
 		}
 	}
 
+	for _, pdService := range pdServiceList.Items {
+		if strings.HasSuffix(pdService.Name, config.PagerDutyServiceSuffix) {
+			// too bad, found a pagerdutyservice associated with this operator
+			return false
+		}
+	}
+
+	// if we got here, it's good.  list was empty or everything passed
+	return true
+}
+
+// verifyNoLegacyConfigMapExists verifies that the "-pd-config" ConfigMap is gone once the
+// cluster config has been migrated to a PagerDutyService.
+func verifyNoLegacyConfigMapExists(c client.Client) bool {
+	cmList := &corev1.ConfigMapList{}
+	opts := client.ListOptions{Namespace: testNamespace}
+	if err := c.List(context.TODO(), cmList, &opts); err != nil {
+		return errors.IsNotFound(err)
+	}
+
 	for _, cm := range cmList.Items {
 		if strings.HasSuffix(cm.Name, config.ConfigMapSuffix) {
-			// too bad, found a configmap associated with this operator
 			return false
 		}
 	}
 
-	// if we got here, it's good.  list was empty or everything passed
 	return true
 }
 
+func TestMigrateLegacyClusterConfig(t *testing.T) {
+	pdServiceName := config.Name(testServicePrefix, testClusterName, config.PagerDutyServiceSuffix)
+
+	existingPDService := testCDPagerDutyService(false, false, false, true)
+	existingPDService.Spec.ServiceID = "EXISTING"
+
+	incompleteCM := testCDConfigMap(false, false, false, true)
+	delete(incompleteCM.Data, "SERVICE_ID")
+
+	tests := []struct {
+		name              string
+		localObjects      []client.Object
+		setupPDMock       func(*pd.MockClientMockRecorder)
+		expectedServiceID string
+		expectOwnerRef    bool
+	}{
+		{
+			name: "Test Legacy ConfigMap Migrated",
+			localObjects: []client.Object{
+				testClusterDeployment(true, true, true, false, false, false, false),
+				testPDISecret(),
+				testPagerDutyIntegration(),
+				testCDConfigMap(false, false, false, true),
+				testCDSyncSet(),
+				testCDSecret(),
+			},
+			setupPDMock: func(r *pd.MockClientMockRecorder) {
+				r.CreateService(gomock.Any(), gomock.Any()).Times(0)
+				// the legacy ConfigMap doesn't record the timeouts, so they are applied once
+				r.UpdateServiceSettings(gomock.Any(), gomock.Any()).Return(nil).Times(1)
+			},
+			expectedServiceID: testServiceID,
+			expectOwnerRef:    true,
+		},
+		{
+			name: "Test Existing PagerDutyService Takes Precedence",
+			localObjects: []client.Object{
+				testClusterDeployment(true, true, true, false, false, false, false),
+				testPDISecret(),
+				testPagerDutyIntegration(),
+				testCDConfigMap(false, false, false, true),
+				existingPDService,
+				testCDSyncSet(),
+				testCDSecret(),
+			},
+			setupPDMock: func(r *pd.MockClientMockRecorder) {
+				r.CreateService(gomock.Any(), gomock.Any()).Times(0)
+			},
+			expectedServiceID: "EXISTING",
+		},
+		{
+			name: "Test Incomplete Legacy ConfigMap Dropped",
+			localObjects: []client.Object{
+				testClusterDeployment(true, true, true, false, false, false, false),
+				testPDISecret(),
+				testPagerDutyIntegration(),
+				incompleteCM,
+				testCDSyncSet(),
+				testCDSecret(),
+			},
+			setupPDMock: func(r *pd.MockClientMockRecorder) {
+				r.CreateService(gomock.Any(), gomock.Any()).Times(1).DoAndReturn(
+					func(_ context.Context, data *pd.Data) (string, error) {
+						data.ServiceID = "XYZ123"
+						data.IntegrationID = "LMN456"
+						return data.IntegrationID, nil
+					})
+			},
+			expectedServiceID: "XYZ123",
+			expectOwnerRef:    true,
+		},
+		{
+			name: "Test Deleting ClusterDeployment With Legacy ConfigMap",
+			localObjects: []client.Object{
+				testClusterDeployment(true, true, true, true, false, false, false),
+				testPDISecret(),
+				testPagerDutyIntegration(),
+				testCDConfigMap(false, false, false, true),
+				testCDSyncSet(),
+				testCDSecret(),
+			},
+			setupPDMock: func(r *pd.MockClientMockRecorder) {
+				r.GetService(gomock.Any(), gomock.Any()).Return(nil, nil).Times(1)
+				r.ResolvePendingIncidents(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil).Times(1)
+				r.CountUnresolvedIncidents(gomock.Any(), gomock.Any()).Return(0, nil).Times(1)
+				r.DeleteService(gomock.Any(), gomock.Any()).Times(1).DoAndReturn(
+					func(_ context.Context, data *pd.Data) error {
+						assert.Equal(t, testServiceID, data.ServiceID)
+						return nil
+					})
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			mocks := setupDefaultMocks(t, test.localObjects)
+			test.setupPDMock(mocks.mockPDClient.EXPECT())
+
+			defer mocks.mockCtrl.Finish()
+
+			rpdi := newTestReconciler(mocks)
+
+			_, err := rpdi.Reconcile(context.TODO(), reconcile.Request{
+				NamespacedName: types.NamespacedName{
+					Name:      testPagerDutyIntegrationName,
+					Namespace: config.OperatorNamespace,
+				},
+			})
+			assert.NoError(t, err)
+			assert.True(t, verifyNoLegacyConfigMapExists(mocks.fakeKubeClient), "verifyNoLegacyConfigMapExists: "+test.name)
+
+			if test.expectedServiceID == "" {
+				assert.True(t, verifyNoPagerDutyServiceExists(mocks.fakeKubeClient), "verifyNoPagerDutyServiceExists: "+test.name)
+				return
+			}
+
+			pdService := &pagerdutyv1alpha1.PagerDutyService{}
+			err = mocks.fakeKubeClient.Get(context.TODO(), types.NamespacedName{Name: pdServiceName, Namespace: testNamespace}, pdService)
+			assert.NoError(t, err)
+			assert.Equal(t, test.expectedServiceID, pdService.Spec.ServiceID)
+			assert.Equal(t, testClusterName, pdService.Spec.ClusterDeploymentRef.Name)
+			assert.Equal(t, testPagerDutyIntegrationName, pdService.Spec.PagerDutyIntegrationRef.Name)
+			if test.expectOwnerRef && assert.Len(t, pdService.OwnerReferences, 1) {
+				assert.Equal(t, testClusterName, pdService.OwnerReferences[0].Name)
+			}
+		})
+	}
+}
+
+func TestReconcileServiceSettings(t *testing.T) {
+	pdiWithTimeouts := func(resolveTimeout, acknowledgeTimeout uint) *pagerdutyv1alpha1.PagerDutyIntegration {
+		pdi := testPagerDutyIntegration()
+		pdi.Spec.ResolveTimeout = resolveTimeout
+		pdi.Spec.AcknowledgeTimeout = acknowledgeTimeout
+		return pdi
+	}
+	pdiWithoutAlertGrouping := testPagerDutyIntegration()
+	pdiWithoutAlertGrouping.Spec.AlertGroupingParameters = nil
+	pdiWithUrgencyRule := testPagerDutyIntegration()
+	pdiWithUrgencyRule.Spec.IncidentUrgencyRule = &pagerdutyv1alpha1.IncidentUrgencyRuleSpec{
+		Type:                pagerdutyv1alpha1.IncidentUrgencyRuleUseSupportHours,
+		DuringSupportHours:  "high",
+		OutsideSupportHours: "low",
+		SupportHours: &pagerdutyv1alpha1.SupportHoursSpec{
+			TimeZone:   "Europe/Berlin",
+			DaysOfWeek: []uint{1, 2, 3, 4, 5},
+			StartTime:  "08:00:00",
+			EndTime:    "18:00:00",
+		},
+	}
+
+	pdiWithNameTemplate := testPagerDutyIntegration()
+	pdiWithNameTemplate.Spec.ServiceNameTemplate = "{{.ServicePrefix}}-{{.ClusterID}}-{{index .Labels \"" + config.ClusterDeploymentManagedLabel + "\"}}"
+
+	pdiWithEscalationPolicyAndTimeout := pdiWithTimeouts(0, testAcknowledgeTimeout)
+	pdiWithEscalationPolicyAndTimeout.Spec.EscalationPolicy = "new-escalation-policy"
+	pdiWithEscalationPolicyAndUrgencyRule := pdiWithUrgencyRule.DeepCopy()
+	pdiWithEscalationPolicyAndUrgencyRule.Spec.EscalationPolicy = "new-escalation-policy"
+	pdiWithEscalationPolicyAndNameTemplate := pdiWithNameTemplate.DeepCopy()
+	pdiWithEscalationPolicyAndNameTemplate.Spec.EscalationPolicy = "new-escalation-policy"
+
+	tests := []struct {
+		name                       string
+		pdi                        *pagerdutyv1alpha1.PagerDutyIntegration
+		expectEscalationPolicy     bool
+		expectUpdate               bool
+		expectedResolveTimeout     uint
+		expectedAcknowledgeTimeout uint
+		expectedServiceName        string
+	}{
+		{
+			name:                       "Test Settings Unchanged",
+			pdi:                        testPagerDutyIntegration(),
+			expectUpdate:               false,
+			expectedResolveTimeout:     testResolveTimeout,
+			expectedAcknowledgeTimeout: testAcknowledgeTimeout,
+		},
+		{
+			name:                       "Test Resolve Timeout Changed",
+			pdi:                        pdiWithTimeouts(0, testAcknowledgeTimeout),
+			expectUpdate:               true,
+			expectedResolveTimeout:     0,
+			expectedAcknowledgeTimeout: testAcknowledgeTimeout,
+		},
+		{
+			name:                       "Test Acknowledge Timeout Changed",
+			pdi:                        pdiWithTimeouts(testResolveTimeout, 1800),
+			expectUpdate:               true,
+			expectedResolveTimeout:     testResolveTimeout,
+			expectedAcknowledgeTimeout: 1800,
+		},
+		{
+			name:                       "Test Alert Grouping Not Configured Is Not Drift",
+			pdi:                        pdiWithoutAlertGrouping,
+			expectUpdate:               false,
+			expectedResolveTimeout:     testResolveTimeout,
+			expectedAcknowledgeTimeout: testAcknowledgeTimeout,
+		},
+		{
+			name:                       "Test Incident Urgency Rule Changed",
+			pdi:                        pdiWithUrgencyRule,
+			expectUpdate:               true,
+			expectedResolveTimeout:     testResolveTimeout,
+			expectedAcknowledgeTimeout: testAcknowledgeTimeout,
+		},
+		{
+			name:                       "Test Service Name Template Changed",
+			pdi:                        pdiWithNameTemplate,
+			expectUpdate:               true,
+			expectedResolveTimeout:     testResolveTimeout,
+			expectedAcknowledgeTimeout: testAcknowledgeTimeout,
+			expectedServiceName:        testServicePrefix + "-" + testClusterName + "-true",
+		},
+		{
+			name:                       "Test Escalation Policy And Resolve Timeout Changed",
+			pdi:                        pdiWithEscalationPolicyAndTimeout,
+			expectEscalationPolicy:     true,
+			expectUpdate:               true,
+			expectedResolveTimeout:     0,
+			expectedAcknowledgeTimeout: testAcknowledgeTimeout,
+		},
+		{
+			name:                       "Test Escalation Policy And Incident Urgency Rule Changed",
+			pdi:                        pdiWithEscalationPolicyAndUrgencyRule,
+			expectEscalationPolicy:     true,
+			expectUpdate:               true,
+			expectedResolveTimeout:     testResolveTimeout,
+			expectedAcknowledgeTimeout: testAcknowledgeTimeout,
+		},
+		{
+			name:                       "Test Escalation Policy And Service Name Template Changed",
+			pdi:                        pdiWithEscalationPolicyAndNameTemplate,
+			expectEscalationPolicy:     true,
+			expectUpdate:               true,
+			expectedResolveTimeout:     testResolveTimeout,
+			expectedAcknowledgeTimeout: testAcknowledgeTimeout,
+			expectedServiceName:        testServicePrefix + "-" + testClusterName + "-true",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			mocks := setupDefaultMocks(t, []client.Object{
+				testClusterDeployment(true, true, true, false, false, false, false),
+				testPDISecret(),
+				test.pdi,
+				testCDPagerDutyService(false, false, false, true),
+				testCDSyncSet(),
+				testCDSecret(),
+			})
+
+			if test.expectEscalationPolicy {
+				mocks.mockPDClient.EXPECT().UpdateEscalationPolicy(gomock.Any(), gomock.Any()).Return(nil).Times(1)
+			}
+			if test.expectUpdate {
+				mocks.mockPDClient.EXPECT().UpdateServiceSettings(gomock.Any(), gomock.Any()).Times(1).DoAndReturn(
+					func(_ context.Context, data *pd.Data) error {
+						assert.Equal(t, testServiceID, data.ServiceID)
+						assert.Equal(t, test.expectedResolveTimeout, data.ResolveTimeout)
+						assert.Equal(t, test.expectedAcknowledgeTimeout, data.AcknowledgeTimeOut)
+						assert.Equal(t, test.pdi.Spec.IncidentUrgencyRule, data.IncidentUrgencyRule)
+						return nil
+					})
+			} else {
+				mocks.mockPDClient.EXPECT().UpdateServiceSettings(gomock.Any(), gomock.Any()).Times(0)
+			}
+
+			defer mocks.mockCtrl.Finish()
+
+			rpdi := newTestReconciler(mocks)
+
+			_, err := rpdi.Reconcile(context.TODO(), reconcile.Request{
+				NamespacedName: types.NamespacedName{
+					Name:      testPagerDutyIntegrationName,
+					Namespace: config.OperatorNamespace,
+				},
+			})
+			assert.NoError(t, err)
+
+			pdService := &pagerdutyv1alpha1.PagerDutyService{}
+			err = mocks.fakeKubeClient.Get(context.TODO(), types.NamespacedName{Name: config.Name(testServicePrefix, testClusterName, config.PagerDutyServiceSuffix), Namespace: testNamespace}, pdService)
+			assert.NoError(t, err)
+			assert.Equal(t, test.expectedResolveTimeout, pdService.Spec.ResolveTimeout)
+			assert.Equal(t, test.expectedAcknowledgeTimeout, pdService.Spec.AcknowledgeTimeout)
+			assert.Equal(t, testAlertGroupingType, pdService.Spec.AlertGroupingType)
+			assert.Equal(t, test.pdi.Spec.IncidentUrgencyRule, pdService.Spec.IncidentUrgencyRule)
+			assert.Equal(t, test.expectedServiceName, pdService.Spec.ServiceName)
+		})
+	}
+}
+
+func TestReconcileEscalationPolicyRules(t *testing.T) {
+	assert.Nil(t, hiveapis.AddToScheme(scheme.Scheme))
+	assert.Nil(t, pagerdutyapi.AddToScheme(scheme.Scheme))
+
+	const silentEscalationPolicy = "silent-escalation-policy"
+	pdiWithRules := testPagerDutyIntegration()
+	pdiWithRules.Spec.EscalationPolicyRules = []pagerdutyv1alpha1.EscalationPolicyRule{
+		{
+			ClusterDeploymentSelector: metav1.LabelSelector{
+				MatchLabels: map[string]string{"api.openshift.com/legal-entity-id": "silent"},
+			},
+			EscalationPolicy: silentEscalationPolicy,
+		},
+	}
+	silentClusterDeployment := testClusterDeployment(true, true, true, false, false, false, false)
+	silentClusterDeployment.Labels["api.openshift.com/legal-entity-id"] = "silent"
+
+	tests := []struct {
+		name                     string
+		cd                       *hivev1.ClusterDeployment
+		pdService                *pagerdutyv1alpha1.PagerDutyService
+		setupPDMock              func(*pd.MockClientMockRecorder)
+		expectedEscalationPolicy string
+	}{
+		{
+			name:      "Test No Rule Matches",
+			cd:        testClusterDeployment(true, true, true, false, false, false, false),
+			pdService: testCDPagerDutyService(false, false, false, true),
+			setupPDMock: func(r *pd.MockClientMockRecorder) {
+				r.UpdateEscalationPolicy(gomock.Any(), gomock.Any()).Times(0)
+			},
+			expectedEscalationPolicy: testEscalationPolicy,
+		},
+		{
+			name:      "Test Labels Match Rule",
+			cd:        silentClusterDeployment,
+			pdService: testCDPagerDutyService(false, false, false, true),
+			setupPDMock: func(r *pd.MockClientMockRecorder) {
+				r.UpdateEscalationPolicy(gomock.Any(), gomock.Any()).Times(1).DoAndReturn(
+					func(_ context.Context, data *pd.Data) error {
+						assert.Equal(t, testServiceID, data.ServiceID)
+						assert.Equal(t, silentEscalationPolicy, data.EscalationPolicyID)
+						return nil
+					})
+			},
+			expectedEscalationPolicy: silentEscalationPolicy,
+		},
+		{
+			name: "Test New Service Created With Rule Escalation Policy",
+			cd:   silentClusterDeployment,
+			setupPDMock: func(r *pd.MockClientMockRecorder) {
+				r.CreateService(gomock.Any(), gomock.Any()).Times(1).DoAndReturn(
+					func(_ context.Context, data *pd.Data) (string, error) {
+						assert.Equal(t, silentEscalationPolicy, data.EscalationPolicyID)
+						data.ServiceID = testServiceID
+						data.IntegrationID = testIntegrationID
+						return data.IntegrationID, nil
+					})
+				r.GetIntegrationKey(gomock.Any(), gomock.Any()).Return(testIntegrationID, nil).AnyTimes()
+				r.UpdateEscalationPolicy(gomock.Any(), gomock.Any()).Times(0)
+			},
+			expectedEscalationPolicy: silentEscalationPolicy,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			localObjects := []client.Object{test.cd.DeepCopy(), testPDISecret(), pdiWithRules.DeepCopy()}
+			if test.pdService != nil {
+				localObjects = append(localObjects, test.pdService, testCDSyncSet(), testCDSecret())
+			}
+			mocks := setupDefaultMocks(t, localObjects)
+			test.setupPDMock(mocks.mockPDClient.EXPECT())
+
+			defer mocks.mockCtrl.Finish()
+
+			rpdi := newTestReconciler(mocks)
+
+			_, err := rpdi.Reconcile(context.TODO(), reconcile.Request{
+				NamespacedName: types.NamespacedName{
+					Name:      testPagerDutyIntegrationName,
+					Namespace: config.OperatorNamespace,
+				},
+			})
+			assert.NoError(t, err)
+
+			pdService := &pagerdutyv1alpha1.PagerDutyService{}
+			err = mocks.fakeKubeClient.Get(context.TODO(), types.NamespacedName{Name: config.Name(testServicePrefix, testClusterName, config.PagerDutyServiceSuffix), Namespace: testNamespace}, pdService)
+			assert.NoError(t, err)
+			assert.Equal(t, test.expectedEscalationPolicy, pdService.Spec.EscalationPolicyID)
+		})
+	}
+}
+
+// testPredecessorPagerDutyIntegration returns testPagerDutyIntegration selecting the clusters
+// of a team, they are handed over to testSuccessorPagerDutyIntegration when they move
+func testPredecessorPagerDutyIntegration() *pagerdutyv1alpha1.PagerDutyIntegration {
+	pdi := testPagerDutyIntegration()
+	pdi.Finalizers = []string{config.PagerDutyIntegrationFinalizer}
+	pdi.Spec.ClusterDeploymentSelector = metav1.LabelSelector{
+		MatchLabels: map[string]string{"api.openshift.com/team": "old"},
+	}
+	return pdi
+}
+
+// testSuccessorPagerDutyIntegration returns a PagerDutyIntegration selecting the clusters of
+// another team, to which testPagerDutyIntegration hands the PD services of those clusters over
+func testSuccessorPagerDutyIntegration() *pagerdutyv1alpha1.PagerDutyIntegration {
+	pdi := testPagerDutyIntegration()
+	pdi.Name = "newPagerDutyIntegration"
+	pdi.Finalizers = []string{config.PagerDutyIntegrationFinalizer}
+	pdi.Spec.ServicePrefix = "new-service-prefix"
+	pdi.Spec.EscalationPolicy = "new-escalation-policy"
+	pdi.Spec.ClusterDeploymentSelector = metav1.LabelSelector{
+		MatchLabels: map[string]string{"api.openshift.com/team": "new"},
+	}
+	return pdi
+}
+
+func TestReconcileHandoff(t *testing.T) {
+	assert.Nil(t, hiveapis.AddToScheme(scheme.Scheme))
+	assert.Nil(t, pagerdutyapi.AddToScheme(scheme.Scheme))
+
+	oldPDI := testPredecessorPagerDutyIntegration()
+	newPDI := testSuccessorPagerDutyIntegration()
+	// the cluster moved to the team of newPDI
+	cd := testClusterDeployment(true, true, true, false, false, false, false)
+	cd.Labels["api.openshift.com/team"] = "new"
+
+	mocks := setupDefaultMocks(t, []client.Object{cd, testPDISecret(), oldPDI, newPDI, testCDPagerDutyService(false, false, false, true), testCDSyncSet(), testCDSecret()})
+	defer mocks.mockCtrl.Finish()
+	mocks.mockPDClient.EXPECT().UpdateEscalationPolicy(gomock.Any(), gomock.Any()).Times(1).DoAndReturn(
+		func(_ context.Context, data *pd.Data) error {
+			assert.Equal(t, testServiceID, data.ServiceID)
+			assert.Equal(t, newPDI.Spec.EscalationPolicy, data.EscalationPolicyID)
+			return nil
+		})
+	mocks.mockPDClient.EXPECT().GetIntegrationKey(gomock.Any(), gomock.Any()).Return(testIntegrationID, nil).Times(1)
+	// the service is never deleted or recreated, and its incidents aren't resolved
+	mocks.mockPDClient.EXPECT().CreateService(gomock.Any(), gomock.Any()).Times(0)
+	mocks.mockPDClient.EXPECT().DeleteService(gomock.Any(), gomock.Any()).Times(0)
+	mocks.mockPDClient.EXPECT().ResolvePendingIncidents(gomock.Any(), gomock.Any(), gomock.Any()).Times(0)
+
+	r := newTestReconciler(mocks).cd
+	req := reconcile.Request{NamespacedName: types.NamespacedName{Namespace: testNamespace, Name: testClusterName}}
+	c := mocks.fakeKubeClient
+
+	var (
+		oldName      = types.NamespacedName{Namespace: testNamespace, Name: config.Name(testServicePrefix, testClusterName, config.PagerDutyServiceSuffix)}
+		newName      = types.NamespacedName{Namespace: testNamespace, Name: config.Name(newPDI.Spec.ServicePrefix, testClusterName, config.PagerDutyServiceSuffix)}
+		oldSecret    = types.NamespacedName{Namespace: testNamespace, Name: config.Name(testServicePrefix, testClusterName, config.SecretSuffix)}
+		newSecret    = types.NamespacedName{Namespace: testNamespace, Name: config.Name(newPDI.Spec.ServicePrefix, testClusterName, config.SecretSuffix)}
+		oldFinalizer = config.PagerDutyFinalizerPrefix + oldPDI.Name
+		newFinalizer = config.PagerDutyFinalizerPrefix + newPDI.Name
+	)
+
+	// expectPhase reconciles cd and checks that the handoff waits in phase
+	expectPhase := func(phase pagerdutyv1alpha1.ServiceOperationPhase) {
+		t.Helper()
+		result, err := r.Reconcile(context.TODO(), req)
+		assert.NoError(t, err)
+		assert.Equal(t, handoffPollInterval, result.RequeueAfter)
+
+		pdService := &pagerdutyv1alpha1.PagerDutyService{}
+		assert.NoError(t, c.Get(context.TODO(), oldName, pdService))
+		if assert.NotNil(t, pdService.Status.Operation) {
+			assert.Equal(t, pagerdutyv1alpha1.ServiceOperationHandoff, pdService.Status.Operation.Type)
+			assert.Equal(t, phase, pdService.Status.Operation.Phase)
+			assert.Equal(t, newPDI.Name, pdService.Status.Operation.HandoffTo.Name)
+		}
+		// the cluster keeps paging through the objects of oldPDI meanwhile
+		assert.NoError(t, c.Get(context.TODO(), oldSecret, &corev1.Secret{}))
+		assert.NoError(t, c.Get(context.TODO(), oldSecret, &hivev1.SyncSet{}))
+		updatedCD := &hivev1.ClusterDeployment{}
+		assert.NoError(t, c.Get(context.TODO(), req.NamespacedName, updatedCD))
+		assert.Contains(t, updatedCD.Finalizers, oldFinalizer)
+	}
+
+	// newPDI adds its finalizer first, oldPDI waits for it to adopt the service
+	expectPhase(pagerdutyv1alpha1.ServiceOperationAwaitingAdoption)
+
+	// newPDI adopts the service and moves it to its escalation policy
+	expectPhase(pagerdutyv1alpha1.ServiceOperationAwaitingSync)
+	adopted := &pagerdutyv1alpha1.PagerDutyService{}
+	assert.NoError(t, c.Get(context.TODO(), newName, adopted))
+	assert.Equal(t, testServiceID, adopted.Spec.ServiceID)
+	assert.Equal(t, testIntegrationID, adopted.Spec.IntegrationID)
+	assert.Equal(t, newPDI.Spec.EscalationPolicy, adopted.Spec.EscalationPolicyID)
+	assert.Equal(t, newPDI.Name, adopted.Spec.PagerDutyIntegrationRef.Name)
+
+	// newPDI deploys the integration key, which hive hasn't applied yet
+	expectPhase(pagerdutyv1alpha1.ServiceOperationAwaitingSync)
+	assert.NoError(t, c.Get(context.TODO(), newSecret, &corev1.Secret{}))
+	newSyncSet := &hivev1.SyncSet{}
+	assert.NoError(t, c.Get(context.TODO(), newSecret, newSyncSet))
+
+	// hive applied the SyncSet of newPDI, both deploy the same Secret so the one of oldPDI
+	// must not delete it from the cluster
+	clusterSync := &hiveintv1alpha1.ClusterSync{
+		ObjectMeta: metav1.ObjectMeta{Namespace: testNamespace, Name: testClusterName},
+		Status: hiveintv1alpha1.ClusterSyncStatus{
+			SyncSets: []hiveintv1alpha1.SyncStatus{
+				{Name: newSyncSet.Name, ObservedGeneration: newSyncSet.Generation, Result: hiveintv1alpha1.SuccessSyncSetResult},
+			},
+		},
+	}
+	assert.NoError(t, c.Create(context.TODO(), clusterSync))
+	expectPhase(pagerdutyv1alpha1.ServiceOperationAwaitingSync)
+	oldSyncSet := &hivev1.SyncSet{}
+	assert.NoError(t, c.Get(context.TODO(), oldSecret, oldSyncSet))
+	assert.Equal(t, hivev1.UpsertResourceApplyMode, oldSyncSet.Spec.ResourceApplyMode)
+
+	// hive applied the Upsert SyncSet of oldPDI, it can be deleted now
+	clusterSync.Status.SyncSets = append(clusterSync.Status.SyncSets,
+		hiveintv1alpha1.SyncStatus{Name: oldSyncSet.Name, ObservedGeneration: oldSyncSet.Generation, Result: hiveintv1alpha1.SuccessSyncSetResult})
+	assert.NoError(t, c.Update(context.TODO(), clusterSync))
+	result, err := r.Reconcile(context.TODO(), req)
+	assert.NoError(t, err)
+	assert.Zero(t, result.RequeueAfter)
+
+	assert.True(t, errors.IsNotFound(c.Get(context.TODO(), oldName, &pagerdutyv1alpha1.PagerDutyService{})))
+	assert.True(t, errors.IsNotFound(c.Get(context.TODO(), oldSecret, &corev1.Secret{})))
+	assert.True(t, errors.IsNotFound(c.Get(context.TODO(), oldSecret, &hivev1.SyncSet{})))
+	assert.NoError(t, c.Get(context.TODO(), newName, &pagerdutyv1alpha1.PagerDutyService{}))
+	assert.NoError(t, c.Get(context.TODO(), newSecret, &corev1.Secret{}))
+	assert.NoError(t, c.Get(context.TODO(), newSecret, &hivev1.SyncSet{}))
+	updatedCD := &hivev1.ClusterDeployment{}
+	assert.NoError(t, c.Get(context.TODO(), req.NamespacedName, updatedCD))
+	assert.Equal(t, []string{newFinalizer}, updatedCD.Finalizers)
+}
+
+func TestReconcileHandoffSameServicePrefix(t *testing.T) {
+	assert.Nil(t, hiveapis.AddToScheme(scheme.Scheme))
+	assert.Nil(t, pagerdutyapi.AddToScheme(scheme.Scheme))
+
+	oldPDI := testPredecessorPagerDutyIntegration()
+	newPDI := testSuccessorPagerDutyIntegration()
+	newPDI.Spec.ServicePrefix = testServicePrefix
+	cd := testClusterDeployment(true, true, true, false, false, false, false)
+	cd.Labels["api.openshift.com/team"] = "new"
+
+	mocks := setupDefaultMocks(t, []client.Object{cd, testPDISecret(), oldPDI, newPDI, testCDPagerDutyService(false, false, false, true), testCDSyncSet(), testCDSecret()})
+	defer mocks.mockCtrl.Finish()
+	mocks.mockPDClient.EXPECT().UpdateEscalationPolicy(gomock.Any(), gomock.Any()).Return(nil).Times(1)
+	mocks.mockPDClient.EXPECT().DeleteService(gomock.Any(), gomock.Any()).Times(0)
+	mocks.mockPDClient.EXPECT().ResolvePendingIncidents(gomock.Any(), gomock.Any(), gomock.Any()).Times(0)
+
+	r := newTestReconciler(mocks).cd
+	req := reconcile.Request{NamespacedName: types.NamespacedName{Namespace: testNamespace, Name: testClusterName}}
+	for range 3 {
+		_, err := r.Reconcile(context.TODO(), req)
+		assert.NoError(t, err)
+	}
+
+	// the objects are shared, newPDI took them over along with the service
+	pdService := &pagerdutyv1alpha1.PagerDutyService{}
+	assert.NoError(t, mocks.fakeKubeClient.Get(context.TODO(), types.NamespacedName{Namespace: testNamespace, Name: config.Name(testServicePrefix, testClusterName, config.PagerDutyServiceSuffix)}, pdService))
+	assert.Equal(t, newPDI.Name, pdService.Spec.PagerDutyIntegrationRef.Name)
+	assert.Equal(t, newPDI.Spec.EscalationPolicy, pdService.Spec.EscalationPolicyID)
+	assert.Nil(t, pdService.Status.Operation)
+	assert.True(t, verifySecretExists(mocks.fakeKubeClient, &SecretEntry{
+		name:         config.Name(testServicePrefix, testClusterName, config.SecretSuffix),
+		pagerdutyKey: testIntegrationID,
+	}))
+	updatedCD := &hivev1.ClusterDeployment{}
+	assert.NoError(t, mocks.fakeKubeClient.Get(context.TODO(), req.NamespacedName, updatedCD))
+	assert.Equal(t, []string{config.PagerDutyFinalizerPrefix + newPDI.Name}, updatedCD.Finalizers)
+}
+
+func TestReconcileHandoffOtherAccount(t *testing.T) {
+	assert.Nil(t, hiveapis.AddToScheme(scheme.Scheme))
+	assert.Nil(t, pagerdutyapi.AddToScheme(scheme.Scheme))
+
+	oldPDI := testPredecessorPagerDutyIntegration()
+	newPDI := testSuccessorPagerDutyIntegration()
+	newPDI.Spec.PagerdutyApiKeySecretRef.Name = "other-api-key"
+	otherAPIKey := testPDISecret()
+	otherAPIKey.Name = newPDI.Spec.PagerdutyApiKeySecretRef.Name
+	otherAPIKey.Data[config.PagerDutyAPISecretKey] = []byte("other-pd-api-key")
+	cd := testClusterDeployment(true, true, true, false, false, false, false)
+	cd.Labels["api.openshift.com/team"] = "new"
+
+	mocks := setupDefaultMocks(t, []client.Object{cd, testPDISecret(), otherAPIKey, oldPDI, newPDI, testCDPagerDutyService(false, false, false, true), testCDSyncSet(), testCDSecret()})
+	defer mocks.mockCtrl.Finish()
+	// newPDI can't manage the service of oldPDI, so the service is deleted as before
+	mocks.mockPDClient.EXPECT().GetService(gomock.Any(), gomock.Any()).Return(&pdApi.Service{}, nil).Times(1)
+	mocks.mockPDClient.EXPECT().ResolvePendingIncidents(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil).Times(1)
+	mocks.mockPDClient.EXPECT().CountUnresolvedIncidents(gomock.Any(), gomock.Any()).Return(0, nil).Times(1)
+	mocks.mockPDClient.EXPECT().DeleteService(gomock.Any(), gomock.Any()).Return(nil).Times(1)
+	mocks.mockPDClient.EXPECT().UpdateEscalationPolicy(gomock.Any(), gomock.Any()).Times(0)
+
+	r := newTestReconciler(mocks).cd
+	_, err := r.Reconcile(context.TODO(), reconcile.Request{NamespacedName: types.NamespacedName{Namespace: testNamespace, Name: testClusterName}})
+	assert.NoError(t, err)
+
+	assert.True(t, verifyNoPagerDutyServiceExists(mocks.fakeKubeClient))
+	updatedCD := &hivev1.ClusterDeployment{}
+	assert.NoError(t, mocks.fakeKubeClient.Get(context.TODO(), types.NamespacedName{Namespace: testNamespace, Name: testClusterName}, updatedCD))
+	assert.Equal(t, []string{config.PagerDutyFinalizerPrefix + newPDI.Name}, updatedCD.Finalizers)
+}
+
+func TestReconcileDriftCheck(t *testing.T) {
+	pdiWithDriftPolicy := func(policy pagerdutyv1alpha1.DriftPolicy) *pagerdutyv1alpha1.PagerDutyIntegration {
+		pdi := testPagerDutyIntegration()
+		pdi.Spec.DriftPolicy = policy
+		return pdi
+	}
+	recentlyCheckedPDService := testCDPagerDutyService(false, false, false, true)
+	recentlyCheckedPDService.Status.LastDriftCheckTime = &metav1.Time{Time: time.Now().Add(-time.Minute)}
+
+	tests := []struct {
+		name                  string
+		pdi                   *pagerdutyv1alpha1.PagerDutyIntegration
+		pdService             *pagerdutyv1alpha1.PagerDutyService
+		setupPDMock           func(*pd.MockClientMockRecorder)
+		expectedEventReasons  []string
+		expectedDriftedFields []string
+		expectCheck           bool
+	}{
+		{
+			name:      "Test Drift Enforced",
+			pdi:       pdiWithDriftPolicy(pagerdutyv1alpha1.DriftPolicyEnforce),
+			pdService: testCDPagerDutyService(false, false, false, true),
+			setupPDMock: func(r *pd.MockClientMockRecorder) {
+				r.GetService(gomock.Any(), gomock.Any()).Return(driftedTestService(), nil).Times(1)
+				r.RestoreService(gomock.Any(), gomock.Any()).Times(1).DoAndReturn(
+					func(_ context.Context, data *pd.Data) error {
+						assert.Equal(t, testServiceID, data.ServiceID)
+						assert.Equal(t, testEscalationPolicy, data.EscalationPolicyID)
+						return nil
+					})
+			},
+			expectedEventReasons:  []string{reasonServiceDriftDetected, reasonServiceDriftCorrected},
+			expectedDriftedFields: nil,
+			expectCheck:           true,
+		},
+		{
+			name:      "Test Drift Reported",
+			pdi:       pdiWithDriftPolicy(pagerdutyv1alpha1.DriftPolicyReport),
+			pdService: testCDPagerDutyService(false, false, false, true),
+			setupPDMock: func(r *pd.MockClientMockRecorder) {
+				r.GetService(gomock.Any(), gomock.Any()).Return(driftedTestService(), nil).Times(1)
+				r.RestoreService(gomock.Any(), gomock.Any()).Times(0)
+			},
+			expectedEventReasons:  []string{reasonServiceDriftDetected},
+			expectedDriftedFields: []string{pd.DriftFieldStatus},
+			expectCheck:           true,
+		},
+		{
+			name:      "Test Recently Checked",
+			pdi:       testPagerDutyIntegration(),
+			pdService: recentlyCheckedPDService,
+			setupPDMock: func(r *pd.MockClientMockRecorder) {
+				r.GetService(gomock.Any(), gomock.Any()).Times(0)
+				r.RestoreService(gomock.Any(), gomock.Any()).Times(0)
+			},
+			expectCheck: false,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			mocks := setupDefaultMocks(t, []client.Object{
+				testClusterDeployment(true, true, true, false, false, false, false),
+				testPDISecret(),
+				test.pdi,
+				test.pdService,
+				testCDSyncSet(),
+				testCDSecret(),
+			})
+			test.setupPDMock(mocks.mockPDClient.EXPECT())
+
+			defer mocks.mockCtrl.Finish()
+
+			recorder := events.NewFakeRecorder(10)
+			rpdi := newTestReconciler(mocks)
+			rpdi.cd.Recorder = recorder
+			rpdi.cd.DriftCheckInterval = time.Hour
+
+			result, err := rpdi.Reconcile(context.TODO(), reconcile.Request{
+				NamespacedName: types.NamespacedName{
+					Name:      testPagerDutyIntegrationName,
+					Namespace: config.OperatorNamespace,
+				},
+			})
+			assert.NoError(t, err)
+			assert.Equal(t, time.Hour, result.RequeueAfter)
+
+			var reasons []string
+			for len(recorder.Events) > 0 {
+				reasons = append(reasons, strings.Fields(<-recorder.Events)[1])
+			}
+			assert.Equal(t, test.expectedEventReasons, reasons)
+
+			pdService := &pagerdutyv1alpha1.PagerDutyService{}
+			err = mocks.fakeKubeClient.Get(context.TODO(), types.NamespacedName{Name: config.Name(testServicePrefix, testClusterName, config.PagerDutyServiceSuffix), Namespace: testNamespace}, pdService)
+			assert.NoError(t, err)
+			assert.Equal(t, test.expectedDriftedFields, pdService.Status.DriftedFields)
+			if test.expectCheck && assert.NotNil(t, pdService.Status.LastDriftCheckTime) {
+				assert.WithinDuration(t, time.Now(), pdService.Status.LastDriftCheckTime.Time, time.Minute)
+			}
+		})
+	}
+}
+
+func TestSelfHealPagerDutyService(t *testing.T) {
+	const (
+		newServiceID      = "NEWSVC"
+		newIntegrationID  = "NEWINT"
+		newIntegrationKey = "new-integration-key"
+	)
+	notFound := pdApi.APIError{StatusCode: http.StatusNotFound}
+
+	serviceWithoutIntegration := driftedTestService()
+	serviceWithoutIntegration.Status = "active"
+	serviceWithoutIntegration.Integrations = nil
+
+	tests := []struct {
+		name                  string
+		localObjects          []client.Object
+		driftCheckInterval    time.Duration
+		setupPDMock           func(*pd.MockClientMockRecorder)
+		expectedServiceID     string
+		expectedIntegrationID string
+		expectedEventReason   string
+	}{
+		{
+			name: "Test Service Deleted, Secret Missing",
+			localObjects: []client.Object{
+				testClusterDeployment(true, true, true, false, false, false, false),
+				testPDISecret(),
+				testPagerDutyIntegration(),
+				testCDPagerDutyService(false, false, false, true),
+				testCDSyncSet(),
+			},
+			setupPDMock: func(r *pd.MockClientMockRecorder) {
+				r.GetIntegrationKey(gomock.Any(), gomock.Any()).Return("", fmt.Errorf("unable to get integration: %w", notFound)).Times(1)
+				r.GetService(gomock.Any(), gomock.Any()).Return(nil, fmt.Errorf("unable to get service: %w", notFound)).Times(1)
+				r.CreateService(gomock.Any(), gomock.Any()).Times(1).DoAndReturn(
+					func(_ context.Context, data *pd.Data) (string, error) {
+						assert.Empty(t, data.ServiceID)
+						data.ServiceID = newServiceID
+						data.IntegrationID = newIntegrationID
+						return newIntegrationKey, nil
+					})
+				r.GetIntegrationKey(gomock.Any(), gomock.Any()).Return(newIntegrationKey, nil).Times(1)
+			},
+			expectedServiceID:     newServiceID,
+			expectedIntegrationID: newIntegrationID,
+			expectedEventReason:   reasonServiceRecreated,
+		},
+		{
+			name: "Test Integration Deleted, Secret Missing",
+			localObjects: []client.Object{
+				testClusterDeployment(true, true, true, false, false, false, false),
+				testPDISecret(),
+				testPagerDutyIntegration(),
+				testCDPagerDutyService(false, false, false, true),
+				testCDSyncSet(),
+			},
+			setupPDMock: func(r *pd.MockClientMockRecorder) {
+				r.GetIntegrationKey(gomock.Any(), gomock.Any()).Return("", fmt.Errorf("unable to get integration: %w", notFound)).Times(1)
+				r.GetService(gomock.Any(), gomock.Any()).Return(serviceWithoutIntegration, nil).Times(1)
+				r.CreateService(gomock.Any(), gomock.Any()).Times(0)
+				r.CreateIntegration(gomock.Any(), gomock.Any()).Times(1).DoAndReturn(
+					func(_ context.Context, data *pd.Data) error {
+						assert.Equal(t, testServiceID, data.ServiceID)
+						data.IntegrationID = newIntegrationID
+						return nil
+					})
+				r.GetIntegrationKey(gomock.Any(), gomock.Any()).Return(newIntegrationKey, nil).Times(1)
+			},
+			expectedServiceID:     testServiceID,
+			expectedIntegrationID: newIntegrationID,
+			expectedEventReason:   reasonIntegrationRecreated,
+		},
+		{
+			name: "Test Service Deleted, Found By Drift Check",
+			localObjects: []client.Object{
+				testClusterDeployment(true, true, true, false, false, false, false),
+				testPDISecret(),
+				testPagerDutyIntegration(),
+				testCDPagerDutyService(false, false, false, true),
+				testCDSyncSet(),
+				testCDSecret(),
+			},
+			driftCheckInterval: time.Hour,
+			setupPDMock: func(r *pd.MockClientMockRecorder) {
+				r.GetService(gomock.Any(), gomock.Any()).Return(nil, fmt.Errorf("unable to get service: %w", notFound)).Times(2)
+				r.CreateService(gomock.Any(), gomock.Any()).Times(1).DoAndReturn(
+					func(_ context.Context, data *pd.Data) (string, error) {
+						data.ServiceID = newServiceID
+						data.IntegrationID = newIntegrationID
+						return newIntegrationKey, nil
+					})
+				r.GetIntegrationKey(gomock.Any(), gomock.Any()).Return(newIntegrationKey, nil).Times(1)
+			},
+			expectedServiceID:     newServiceID,
+			expectedIntegrationID: newIntegrationID,
+			expectedEventReason:   reasonServiceRecreated,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			mocks := setupDefaultMocks(t, test.localObjects)
+			test.setupPDMock(mocks.mockPDClient.EXPECT())
+
+			defer mocks.mockCtrl.Finish()
+
+			recorder := events.NewFakeRecorder(10)
+			rpdi := newTestReconciler(mocks)
+			rpdi.cd.Recorder = recorder
+			rpdi.cd.DriftCheckInterval = test.driftCheckInterval
+
+			_, err := rpdi.Reconcile(context.TODO(), reconcile.Request{
+				NamespacedName: types.NamespacedName{
+					Name:      testPagerDutyIntegrationName,
+					Namespace: config.OperatorNamespace,
+				},
+			})
+			assert.NoError(t, err)
+
+			pdService := &pagerdutyv1alpha1.PagerDutyService{}
+			err = mocks.fakeKubeClient.Get(context.TODO(), types.NamespacedName{Name: config.Name(testServicePrefix, testClusterName, config.PagerDutyServiceSuffix), Namespace: testNamespace}, pdService)
+			assert.NoError(t, err)
+			assert.Equal(t, test.expectedServiceID, pdService.Spec.ServiceID)
+			assert.Equal(t, test.expectedIntegrationID, pdService.Spec.IntegrationID)
+
+			secret := &corev1.Secret{}
+			err = mocks.fakeKubeClient.Get(context.TODO(), types.NamespacedName{Name: config.Name(testServicePrefix, testClusterName, config.SecretSuffix), Namespace: testNamespace}, secret)
+			assert.NoError(t, err)
+			assert.Equal(t, newIntegrationKey, string(secret.Data[config.PagerDutySecretKey]))
+			assert.True(t, verifySyncSetExists(mocks.fakeKubeClient, &SyncSetEntry{
+				name:                     config.Name(testServicePrefix, testClusterName, config.SecretSuffix),
+				clusterDeploymentRefName: testClusterName,
+				targetSecret: hivev1.SecretReference{
+					Name:      testPagerDutyIntegration().Spec.TargetSecretRef.Name,
+					Namespace: testPagerDutyIntegration().Spec.TargetSecretRef.Namespace,
+				},
+			}))
+
+			if assert.NotEmpty(t, recorder.Events) {
+				assert.Equal(t, test.expectedEventReason, strings.Fields(<-recorder.Events)[1])
+			}
+		})
+	}
+}
+
+func TestSelfHealRequiresDriftCheck(t *testing.T) {
+	mocks := setupDefaultMocks(t, []client.Object{
+		testClusterDeployment(true, true, true, false, false, false, false),
+		testPDISecret(),
+		testPagerDutyIntegration(),
+		testCDPagerDutyService(false, false, false, true),
+		testCDSyncSet(),
+		testCDSecret(),
+	})
+	// the service was deleted in PagerDuty, but with the Secret in place nothing asks PD
+	r := mocks.mockPDClient.EXPECT()
+	r.GetService(gomock.Any(), gomock.Any()).Times(0)
+	r.GetIntegrationKey(gomock.Any(), gomock.Any()).Times(0)
+	r.CreateService(gomock.Any(), gomock.Any()).Times(0)
+	defer mocks.mockCtrl.Finish()
+
+	rpdi := newTestReconciler(mocks)
+	rpdi.cd.DriftCheckInterval = 0
+
+	_, err := rpdi.Reconcile(context.TODO(), reconcile.Request{
+		NamespacedName: types.NamespacedName{
+			Name:      testPagerDutyIntegrationName,
+			Namespace: config.OperatorNamespace,
+		},
+	})
+	assert.NoError(t, err)
+
+	pdService := &pagerdutyv1alpha1.PagerDutyService{}
+	err = mocks.fakeKubeClient.Get(context.TODO(), types.NamespacedName{Name: config.Name(testServicePrefix, testClusterName, config.PagerDutyServiceSuffix), Namespace: testNamespace}, pdService)
+	assert.NoError(t, err)
+	assert.Equal(t, testServiceID, pdService.Spec.ServiceID)
+}
+
+func TestReconcilePagerDutyAPIErrors(t *testing.T) {
+	rateLimited := &pd.APIError{
+		Kind:       pd.ErrRateLimited,
+		StatusCode: http.StatusTooManyRequests,
+		RetryAfter: 30 * time.Second,
+		Err:        fmt.Errorf("failed pdHttpRequest, returned status code is non 2xx: Status: 429 Too Many Requests"),
+	}
+
+	tests := []struct {
+		name              string
+		setupPDMock       func(*pd.MockClientMockRecorder)
+		expectErr         bool
+		expectRequeue     time.Duration
+		expectNoFinalizer bool
+	}{
+		{
+			name: "Test PD Service Already Deleted",
+			setupPDMock: func(r *pd.MockClientMockRecorder) {
+				r.GetService(gomock.Any(), gomock.Any()).Return(nil, fmt.Errorf("unable to get service: %w", pdApi.APIError{StatusCode: http.StatusNotFound})).Times(1)
+				r.DeleteService(gomock.Any(), gomock.Any()).Times(0)
+			},
+			expectNoFinalizer: true,
+		},
+		{
+			name: "Test Rate Limited, Retry-After Honoured",
+			setupPDMock: func(r *pd.MockClientMockRecorder) {
+				r.GetService(gomock.Any(), gomock.Any()).Return(nil, nil).Times(1)
+				r.ResolvePendingIncidents(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil).Times(1)
+				r.CountUnresolvedIncidents(gomock.Any(), gomock.Any()).Return(0, nil).Times(1)
+				r.DeleteService(gomock.Any(), gomock.Any()).Return(fmt.Errorf("unable to resolve pending incidents: %w", rateLimited)).Times(1)
+			},
+			expectRequeue: 30 * time.Second,
+		},
+		{
+			name: "Test Unauthorized",
+			setupPDMock: func(r *pd.MockClientMockRecorder) {
+				r.GetService(gomock.Any(), gomock.Any()).Return(nil, fmt.Errorf("unable to get service: %w", pdApi.APIError{StatusCode: http.StatusUnauthorized})).Times(1)
+				r.DeleteService(gomock.Any(), gomock.Any()).Times(0)
+			},
+			expectErr: true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			mocks := setupDefaultMocks(t, []client.Object{
+				testClusterDeployment(true, true, true, true, false, false, false),
+				testPDISecret(),
+				testPagerDutyIntegration(),
+				testCDPagerDutyService(false, false, false, true),
+				testCDSyncSet(),
+				testCDSecret(),
+			})
+			test.setupPDMock(mocks.mockPDClient.EXPECT())
+			defer mocks.mockCtrl.Finish()
+
+			rpdi := newTestReconciler(mocks)
+
+			result, err := rpdi.Reconcile(context.TODO(), reconcile.Request{
+				NamespacedName: types.NamespacedName{
+					Name:      testPagerDutyIntegrationName,
+					Namespace: config.OperatorNamespace,
+				},
+			})
+			if test.expectErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+			assert.Equal(t, test.expectRequeue, result.RequeueAfter)
+			assert.Equal(t, test.expectNoFinalizer, verifyNoFinalizer(mocks.fakeKubeClient, &ClusterDeploymentEntry{name: testClusterName}))
+		})
+	}
+}
+
+func TestReconcileServiceOperation(t *testing.T) {
+	assert.Nil(t, hiveapis.AddToScheme(scheme.Scheme))
+	assert.Nil(t, pagerdutyapi.AddToScheme(scheme.Scheme))
+
+	// testPDServiceWithOperation returns the PagerDutyService of the test cluster with op in progress
+	testPDServiceWithOperation := func(opType pagerdutyv1alpha1.ServiceOperationType, lastResolve time.Duration) *pagerdutyv1alpha1.PagerDutyService {
+		pdService := testCDPagerDutyService(false, false, false, true)
+		lastResolveTime := metav1.NewTime(time.Now().Add(-lastResolve))
+		pdService.Status.Operation = &pagerdutyv1alpha1.ServiceOperation{
+			Type:                opType,
+			Phase:               pagerdutyv1alpha1.ServiceOperationAwaitingResolution,
+			StartTime:           lastResolveTime,
+			LastResolveTime:     &lastResolveTime,
+			UnresolvedIncidents: 1,
+		}
+		return pdService
+	}
+
+	tests := []struct {
+		name                 string
+		isDeleting           bool
+		isLimitedSupport     bool
+		pdService            *pagerdutyv1alpha1.PagerDutyService
+		legacyConfigMap      bool
+		setupPDMock          func(*pd.MockClientMockRecorder)
+		expectRequeue        time.Duration
+		expectNoFinalizer    bool
+		expectLimitedSupport bool
+		expectPhase          pagerdutyv1alpha1.ServiceOperationPhase
+	}{
+		{
+			name:       "Test Delete, Incidents Unresolved",
+			isDeleting: true,
+			pdService:  testCDPagerDutyService(false, false, false, true),
+			setupPDMock: func(r *pd.MockClientMockRecorder) {
+				r.GetService(gomock.Any(), gomock.Any()).Return(nil, nil).Times(1)
+				r.ResolvePendingIncidents(gomock.Any(), gomock.Any(), pd.AlertResolvedSummaryDeleted).Return(nil).Times(1)
+				r.CountUnresolvedIncidents(gomock.Any(), gomock.Any()).Return(2, nil).Times(1)
+				r.DeleteService(gomock.Any(), gomock.Any()).Times(0)
+			},
+			expectRequeue: incidentResolutionPollInterval,
+			expectPhase:   pagerdutyv1alpha1.ServiceOperationAwaitingResolution,
+		},
+		{
+			// the legacy ConfigMap is migrated first, so the phase is recorded and resumed
+			name:            "Test Delete With Legacy ConfigMap, Incidents Unresolved",
+			isDeleting:      true,
+			legacyConfigMap: true,
+			setupPDMock: func(r *pd.MockClientMockRecorder) {
+				r.GetService(gomock.Any(), gomock.Any()).Return(nil, nil).Times(1)
+				r.ResolvePendingIncidents(gomock.Any(), gomock.Any(), pd.AlertResolvedSummaryDeleted).Return(nil).Times(1)
+				r.CountUnresolvedIncidents(gomock.Any(), gomock.Any()).Return(2, nil).Times(1)
+				r.DeleteService(gomock.Any(), gomock.Any()).Times(0)
+			},
+			expectRequeue: incidentResolutionPollInterval,
+			expectPhase:   pagerdutyv1alpha1.ServiceOperationAwaitingResolution,
+		},
+		{
+			name:       "Test Delete, Resumed, Incidents Resolved",
+			isDeleting: true,
+			pdService:  testPDServiceWithOperation(pagerdutyv1alpha1.ServiceOperationDelete, time.Minute),
+			setupPDMock: func(r *pd.MockClientMockRecorder) {
+				r.GetService(gomock.Any(), gomock.Any()).Return(nil, nil).Times(1)
+				r.ResolvePendingIncidents(gomock.Any(), gomock.Any(), gomock.Any()).Times(0)
+				r.CountUnresolvedIncidents(gomock.Any(), gomock.Any()).Return(0, nil).Times(1)
+				r.DeleteService(gomock.Any(), gomock.Any()).Return(nil).Times(1)
+			},
+			expectNoFinalizer: true,
+		},
+		{
+			name:       "Test Delete, Resumed, Resolution Timed Out",
+			isDeleting: true,
+			pdService:  testPDServiceWithOperation(pagerdutyv1alpha1.ServiceOperationDelete, 2*incidentResolutionTimeout),
+			setupPDMock: func(r *pd.MockClientMockRecorder) {
+				r.GetService(gomock.Any(), gomock.Any()).Return(nil, nil).Times(1)
+				r.ResolvePendingIncidents(gomock.Any(), gomock.Any(), pd.AlertResolvedSummaryDeleted).Return(nil).Times(1)
+				r.CountUnresolvedIncidents(gomock.Any(), gomock.Any()).Return(1, nil).Times(2)
+				r.DeleteService(gomock.Any(), gomock.Any()).Times(0)
+			},
+			expectRequeue: incidentResolutionPollInterval,
+			expectPhase:   pagerdutyv1alpha1.ServiceOperationAwaitingResolution,
+		},
+		{
+			name:             "Test Disable, Incidents Unresolved",
+			isLimitedSupport: true,
+			pdService:        testCDPagerDutyService(false, false, false, true),
+			setupPDMock: func(r *pd.MockClientMockRecorder) {
+				r.ResolvePendingIncidents(gomock.Any(), gomock.Any(), pd.AlertResolvedSummaryLimitedSupport).Return(nil).Times(1)
+				r.CountUnresolvedIncidents(gomock.Any(), gomock.Any()).Return(1, nil).Times(1)
+				r.DisableService(gomock.Any(), gomock.Any()).Times(0)
+			},
+			expectRequeue: incidentResolutionPollInterval,
+			expectPhase:   pagerdutyv1alpha1.ServiceOperationAwaitingResolution,
+		},
+		{
+			name:             "Test Disable, Resumed, Incidents Resolved",
+			isLimitedSupport: true,
+			pdService:        testPDServiceWithOperation(pagerdutyv1alpha1.ServiceOperationDisable, time.Minute),
+			setupPDMock: func(r *pd.MockClientMockRecorder) {
+				r.ResolvePendingIncidents(gomock.Any(), gomock.Any(), gomock.Any()).Times(0)
+				r.CountUnresolvedIncidents(gomock.Any(), gomock.Any()).Return(0, nil).Times(1)
+				r.DisableService(gomock.Any(), gomock.Any()).Return(nil).Times(1)
+			},
+			expectLimitedSupport: true,
+		},
+		{
+			name:      "Test Disable, Cancelled When Leaving Limited Support",
+			pdService: testPDServiceWithOperation(pagerdutyv1alpha1.ServiceOperationDisable, time.Minute),
+			setupPDMock: func(r *pd.MockClientMockRecorder) {
+				r.ResolvePendingIncidents(gomock.Any(), gomock.Any(), gomock.Any()).Times(0)
+				r.CountUnresolvedIncidents(gomock.Any(), gomock.Any()).Times(0)
+				r.DisableService(gomock.Any(), gomock.Any()).Times(0)
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			localObjects := []client.Object{
+				testClusterDeployment(true, true, true, test.isDeleting, false, false, test.isLimitedSupport),
+				testPDISecret(),
+				testPagerDutyIntegration(),
+				testCDSyncSet(),
+				testCDSecret(),
+			}
+			if test.legacyConfigMap {
+				localObjects = append(localObjects, testCDConfigMap(false, false, false, true))
+			} else {
+				localObjects = append(localObjects, test.pdService)
+			}
+			mocks := setupDefaultMocks(t, localObjects)
+			test.setupPDMock(mocks.mockPDClient.EXPECT())
+			defer mocks.mockCtrl.Finish()
+
+			rpdi := newTestReconciler(mocks)
+
+			result, err := rpdi.Reconcile(context.TODO(), reconcile.Request{
+				NamespacedName: types.NamespacedName{
+					Name:      testPagerDutyIntegrationName,
+					Namespace: config.OperatorNamespace,
+				},
+			})
+			assert.NoError(t, err)
+			assert.Equal(t, test.expectRequeue, result.RequeueAfter)
+			assert.Equal(t, test.expectNoFinalizer, verifyNoFinalizer(mocks.fakeKubeClient, &ClusterDeploymentEntry{name: testClusterName}))
+
+			if test.expectNoFinalizer {
+				return
+			}
+			pdService := &pagerdutyv1alpha1.PagerDutyService{}
+			pdServiceName := config.Name(testServicePrefix, testClusterName, config.PagerDutyServiceSuffix)
+			err = mocks.fakeKubeClient.Get(context.TODO(), types.NamespacedName{Name: pdServiceName, Namespace: testNamespace}, pdService)
+			assert.NoError(t, err)
+			assert.Equal(t, test.expectLimitedSupport, pdService.Spec.LimitedSupport)
+			if test.expectPhase == "" {
+				assert.Nil(t, pdService.Status.Operation)
+			} else if assert.NotNil(t, pdService.Status.Operation) {
+				assert.Equal(t, test.expectPhase, pdService.Status.Operation.Phase)
+			}
+		})
+	}
+}
+
+// driftedTestService returns the PD service of the test ClusterDeployment as created by
+// the operator, except that it was disabled in PagerDuty
+func driftedTestService() *pdApi.Service {
+	resolveTimeout, acknowledgeTimeout, alertGroupingTimeout := uint(testResolveTimeout), uint(testAcknowledgeTimeout), uint(testAlertGroupingTimeout)
+	return &pdApi.Service{
+		APIObject:   pdApi.APIObject{ID: testServiceID},
+		Name:        testServicePrefix + "-" + testClusterName + "." + testBaseDomain + "-hive-cluster",
+		Description: testClusterName + " - A managed hive created cluster",
+		Status:      "disabled",
+		EscalationPolicy: pdApi.EscalationPolicy{
+			APIObject: pdApi.APIObject{ID: testEscalationPolicy},
+		},
+		IncidentUrgencyRule: &pdApi.IncidentUrgencyRule{
+			Type:    "constant",
+			Urgency: config.PagerDutyUrgencyRule,
+		},
+		AutoResolveTimeout:     &resolveTimeout,
+		AcknowledgementTimeout: &acknowledgeTimeout,
+		AlertGroupingParameters: &pdApi.AlertGroupingParameters{
+			Type:   testAlertGroupingType,
+			Config: &pdApi.AlertGroupParamsConfig{Timeout: &alertGroupingTimeout},
+		},
+		Integrations: []pdApi.Integration{
+			{APIObject: pdApi.APIObject{ID: testIntegrationID}},
+		},
+	}
+}
+
+func TestReconcilePagerDutyIntegrationStatus(t *testing.T) {
+	tests := []struct {
+		name         string
+		localObjects []client.Object
+		setupPDMock  func(*pd.MockClientMockRecorder)
+		expectErr    bool
+		verifyStatus func(*testing.T, *pagerdutyv1alpha1.PagerDutyIntegrationStatus)
+	}{
+		{
+			name: "Test All ClusterDeployments Reconciled",
+			localObjects: []client.Object{
+				testClusterDeployment(true, true, true, false, false, false, false),
+				testPDISecret(),
+				testPagerDutyIntegration(),
+				testCDPagerDutyService(false, false, false, true),
+				testCDSyncSet(),
+				testCDSecret(),
+			},
+			setupPDMock: func(r *pd.MockClientMockRecorder) {},
+			verifyStatus: func(t *testing.T, status *pagerdutyv1alpha1.PagerDutyIntegrationStatus) {
+				assert.True(t, meta.IsStatusConditionTrue(status.Conditions, pagerdutyv1alpha1.ConditionReady))
+				assert.True(t, meta.IsStatusConditionTrue(status.Conditions, pagerdutyv1alpha1.ConditionSecretLoaded))
+				assert.True(t, meta.IsStatusConditionFalse(status.Conditions, pagerdutyv1alpha1.ConditionDegraded))
+				assert.Equal(t, int32(1), status.MatchedClusterDeployments)
+				assert.Equal(t, int32(1), status.ProvisionedClusterDeployments)
+				assert.Equal(t, int32(0), status.FailedClusterDeployments)
+				assert.Equal(t, int32(0), status.LimitedSupportClusterDeployments)
+				assert.NotNil(t, status.LastReconcileTime)
+				assert.Empty(t, status.RecentErrors)
+			},
+		},
+		{
+			name: "Test Limited Support ClusterDeployment Counted",
+			localObjects: []client.Object{
+				testClusterDeployment(true, true, true, false, false, false, true),
+				testPDISecret(),
+				testPagerDutyIntegration(),
+				testCDPagerDutyService(true, false, false, true),
+				testCDSyncSet(),
+				testCDSecret(),
+			},
+			setupPDMock: func(r *pd.MockClientMockRecorder) {},
+			verifyStatus: func(t *testing.T, status *pagerdutyv1alpha1.PagerDutyIntegrationStatus) {
+				assert.True(t, meta.IsStatusConditionTrue(status.Conditions, pagerdutyv1alpha1.ConditionReady))
+				assert.Equal(t, int32(1), status.ProvisionedClusterDeployments)
+				assert.Equal(t, int32(1), status.LimitedSupportClusterDeployments)
+			},
+		},
+		{
+			name: "Test ClusterDeployment Fails To Reconcile",
+			localObjects: []client.Object{
+				testClusterDeployment(true, true, true, false, false, false, false),
+				testPDISecret(),
+				testPagerDutyIntegration(),
+			},
+			setupPDMock: func(r *pd.MockClientMockRecorder) {
+				r.CreateService(gomock.Any(), gomock.Any()).Return("", fmt.Errorf("pagerduty unavailable")).Times(1)
+			},
+			expectErr: true,
+			verifyStatus: func(t *testing.T, status *pagerdutyv1alpha1.PagerDutyIntegrationStatus) {
+				assert.True(t, meta.IsStatusConditionFalse(status.Conditions, pagerdutyv1alpha1.ConditionReady))
+				assert.True(t, meta.IsStatusConditionTrue(status.Conditions, pagerdutyv1alpha1.ConditionDegraded))
+				assert.Equal(t, int32(1), status.MatchedClusterDeployments)
+				assert.Equal(t, int32(0), status.ProvisionedClusterDeployments)
+				assert.Equal(t, int32(1), status.FailedClusterDeployments)
+				if assert.Len(t, status.RecentErrors, 1) {
+					assert.Equal(t, testClusterName, status.RecentErrors[0].Name)
+					assert.Equal(t, testNamespace, status.RecentErrors[0].Namespace)
+					assert.Equal(t, "pagerduty unavailable", status.RecentErrors[0].Message)
+				}
+			},
+		},
+		{
+			name: "Test Service Name Template Does Not Parse",
+			localObjects: []client.Object{
+				testClusterDeployment(true, true, true, false, false, false, false),
+				testPDISecret(),
+				func() *pagerdutyv1alpha1.PagerDutyIntegration {
+					pdi := testPagerDutyIntegration()
+					pdi.Spec.ServiceNameTemplate = "{{.ClusterID"
+					return pdi
+				}(),
+			},
+			setupPDMock: func(r *pd.MockClientMockRecorder) {
+				r.CreateService(gomock.Any(), gomock.Any()).Times(0)
+			},
+			verifyStatus: func(t *testing.T, status *pagerdutyv1alpha1.PagerDutyIntegrationStatus) {
+				assert.True(t, meta.IsStatusConditionFalse(status.Conditions, pagerdutyv1alpha1.ConditionReady))
+				degraded := meta.FindStatusCondition(status.Conditions, pagerdutyv1alpha1.ConditionDegraded)
+				if assert.NotNil(t, degraded) {
+					assert.Equal(t, metav1.ConditionTrue, degraded.Status)
+					assert.Equal(t, pagerdutyv1alpha1.ReasonInvalidServiceTemplate, degraded.Reason)
+					assert.Contains(t, degraded.Message, "serviceNameTemplate")
+				}
+				// the ClusterDeployment isn't failed for it
+				assert.Equal(t, int32(0), status.FailedClusterDeployments)
+				assert.Empty(t, status.RecentErrors)
+			},
+		},
+		{
+			name: "Test PagerDuty API Key Secret Missing",
+			localObjects: []client.Object{
+				testClusterDeployment(true, true, true, false, false, false, false),
+				testPagerDutyIntegration(),
+			},
+			setupPDMock: func(r *pd.MockClientMockRecorder) {},
+			verifyStatus: func(t *testing.T, status *pagerdutyv1alpha1.PagerDutyIntegrationStatus) {
+				assert.True(t, meta.IsStatusConditionFalse(status.Conditions, pagerdutyv1alpha1.ConditionReady))
+				assert.True(t, meta.IsStatusConditionFalse(status.Conditions, pagerdutyv1alpha1.ConditionSecretLoaded))
+				assert.Nil(t, meta.FindStatusCondition(status.Conditions, pagerdutyv1alpha1.ConditionDegraded))
+				assert.NotNil(t, status.LastReconcileTime)
+			},
+		},
+		{
+			name: "Test PagerDutyAccount Missing",
+			localObjects: []client.Object{
+				testClusterDeployment(true, true, true, false, false, false, false),
+				testPDISecret(),
+				testPagerDutyIntegrationWithAccount(),
+			},
+			setupPDMock: func(r *pd.MockClientMockRecorder) {},
+			verifyStatus: func(t *testing.T, status *pagerdutyv1alpha1.PagerDutyIntegrationStatus) {
+				assert.True(t, meta.IsStatusConditionFalse(status.Conditions, pagerdutyv1alpha1.ConditionReady))
+				secretLoaded := meta.FindStatusCondition(status.Conditions, pagerdutyv1alpha1.ConditionSecretLoaded)
+				if assert.NotNil(t, secretLoaded) {
+					assert.Equal(t, metav1.ConditionFalse, secretLoaded.Status)
+					assert.Equal(t, pagerdutyv1alpha1.ReasonAccountNotFound, secretLoaded.Reason)
+				}
+			},
+		},
+		{
+			name: "Test PagerDuty API Key Rejected",
+			localObjects: []client.Object{
+				testClusterDeployment(true, true, true, false, false, false, false),
+				testPDISecret(),
+				testPagerDutyIntegration(),
+			},
+			setupPDMock: func(r *pd.MockClientMockRecorder) {
+				r.ValidateAPIKey(gomock.Any()).Return(fmt.Errorf("unable to validate API key: %w", pd.ErrUnauthorized)).Times(1)
+				r.CreateService(gomock.Any(), gomock.Any()).Times(0)
+			},
+			verifyStatus: func(t *testing.T, status *pagerdutyv1alpha1.PagerDutyIntegrationStatus) {
+				assert.True(t, meta.IsStatusConditionFalse(status.Conditions, pagerdutyv1alpha1.ConditionReady))
+				secretLoaded := meta.FindStatusCondition(status.Conditions, pagerdutyv1alpha1.ConditionSecretLoaded)
+				if assert.NotNil(t, secretLoaded) {
+					assert.Equal(t, metav1.ConditionFalse, secretLoaded.Status)
+					assert.Equal(t, pagerdutyv1alpha1.ReasonAPIKeyRejected, secretLoaded.Reason)
+				}
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			mocks := setupDefaultMocks(t, test.localObjects)
+			test.setupPDMock(mocks.mockPDClient.EXPECT())
+
+			defer mocks.mockCtrl.Finish()
+
+			rpdi := newTestReconciler(mocks)
+
+			_, err := rpdi.Reconcile(context.TODO(), reconcile.Request{
+				NamespacedName: types.NamespacedName{
+					Name:      testPagerDutyIntegrationName,
+					Namespace: config.OperatorNamespace,
+				},
+			})
+			if test.expectErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+
+			pdi := &pagerdutyv1alpha1.PagerDutyIntegration{}
+			err = mocks.fakeKubeClient.Get(context.TODO(), types.NamespacedName{Name: testPagerDutyIntegrationName, Namespace: config.OperatorNamespace}, pdi)
+			assert.NoError(t, err)
+			test.verifyStatus(t, &pdi.Status)
+		})
+	}
+}
+
+func TestPagerDutyIntegrationsForSecret(t *testing.T) {
+	otherPDI := testPagerDutyIntegration()
+	otherPDI.Name = "other-pdi"
+	otherPDI.Spec.PagerdutyApiKeySecretRef.Name = "other-api-key"
+
+	mocks := setupDefaultMocks(t, []client.Object{testPDISecret(), testPagerDutyIntegration(), otherPDI})
+	defer mocks.mockCtrl.Finish()
+
+	rpdi := newTestReconciler(mocks)
+	reqs := rpdi.pdi.pagerDutyIntegrationsForSecret(context.TODO(), testPDISecret())
+	assert.Equal(t, []reconcile.Request{{NamespacedName: types.NamespacedName{Namespace: config.OperatorNamespace, Name: testPagerDutyIntegrationName}}}, reqs)
+
+	unreferenced := testPDISecret()
+	unreferenced.Name = "unreferenced"
+	assert.Empty(t, rpdi.pdi.pagerDutyIntegrationsForSecret(context.TODO(), unreferenced))
+}
+
+func TestPagerDutyIntegrationsForAccountSecret(t *testing.T) {
+	accountPDI := testPagerDutyIntegrationWithAccount()
+	accountPDI.Name = "account-pdi"
+
+	mocks := setupDefaultMocks(t, []client.Object{testPDISecret(), testAccountSecret(), testPagerDutyAccount(), testPagerDutyIntegration(), accountPDI})
+	defer mocks.mockCtrl.Finish()
+
+	rpdi := newTestReconciler(mocks)
+	accountPDIRequest := reconcile.Request{NamespacedName: types.NamespacedName{Namespace: config.OperatorNamespace, Name: accountPDI.Name}}
+	// the Secret of the account reaches the PDIs using the account
+	assert.Equal(t, []reconcile.Request{accountPDIRequest}, rpdi.pdi.pagerDutyIntegrationsForSecret(context.TODO(), testAccountSecret()))
+	assert.Equal(t, []reconcile.Request{accountPDIRequest}, rpdi.pdi.pagerDutyIntegrationsForAccount(context.TODO(), testPagerDutyAccount()))
+}
+
 func TestSanitizeLabelSelector(t *testing.T) {
 	logger := logf.Log.WithName("test_sanitize_label_selector")
 
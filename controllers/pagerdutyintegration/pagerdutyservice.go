@@ -0,0 +1,86 @@
+// Copyright 2019 RedHat
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pagerdutyintegration
+
+import (
+	"context"
+
+	hivev1 "github.com/openshift/hive/apis/hive/v1"
+	pagerdutyv1alpha1 "github.com/openshift/pagerduty-operator/api/v1alpha1"
+	"github.com/openshift/pagerduty-operator/config"
+	"github.com/openshift/pagerduty-operator/pkg/kube"
+	pd "github.com/openshift/pagerduty-operator/pkg/pagerduty"
+	"github.com/openshift/pagerduty-operator/pkg/utils"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+)
+
+// migrateLegacyClusterConfig moves the cluster config stored in the legacy "-pd-config"
+// ConfigMap of a ClusterDeployment into a PagerDutyService, then deletes the ConfigMap.
+// An existing PagerDutyService always takes precedence over the ConfigMap.
+func (r *ClusterDeploymentReconciler) migrateLegacyClusterConfig(ctx context.Context, pdi *pagerdutyv1alpha1.PagerDutyIntegration, cd *hivev1.ClusterDeployment) error {
+	var (
+		configMapName = config.Name(pdi.Spec.ServicePrefix, cd.Name, config.ConfigMapSuffix)
+		pdServiceName = config.Name(pdi.Spec.ServicePrefix, cd.Name, config.PagerDutyServiceSuffix)
+	)
+
+	cm := &corev1.ConfigMap{}
+	if err := r.Get(ctx, types.NamespacedName{Namespace: cd.Namespace, Name: configMapName}, cm); err != nil {
+		if errors.IsNotFound(err) {
+			// nothing to migrate
+			return nil
+		}
+		return err
+	}
+
+	err := r.Get(ctx, types.NamespacedName{Namespace: cd.Namespace, Name: pdServiceName}, &pagerdutyv1alpha1.PagerDutyService{})
+	if err != nil && !errors.IsNotFound(err) {
+		return err
+	}
+
+	if errors.IsNotFound(err) {
+		pdData, err := pd.NewData(pdi, cd, utils.GetClusterID(cd, r.IsFedramp), r.IsFedramp)
+		if err != nil {
+			return err
+		}
+
+		if err := pdData.ParseLegacyClusterConfig(cm); err != nil {
+			// The ConfigMap doesn't identify a PagerDuty service, so there is nothing worth
+			// keeping. handleCreate will create (or adopt) the service as it always did.
+			r.reqLogger.Info("Legacy PD ConfigMap is incomplete, dropping it", "ClusterDeployment.Namespace", cd.Namespace, "Name", configMapName, "Reason", err.Error())
+		} else {
+			r.reqLogger.Info("Migrating PD ConfigMap to PagerDutyService", "ClusterDeployment.Namespace", cd.Namespace, "Name", pdServiceName)
+			pdService := kube.GeneratePagerDutyService(cd.Namespace, pdServiceName, cd.Name, pdi)
+			pdData.UpdatePagerDutyServiceSpec(&pdService.Spec)
+			pdData.RecordAppliedSettings(&pdService.Spec)
+			// The ConfigMap never recorded the timeouts, so leave them unset to have
+			// handleUpdate apply the ones from the PagerDutyIntegration.
+			pdService.Spec.ResolveTimeout = 0
+			pdService.Spec.AcknowledgeTimeout = 0
+			if err := controllerutil.SetControllerReference(cd, pdService, r.Scheme); err != nil {
+				r.reqLogger.Error(err, "Error setting controller reference on PagerDutyService")
+				return err
+			}
+			if err := r.Create(ctx, pdService); err != nil && !errors.IsAlreadyExists(err) {
+				r.reqLogger.Error(err, "Error creating PagerDutyService", "Name", pdServiceName)
+				return err
+			}
+		}
+	}
+
+	return utils.DeleteConfigMap(configMapName, cd.Namespace, r.Client, r.reqLogger)
+}
@@ -0,0 +1,76 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pagerdutyintegration
+
+import (
+	hivev1 "github.com/openshift/hive/apis/hive/v1"
+	"github.com/openshift/pagerduty-operator/pkg/localmetrics"
+	"k8s.io/apimachinery/pkg/api/equality"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+)
+
+// finalizersChangedPredicate passes updates that add or remove finalizers, which don't
+// bump the generation
+var finalizersChangedPredicate = predicate.Funcs{
+	UpdateFunc: func(e event.UpdateEvent) bool {
+		if e.ObjectOld == nil || e.ObjectNew == nil {
+			return false
+		}
+		return !equality.Semantic.DeepEqual(e.ObjectOld.GetFinalizers(), e.ObjectNew.GetFinalizers())
+	},
+}
+
+// clusterDeploymentChangedPredicate passes ClusterDeployment updates that change a field
+// the reconcilers read. Hive updates the status of ClusterDeployments all the time, those
+// updates are dropped and counted for the given controller.
+func clusterDeploymentChangedPredicate(controller string) predicate.Funcs {
+	return predicate.Funcs{
+		UpdateFunc: func(e event.UpdateEvent) bool {
+			oldCD, okOld := e.ObjectOld.(*hivev1.ClusterDeployment)
+			newCD, okNew := e.ObjectNew.(*hivev1.ClusterDeployment)
+			if !okOld || !okNew {
+				return true
+			}
+			if clusterDeploymentChanged(oldCD, newCD) {
+				return true
+			}
+			localmetrics.AddMetricFilteredEvent(controller, "ClusterDeployment")
+			return false
+		},
+	}
+}
+
+// clusterDeploymentChanged returns whether any of the fields of a ClusterDeployment the
+// reconcilers read differs between oldCD and newCD
+func clusterDeploymentChanged(oldCD, newCD *hivev1.ClusterDeployment) bool {
+	return !equality.Semantic.DeepEqual(oldCD.Labels, newCD.Labels) ||
+		!equality.Semantic.DeepEqual(oldCD.Annotations, newCD.Annotations) ||
+		!equality.Semantic.DeepEqual(oldCD.Finalizers, newCD.Finalizers) ||
+		!equality.Semantic.DeepEqual(oldCD.DeletionTimestamp, newCD.DeletionTimestamp) ||
+		oldCD.Spec.Installed != newCD.Spec.Installed ||
+		oldCD.Spec.ClusterName != newCD.Spec.ClusterName ||
+		oldCD.Spec.BaseDomain != newCD.Spec.BaseDomain ||
+		clusterID(oldCD) != clusterID(newCD)
+}
+
+func clusterID(cd *hivev1.ClusterDeployment) string {
+	if cd.Spec.ClusterMetadata == nil {
+		return ""
+	}
+	return cd.Spec.ClusterMetadata.ClusterID
+}
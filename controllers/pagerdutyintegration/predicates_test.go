@@ -0,0 +1,108 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pagerdutyintegration
+
+import (
+	"testing"
+
+	hivev1 "github.com/openshift/hive/apis/hive/v1"
+	"github.com/openshift/pagerduty-operator/config"
+	"github.com/openshift/pagerduty-operator/pkg/localmetrics"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+)
+
+func TestClusterDeploymentChangedPredicate(t *testing.T) {
+	tests := []struct {
+		name         string
+		update       func(cd *hivev1.ClusterDeployment)
+		expectPassed bool
+	}{
+		{
+			name: "Test Status Update Is Filtered",
+			update: func(cd *hivev1.ClusterDeployment) {
+				cd.Status.InstallRestarts++
+				cd.ResourceVersion = "2"
+			},
+			expectPassed: false,
+		},
+		{
+			name:         "Test Label Change Passes",
+			update:       func(cd *hivev1.ClusterDeployment) { cd.Labels[config.ClusterDeploymentLimitedSupportLabel] = "true" },
+			expectPassed: true,
+		},
+		{
+			name: "Test Annotation Change Passes",
+			update: func(cd *hivev1.ClusterDeployment) {
+				cd.Annotations = map[string]string{"managed.openshift.com/fake": "true"}
+			},
+			expectPassed: true,
+		},
+		{
+			name:         "Test Finalizer Change Passes",
+			update:       func(cd *hivev1.ClusterDeployment) { cd.Finalizers = nil },
+			expectPassed: true,
+		},
+		{
+			name: "Test Deletion Passes",
+			update: func(cd *hivev1.ClusterDeployment) {
+				now := metav1.Now()
+				cd.DeletionTimestamp = &now
+			},
+			expectPassed: true,
+		},
+		{
+			name:         "Test Installed Change Passes",
+			update:       func(cd *hivev1.ClusterDeployment) { cd.Spec.Installed = !cd.Spec.Installed },
+			expectPassed: true,
+		},
+		{
+			name:         "Test Base Domain Change Passes",
+			update:       func(cd *hivev1.ClusterDeployment) { cd.Spec.BaseDomain = "new.example.com" },
+			expectPassed: true,
+		},
+		{
+			name: "Test Cluster ID Change Passes",
+			update: func(cd *hivev1.ClusterDeployment) {
+				cd.Spec.ClusterMetadata = &hivev1.ClusterMetadata{ClusterID: "new-id"}
+			},
+			expectPassed: true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			oldCD := testClusterDeployment(true, true, true, false, false, false, false)
+			newCD := oldCD.DeepCopy()
+			test.update(newCD)
+
+			filtered := localmetrics.MetricFilteredEvents.WithLabelValues("test", "ClusterDeployment")
+			before := testutil.ToFloat64(filtered)
+
+			passed := clusterDeploymentChangedPredicate("test").Update(event.UpdateEvent{ObjectOld: oldCD, ObjectNew: newCD})
+			assert.Equal(t, test.expectPassed, passed)
+
+			expectFiltered := before
+			if !test.expectPassed {
+				expectFiltered++
+			}
+			assert.Equal(t, expectFiltered, testutil.ToFloat64(filtered))
+		})
+	}
+}
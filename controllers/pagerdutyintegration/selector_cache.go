@@ -0,0 +1,106 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pagerdutyintegration
+
+import (
+	"sync"
+
+	"github.com/go-logr/logr"
+	pagerdutyv1alpha1 "github.com/openshift/pagerduty-operator/api/v1alpha1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+// SelectorCache keeps the parsed ClusterDeployment selector of each PagerDutyIntegration,
+// so that event handlers and reconciles don't parse every selector for every event.
+// An entry is replaced as soon as the generation or UID of the PagerDutyIntegration
+// changes. A nil SelectorCache parses the selector on every call.
+type SelectorCache struct {
+	mu      sync.RWMutex
+	entries map[types.NamespacedName]cachedSelector
+}
+
+// cachedSelector is the selector of a PagerDutyIntegration at a given generation,
+// selector is nil when it can't match anything
+type cachedSelector struct {
+	uid        types.UID
+	generation int64
+	selector   labels.Selector
+}
+
+// NewSelectorCache returns an empty SelectorCache, to be shared by both controllers
+func NewSelectorCache() *SelectorCache {
+	return &SelectorCache{entries: map[types.NamespacedName]cachedSelector{}}
+}
+
+// selectorFor returns the ClusterDeployment selector of pdi, or nil if it can't
+// match anything
+func (c *SelectorCache) selectorFor(pdi *pagerdutyv1alpha1.PagerDutyIntegration, logger logr.Logger) (labels.Selector, error) {
+	if c == nil {
+		return parseClusterDeploymentSelector(pdi, logger)
+	}
+
+	key := types.NamespacedName{Namespace: pdi.Namespace, Name: pdi.Name}
+	c.mu.RLock()
+	entry, ok := c.entries[key]
+	c.mu.RUnlock()
+	if ok && entry.uid == pdi.UID && entry.generation == pdi.Generation {
+		return entry.selector, nil
+	}
+
+	selector, err := parseClusterDeploymentSelector(pdi, logger)
+	if err != nil {
+		// not cached, so that the error is reported every time
+		return nil, err
+	}
+
+	c.mu.Lock()
+	c.entries[key] = cachedSelector{uid: pdi.UID, generation: pdi.Generation, selector: selector}
+	c.mu.Unlock()
+	return selector, nil
+}
+
+// matches returns whether the ClusterDeployment selector of pdi matches the labels of obj
+func (c *SelectorCache) matches(pdi *pagerdutyv1alpha1.PagerDutyIntegration, obj metav1.Object, logger logr.Logger) bool {
+	selector, err := c.selectorFor(pdi, logger)
+	if err != nil {
+		logger.Error(err, "could not build ClusterDeployment label selector", "PagerDutyIntegration", pdi.Name)
+		return false
+	}
+	return selector != nil && selector.Matches(labels.Set(obj.GetLabels()))
+}
+
+// forget drops the selector of a deleted PagerDutyIntegration
+func (c *SelectorCache) forget(key types.NamespacedName) {
+	if c == nil {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	delete(c.entries, key)
+}
+
+// parseClusterDeploymentSelector returns the ClusterDeployment selector of pdi, or nil
+// if it can't match anything
+func parseClusterDeploymentSelector(pdi *pagerdutyv1alpha1.PagerDutyIntegration, logger logr.Logger) (labels.Selector, error) {
+	sanitized, matchesNothing := sanitizeLabelSelector(&pdi.Spec.ClusterDeploymentSelector, logger)
+	if matchesNothing {
+		return nil, nil
+	}
+	return metav1.LabelSelectorAsSelector(sanitized)
+}
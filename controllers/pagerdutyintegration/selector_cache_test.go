@@ -0,0 +1,117 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pagerdutyintegration
+
+import (
+	"context"
+	"testing"
+
+	"github.com/openshift/pagerduty-operator/config"
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+func TestSelectorCache(t *testing.T) {
+	logger := logf.Log.WithName("test_selector_cache")
+	cd := &metav1.ObjectMeta{Labels: map[string]string{"pdiWatching": "cd2"}}
+
+	tests := []struct {
+		name        string
+		update      func(pdi *metav1.ObjectMeta)
+		expectMatch bool
+	}{
+		{
+			name:        "Test Cached Selector Is Reused For The Same Generation",
+			update:      func(pdi *metav1.ObjectMeta) {},
+			expectMatch: false,
+		},
+		{
+			name:        "Test New Generation Invalidates The Selector",
+			update:      func(pdi *metav1.ObjectMeta) { pdi.Generation++ },
+			expectMatch: true,
+		},
+		{
+			name:        "Test Recreated PagerDutyIntegration Invalidates The Selector",
+			update:      func(pdi *metav1.ObjectMeta) { pdi.UID = "recreated" },
+			expectMatch: true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			cache := NewSelectorCache()
+			pdi := mockPagerDutyIntegration("pdi1", map[string]string{"pdiWatching": "cd1"})
+			pdi.Generation = 1
+			pdi.UID = "original"
+			assert.False(t, cache.matches(pdi, cd, logger))
+
+			// only a new generation or UID is expected to change the selector
+			pdi.Spec.ClusterDeploymentSelector.MatchLabels = map[string]string{"pdiWatching": "cd2"}
+			test.update(&pdi.ObjectMeta)
+			assert.Equal(t, test.expectMatch, cache.matches(pdi, cd, logger))
+		})
+	}
+}
+
+// TestSelectorCacheSharedByControllers checks that the ClusterDeployment controller doesn't
+// keep the selector of a PagerDutyIntegration the PagerDutyIntegration controller forgot
+func TestSelectorCacheSharedByControllers(t *testing.T) {
+	logger := logf.Log.WithName("test_selector_cache")
+	mocks := setupDefaultMocks(t, []client.Object{})
+	defer mocks.mockCtrl.Finish()
+	r := newTestReconciler(mocks)
+
+	pdi := mockPagerDutyIntegration("pdi1", map[string]string{"pdiWatching": "cd1"})
+	_, err := r.cd.Selectors.selectorFor(pdi, logger)
+	assert.NoError(t, err)
+	assert.Len(t, r.cd.Selectors.entries, 1)
+
+	// the PagerDutyIntegration is gone
+	_, err = r.pdi.Reconcile(context.TODO(), reconcile.Request{NamespacedName: types.NamespacedName{Namespace: pdi.Namespace, Name: pdi.Name}})
+	assert.NoError(t, err)
+	assert.Empty(t, r.cd.Selectors.entries)
+}
+
+func TestSelectorCacheMatchesNothing(t *testing.T) {
+	logger := logf.Log.WithName("test_selector_cache")
+	cd := &metav1.ObjectMeta{Labels: map[string]string{"env": "prod"}}
+
+	for name, cache := range map[string]*SelectorCache{"cached": NewSelectorCache(), "uncached": nil} {
+		t.Run(name, func(t *testing.T) {
+			pdi := mockPagerDutyIntegrationWithExpressions("pdi1", []metav1.LabelSelectorRequirement{
+				{Key: "env", Operator: metav1.LabelSelectorOpIn, Values: []string{}},
+			})
+			selector, err := cache.selectorFor(pdi, logger)
+			assert.NoError(t, err)
+			assert.Nil(t, selector)
+			assert.False(t, cache.matches(pdi, cd, logger))
+
+			cache.forget(types.NamespacedName{Namespace: pdi.Namespace, Name: pdi.Name})
+		})
+	}
+}
+
+func TestPagerDutyFinalizers(t *testing.T) {
+	cd := testClusterDeployment(true, true, true, false, false, false, false)
+	cd.Finalizers = append(cd.Finalizers, "hive.openshift.io/deprovision")
+
+	assert.Equal(t, []string{config.PagerDutyFinalizerPrefix + testPagerDutyIntegrationName}, pagerDutyFinalizers(cd))
+}
@@ -0,0 +1,103 @@
+// Copyright 2019 RedHat
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pagerdutyintegration
+
+import (
+	"context"
+
+	hivev1 "github.com/openshift/hive/apis/hive/v1"
+	pagerdutyv1alpha1 "github.com/openshift/pagerduty-operator/api/v1alpha1"
+	"github.com/openshift/pagerduty-operator/config"
+	"github.com/openshift/pagerduty-operator/pkg/localmetrics"
+	pd "github.com/openshift/pagerduty-operator/pkg/pagerduty"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+// Event reasons emitted when a PD object deleted outside of the operator is recreated
+const (
+	reasonServiceRecreated     = "ServiceRecreated"
+	reasonIntegrationRecreated = "IntegrationRecreated"
+)
+
+// healPagerDutyService recreates the PD service or its integration when they were deleted
+// in PagerDuty, then records the new IDs in the PagerDutyService and the new integration
+// key in the Secret synced to the cluster. It returns false when nothing was missing.
+func (r *ClusterDeploymentReconciler) healPagerDutyService(ctx context.Context, pdclient pd.Client, pdi *pagerdutyv1alpha1.PagerDutyIntegration, cd *hivev1.ClusterDeployment, pdData *pd.Data) (bool, error) {
+	var (
+		secretName    = config.Name(pdi.Spec.ServicePrefix, cd.Name, config.SecretSuffix)
+		pdServiceName = config.Name(pdi.Spec.ServicePrefix, cd.Name, config.PagerDutyServiceSuffix)
+	)
+
+	pdService := &pagerdutyv1alpha1.PagerDutyService{}
+	if err := r.Get(ctx, types.NamespacedName{Namespace: cd.Namespace, Name: pdServiceName}, pdService); err != nil {
+		return false, err
+	}
+
+	// recordErr records the recreated PD objects in the PagerDutyService
+	var recordErr error
+	service, err := pdclient.GetService(ctx, pdData)
+	switch {
+	case err != nil && !pd.IsNotFound(err):
+		return false, err
+	case err != nil:
+		oldServiceID := pdData.ServiceID
+		r.reqLogger.Info("PD service not found, recreating it", "ClusterDeployment.Namespace", cd.Namespace, "ServiceID", oldServiceID)
+
+		// The new service starts out with the default settings of the PDI. Limited support
+		// and service orchestration are applied again by their handlers.
+		pdData.ServiceID = ""
+		pdData.IntegrationID = ""
+		pdData.EscalationPolicyID, err = pd.EscalationPolicyFor(pdi, cd)
+		if err != nil {
+			return false, err
+		}
+		pdData.LimitedSupport = false
+		pdData.ServiceOrchestrationEnabled = false
+		pdData.ServiceOrchestrationRuleApplied = ""
+		if _, err := pdclient.CreateService(ctx, pdData); err != nil {
+			localmetrics.UpdateMetricPagerDutyCreateFailure(1, pdData.ClusterID, pdi.Name)
+			return false, err
+		}
+		localmetrics.UpdateMetricPagerDutyCreateFailure(0, pdData.ClusterID, pdi.Name)
+		r.Recorder.Eventf(pdService, cd, corev1.EventTypeWarning, reasonServiceRecreated, "RecreateService",
+			"PagerDuty service %s was deleted in PagerDuty, recreated it as %s", oldServiceID, pdData.ServiceID)
+		// the new service has the settings of the PDI
+		recordErr = r.recordCreatedService(ctx, pdData, cd.Namespace, pdServiceName)
+	case !pd.HasIntegration(service, pdData.IntegrationID):
+		oldIntegrationID := pdData.IntegrationID
+		r.reqLogger.Info("PD integration not found, recreating it", "ClusterDeployment.Namespace", cd.Namespace, "ServiceID", pdData.ServiceID, "IntegrationID", oldIntegrationID)
+		if err := pdclient.CreateIntegration(ctx, pdData); err != nil {
+			return false, err
+		}
+		r.Recorder.Eventf(pdService, cd, corev1.EventTypeWarning, reasonIntegrationRecreated, "RecreateIntegration",
+			"PagerDuty integration %s of service %s was deleted in PagerDuty, recreated it as %s", oldIntegrationID, pdData.ServiceID, pdData.IntegrationID)
+		recordErr = pdData.SetClusterConfig(ctx, r.Client, cd.Namespace, pdServiceName)
+	default:
+		return false, nil
+	}
+
+	if recordErr != nil {
+		r.reqLogger.Error(recordErr, "Error updating PagerDuty cluster config", "Name", pdServiceName)
+		return true, recordErr
+	}
+
+	pdIntegrationKey, err := pdclient.GetIntegrationKey(ctx, pdData)
+	if err != nil {
+		return true, err
+	}
+
+	return true, r.ensureSecretAndSyncSet(ctx, pdi, cd, secretName, pdIntegrationKey)
+}
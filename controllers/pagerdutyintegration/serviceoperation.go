@@ -0,0 +1,150 @@
+// Copyright 2019 RedHat
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pagerdutyintegration
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	pagerdutyv1alpha1 "github.com/openshift/pagerduty-operator/api/v1alpha1"
+	pd "github.com/openshift/pagerduty-operator/pkg/pagerduty"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	// incidentResolutionPollInterval is how often the incidents of a PD service that is
+	// being deleted or disabled are checked
+	incidentResolutionPollInterval = 15 * time.Second
+
+	// incidentResolutionTimeout is how long PD gets to resolve the incidents before
+	// their alerts are resolved again
+	incidentResolutionTimeout = 5 * time.Minute
+)
+
+// operationInProgressError is returned while a PD service deletion or disablement waits
+// for PD to resolve the incidents of the service, or while a handoff waits for the new
+// PagerDutyIntegration. The ClusterDeployment isn't failing,
+// it only has to be reconciled again after requeueAfter.
+type operationInProgressError struct {
+	serviceID    string
+	operation    *pagerdutyv1alpha1.ServiceOperation
+	requeueAfter time.Duration
+}
+
+func (e *operationInProgressError) Error() string {
+	if e.operation.Type == pagerdutyv1alpha1.ServiceOperationHandoff {
+		return fmt.Sprintf("%s of PD service %s is in phase %s", e.operation.Type, e.serviceID, e.operation.Phase)
+	}
+	return fmt.Sprintf("%s of PD service %s is in phase %s with %d unresolved incidents",
+		e.operation.Type, e.serviceID, e.operation.Phase, e.operation.UnresolvedIncidents)
+}
+
+// asOperationInProgress returns the *operationInProgressError wrapped by err, if any
+func asOperationInProgress(err error) (*operationInProgressError, bool) {
+	var inProgress *operationInProgressError
+	return inProgress, errors.As(err, &inProgress)
+}
+
+// advanceServiceOperation moves the deletion or disablement of the PD service as far as
+// possible without waiting: the pending incidents are resolved, then the service is
+// deleted or disabled once PD resolved them. The progress is recorded in the status of
+// pdService, so legacy ConfigMaps have to be migrated before an operation is started.
+// It returns nil once the operation is done, or an *operationInProgressError while PD is
+// still resolving incidents.
+func (r *ClusterDeploymentReconciler) advanceServiceOperation(ctx context.Context, pdclient pd.Client, pdService *pagerdutyv1alpha1.PagerDutyService, pdData *pd.Data, opType pagerdutyv1alpha1.ServiceOperationType) error {
+	if pdService == nil {
+		// without it, every requeue would start over resolving the incidents
+		return fmt.Errorf("no PagerDutyService to record the %s of PD service %s in", opType, pdData.ServiceID)
+	}
+
+	var op *pagerdutyv1alpha1.ServiceOperation
+	if pdService.Status.Operation != nil && pdService.Status.Operation.Type == opType {
+		op = pdService.Status.Operation.DeepCopy()
+	} else {
+		op = &pagerdutyv1alpha1.ServiceOperation{
+			Type:      opType,
+			Phase:     pagerdutyv1alpha1.ServiceOperationResolvingIncidents,
+			StartTime: metav1.Now(),
+		}
+	}
+
+	summary, finalPhase := pd.AlertResolvedSummaryDeleted, pagerdutyv1alpha1.ServiceOperationDeleting
+	if opType == pagerdutyv1alpha1.ServiceOperationDisable {
+		summary, finalPhase = pd.AlertResolvedSummaryLimitedSupport, pagerdutyv1alpha1.ServiceOperationDisabling
+	}
+
+	for {
+		switch op.Phase {
+		case pagerdutyv1alpha1.ServiceOperationResolvingIncidents:
+			if err := pdclient.ResolvePendingIncidents(ctx, pdData, summary); err != nil {
+				return fmt.Errorf("unable to resolve pending incidents for service ID %v: %w", pdData.ServiceID, err)
+			}
+			now := metav1.Now()
+			op.LastResolveTime = &now
+			op.Phase = pagerdutyv1alpha1.ServiceOperationAwaitingResolution
+
+		case pagerdutyv1alpha1.ServiceOperationAwaitingResolution:
+			unresolved, err := pdclient.CountUnresolvedIncidents(ctx, pdData)
+			if err != nil {
+				return fmt.Errorf("unable to get unresolved incidents for service ID %v: %w", pdData.ServiceID, err)
+			}
+			op.UnresolvedIncidents = unresolved
+			if unresolved == 0 {
+				op.Phase = finalPhase
+				continue
+			}
+
+			if op.LastResolveTime == nil || time.Since(op.LastResolveTime.Time) > incidentResolutionTimeout {
+				// new alerts may have arrived since, resolve them as well
+				r.reqLogger.Info("PD incidents still unresolved, resolving their alerts again", "ServiceID", pdData.ServiceID, "UnresolvedIncidents", unresolved)
+				op.Phase = pagerdutyv1alpha1.ServiceOperationResolvingIncidents
+				continue
+			}
+
+			if err := r.setServiceOperation(ctx, pdService, op); err != nil {
+				return err
+			}
+			return &operationInProgressError{serviceID: pdData.ServiceID, operation: op, requeueAfter: incidentResolutionPollInterval}
+
+		case pagerdutyv1alpha1.ServiceOperationDeleting:
+			if err := pdclient.DeleteService(ctx, pdData); err != nil && !pd.IsNotFound(err) {
+				return err
+			}
+			op.Phase = pagerdutyv1alpha1.ServiceOperationDone
+
+		case pagerdutyv1alpha1.ServiceOperationDisabling:
+			if err := pdclient.DisableService(ctx, pdData); err != nil {
+				return err
+			}
+			op.Phase = pagerdutyv1alpha1.ServiceOperationDone
+
+		case pagerdutyv1alpha1.ServiceOperationDone:
+			return r.setServiceOperation(ctx, pdService, op)
+
+		default:
+			return fmt.Errorf("unknown phase %q of PD service %s %s", op.Phase, pdData.ServiceID, op.Type)
+		}
+	}
+}
+
+// setServiceOperation records op in the status of pdService, nil clears it
+func (r *ClusterDeploymentReconciler) setServiceOperation(ctx context.Context, pdService *pagerdutyv1alpha1.PagerDutyService, op *pagerdutyv1alpha1.ServiceOperation) error {
+	base := pdService.DeepCopy()
+	pdService.Status.Operation = op
+	return r.Status().Patch(ctx, pdService, client.MergeFrom(base))
+}
@@ -22,16 +22,16 @@ const (
 )
 
 // handleServiceOrchestration enables and applies the service orchestration rule to the PD service if it is enabled in PDI
-func (r *PagerDutyIntegrationReconciler) handleServiceOrchestration(pdclient pd.Client, pdi *pagerdutyv1alpha1.PagerDutyIntegration, cd *hivev1.ClusterDeployment) error {
+func (r *ClusterDeploymentReconciler) handleServiceOrchestration(ctx context.Context, pdclient pd.Client, pdi *pagerdutyv1alpha1.PagerDutyIntegration, cd *hivev1.ClusterDeployment) error {
 	if reflect.ValueOf(pdi.Spec.ServiceOrchestration.RuleConfigConfigMapRef).IsZero() {
 		r.reqLogger.Info("service orchestration is not defined correctly in PagerdutyIntegration, skipping...")
 		return nil
 	}
 
 	var (
-		// clusterConfigmapName is the name of the ConfigMap containing the
-		// SERVICE_ID and INTEGRATION_ID
-		clusterConfigmapName = config.Name(pdi.Spec.ServicePrefix, cd.Name, config.ConfigMapSuffix)
+		// pdServiceName is the name of the PagerDutyService containing the
+		// service ID and integration ID
+		pdServiceName = config.Name(pdi.Spec.ServicePrefix, cd.Name, config.PagerDutyServiceSuffix)
 
 		// orchestrationConfigmapName is the name of the configmap containing the
 		// service orchestration rules
@@ -52,21 +52,13 @@ func (r *PagerDutyIntegrationReconciler) handleServiceOrchestration(pdclient pd.
 	}
 
 	clusterID := utils.GetClusterID(cd, r.IsFedramp)
-	pdData, err := pd.NewData(pdi, clusterID, cd.Spec.BaseDomain, r.IsFedramp)
-	if err != nil {
-		return err
-	}
-
-	clusterConfigMap := &corev1.ConfigMap{}
-
-	err = r.Get(context.TODO(), types.NamespacedName{
-		Namespace: cd.Namespace, Name: clusterConfigmapName}, clusterConfigMap)
+	pdData, err := pd.NewData(pdi, cd, clusterID, r.IsFedramp)
 	if err != nil {
 		return err
 	}
 
 	// load configuration
-	err = pdData.ParseClusterConfig(r.Client, cd.Namespace, clusterConfigmapName)
+	err = pdData.ParseClusterConfig(ctx, r.Client, cd.Namespace, pdServiceName)
 	if err != nil {
 		return err
 	}
@@ -78,17 +70,20 @@ func (r *PagerDutyIntegrationReconciler) handleServiceOrchestration(pdclient pd.
 
 	if !pdData.ServiceOrchestrationEnabled {
 		r.reqLogger.Info("enabling the service orchestration")
-		err = pdclient.ToggleServiceOrchestration(pdData, true)
+		err = pdclient.ToggleServiceOrchestration(ctx, pdData, true)
 		if err != nil {
+			r.recordPagerDutyFailure(pdi, cd, reasonOrchestrationEnableFailed, "EnableOrchestration", "Failed to enable the event orchestration of PagerDuty service "+pdData.ServiceID, err)
 			return err
 		}
+		r.recordPagerDutyEvent(pdi, cd, corev1.EventTypeNormal, reasonOrchestrationEnabled, "EnableOrchestration",
+			"Enabled the event orchestration of PagerDuty service %s", pdData.ServiceID)
 
 		pdData.ServiceOrchestrationEnabled = true
 
-		err = pdData.SetClusterConfig(r.Client, cd.Namespace, clusterConfigmapName)
+		err = pdData.SetClusterConfig(ctx, r.Client, cd.Namespace, pdServiceName)
 		if err != nil {
 			r.reqLogger.Error(err, "Error updating PagerDuty cluster config", "Name",
-				clusterConfigmapName)
+				pdServiceName)
 			return err
 		}
 	}
@@ -130,15 +125,18 @@ func (r *PagerDutyIntegrationReconciler) handleServiceOrchestration(pdclient pd.
 		pdData.ServiceOrchestrationRuleApplied = orchestrationRuleConfigData
 		r.reqLogger.Info(fmt.Sprintf("applying the service orchestration rules from configmap: %s",
 			orchestrationConfigmapName))
-		err = pdclient.ApplyServiceOrchestrationRule(pdData)
+		err = pdclient.ApplyServiceOrchestrationRule(ctx, pdData)
 		if err != nil {
+			r.recordPagerDutyFailure(pdi, cd, reasonOrchestrationRuleApplyFailed, "ApplyOrchestrationRule", "Failed to apply the event orchestration rules to PagerDuty service "+pdData.ServiceID, err)
 			return err
 		}
+		r.recordPagerDutyEvent(pdi, cd, corev1.EventTypeNormal, reasonOrchestrationRuleApplied, "ApplyOrchestrationRule",
+			"Applied the event orchestration rules of ConfigMap %s/%s to PagerDuty service %s", serviceOrchestrationConfigMap.Namespace, serviceOrchestrationConfigMap.Name, pdData.ServiceID)
 
-		err = pdData.SetClusterConfig(r.Client, cd.Namespace, clusterConfigmapName)
+		err = pdData.SetClusterConfig(ctx, r.Client, cd.Namespace, pdServiceName)
 		if err != nil {
 			r.reqLogger.Error(err, "Error updating PagerDuty cluster config", "Name",
-				clusterConfigmapName)
+				pdServiceName)
 			return err
 		}
 	} else {
@@ -0,0 +1,442 @@
+// Copyright 2019 RedHat
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pagerdutyintegration
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	hivev1 "github.com/openshift/hive/apis/hive/v1"
+	pagerdutyv1alpha1 "github.com/openshift/pagerduty-operator/api/v1alpha1"
+	"github.com/openshift/pagerduty-operator/config"
+	pd "github.com/openshift/pagerduty-operator/pkg/pagerduty"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/util/workqueue"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	"sigs.k8s.io/controller-runtime/pkg/source"
+)
+
+const (
+	// quarantineThreshold is the number of consecutive failures after which a
+	// ClusterDeployment is quarantined
+	quarantineThreshold = 5
+
+	// quarantineBaseBackoff is how long a ClusterDeployment stays quarantined after
+	// reaching quarantineThreshold, it doubles with each further failure
+	quarantineBaseBackoff = time.Minute
+
+	// quarantineMaxBackoff caps how long a ClusterDeployment stays quarantined
+	quarantineMaxBackoff = time.Hour
+)
+
+// ClusterDeploymentResults keeps the last reconcile error of each ClusterDeployment
+// per PagerDutyIntegration. The ClusterDeployment controller records them and the
+// PagerDutyIntegration controller reports them in the PagerDutyIntegration status.
+//
+// A ClusterDeployment that fails quarantineThreshold times in a row is quarantined: it
+// is not reconciled against the PagerDutyIntegration again until an exponential
+// backoff expires, or either object changes.
+type ClusterDeploymentResults struct {
+	mu     sync.Mutex
+	errors map[types.NamespacedName]map[types.NamespacedName]clusterDeploymentResult
+
+	// pending holds the PagerDutyIntegrations whose results changed before the
+	// PagerDutyIntegration controller started, queue is the work queue of the started
+	// controller, which their status updates are enqueued to
+	pending map[types.NamespacedName]struct{}
+	queue   workqueue.TypedRateLimitingInterface[reconcile.Request]
+
+	// now returns the current time, it is replaced in tests
+	now func() time.Time
+}
+
+// clusterDeploymentResult is the failure of a ClusterDeployment along with the
+// generations it was recorded at, a change of either lifts the quarantine
+type clusterDeploymentResult struct {
+	pagerdutyv1alpha1.ClusterDeploymentError
+	pdiGeneration int64
+	cdGeneration  int64
+}
+
+// NewClusterDeploymentResults returns an empty ClusterDeploymentResults, to be shared by
+// the PagerDutyIntegration and ClusterDeployment controllers
+func NewClusterDeploymentResults() *ClusterDeploymentResults {
+	return &ClusterDeploymentResults{
+		errors:  map[types.NamespacedName]map[types.NamespacedName]clusterDeploymentResult{},
+		pending: map[types.NamespacedName]struct{}{},
+		now:     time.Now,
+	}
+}
+
+// record stores the outcome of reconciling cd against pdi, a nil err clears a
+// previous failure. It returns how long cd is quarantined for, zero if it isn't.
+// Rate limited errors don't count towards the quarantine, they aren't specific
+// to cd.
+func (c *ClusterDeploymentResults) record(pdi *pagerdutyv1alpha1.PagerDutyIntegration, cd *hivev1.ClusterDeployment, err error) time.Duration {
+	pdiKey := types.NamespacedName{Namespace: pdi.Namespace, Name: pdi.Name}
+	cdKey := types.NamespacedName{Namespace: cd.Namespace, Name: cd.Name}
+
+	c.mu.Lock()
+	previous, failed := c.errors[pdiKey][cdKey]
+	if err == nil {
+		if failed {
+			delete(c.errors[pdiKey], cdKey)
+		}
+		c.mu.Unlock()
+		if failed {
+			c.notify(pdiKey)
+		}
+		return 0
+	}
+
+	result := clusterDeploymentResult{
+		ClusterDeploymentError: pagerdutyv1alpha1.ClusterDeploymentError{
+			Namespace: cd.Namespace,
+			Name:      cd.Name,
+			Message:   err.Error(),
+			Time:      metav1.NewTime(c.now()),
+		},
+		pdiGeneration: pdi.Generation,
+		cdGeneration:  cd.Generation,
+	}
+	if failed && previous.pdiGeneration == pdi.Generation && previous.cdGeneration == cd.Generation {
+		result.ConsecutiveFailures = previous.ConsecutiveFailures
+	}
+	counted := !pd.IsRateLimited(err)
+	if counted {
+		result.ConsecutiveFailures++
+	}
+
+	var quarantine time.Duration
+	if counted && result.ConsecutiveFailures >= quarantineThreshold {
+		quarantine = quarantineBackoff(result.ConsecutiveFailures)
+		until := metav1.NewTime(c.now().Add(quarantine))
+		result.QuarantinedUntil = &until
+	}
+
+	changed := !failed || previous.Message != result.Message || previous.ConsecutiveFailures != result.ConsecutiveFailures
+	if !changed {
+		// nothing new to report
+		result.Time = previous.Time
+	}
+	if c.errors[pdiKey] == nil {
+		c.errors[pdiKey] = map[types.NamespacedName]clusterDeploymentResult{}
+	}
+	c.errors[pdiKey][cdKey] = result
+	c.mu.Unlock()
+
+	if changed {
+		c.notify(pdiKey)
+	}
+	return quarantine
+}
+
+// quarantined returns how long cd remains quarantined for pdi, if it is
+func (c *ClusterDeploymentResults) quarantined(pdi *pagerdutyv1alpha1.PagerDutyIntegration, cd *hivev1.ClusterDeployment) (time.Duration, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	result, ok := c.errors[types.NamespacedName{Namespace: pdi.Namespace, Name: pdi.Name}][types.NamespacedName{Namespace: cd.Namespace, Name: cd.Name}]
+	if !ok || result.QuarantinedUntil == nil {
+		return 0, false
+	}
+	if result.pdiGeneration != pdi.Generation || result.cdGeneration != cd.Generation {
+		// either object changed, which may well have fixed the failure
+		return 0, false
+	}
+
+	remaining := result.QuarantinedUntil.Sub(c.now())
+	return remaining, remaining > 0
+}
+
+// quarantineBackoff returns how long a ClusterDeployment that failed the given number
+// of times in a row is quarantined for
+func quarantineBackoff(consecutiveFailures int32) time.Duration {
+	backoff := quarantineBaseBackoff
+	for i := int32(quarantineThreshold); i < consecutiveFailures && backoff < quarantineMaxBackoff; i++ {
+		backoff *= 2
+	}
+	return min(backoff, quarantineMaxBackoff)
+}
+
+// liftQuarantine clears the failure counts of every ClusterDeployment that failed against
+// the PagerDutyIntegration, for when the cause of their failures may have been fixed, and
+// returns those ClusterDeployments so they can be retried
+func (c *ClusterDeploymentResults) liftQuarantine(pdiKey types.NamespacedName) []types.NamespacedName {
+	var (
+		cdKeys  []types.NamespacedName
+		changed bool
+	)
+
+	c.mu.Lock()
+	for cdKey, result := range c.errors[pdiKey] {
+		cdKeys = append(cdKeys, cdKey)
+		if result.ConsecutiveFailures == 0 && result.QuarantinedUntil == nil {
+			continue
+		}
+		result.ConsecutiveFailures = 0
+		result.QuarantinedUntil = nil
+		c.errors[pdiKey][cdKey] = result
+		changed = true
+	}
+	c.mu.Unlock()
+
+	if changed {
+		c.notify(pdiKey)
+	}
+	sort.Slice(cdKeys, func(i, j int) bool { return cdKeys[i].String() < cdKeys[j].String() })
+	return cdKeys
+}
+
+// forgetClusterDeployment drops the results of a ClusterDeployment that no longer exists
+func (c *ClusterDeploymentResults) forgetClusterDeployment(cdKey types.NamespacedName) {
+	var changed []types.NamespacedName
+
+	c.mu.Lock()
+	for pdiKey, errs := range c.errors {
+		if _, ok := errs[cdKey]; ok {
+			delete(errs, cdKey)
+			changed = append(changed, pdiKey)
+		}
+	}
+	c.mu.Unlock()
+
+	for _, pdiKey := range changed {
+		c.notify(pdiKey)
+	}
+}
+
+// forgetPagerDutyIntegration drops the results of a deleted PagerDutyIntegration
+func (c *ClusterDeploymentResults) forgetPagerDutyIntegration(pdiKey types.NamespacedName) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	delete(c.errors, pdiKey)
+}
+
+// failures returns the errors of the ClusterDeployments that failed to reconcile
+// against pdi, ordered by ClusterDeployment namespace and name
+func (c *ClusterDeploymentResults) failures(pdi *pagerdutyv1alpha1.PagerDutyIntegration) []pagerdutyv1alpha1.ClusterDeploymentError {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	var failures []pagerdutyv1alpha1.ClusterDeploymentError
+	for _, result := range c.errors[types.NamespacedName{Namespace: pdi.Namespace, Name: pdi.Name}] {
+		failures = append(failures, *result.DeepCopy())
+	}
+	sort.Slice(failures, func(i, j int) bool {
+		if failures[i].Namespace != failures[j].Namespace {
+			return failures[i].Namespace < failures[j].Namespace
+		}
+		return failures[i].Name < failures[j].Name
+	})
+	return failures
+}
+
+// notify enqueues the PagerDutyIntegration for a status update. The work queue and the
+// pending set both hold a PagerDutyIntegration at most once, so notifications never
+// block and are never dropped, however many arrive.
+func (c *ClusterDeploymentResults) notify(pdiKey types.NamespacedName) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.queue == nil {
+		c.pending[pdiKey] = struct{}{}
+		return
+	}
+	c.queue.Add(reconcile.Request{NamespacedName: pdiKey})
+}
+
+// source returns the source of the PagerDutyIntegration controller for the
+// PagerDutyIntegrations whose results changed. Those notified before the controller
+// started are enqueued when it starts.
+func (c *ClusterDeploymentResults) source() source.Source {
+	return source.Func(func(_ context.Context, queue workqueue.TypedRateLimitingInterface[reconcile.Request]) error {
+		c.mu.Lock()
+		defer c.mu.Unlock()
+
+		c.queue = queue
+		for pdiKey := range c.pending {
+			queue.Add(reconcile.Request{NamespacedName: pdiKey})
+		}
+		clear(c.pending)
+		return nil
+	})
+}
+
+// setSecretLoadFailedStatus reports that the PagerDuty API key could not be loaded.
+// Nothing else can be reconciled in this state, so the counts are left untouched.
+func setSecretLoadFailedStatus(pdi *pagerdutyv1alpha1.PagerDutyIntegration, loadErr error) {
+	reason := pagerdutyv1alpha1.ReasonSecretLoadFailed
+	if errors.Is(loadErr, errPagerDutyAccountNotFound) {
+		reason = pagerdutyv1alpha1.ReasonAccountNotFound
+	}
+	setAPIKeyUnusableStatus(pdi, reason, fmt.Sprintf("Failed to load PagerDuty API key from %s: %v", apiKeySource(pdi), loadErr))
+}
+
+// setAPIKeyRejectedStatus reports that PagerDuty rejected the API key
+func setAPIKeyRejectedStatus(pdi *pagerdutyv1alpha1.PagerDutyIntegration, validateErr error) {
+	setAPIKeyUnusableStatus(pdi, pagerdutyv1alpha1.ReasonAPIKeyRejected, fmt.Sprintf("PagerDuty rejected the API key from %s: %v", apiKeySource(pdi), validateErr))
+}
+
+// setAPIKeyUnusableStatus sets the SecretLoaded and Ready conditions to False
+func setAPIKeyUnusableStatus(pdi *pagerdutyv1alpha1.PagerDutyIntegration, reason string, message string) {
+	now := metav1.Now()
+	pdi.Status.ObservedGeneration = pdi.Generation
+	pdi.Status.LastReconcileTime = &now
+
+	meta.SetStatusCondition(&pdi.Status.Conditions, metav1.Condition{
+		Type:               pagerdutyv1alpha1.ConditionSecretLoaded,
+		Status:             metav1.ConditionFalse,
+		Reason:             reason,
+		Message:            message,
+		ObservedGeneration: pdi.Generation,
+	})
+	meta.SetStatusCondition(&pdi.Status.Conditions, metav1.Condition{
+		Type:               pagerdutyv1alpha1.ConditionReady,
+		Status:             metav1.ConditionFalse,
+		Reason:             reason,
+		Message:            message,
+		ObservedGeneration: pdi.Generation,
+	})
+}
+
+// setReconciledStatus sets the conditions and counts of the PagerDutyIntegration
+// from the last results of its ClusterDeployments. templateErr is set when the service
+// templates don't parse, which degrades the PagerDutyIntegration as a whole.
+func (r *PagerDutyIntegrationReconciler) setReconciledStatus(ctx context.Context, pdi *pagerdutyv1alpha1.PagerDutyIntegration, matching *hivev1.ClusterDeploymentList, failures []pagerdutyv1alpha1.ClusterDeploymentError, templateErr error) {
+	now := metav1.Now()
+	pdi.Status.ObservedGeneration = pdi.Generation
+	pdi.Status.LastReconcileTime = &now
+	pdi.Status.MatchedClusterDeployments = int32(len(matching.Items))
+	pdi.Status.ProvisionedClusterDeployments, pdi.Status.LimitedSupportClusterDeployments = r.countProvisioned(ctx, pdi, matching)
+	pdi.Status.FailedClusterDeployments = int32(len(failures))
+	pdi.Status.QuarantinedClusterDeployments = countQuarantined(failures)
+	pdi.Status.RecentErrors = failures[:min(len(failures), pagerdutyv1alpha1.MaxRecentErrors)]
+
+	meta.SetStatusCondition(&pdi.Status.Conditions, metav1.Condition{
+		Type:               pagerdutyv1alpha1.ConditionSecretLoaded,
+		Status:             metav1.ConditionTrue,
+		Reason:             pagerdutyv1alpha1.ReasonSecretLoaded,
+		Message:            "PagerDuty API key loaded",
+		ObservedGeneration: pdi.Generation,
+	})
+
+	if templateErr != nil {
+		meta.SetStatusCondition(&pdi.Status.Conditions, metav1.Condition{
+			Type:               pagerdutyv1alpha1.ConditionDegraded,
+			Status:             metav1.ConditionTrue,
+			Reason:             pagerdutyv1alpha1.ReasonInvalidServiceTemplate,
+			Message:            templateErr.Error(),
+			ObservedGeneration: pdi.Generation,
+		})
+		meta.SetStatusCondition(&pdi.Status.Conditions, metav1.Condition{
+			Type:               pagerdutyv1alpha1.ConditionReady,
+			Status:             metav1.ConditionFalse,
+			Reason:             pagerdutyv1alpha1.ReasonInvalidServiceTemplate,
+			Message:            templateErr.Error(),
+			ObservedGeneration: pdi.Generation,
+		})
+		return
+	}
+
+	if len(failures) > 0 {
+		message := fmt.Sprintf("%d ClusterDeployment(s) failed to reconcile", len(failures))
+		if pdi.Status.QuarantinedClusterDeployments > 0 {
+			message += fmt.Sprintf(", %d quarantined", pdi.Status.QuarantinedClusterDeployments)
+		}
+		meta.SetStatusCondition(&pdi.Status.Conditions, metav1.Condition{
+			Type:               pagerdutyv1alpha1.ConditionDegraded,
+			Status:             metav1.ConditionTrue,
+			Reason:             pagerdutyv1alpha1.ReasonClusterDeploymentErrors,
+			Message:            message,
+			ObservedGeneration: pdi.Generation,
+		})
+		meta.SetStatusCondition(&pdi.Status.Conditions, metav1.Condition{
+			Type:               pagerdutyv1alpha1.ConditionReady,
+			Status:             metav1.ConditionFalse,
+			Reason:             pagerdutyv1alpha1.ReasonClusterDeploymentErrors,
+			Message:            message,
+			ObservedGeneration: pdi.Generation,
+		})
+		return
+	}
+
+	meta.SetStatusCondition(&pdi.Status.Conditions, metav1.Condition{
+		Type:               pagerdutyv1alpha1.ConditionDegraded,
+		Status:             metav1.ConditionFalse,
+		Reason:             pagerdutyv1alpha1.ReasonAsExpected,
+		Message:            "All matching ClusterDeployments reconciled",
+		ObservedGeneration: pdi.Generation,
+	})
+	meta.SetStatusCondition(&pdi.Status.Conditions, metav1.Condition{
+		Type:               pagerdutyv1alpha1.ConditionReady,
+		Status:             metav1.ConditionTrue,
+		Reason:             pagerdutyv1alpha1.ReasonReconcileSucceeded,
+		Message:            "All matching ClusterDeployments reconciled",
+		ObservedGeneration: pdi.Generation,
+	})
+}
+
+// countQuarantined returns how many of the failures are quarantined
+func countQuarantined(failures []pagerdutyv1alpha1.ClusterDeploymentError) int32 {
+	var quarantined int32
+	for _, failure := range failures {
+		if failure.QuarantinedUntil != nil {
+			quarantined++
+		}
+	}
+	return quarantined
+}
+
+// countProvisioned returns how many of the matching ClusterDeployments have a
+// PagerDuty service recorded in their PagerDutyService, and how many of those are
+// in limited support
+func (r *PagerDutyIntegrationReconciler) countProvisioned(ctx context.Context, pdi *pagerdutyv1alpha1.PagerDutyIntegration, matching *hivev1.ClusterDeploymentList) (provisioned int32, limitedSupport int32) {
+	for _, cd := range matching.Items {
+		if cd.DeletionTimestamp != nil {
+			continue
+		}
+
+		pdData := &pd.Data{}
+		pdServiceName := config.Name(pdi.Spec.ServicePrefix, cd.Name, config.PagerDutyServiceSuffix)
+		if err := pdData.ParseClusterConfig(ctx, r.Client, cd.Namespace, pdServiceName); err != nil || pdData.ServiceID == "" {
+			continue
+		}
+
+		provisioned++
+		if pdData.LimitedSupport {
+			limitedSupport++
+		}
+	}
+
+	return provisioned, limitedSupport
+}
+
+// updateStatus writes the status of the PagerDutyIntegration, patching it
+// against base so concurrent changes to other fields do not conflict
+func (r *PagerDutyIntegrationReconciler) updateStatus(ctx context.Context, pdi *pagerdutyv1alpha1.PagerDutyIntegration, base *pagerdutyv1alpha1.PagerDutyIntegration) error {
+	if err := r.Status().Patch(ctx, pdi, client.MergeFrom(base)); err != nil {
+		r.reqLogger.Error(err, "Failed to update PagerDutyIntegration status")
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,20 @@
+#!/usr/bin/env bash
+# Generates the ClusterRole of the operator from the kubebuilder RBAC markers in the Go
+# sources, for every way the operator is deployed. Run through `go generate`, so
+# `make generate` keeps the role files in sync with the code.
+set -euo pipefail
+
+cd "$(git rev-parse --show-toplevel)"
+
+CONTROLLER_GEN=${CONTROLLER_GEN:-controller-gen}
+
+"$CONTROLLER_GEN" rbac:roleName=pagerduty-operator paths=./... output:rbac:stdout > deploy/role.yaml
+cp deploy/role.yaml manifests/02-role.yaml
+
+sed '/^  name: pagerduty-operator$/a\
+  annotations:\
+    package-operator.run/phase: rbac\
+    package-operator.run/collision-protection: IfNoController' deploy/role.yaml > deploy_pko/ClusterRole-pagerduty-operator.yaml
+for fixture in deploy_pko/.test-fixtures/*/; do
+	cp deploy_pko/ClusterRole-pagerduty-operator.yaml "$fixture"
+done
@@ -22,23 +22,24 @@ import (
 	"fmt"
 	"os"
 	"runtime"
+	"time"
 
 	hivev1 "github.com/openshift/hive/apis/hive/v1"
+	hiveintv1alpha1 "github.com/openshift/hive/apis/hiveinternal/v1alpha1"
 	"github.com/openshift/operator-custom-metrics/pkg/metrics"
 	pagerdutyv1alpha1 "github.com/openshift/pagerduty-operator/api/v1alpha1"
 	operatorconfig "github.com/openshift/pagerduty-operator/config"
 	"github.com/openshift/pagerduty-operator/controllers/pagerdutyintegration"
 	"github.com/openshift/pagerduty-operator/pkg/localmetrics"
+	pd "github.com/openshift/pagerduty-operator/pkg/pagerduty"
 	"github.com/prometheus/client_golang/prometheus"
 	"go.uber.org/zap/zapcore"
-	corev1 "k8s.io/api/core/v1"
 
 	// Import all Kubernetes client auth plugins (e.g. Azure, GCP, OIDC, etc.)
 	// to ensure that exec-entrypoint and run can make use of them.
 	_ "k8s.io/client-go/plugin/pkg/client/auth"
 
 	k8sruntime "k8s.io/apimachinery/pkg/runtime"
-	"k8s.io/apimachinery/pkg/types"
 	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
 	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
 	ctrl "sigs.k8s.io/controller-runtime"
@@ -61,10 +62,21 @@ func init() {
 	utilruntime.Must(clientgoscheme.AddToScheme(scheme))
 
 	utilruntime.Must(hivev1.AddToScheme(scheme))
+	utilruntime.Must(hiveintv1alpha1.AddToScheme(scheme))
 	utilruntime.Must(pagerdutyv1alpha1.AddToScheme(scheme))
 	//+kubebuilder:scaffold:scheme
 }
 
+// The ClusterRole files are generated from the RBAC markers of every package
+//go:generate bash hack/generate-rbac.sh
+
+// The metrics Service, ServiceMonitor and Route are created by the operator itself
+//+kubebuilder:rbac:groups="",resources=pods;services;endpoints;persistentvolumeclaims;events,verbs=*
+//+kubebuilder:rbac:groups="",resources=namespaces,verbs=get
+//+kubebuilder:rbac:groups=apps,resources=deployments;daemonsets;replicasets;statefulsets,verbs=*
+//+kubebuilder:rbac:groups=monitoring.coreos.com,resources=servicemonitors,verbs=get;create
+//+kubebuilder:rbac:groups=route.openshift.io,resources=routes,verbs=*
+
 func printVersion() {
 	setupLog.Info(fmt.Sprintf("Go Version: %s", runtime.Version()))
 	setupLog.Info(fmt.Sprintf("Go OS/Arch: %s/%s", runtime.GOOS, runtime.GOARCH))
@@ -73,10 +85,23 @@ func printVersion() {
 func main() {
 	var enableLeaderElection bool
 	var probeAddr string
+	var driftCheckInterval time.Duration
+	var maxConcurrentReconciles int
+	var heartbeatAPIURL string
+	var heartbeatAccount string
 	flag.StringVar(&probeAddr, "health-probe-bind-address", ":8081", "The address the probe endpoint binds to.")
 	flag.BoolVar(&enableLeaderElection, "leader-elect", false,
 		"Enable leader election for controller manager. "+
 			"Enabling this will ensure there is only one active controller manager.")
+	flag.DurationVar(&driftCheckInterval, "drift-check-interval", time.Hour,
+		"How often each managed PagerDuty service is compared with its desired settings. 0 disables drift checks, "+
+			"along with the recreation of PagerDuty services deleted while the Secret with their integration key exists.")
+	flag.IntVar(&maxConcurrentReconciles, "max-concurrent-reconciles", 5,
+		"How many ClusterDeployments are reconciled in parallel. Their PagerDuty API calls share the rate limit of each API key.")
+	flag.StringVar(&heartbeatAPIURL, "heartbeat-api-url", pd.USEndpoint.APIURL,
+		"Base URL of the PagerDuty REST API checked by the pagerduty_heartbeat metric, with the credentials of the default Secret.")
+	flag.StringVar(&heartbeatAccount, "heartbeat-account", "",
+		"PagerDutyAccount whose credentials and endpoint are checked by the pagerduty_heartbeat metric, instead of the default Secret and --heartbeat-api-url.")
 	opts := zap.Options{
 		Development: false,
 		TimeEncoder: zapcore.RFC3339TimeEncoder,
@@ -113,12 +138,48 @@ func main() {
 		os.Exit(1)
 	}
 
+	if err = pagerdutyintegration.SetupIndexes(context.Background(), mgr.GetFieldIndexer()); err != nil {
+		setupLog.Error(err, "unable to set up field indexes")
+		os.Exit(1)
+	}
+
+	// results carries the outcome of each ClusterDeployment reconcile to the PagerDutyIntegration status
+	results := pagerdutyintegration.NewClusterDeploymentResults()
+	// keyValidator remembers which PagerDuty API keys PD accepted, for both controllers
+	keyValidator := pd.NewAPIKeyValidator()
+	// selectors caches the parsed ClusterDeployment selectors, for both controllers
+	selectors := pagerdutyintegration.NewSelectorCache()
 	if err = (&pagerdutyintegration.PagerDutyIntegrationReconciler{
+		Client:  mgr.GetClient(),
+		Scheme:  mgr.GetScheme(),
+		Results: results,
+
+		KeyValidator: keyValidator,
+		Selectors:    selectors,
+	}).SetupWithManager(mgr); err != nil {
+		setupLog.Error(err, "unable to create controller", "controller", "PagerDutyIntegration")
+		os.Exit(1)
+	}
+	if err = (&pagerdutyintegration.ClusterDeploymentReconciler{
 		Client:    mgr.GetClient(),
 		Scheme:    mgr.GetScheme(),
 		IsFedramp: fedrampEnabled,
+		Recorder:  mgr.GetEventRecorder("pagerduty-operator"),
+		Results:   results,
+
+		DriftCheckInterval:      driftCheckInterval,
+		MaxConcurrentReconciles: maxConcurrentReconciles,
+		KeyValidator:            keyValidator,
+		Selectors:               selectors,
 	}).SetupWithManager(mgr); err != nil {
-		setupLog.Error(err, "unable to create controller", "controller", "PagerDutyIntegration")
+		setupLog.Error(err, "unable to create controller", "controller", "ClusterDeployment")
+		os.Exit(1)
+	}
+	if err = (&pagerdutyintegration.PagerDutyAccountReconciler{
+		Client: mgr.GetClient(),
+		Scheme: mgr.GetScheme(),
+	}).SetupWithManager(mgr); err != nil {
+		setupLog.Error(err, "unable to create controller", "controller", "PagerDutyAccount")
 		os.Exit(1)
 	}
 	//+kubebuilder:scaffold:builder
@@ -147,16 +208,14 @@ func main() {
 
 	// Add runnable custom metrics
 	err = mgr.Add(manager.RunnableFunc(func(ctx context.Context) error {
-		client := mgr.GetClient()
-		pdAPISecret := &corev1.Secret{}
-		err = client.Get(context.TODO(), types.NamespacedName{Namespace: operatorconfig.OperatorNamespace, Name: operatorconfig.PagerDutyAPISecretName}, pdAPISecret)
+		account, err := pagerdutyintegration.LoadHeartbeatAccount(ctx, mgr.GetClient(), heartbeatAccount, heartbeatAPIURL)
 		if err != nil {
-			setupLog.Error(err, "Failed to get secret")
+			setupLog.Error(err, "Failed to load PagerDuty credentials for the heartbeat")
 			return err
 		}
-		var APIKey = string(pdAPISecret.Data[operatorconfig.PagerDutyAPISecretKey])
+		pdClient := pd.NewClient(account, "heartbeat")
 		timer := prometheus.NewTimer(localmetrics.MetricPagerDutyHeartbeat)
-		localmetrics.UpdateAPIMetrics(APIKey, timer)
+		localmetrics.UpdateAPIMetrics(func() error { return pdClient.ValidateAPIKey(ctx) }, timer)
 
 		return nil
 	}))
@@ -0,0 +1,39 @@
+// Copyright 2019 RedHat
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package kube
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	pagerdutyv1alpha1 "github.com/openshift/pagerduty-operator/api/v1alpha1"
+)
+
+// GeneratePagerDutyService returns a PagerDutyService for the given ClusterDeployment and
+// PagerDutyIntegration that can be created with the oc client. The PagerDuty specific
+// fields of the spec are left for the caller to fill in.
+func GeneratePagerDutyService(namespace string, name string, clusterDeploymentName string, pdi *pagerdutyv1alpha1.PagerDutyIntegration) *pagerdutyv1alpha1.PagerDutyService {
+	pdService := &pagerdutyv1alpha1.PagerDutyService{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: namespace,
+		},
+	}
+	pdService.Spec.ClusterDeploymentRef.Name = clusterDeploymentName
+	pdService.Spec.PagerDutyIntegrationRef = pagerdutyv1alpha1.PagerDutyIntegrationReference{
+		Name:      pdi.Name,
+		Namespace: pdi.Namespace,
+	}
+	return pdService
+}
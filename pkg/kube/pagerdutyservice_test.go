@@ -0,0 +1,29 @@
+package kube
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	pagerdutyv1alpha1 "github.com/openshift/pagerduty-operator/api/v1alpha1"
+)
+
+func TestGeneratePagerDutyService(t *testing.T) {
+	pdi := &pagerdutyv1alpha1.PagerDutyIntegration{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "osd",
+			Namespace: "pagerduty-operator",
+		},
+	}
+
+	pdService := GeneratePagerDutyService("hive-ns", "osd-test-cluster-pd-service", "test-cluster", pdi)
+
+	assert.Equal(t, "osd-test-cluster-pd-service", pdService.Name)
+	assert.Equal(t, "hive-ns", pdService.Namespace)
+	assert.Equal(t, "test-cluster", pdService.Spec.ClusterDeploymentRef.Name)
+	assert.Equal(t, "osd", pdService.Spec.PagerDutyIntegrationRef.Name)
+	assert.Equal(t, "pagerduty-operator", pdService.Spec.PagerDutyIntegrationRef.Namespace)
+	assert.Empty(t, pdService.Spec.ServiceID)
+	assert.Empty(t, pdService.Spec.IntegrationID)
+}
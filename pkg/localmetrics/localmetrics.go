@@ -15,7 +15,6 @@
 package localmetrics
 
 import (
-	"fmt"
 	"net/http"
 	neturl "net/url"
 	"strings"
@@ -29,7 +28,6 @@ import (
 var log = logf.Log.WithName("localmetrics")
 
 const (
-	apiEndpoint     = "https://api.pagerduty.com/users"
 	operatorName    = "pagerduty-operator"
 	pagerdutyDomain = "pagerduty.com"
 )
@@ -82,6 +80,42 @@ var (
 		ConstLabels: prometheus.Labels{"name": operatorName},
 	}, []string{"pagerdutyintegration_name"})
 
+	MetricPagerDutyServiceDrift = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name:        "pagerduty_service_drift_total",
+		Help:        "Number of times a setting of a managed PagerDuty service was found to differ from its desired value",
+		ConstLabels: prometheus.Labels{"name": operatorName},
+	}, []string{"pagerdutyintegration_name", "field"})
+
+	MetricPagerDutyReconcileErrors = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name:        "pagerduty_reconcile_errors_total",
+		Help:        "Number of ClusterDeployment reconcile errors, broken down by PagerDuty API error class (Other for any other error)",
+		ConstLabels: prometheus.Labels{"name": operatorName},
+	}, []string{"pagerdutyintegration_name", "reason"})
+
+	MetricPagerDutyQuarantinedClusterDeployments = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name:        "pagerduty_quarantined_clusterdeployments",
+		Help:        "Number of ClusterDeployments that are not reconciled until their backoff expires because they failed too many times in a row",
+		ConstLabels: prometheus.Labels{"name": operatorName},
+	}, []string{"pagerdutyintegration_name"})
+
+	MetricFilteredEvents = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name:        "pagerduty_filtered_events_total",
+		Help:        "Number of watch events dropped because they change nothing the controller reads, broken down by controller and kind",
+		ConstLabels: prometheus.Labels{"name": operatorName},
+	}, []string{"controller", "kind"})
+
+	MetricPagerDutyAPIThrottled = prometheus.NewCounter(prometheus.CounterOpts{
+		Name:        "pagerduty_api_requests_throttled_total",
+		Help:        "Number of PagerDuty API requests that were delayed to stay within the rate limit",
+		ConstLabels: prometheus.Labels{"name": operatorName},
+	})
+
+	MetricPagerDutyAPIRetried = prometheus.NewCounter(prometheus.CounterOpts{
+		Name:        "pagerduty_api_requests_retried_total",
+		Help:        "Number of PagerDuty API requests that were retried after being rate limited",
+		ConstLabels: prometheus.Labels{"name": operatorName},
+	})
+
 	MetricsList = []prometheus.Collector{
 		MetricPagerDutyCreateFailure,
 		MetricPagerDutyDeleteFailure,
@@ -90,14 +124,20 @@ var (
 		ReconcileDuration,
 		MetricPagerDutyIntegrationSecretLoaded,
 		MetricPagerDutyServiceOrchestrationFailure,
+		MetricPagerDutyServiceDrift,
+		MetricPagerDutyReconcileErrors,
+		MetricPagerDutyQuarantinedClusterDeployments,
+		MetricFilteredEvents,
+		MetricPagerDutyAPIThrottled,
+		MetricPagerDutyAPIRetried,
 	}
 )
 
 // UpdateAPIMetrics updates all API endpoint metrics every 5 minutes
-func UpdateAPIMetrics(APIKey string, timer *prometheus.Timer) {
+func UpdateAPIMetrics(check func() error, timer *prometheus.Timer) {
 	d := time.Tick(5 * time.Minute)
 	for range d {
-		UpdateMetricPagerDutyHeartbeat(APIKey, timer)
+		UpdateMetricPagerDutyHeartbeat(check, timer)
 	}
 
 }
@@ -136,6 +176,56 @@ func UpdateMetricPagerDutyServiceOrchestrationFailure(v int, pdiName string) {
 	}).Set(float64(v))
 }
 
+// AddMetricPagerDutyServiceDrift counts a drifted setting of a PD service
+func AddMetricPagerDutyServiceDrift(pdiName string, field string) {
+	MetricPagerDutyServiceDrift.With(prometheus.Labels{
+		"pagerdutyintegration_name": pdiName,
+		"field":                     field,
+	}).Inc()
+}
+
+// AddMetricPagerDutyReconcileError counts a reconcile error of the given class, e.g. "RateLimited"
+func AddMetricPagerDutyReconcileError(pdiName string, reason string) {
+	MetricPagerDutyReconcileErrors.With(prometheus.Labels{
+		"pagerdutyintegration_name": pdiName,
+		"reason":                    reason,
+	}).Inc()
+}
+
+// UpdateMetricPagerDutyQuarantinedClusterDeployments sets the number of quarantined
+// ClusterDeployments of a PagerDutyIntegration
+func UpdateMetricPagerDutyQuarantinedClusterDeployments(count int32, pdiName string) {
+	MetricPagerDutyQuarantinedClusterDeployments.With(prometheus.Labels{
+		"pagerdutyintegration_name": pdiName,
+	}).Set(float64(count))
+}
+
+// DeleteMetricPagerDutyQuarantinedClusterDeployments deletes the metric for the
+// PagerDutyIntegration name provided, e.g. when it is being deleted
+func DeleteMetricPagerDutyQuarantinedClusterDeployments(pdiName string) bool {
+	return MetricPagerDutyQuarantinedClusterDeployments.Delete(
+		prometheus.Labels{"pagerdutyintegration_name": pdiName},
+	)
+}
+
+// AddMetricFilteredEvent counts a watch event of the given kind dropped by a predicate of controller
+func AddMetricFilteredEvent(controller string, kind string) {
+	MetricFilteredEvents.With(prometheus.Labels{
+		"controller": controller,
+		"kind":       kind,
+	}).Inc()
+}
+
+// AddMetricPagerDutyAPIThrottled counts a PD API request delayed by the rate limiter
+func AddMetricPagerDutyAPIThrottled() {
+	MetricPagerDutyAPIThrottled.Inc()
+}
+
+// AddMetricPagerDutyAPIRetried counts a PD API request retried after a 429 response
+func AddMetricPagerDutyAPIRetried() {
+	MetricPagerDutyAPIRetried.Inc()
+}
+
 // UpdateMetricPagerDutyDeleteFailure updates gauge to 1 when deletion fails
 func UpdateMetricPagerDutyDeleteFailure(x int, cd string, pdiName string) {
 	MetricPagerDutyDeleteFailure.With(prometheus.Labels{
@@ -149,29 +239,18 @@ func SetReconcileDuration(controller string, duration float64) {
 	ReconcileDuration.WithLabelValues(controller).Observe(duration)
 }
 
-// UpdateMetricPagerDutyHeartbeat curls the PD API, updates the gauge to 1
-// when successful.
-func UpdateMetricPagerDutyHeartbeat(APIKey string, timer *prometheus.Timer) {
+// UpdateMetricPagerDutyHeartbeat makes the authenticated call check to the PD API, updates
+// the gauge to 1 when successful.
+func UpdateMetricPagerDutyHeartbeat(check func() error, timer *prometheus.Timer) {
 	metricLogger := log.WithValues("Namespace", "pagerduty-operator")
 	metricLogger.Info("Metrics for PD API")
 
-	// if there is an api key make an authenticated called
-	if APIKey != "" {
-		req, _ := http.NewRequest("GET", apiEndpoint, nil)
-		req.Header.Set("Accept", "application/vnd.pagerduty+json;version=2")
-		req.Header.Set("Content-Type", "application/json")
-		req.Header.Set("Authorization", fmt.Sprintf("Token token=%s", APIKey))
-		resp, err := http.DefaultClient.Do(req)
-
-		if err != nil {
-			metricLogger.Error(err, "Failed to reach api when authenticated")
-			MetricPagerDutyHeartbeat.Observe(
-				float64(timer.ObserveDuration().Seconds()))
-
-			return
-		}
-		defer resp.Body.Close()
+	if err := check(); err != nil {
+		metricLogger.Error(err, "Failed to reach api when authenticated")
+		MetricPagerDutyHeartbeat.Observe(
+			float64(timer.ObserveDuration().Seconds()))
 
+		return
 	}
 	MetricPagerDutyHeartbeat.Observe(float64(0))
 }
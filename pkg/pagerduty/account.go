@@ -0,0 +1,68 @@
+// Copyright 2019 RedHat
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pagerduty
+
+import (
+	"crypto/sha256"
+	"sync"
+)
+
+// Account is what a Client needs to talk to a PagerDuty account
+type Account struct {
+	// APIKey is the REST API key of the account, unless OAuth is set
+	APIKey string
+	// OAuth are the client credentials of a scoped OAuth app, used instead of APIKey
+	OAuth    *OAuthCredentials
+	Endpoint Endpoint
+	// RequestsPerSecond is the rate limit budget of the API key, the default when zero
+	RequestsPerSecond int
+}
+
+// credentials returns what authenticates the account: the API key, or the OAuth client
+// credentials along with the scope they are exchanged for
+func (a Account) credentials() string {
+	if a.OAuth != nil {
+		return "oauth\x00" + a.OAuth.ClientID + "\x00" + a.OAuth.ClientSecret + "\x00" + a.OAuth.Scope
+	}
+	return a.APIKey
+}
+
+// SameAccount reports whether a and b reach the same PD API with the same credentials, so
+// the PD services created with one of them can be managed with the other
+func (a Account) SameAccount(b Account) bool {
+	return a.Endpoint.APIURL == b.Endpoint.APIURL && a.credentials() == b.credentials()
+}
+
+// rateLimitKey returns the key of the rate limit budget of the account. The requests of
+// an OAuth app share one budget whatever access token they are sent with.
+func (a Account) rateLimitKey() string {
+	if a.OAuth != nil {
+		return "oauth\x00" + a.OAuth.ClientID
+	}
+	return a.APIKey
+}
+
+// clientKey identifies the Client shared by the callers using the same account
+type clientKey struct {
+	credentials       [sha256.Size]byte
+	endpoint          Endpoint
+	requestsPerSecond int
+	controller        string
+}
+
+var (
+	clientsMu sync.Mutex
+	clients   = map[clientKey]Client{}
+)
@@ -0,0 +1,52 @@
+package pagerduty
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAccount_SameAccount(t *testing.T) {
+	oauth := &OAuthCredentials{ClientID: "client", ClientSecret: "secret", Scope: "as_account-us.example services.write"}
+
+	tests := []struct {
+		name     string
+		a, b     Account
+		expected bool
+	}{
+		{
+			name:     "Same API key",
+			a:        Account{APIKey: "key", Endpoint: USEndpoint},
+			b:        Account{APIKey: "key", Endpoint: USEndpoint, RequestsPerSecond: 5},
+			expected: true,
+		},
+		{
+			name: "Other API key",
+			a:    Account{APIKey: "key", Endpoint: USEndpoint},
+			b:    Account{APIKey: "other", Endpoint: USEndpoint},
+		},
+		{
+			name: "Other region",
+			a:    Account{APIKey: "key", Endpoint: USEndpoint},
+			b:    Account{APIKey: "key", Endpoint: EUEndpoint},
+		},
+		{
+			name:     "Same OAuth app",
+			a:        Account{OAuth: oauth, Endpoint: USEndpoint},
+			b:        Account{OAuth: &OAuthCredentials{ClientID: "client", ClientSecret: "secret", Scope: oauth.Scope}, Endpoint: USEndpoint},
+			expected: true,
+		},
+		{
+			name: "OAuth app and API key",
+			a:    Account{OAuth: oauth, Endpoint: USEndpoint},
+			b:    Account{APIKey: "key", Endpoint: USEndpoint},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			assert.Equal(t, test.expected, test.a.SameAccount(test.b))
+			assert.Equal(t, test.expected, test.b.SameAccount(test.a))
+		})
+	}
+}
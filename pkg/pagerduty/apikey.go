@@ -0,0 +1,81 @@
+// Copyright 2019 RedHat
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pagerduty
+
+import (
+	"context"
+	"crypto/sha256"
+	"sync"
+	"time"
+)
+
+// rejectedAPIKeyRecheckInterval is how long an API key rejected by PD is reported as
+// invalid before it is checked again
+const rejectedAPIKeyRecheckInterval = 10 * time.Minute
+
+// APIKeyValidator remembers which API keys PD accepted or rejected, so each key is
+// checked with a single cheap API call before it is used rather than on every reconcile
+type APIKeyValidator struct {
+	mu sync.Mutex
+	// results are keyed by the hash of the endpoint and API key
+	results map[[sha256.Size]byte]apiKeyCheck
+
+	// now returns the current time, it is replaced in tests
+	now func() time.Time
+}
+
+// apiKeyCheck is the outcome of validating an API key
+type apiKeyCheck struct {
+	err       error
+	checkedAt time.Time
+}
+
+// NewAPIKeyValidator returns an APIKeyValidator that hasn't checked any key yet
+func NewAPIKeyValidator() *APIKeyValidator {
+	return &APIKeyValidator{
+		results: map[[sha256.Size]byte]apiKeyCheck{},
+		now:     time.Now,
+	}
+}
+
+// Validate returns nil if PD accepts the API key or OAuth credentials of account, checking
+// them with client the first time they are seen. Rejected keys are checked again after
+// rejectedAPIKeyRecheckInterval, other errors aren't remembered. A nil APIKeyValidator
+// checks the key on every call.
+func (v *APIKeyValidator) Validate(ctx context.Context, client Client, account Account) error {
+	if v == nil {
+		return client.ValidateAPIKey(ctx)
+	}
+
+	// a key of one service region is unknown to the others
+	key := sha256.Sum256([]byte(account.Endpoint.APIURL + "\x00" + account.credentials()))
+	v.mu.Lock()
+	result, ok := v.results[key]
+	v.mu.Unlock()
+	if ok && (result.err == nil || v.now().Sub(result.checkedAt) < rejectedAPIKeyRecheckInterval) {
+		return result.err
+	}
+
+	err := client.ValidateAPIKey(ctx)
+	if err != nil && !IsUnauthorized(err) {
+		// e.g. rate limited or PD unavailable, that doesn't tell anything about the key
+		return err
+	}
+
+	v.mu.Lock()
+	v.results[key] = apiKeyCheck{err: err, checkedAt: v.now()}
+	v.mu.Unlock()
+	return err
+}
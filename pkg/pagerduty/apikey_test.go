@@ -0,0 +1,77 @@
+package pagerduty
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+)
+
+func TestAPIKeyValidator(t *testing.T) {
+	tests := []struct {
+		name string
+		// results are returned by successive ValidateAPIKey calls, each is expected once
+		results []error
+		// elapsed is how long passes between Validate calls
+		elapsed    time.Duration
+		expectErrs []bool
+	}{
+		{
+			name:       "Accepted key is checked once",
+			results:    []error{nil},
+			expectErrs: []bool{false, false, false},
+		},
+		{
+			name:       "Rejected key is remembered",
+			results:    []error{ErrUnauthorized},
+			elapsed:    time.Minute,
+			expectErrs: []bool{true, true, true},
+		},
+		{
+			name:       "Rejected key is checked again after a while",
+			results:    []error{ErrUnauthorized, nil},
+			elapsed:    rejectedAPIKeyRecheckInterval,
+			expectErrs: []bool{true, false, false},
+		},
+		{
+			name:       "Other errors aren't remembered",
+			results:    []error{fmt.Errorf("unable to validate API key: %w", ErrRateLimited), nil},
+			expectErrs: []bool{true, false, false},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			client := NewMockClient(gomock.NewController(t))
+			calls := make([]any, 0, len(test.results))
+			for _, result := range test.results {
+				calls = append(calls, client.EXPECT().ValidateAPIKey(gomock.Any()).Return(result))
+			}
+			gomock.InOrder(calls...)
+
+			now := time.Now()
+			v := NewAPIKeyValidator()
+			v.now = func() time.Time { return now }
+
+			for _, expectErr := range test.expectErrs {
+				err := v.Validate(context.TODO(), client, Account{APIKey: "apiKey", Endpoint: USEndpoint})
+				assert.Equal(t, expectErr, err != nil)
+				now = now.Add(test.elapsed)
+			}
+		})
+	}
+}
+
+func TestAPIKeyValidator_KeysAreIndependent(t *testing.T) {
+	client := NewMockClient(gomock.NewController(t))
+	client.EXPECT().ValidateAPIKey(gomock.Any()).Return(ErrUnauthorized).Times(1)
+	client.EXPECT().ValidateAPIKey(gomock.Any()).Return(nil).Times(1)
+
+	v := NewAPIKeyValidator()
+	assert.Error(t, v.Validate(context.TODO(), client, Account{APIKey: "revoked", Endpoint: USEndpoint}))
+	// a rotated key is checked right away
+	assert.NoError(t, v.Validate(context.TODO(), client, Account{APIKey: "rotated", Endpoint: USEndpoint}))
+}
@@ -0,0 +1,124 @@
+// Copyright 2019 RedHat
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pagerduty
+
+import (
+	"fmt"
+
+	pdApi "github.com/PagerDuty/go-pagerduty"
+)
+
+// Settings of a PD service that are checked for drift
+const (
+	DriftFieldName                = "name"
+	DriftFieldDescription         = "description"
+	DriftFieldEscalationPolicy    = "escalationPolicy"
+	DriftFieldStatus              = "status"
+	DriftFieldIncidentUrgencyRule = "incidentUrgencyRule"
+	DriftFieldResolveTimeout      = "resolveTimeout"
+	DriftFieldAcknowledgeTimeout  = "acknowledgeTimeout"
+	DriftFieldAlertGrouping       = "alertGrouping"
+)
+
+const (
+	serviceStatusActive   = "active"
+	serviceStatusDisabled = "disabled"
+)
+
+// ServiceDrift describes a setting of a PD service that differs from its desired value
+type ServiceDrift struct {
+	Field   string
+	Desired string
+	Actual  string
+}
+
+// DetectServiceDrift compares the live PD service with the desired settings in data
+// and returns the settings that differ. Alert grouping is only compared when it is
+// configured in data.
+func DetectServiceDrift(service *pdApi.Service, data *Data) []ServiceDrift {
+	var drifts []ServiceDrift
+	compare := func(field, desired, actual string) {
+		if desired != actual {
+			drifts = append(drifts, ServiceDrift{Field: field, Desired: desired, Actual: actual})
+		}
+	}
+
+	compare(DriftFieldName, generatePDServiceName(data), service.Name)
+	compare(DriftFieldDescription, generatePDServiceDescription(data), service.Description)
+	compare(DriftFieldEscalationPolicy, data.EscalationPolicyID, service.EscalationPolicy.ID)
+
+	// PD reports active services as "warning" or "critical" while they have open
+	// incidents, so only enabled vs disabled is compared
+	desiredStatus, actualStatus := serviceStatusActive, serviceStatusActive
+	if data.LimitedSupport {
+		desiredStatus = serviceStatusDisabled
+	}
+	if service.Status == serviceStatusDisabled {
+		actualStatus = serviceStatusDisabled
+	}
+	compare(DriftFieldStatus, desiredStatus, actualStatus)
+
+	desiredUrgency := &pdApi.Service{}
+	applyUrgencyRule(desiredUrgency, data)
+	compare(DriftFieldIncidentUrgencyRule, formatUrgencyRule(desiredUrgency), formatUrgencyRule(service))
+	compare(DriftFieldResolveTimeout, fmt.Sprint(data.ResolveTimeout), fmt.Sprint(derefUint(service.AutoResolveTimeout)))
+	compare(DriftFieldAcknowledgeTimeout, fmt.Sprint(data.AcknowledgeTimeOut), fmt.Sprint(derefUint(service.AcknowledgementTimeout)))
+
+	if data.AlertGroupingType != "" {
+		actual := ""
+		if service.AlertGroupingParameters != nil {
+			actual = service.AlertGroupingParameters.Type
+			if service.AlertGroupingParameters.Config != nil {
+				actual = fmt.Sprintf("%s/%d", actual, derefUint(service.AlertGroupingParameters.Config.Timeout))
+			}
+		}
+		compare(DriftFieldAlertGrouping, fmt.Sprintf("%s/%d", data.AlertGroupingType, data.AlertGroupingTimeout), actual)
+	}
+
+	return drifts
+}
+
+// applyDesiredSettings sets every setting checked by DetectServiceDrift to its desired value
+func applyDesiredSettings(service *pdApi.Service, data *Data) {
+	service.Name = generatePDServiceName(data)
+	service.Description = generatePDServiceDescription(data)
+	service.EscalationPolicy.ID = data.EscalationPolicyID
+
+	if data.LimitedSupport {
+		service.Status = serviceStatusDisabled
+	} else if service.Status == serviceStatusDisabled {
+		service.Status = serviceStatusActive
+	}
+
+	applyUrgencyRule(service, data)
+	service.AutoResolveTimeout = &data.ResolveTimeout
+	service.AcknowledgementTimeout = &data.AcknowledgeTimeOut
+
+	if data.AlertGroupingType != "" {
+		service.AlertGroupingParameters = &pdApi.AlertGroupingParameters{
+			Type: data.AlertGroupingType,
+			Config: &pdApi.AlertGroupParamsConfig{
+				Timeout: &data.AlertGroupingTimeout,
+			},
+		}
+	}
+}
+
+func derefUint(v *uint) uint {
+	if v == nil {
+		return 0
+	}
+	return *v
+}
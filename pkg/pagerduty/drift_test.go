@@ -0,0 +1,169 @@
+package pagerduty
+
+import (
+	"testing"
+
+	pdApi "github.com/PagerDuty/go-pagerduty"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDetectServiceDrift(t *testing.T) {
+	desiredData := func() *Data {
+		return &Data{
+			ServicePrefix:        "osd",
+			ClusterID:            "abc123",
+			BaseDomain:           "example.com",
+			EscalationPolicyID:   "EP1",
+			ResolveTimeout:       300,
+			AcknowledgeTimeOut:   0,
+			AlertGroupingType:    "time",
+			AlertGroupingTimeout: 60,
+		}
+	}
+	// desiredService returns a service matching desiredData
+	desiredService := func() *pdApi.Service {
+		service := &pdApi.Service{}
+		applyDesiredSettings(service, desiredData())
+		return service
+	}
+
+	tests := []struct {
+		name           string
+		service        func() *pdApi.Service
+		data           func() *Data
+		expectedFields []string
+	}{
+		{
+			name:           "No drift",
+			service:        desiredService,
+			data:           desiredData,
+			expectedFields: nil,
+		},
+		{
+			name: "Renamed, new escalation policy and urgency rule",
+			service: func() *pdApi.Service {
+				service := desiredService()
+				service.Name = "renamed"
+				service.EscalationPolicy.ID = "EP2"
+				service.IncidentUrgencyRule = &pdApi.IncidentUrgencyRule{Type: "constant", Urgency: "low"}
+				return service
+			},
+			data:           desiredData,
+			expectedFields: []string{DriftFieldName, DriftFieldEscalationPolicy, DriftFieldIncidentUrgencyRule},
+		},
+		{
+			name: "Disabled out of band",
+			service: func() *pdApi.Service {
+				service := desiredService()
+				service.Status = "disabled"
+				return service
+			},
+			data:           desiredData,
+			expectedFields: []string{DriftFieldStatus},
+		},
+		{
+			name: "Enabled while in limited support",
+			service: func() *pdApi.Service {
+				service := desiredService()
+				service.Status = "warning"
+				return service
+			},
+			data: func() *Data {
+				data := desiredData()
+				data.LimitedSupport = true
+				return data
+			},
+			expectedFields: []string{DriftFieldStatus},
+		},
+		{
+			name: "Active service with open incidents is not drift",
+			service: func() *pdApi.Service {
+				service := desiredService()
+				service.Status = "critical"
+				return service
+			},
+			data:           desiredData,
+			expectedFields: nil,
+		},
+		{
+			name: "Timeouts changed, disabled timeout reported as null",
+			service: func() *pdApi.Service {
+				service := desiredService()
+				resolveTimeout := uint(14400)
+				service.AutoResolveTimeout = &resolveTimeout
+				service.AcknowledgementTimeout = nil
+				return service
+			},
+			data:           desiredData,
+			expectedFields: []string{DriftFieldResolveTimeout},
+		},
+		{
+			name: "Alert grouping changed",
+			service: func() *pdApi.Service {
+				service := desiredService()
+				service.AlertGroupingParameters = nil
+				return service
+			},
+			data:           desiredData,
+			expectedFields: []string{DriftFieldAlertGrouping},
+		},
+		{
+			name: "Alert grouping not configured is not compared",
+			service: func() *pdApi.Service {
+				service := desiredService()
+				service.AlertGroupingParameters = nil
+				return service
+			},
+			data: func() *Data {
+				data := desiredData()
+				data.AlertGroupingType = ""
+				return data
+			},
+			expectedFields: nil,
+		},
+		{
+			name: "Support hours changed",
+			service: func() *pdApi.Service {
+				service := &pdApi.Service{}
+				applyDesiredSettings(service, supportHoursData(desiredData()))
+				service.SupportHours.EndTime = "18:00:00"
+				return service
+			},
+			data: func() *Data {
+				return supportHoursData(desiredData())
+			},
+			expectedFields: []string{DriftFieldIncidentUrgencyRule},
+		},
+		{
+			name: "Support hours days in another order are not drift",
+			service: func() *pdApi.Service {
+				service := &pdApi.Service{}
+				applyDesiredSettings(service, supportHoursData(desiredData()))
+				service.SupportHours.DaysOfWeek = []uint{5, 4, 3, 2, 1}
+				return service
+			},
+			data: func() *Data {
+				return supportHoursData(desiredData())
+			},
+			expectedFields: nil,
+		},
+		{
+			name:    "Support hours rule replaced the constant rule",
+			service: desiredService,
+			data: func() *Data {
+				return supportHoursData(desiredData())
+			},
+			expectedFields: []string{DriftFieldIncidentUrgencyRule},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var fields []string
+			for _, drift := range DetectServiceDrift(test.service(), test.data()) {
+				fields = append(fields, drift.Field)
+			}
+			assert.Equal(t, test.expectedFields, fields)
+		})
+	}
+}
@@ -0,0 +1,73 @@
+// Copyright 2019 RedHat
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pagerduty
+
+import (
+	"strings"
+
+	pagerdutyv1alpha1 "github.com/openshift/pagerduty-operator/api/v1alpha1"
+)
+
+// Endpoint is where the PagerDuty REST and Events APIs of an account are served
+type Endpoint struct {
+	APIURL    string
+	EventsURL string
+	// TokenURL is where OAuth client credentials are exchanged for access tokens
+	TokenURL string
+}
+
+var (
+	// USEndpoint serves the accounts of the US service region, it is the default
+	USEndpoint = Endpoint{
+		APIURL:    "https://api.pagerduty.com",
+		EventsURL: "https://events.pagerduty.com",
+		TokenURL:  "https://identity.pagerduty.com/oauth/token",
+	}
+
+	// EUEndpoint serves the accounts of the EU service region
+	EUEndpoint = Endpoint{
+		APIURL:    "https://api.eu.pagerduty.com",
+		EventsURL: "https://events.eu.pagerduty.com",
+		TokenURL:  "https://identity.eu.pagerduty.com/oauth/token",
+	}
+)
+
+// EndpointFor returns the Endpoint selected by a PagerDutyIntegration, USEndpoint if
+// it doesn't select any. Custom URLs override the region, and the Events API and
+// OAuth token exchange are assumed to be served along the REST API when only the
+// latter is set.
+func EndpointFor(spec *pagerdutyv1alpha1.PagerDutyEndpoint) Endpoint {
+	if spec == nil {
+		return USEndpoint
+	}
+
+	endpoint := USEndpoint
+	if spec.Region == pagerdutyv1alpha1.PagerDutyRegionEU {
+		endpoint = EUEndpoint
+	}
+	// the paths of the pdApi.Client requests start with a slash
+	if apiURL := strings.TrimRight(spec.APIURL, "/"); apiURL != "" {
+		endpoint.APIURL = apiURL
+		endpoint.EventsURL = apiURL
+		endpoint.TokenURL = apiURL + "/oauth/token"
+	}
+	if eventsURL := strings.TrimRight(spec.EventsURL, "/"); eventsURL != "" {
+		endpoint.EventsURL = eventsURL
+	}
+	if spec.TokenURL != "" {
+		endpoint.TokenURL = spec.TokenURL
+	}
+	return endpoint
+}
@@ -0,0 +1,91 @@
+package pagerduty
+
+import (
+	"context"
+	"testing"
+
+	pagerdutyv1alpha1 "github.com/openshift/pagerduty-operator/api/v1alpha1"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEndpointFor(t *testing.T) {
+	tests := []struct {
+		name     string
+		spec     *pagerdutyv1alpha1.PagerDutyEndpoint
+		expected Endpoint
+	}{
+		{
+			name:     "No endpoint",
+			expected: USEndpoint,
+		},
+		{
+			name:     "US region",
+			spec:     &pagerdutyv1alpha1.PagerDutyEndpoint{Region: pagerdutyv1alpha1.PagerDutyRegionUS},
+			expected: USEndpoint,
+		},
+		{
+			name:     "EU region",
+			spec:     &pagerdutyv1alpha1.PagerDutyEndpoint{Region: pagerdutyv1alpha1.PagerDutyRegionEU},
+			expected: EUEndpoint,
+		},
+		{
+			name:     "API URL serves events as well",
+			spec:     &pagerdutyv1alpha1.PagerDutyEndpoint{Region: pagerdutyv1alpha1.PagerDutyRegionEU, APIURL: "http://localhost:8080/"},
+			expected: Endpoint{APIURL: "http://localhost:8080", EventsURL: "http://localhost:8080", TokenURL: "http://localhost:8080/oauth/token"},
+		},
+		{
+			name:     "Custom events URL",
+			spec:     &pagerdutyv1alpha1.PagerDutyEndpoint{APIURL: "http://localhost:8080", EventsURL: "http://localhost:8081"},
+			expected: Endpoint{APIURL: "http://localhost:8080", EventsURL: "http://localhost:8081", TokenURL: "http://localhost:8080/oauth/token"},
+		},
+		{
+			name:     "Custom events URL in a region",
+			spec:     &pagerdutyv1alpha1.PagerDutyEndpoint{Region: pagerdutyv1alpha1.PagerDutyRegionEU, EventsURL: "http://localhost:8081"},
+			expected: Endpoint{APIURL: EUEndpoint.APIURL, EventsURL: "http://localhost:8081", TokenURL: EUEndpoint.TokenURL},
+		},
+		{
+			name:     "Custom token URL",
+			spec:     &pagerdutyv1alpha1.PagerDutyEndpoint{APIURL: "http://localhost:8080", TokenURL: "http://localhost:8082/token"},
+			expected: Endpoint{APIURL: "http://localhost:8080", EventsURL: "http://localhost:8080", TokenURL: "http://localhost:8082/token"},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			assert.Equal(t, test.expected, EndpointFor(test.spec))
+		})
+	}
+}
+
+func TestNewClient_Endpoint(t *testing.T) {
+	api := defaultMockApi()
+	defer api.cleanup()
+	events := defaultMockApi()
+	defer events.cleanup()
+
+	client := NewClient(Account{APIKey: "apiKey", Endpoint: Endpoint{APIURL: api.server.URL, EventsURL: events.server.URL}}, "test").(*SvcClient)
+
+	// REST API calls, including the ones sent without the pdApi.Client
+	assert.NoError(t, client.ValidateAPIKey(context.TODO()))
+	assert.NoError(t, client.ToggleServiceOrchestration(context.TODO(), &Data{ServiceID: mockServiceId}, true))
+
+	// events resolve the incidents of the events API only
+	assert.NoError(t, client.resolveAlert(context.TODO(), mockIntegrationKey, "test-alert-key", AlertResolvedSummaryDeleted))
+	for _, incident := range events.State.Incidents {
+		assert.NotEqual(t, "triggered", incident.Status)
+	}
+	triggered := 0
+	for _, incident := range api.State.Incidents {
+		if incident.Status == "triggered" {
+			triggered++
+		}
+	}
+	assert.NotZero(t, triggered)
+}
+
+func TestNewClient_Shared(t *testing.T) {
+	account := Account{APIKey: "apiKey", Endpoint: EUEndpoint}
+	assert.Same(t, NewClient(account, "test"), NewClient(account, "test"))
+	assert.NotSame(t, NewClient(account, "test"), NewClient(Account{APIKey: "apiKey", Endpoint: USEndpoint}, "test"))
+	assert.NotSame(t, NewClient(account, "test"), NewClient(Account{APIKey: "apiKey", Endpoint: EUEndpoint, RequestsPerSecond: 4}, "test"))
+}
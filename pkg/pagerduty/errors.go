@@ -0,0 +1,249 @@
+// Copyright 2019 RedHat
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pagerduty
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	pdApi "github.com/PagerDuty/go-pagerduty"
+)
+
+// Sentinel errors that classify failed PD API calls. Errors returned by the SvcClient
+// wrap one of them whenever the failure could be classified. Use the Is* helpers to
+// check for them, they also recognise errors returned by the pdApi.Client directly.
+var (
+	// ErrNotFound is returned when the requested PD object doesn't exist
+	ErrNotFound = errors.New("pagerduty: not found")
+
+	// ErrConflict is returned when the PD object conflicts with an existing one,
+	// e.g. a service with the same name
+	ErrConflict = errors.New("pagerduty: conflict")
+
+	// ErrRateLimited is returned when the PD API rate limit was hit
+	ErrRateLimited = errors.New("pagerduty: rate limited")
+
+	// ErrUnauthorized is returned when the API key is invalid or lacks permissions
+	ErrUnauthorized = errors.New("pagerduty: unauthorized")
+
+	// ErrTransient is returned for server side and network errors that are worth retrying
+	ErrTransient = errors.New("pagerduty: transient error")
+)
+
+// APIError is a classified PD API error. It wraps the pdApi.APIError, or the raw HTTP
+// error, that caused it, so errors.As can still be used to get to the original error.
+type APIError struct {
+	// Kind is one of the sentinel errors above
+	Kind error
+
+	// StatusCode is the HTTP response status code, zero for network errors
+	StatusCode int
+
+	// RetryAfter is how long PD asked to wait before retrying a rate limited call.
+	// It is zero when PD didn't say.
+	RetryAfter time.Duration
+
+	// Err is the underlying error
+	Err error
+}
+
+func (e *APIError) Error() string {
+	return e.Err.Error()
+}
+
+// Unwrap allows errors.Is and errors.As to match both the kind and the underlying error
+func (e *APIError) Unwrap() []error {
+	return []error{e.Kind, e.Err}
+}
+
+// IsNotFound returns true if err reports that the requested PD object doesn't exist
+func IsNotFound(err error) bool {
+	return isKind(err, ErrNotFound)
+}
+
+// IsConflict returns true if err reports that the PD object conflicts with an existing one
+func IsConflict(err error) bool {
+	return isKind(err, ErrConflict)
+}
+
+// IsRateLimited returns true if err reports that the PD API rate limit was hit
+func IsRateLimited(err error) bool {
+	return isKind(err, ErrRateLimited)
+}
+
+// IsUnauthorized returns true if err reports that the API key is invalid or lacks permissions
+func IsUnauthorized(err error) bool {
+	return isKind(err, ErrUnauthorized)
+}
+
+// IsTransient returns true if err reports a server side or network error
+func IsTransient(err error) bool {
+	return isKind(err, ErrTransient)
+}
+
+func isKind(err error, kind error) bool {
+	return errors.Is(classifyError(context.Background(), err), kind)
+}
+
+// RetryAfter returns how long PD asked to wait before retrying the call that failed with err
+func RetryAfter(err error) (time.Duration, bool) {
+	var apiErr *APIError
+	if errors.As(err, &apiErr) && apiErr.RetryAfter > 0 {
+		return apiErr.RetryAfter, true
+	}
+	return 0, false
+}
+
+// ErrorReason returns a short name for the class of err, to be used e.g. as a metric label
+func ErrorReason(err error) string {
+	switch {
+	case IsNotFound(err):
+		return "NotFound"
+	case IsConflict(err):
+		return "Conflict"
+	case IsRateLimited(err):
+		return "RateLimited"
+	case IsUnauthorized(err):
+		return "Unauthorized"
+	case IsTransient(err):
+		return "Transient"
+	default:
+		return "Other"
+	}
+}
+
+// classifyError wraps an error returned by the pdApi.Client in an APIError when it can
+// be classified, otherwise err is returned as is. The pdApi.APIError doesn't keep the
+// response headers, the delay PD asked for is read from the headers the rate limited
+// HTTP client recorded in ctx.
+func classifyError(ctx context.Context, err error) error {
+	var pdErr pdApi.APIError
+	if !errors.As(err, &pdErr) {
+		return err
+	}
+
+	var kind error
+	switch {
+	case pdErr.StatusCode == http.StatusConflict || isNameTaken(pdErr):
+		kind = ErrConflict
+	case pdErr.NotFound():
+		kind = ErrNotFound
+	default:
+		kind = kindFromStatusCode(pdErr.StatusCode)
+	}
+	if kind == nil {
+		return err
+	}
+
+	return &APIError{
+		Kind:       kind,
+		StatusCode: pdErr.StatusCode,
+		RetryAfter: retryAfterOf(kind, responseHeaderOf(ctx)),
+		Err:        err,
+	}
+}
+
+// newHTTPError returns the error for a raw HTTP call to the PD API that was answered with
+// a non 2xx status
+func newHTTPError(resp *http.Response) error {
+	err := fmt.Errorf("failed pdHttpRequest, returned status code is non 2xx: Status: %s", resp.Status)
+
+	kind := kindFromStatusCode(resp.StatusCode)
+	if kind == nil {
+		return err
+	}
+
+	return &APIError{
+		Kind:       kind,
+		StatusCode: resp.StatusCode,
+		RetryAfter: retryAfterOf(kind, resp.Header),
+		Err:        err,
+	}
+}
+
+// classifyHTTPClientError wraps network errors of raw HTTP calls as transient errors
+func classifyHTTPClientError(err error) error {
+	var netErr net.Error
+	if errors.As(err, &netErr) {
+		return &APIError{Kind: ErrTransient, Err: err}
+	}
+	return err
+}
+
+func kindFromStatusCode(statusCode int) error {
+	switch {
+	case statusCode == http.StatusNotFound:
+		return ErrNotFound
+	case statusCode == http.StatusConflict:
+		return ErrConflict
+	case statusCode == http.StatusTooManyRequests:
+		return ErrRateLimited
+	case statusCode == http.StatusUnauthorized || statusCode == http.StatusForbidden:
+		return ErrUnauthorized
+	case statusCode >= 500 && statusCode < 600:
+		return ErrTransient
+	default:
+		return nil
+	}
+}
+
+// isNameTaken returns true for the error PD returns when a service name is already used.
+// PD reports it as invalid input rather than with a dedicated status or error code.
+func isNameTaken(pdErr pdApi.APIError) bool {
+	if !pdErr.APIError.Valid {
+		return false
+	}
+	if strings.Contains(pdErr.APIError.ErrorObject.Message, "already been taken") {
+		return true
+	}
+	for _, msg := range pdErr.APIError.ErrorObject.Errors {
+		if strings.Contains(msg, "already been taken") {
+			return true
+		}
+	}
+	return false
+}
+
+// retryAfterOf returns the delay PD asked for in the headers of a rate limited response,
+// zero for other errors as PD sends its rate limit headers with every response
+func retryAfterOf(kind error, header http.Header) time.Duration {
+	if kind != ErrRateLimited {
+		return 0
+	}
+	return parseRetryAfter(header)
+}
+
+// parseRetryAfter reads the delay from the Retry-After header, or from the
+// ratelimit-reset header PD sends along with its rate limit headers
+func parseRetryAfter(header http.Header) time.Duration {
+	if d := headerSeconds(header, "Retry-After"); d > 0 {
+		return d
+	}
+	return headerSeconds(header, "Ratelimit-Reset")
+}
+
+// headerSeconds reads a header holding a number of seconds, zero if it is missing or invalid
+func headerSeconds(header http.Header, name string) time.Duration {
+	if seconds, err := strconv.Atoi(header.Get(name)); err == nil && seconds > 0 {
+		return time.Duration(seconds) * time.Second
+	}
+	return 0
+}
@@ -0,0 +1,245 @@
+package pagerduty
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	pdApi "github.com/PagerDuty/go-pagerduty"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIsNotFound(t *testing.T) {
+	tests := []struct {
+		name     string
+		err      error
+		expected bool
+	}{
+		{
+			name:     "wrapped 404",
+			err:      fmt.Errorf("unable to get service: %w", pdApi.APIError{StatusCode: http.StatusNotFound}),
+			expected: true,
+		},
+		{
+			name:     "classified 404",
+			err:      fmt.Errorf("unable to get service: %w", classifyError(context.TODO(), pdApi.APIError{StatusCode: http.StatusNotFound})),
+			expected: true,
+		},
+		{
+			name:     "500",
+			err:      pdApi.APIError{StatusCode: http.StatusInternalServerError},
+			expected: false,
+		},
+		{
+			name:     "not an API error",
+			err:      errors.New("Not Found"),
+			expected: false,
+		},
+		{
+			name:     "nil",
+			err:      nil,
+			expected: false,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			assert.Equal(t, test.expected, IsNotFound(test.err))
+		})
+	}
+}
+
+func TestClassifyError(t *testing.T) {
+	nameTaken := pdApi.APIError{StatusCode: http.StatusBadRequest}
+	nameTaken.APIError.Valid = true
+	nameTaken.APIError.ErrorObject.Message = "Invalid Input Provided"
+	nameTaken.APIError.ErrorObject.Errors = []string{"Name has already been taken."}
+
+	tests := []struct {
+		name           string
+		err            error
+		expectedKind   error
+		expectedReason string
+	}{
+		{
+			name:           "Not found",
+			err:            pdApi.APIError{StatusCode: http.StatusNotFound},
+			expectedKind:   ErrNotFound,
+			expectedReason: "NotFound",
+		},
+		{
+			name:           "Name already taken",
+			err:            nameTaken,
+			expectedKind:   ErrConflict,
+			expectedReason: "Conflict",
+		},
+		{
+			name:           "Conflict",
+			err:            pdApi.APIError{StatusCode: http.StatusConflict},
+			expectedKind:   ErrConflict,
+			expectedReason: "Conflict",
+		},
+		{
+			name:           "Rate limited",
+			err:            pdApi.APIError{StatusCode: http.StatusTooManyRequests},
+			expectedKind:   ErrRateLimited,
+			expectedReason: "RateLimited",
+		},
+		{
+			name:           "Unauthorized",
+			err:            pdApi.APIError{StatusCode: http.StatusUnauthorized},
+			expectedKind:   ErrUnauthorized,
+			expectedReason: "Unauthorized",
+		},
+		{
+			name:           "Forbidden",
+			err:            pdApi.APIError{StatusCode: http.StatusForbidden},
+			expectedKind:   ErrUnauthorized,
+			expectedReason: "Unauthorized",
+		},
+		{
+			name:           "Server error",
+			err:            pdApi.APIError{StatusCode: http.StatusBadGateway},
+			expectedKind:   ErrTransient,
+			expectedReason: "Transient",
+		},
+		{
+			name:           "Bad request",
+			err:            pdApi.APIError{StatusCode: http.StatusBadRequest},
+			expectedKind:   nil,
+			expectedReason: "Other",
+		},
+		{
+			name:           "Not an API error",
+			err:            errors.New("boom"),
+			expectedKind:   nil,
+			expectedReason: "Other",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			err := classifyError(context.TODO(), fmt.Errorf("wrapped: %w", test.err))
+
+			var apiErr *APIError
+			if test.expectedKind == nil {
+				assert.False(t, errors.As(err, &apiErr))
+			} else {
+				assert.ErrorIs(t, err, test.expectedKind)
+				// the original error is still reachable
+				var pdErr pdApi.APIError
+				assert.True(t, errors.As(err, &pdErr))
+			}
+			assert.Equal(t, test.expectedReason, ErrorReason(err))
+		})
+	}
+}
+
+func TestSvcClient_PdHttpRequestErrors(t *testing.T) {
+	tests := []struct {
+		name               string
+		status             int
+		header             map[string]string
+		expectedKind       error
+		expectedRetryAfter time.Duration
+	}{
+		{
+			name:               "Rate limited with Retry-After",
+			status:             http.StatusTooManyRequests,
+			header:             map[string]string{"Retry-After": "30"},
+			expectedKind:       ErrRateLimited,
+			expectedRetryAfter: 30 * time.Second,
+		},
+		{
+			name:               "Rate limited with ratelimit-reset",
+			status:             http.StatusTooManyRequests,
+			header:             map[string]string{"ratelimit-reset": "12"},
+			expectedKind:       ErrRateLimited,
+			expectedRetryAfter: 12 * time.Second,
+		},
+		{
+			name:         "Unauthorized",
+			status:       http.StatusUnauthorized,
+			expectedKind: ErrUnauthorized,
+		},
+		{
+			name:         "Service unavailable",
+			status:       http.StatusServiceUnavailable,
+			expectedKind: ErrTransient,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				for k, v := range test.header {
+					w.Header().Set(k, v)
+				}
+				w.WriteHeader(test.status)
+			}))
+			defer server.Close()
+
+			c := &SvcClient{BaseURL: server.URL}
+			err := c.pdHttpRequest(context.TODO(), "PUT", server.URL, strings.NewReader("{}"))
+
+			assert.ErrorIs(t, err, test.expectedKind)
+			retryAfter, ok := RetryAfter(err)
+			assert.Equal(t, test.expectedRetryAfter > 0, ok)
+			assert.Equal(t, test.expectedRetryAfter, retryAfter)
+		})
+	}
+}
+
+func TestSvcClient_RetryAfterOfAPIError(t *testing.T) {
+	tests := []struct {
+		name               string
+		status             int
+		header             map[string]string
+		expectedKind       error
+		expectedRetryAfter time.Duration
+	}{
+		{
+			// longer than maxRetryWait, so the rate limited HTTP client returns the 429
+			name:               "Rate limited with Retry-After",
+			status:             http.StatusTooManyRequests,
+			header:             map[string]string{"Retry-After": "60"},
+			expectedKind:       ErrRateLimited,
+			expectedRetryAfter: 60 * time.Second,
+		},
+		{
+			name:         "Not found with ratelimit-reset",
+			status:       http.StatusNotFound,
+			header:       map[string]string{"Ratelimit-Reset": "12"},
+			expectedKind: ErrNotFound,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				for k, v := range test.header {
+					w.Header().Set(k, v)
+				}
+				w.Header().Set("Content-Type", "application/json")
+				w.WriteHeader(test.status)
+				_, _ = w.Write([]byte(`{"error":{"message":"error","code":2001}}`))
+			}))
+			defer server.Close()
+
+			c := newSvcClient(Account{APIKey: t.Name(), Endpoint: Endpoint{APIURL: server.URL}}, "test")
+			_, err := c.GetService(context.TODO(), &Data{ServiceID: "ABC123"})
+
+			assert.ErrorIs(t, err, test.expectedKind)
+			var pdErr pdApi.APIError
+			assert.True(t, errors.As(err, &pdErr))
+			retryAfter, ok := RetryAfter(err)
+			assert.Equal(t, test.expectedRetryAfter > 0, ok)
+			assert.Equal(t, test.expectedRetryAfter, retryAfter)
+		})
+	}
+}
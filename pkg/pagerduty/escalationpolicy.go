@@ -0,0 +1,44 @@
+// Copyright 2019 RedHat
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pagerduty
+
+import (
+	"fmt"
+
+	hivev1 "github.com/openshift/hive/apis/hive/v1"
+	pagerdutyv1alpha1 "github.com/openshift/pagerduty-operator/api/v1alpha1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+)
+
+// EscalationPolicyFor returns the ID of the escalation policy of the PD service of cd: the
+// one overridden by the annotations of cd, the one of the first escalation policy rule of
+// pdi matching the labels of cd, or the default escalation policy of pdi when no rule matches
+func EscalationPolicyFor(pdi *pagerdutyv1alpha1.PagerDutyIntegration, cd *hivev1.ClusterDeployment) (string, error) {
+	if overrides, _ := parseClusterOverrides(cd); overrides.escalationPolicy != "" {
+		return overrides.escalationPolicy, nil
+	}
+
+	for i, rule := range pdi.Spec.EscalationPolicyRules {
+		selector, err := metav1.LabelSelectorAsSelector(&rule.ClusterDeploymentSelector)
+		if err != nil {
+			return "", fmt.Errorf("invalid clusterDeploymentSelector in escalation policy rule %d: %w", i, err)
+		}
+		if selector.Matches(labels.Set(cd.Labels)) {
+			return rule.EscalationPolicy, nil
+		}
+	}
+	return pdi.Spec.EscalationPolicy, nil
+}
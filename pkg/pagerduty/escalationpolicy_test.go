@@ -0,0 +1,111 @@
+package pagerduty
+
+import (
+	"testing"
+
+	hivev1 "github.com/openshift/hive/apis/hive/v1"
+	pagerdutyv1alpha1 "github.com/openshift/pagerduty-operator/api/v1alpha1"
+	"github.com/openshift/pagerduty-operator/config"
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestEscalationPolicyFor(t *testing.T) {
+	rules := []pagerdutyv1alpha1.EscalationPolicyRule{
+		{
+			ClusterDeploymentSelector: metav1.LabelSelector{
+				MatchLabels: map[string]string{"api.openshift.com/legal-entity-id": "scale-test"},
+			},
+			EscalationPolicy: "SCALE",
+		},
+		{
+			ClusterDeploymentSelector: metav1.LabelSelector{
+				MatchExpressions: []metav1.LabelSelectorRequirement{
+					{Key: "api.openshift.com/legal-entity-id", Operator: metav1.LabelSelectorOpIn, Values: []string{"silent", "scale-test"}},
+				},
+			},
+			EscalationPolicy: "SILENT",
+		},
+	}
+
+	tests := []struct {
+		name        string
+		rules       []pagerdutyv1alpha1.EscalationPolicyRule
+		labels      map[string]string
+		annotations map[string]string
+		expected    string
+		expectErr   bool
+	}{
+		{
+			name:     "No rules",
+			labels:   map[string]string{"api.openshift.com/legal-entity-id": "silent"},
+			expected: mockEscalationPolicyId,
+		},
+		{
+			name:     "No rule matches",
+			rules:    rules,
+			labels:   map[string]string{"api.openshift.com/legal-entity-id": "customer"},
+			expected: mockEscalationPolicyId,
+		},
+		{
+			name:     "Rule matches",
+			rules:    rules,
+			labels:   map[string]string{"api.openshift.com/legal-entity-id": "silent"},
+			expected: "SILENT",
+		},
+		{
+			name:     "First matching rule wins",
+			rules:    rules,
+			labels:   map[string]string{"api.openshift.com/legal-entity-id": "scale-test"},
+			expected: "SCALE",
+		},
+		{
+			name:        "Annotation overrides matching rule",
+			rules:       rules,
+			labels:      map[string]string{"api.openshift.com/legal-entity-id": "silent"},
+			annotations: map[string]string{config.EscalationPolicyOverrideAnnotation: "POVERRIDE"},
+			expected:    "POVERRIDE",
+		},
+		{
+			name:        "Invalid annotation is ignored",
+			rules:       rules,
+			labels:      map[string]string{"api.openshift.com/legal-entity-id": "silent"},
+			annotations: map[string]string{config.EscalationPolicyOverrideAnnotation: "silent"},
+			expected:    "SILENT",
+		},
+		{
+			name: "Invalid selector",
+			rules: []pagerdutyv1alpha1.EscalationPolicyRule{
+				{
+					ClusterDeploymentSelector: metav1.LabelSelector{
+						MatchExpressions: []metav1.LabelSelectorRequirement{
+							{Key: "api.openshift.com/legal-entity-id", Operator: "Matches"},
+						},
+					},
+					EscalationPolicy: "INVALID",
+				},
+			},
+			expectErr: true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			pdi := &pagerdutyv1alpha1.PagerDutyIntegration{
+				Spec: pagerdutyv1alpha1.PagerDutyIntegrationSpec{
+					EscalationPolicy:      mockEscalationPolicyId,
+					EscalationPolicyRules: test.rules,
+				},
+			}
+			cd := &hivev1.ClusterDeployment{ObjectMeta: metav1.ObjectMeta{Labels: test.labels, Annotations: test.annotations}}
+
+			escalationPolicy, err := EscalationPolicyFor(pdi, cd)
+			if test.expectErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, test.expected, escalationPolicy)
+		})
+	}
+}
@@ -10,6 +10,7 @@
 package pagerduty
 
 import (
+	context "context"
 	reflect "reflect"
 
 	pagerduty "github.com/PagerDuty/go-pagerduty"
@@ -20,6 +21,7 @@ import (
 type MockClient struct {
 	ctrl     *gomock.Controller
 	recorder *MockClientMockRecorder
+	isgomock struct{}
 }
 
 // MockClientMockRecorder is the mock recorder for MockClient.
@@ -40,152 +42,224 @@ func (m *MockClient) EXPECT() *MockClientMockRecorder {
 }
 
 // ApplyServiceOrchestrationRule mocks base method.
-func (m *MockClient) ApplyServiceOrchestrationRule(data *Data) error {
+func (m *MockClient) ApplyServiceOrchestrationRule(ctx context.Context, data *Data) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "ApplyServiceOrchestrationRule", data)
+	ret := m.ctrl.Call(m, "ApplyServiceOrchestrationRule", ctx, data)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
 // ApplyServiceOrchestrationRule indicates an expected call of ApplyServiceOrchestrationRule.
-func (mr *MockClientMockRecorder) ApplyServiceOrchestrationRule(data any) *gomock.Call {
+func (mr *MockClientMockRecorder) ApplyServiceOrchestrationRule(ctx, data any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ApplyServiceOrchestrationRule", reflect.TypeOf((*MockClient)(nil).ApplyServiceOrchestrationRule), data)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ApplyServiceOrchestrationRule", reflect.TypeOf((*MockClient)(nil).ApplyServiceOrchestrationRule), ctx, data)
+}
+
+// CountUnresolvedIncidents mocks base method.
+func (m *MockClient) CountUnresolvedIncidents(ctx context.Context, data *Data) (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CountUnresolvedIncidents", ctx, data)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CountUnresolvedIncidents indicates an expected call of CountUnresolvedIncidents.
+func (mr *MockClientMockRecorder) CountUnresolvedIncidents(ctx, data any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CountUnresolvedIncidents", reflect.TypeOf((*MockClient)(nil).CountUnresolvedIncidents), ctx, data)
+}
+
+// CreateIntegration mocks base method.
+func (m *MockClient) CreateIntegration(ctx context.Context, data *Data) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateIntegration", ctx, data)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CreateIntegration indicates an expected call of CreateIntegration.
+func (mr *MockClientMockRecorder) CreateIntegration(ctx, data any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateIntegration", reflect.TypeOf((*MockClient)(nil).CreateIntegration), ctx, data)
 }
 
 // CreateService mocks base method.
-func (m *MockClient) CreateService(data *Data) (string, error) {
+func (m *MockClient) CreateService(ctx context.Context, data *Data) (string, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "CreateService", data)
+	ret := m.ctrl.Call(m, "CreateService", ctx, data)
 	ret0, _ := ret[0].(string)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // CreateService indicates an expected call of CreateService.
-func (mr *MockClientMockRecorder) CreateService(data any) *gomock.Call {
+func (mr *MockClientMockRecorder) CreateService(ctx, data any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateService", reflect.TypeOf((*MockClient)(nil).CreateService), data)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateService", reflect.TypeOf((*MockClient)(nil).CreateService), ctx, data)
 }
 
 // DeleteService mocks base method.
-func (m *MockClient) DeleteService(data *Data) error {
+func (m *MockClient) DeleteService(ctx context.Context, data *Data) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "DeleteService", data)
+	ret := m.ctrl.Call(m, "DeleteService", ctx, data)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
 // DeleteService indicates an expected call of DeleteService.
-func (mr *MockClientMockRecorder) DeleteService(data any) *gomock.Call {
+func (mr *MockClientMockRecorder) DeleteService(ctx, data any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteService", reflect.TypeOf((*MockClient)(nil).DeleteService), data)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteService", reflect.TypeOf((*MockClient)(nil).DeleteService), ctx, data)
 }
 
 // DisableService mocks base method.
-func (m *MockClient) DisableService(data *Data) error {
+func (m *MockClient) DisableService(ctx context.Context, data *Data) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "DisableService", data)
+	ret := m.ctrl.Call(m, "DisableService", ctx, data)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
 // DisableService indicates an expected call of DisableService.
-func (mr *MockClientMockRecorder) DisableService(data any) *gomock.Call {
+func (mr *MockClientMockRecorder) DisableService(ctx, data any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DisableService", reflect.TypeOf((*MockClient)(nil).DisableService), data)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DisableService", reflect.TypeOf((*MockClient)(nil).DisableService), ctx, data)
 }
 
 // EnableService mocks base method.
-func (m *MockClient) EnableService(data *Data) error {
+func (m *MockClient) EnableService(ctx context.Context, data *Data) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "EnableService", data)
+	ret := m.ctrl.Call(m, "EnableService", ctx, data)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
 // EnableService indicates an expected call of EnableService.
-func (mr *MockClientMockRecorder) EnableService(data any) *gomock.Call {
+func (mr *MockClientMockRecorder) EnableService(ctx, data any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "EnableService", reflect.TypeOf((*MockClient)(nil).EnableService), data)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "EnableService", reflect.TypeOf((*MockClient)(nil).EnableService), ctx, data)
 }
 
 // GetIntegrationKey mocks base method.
-func (m *MockClient) GetIntegrationKey(data *Data) (string, error) {
+func (m *MockClient) GetIntegrationKey(ctx context.Context, data *Data) (string, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetIntegrationKey", data)
+	ret := m.ctrl.Call(m, "GetIntegrationKey", ctx, data)
 	ret0, _ := ret[0].(string)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // GetIntegrationKey indicates an expected call of GetIntegrationKey.
-func (mr *MockClientMockRecorder) GetIntegrationKey(data any) *gomock.Call {
+func (mr *MockClientMockRecorder) GetIntegrationKey(ctx, data any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetIntegrationKey", reflect.TypeOf((*MockClient)(nil).GetIntegrationKey), data)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetIntegrationKey", reflect.TypeOf((*MockClient)(nil).GetIntegrationKey), ctx, data)
 }
 
 // GetService mocks base method.
-func (m *MockClient) GetService(data *Data) (*pagerduty.Service, error) {
+func (m *MockClient) GetService(ctx context.Context, data *Data) (*pagerduty.Service, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetService", data)
+	ret := m.ctrl.Call(m, "GetService", ctx, data)
 	ret0, _ := ret[0].(*pagerduty.Service)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // GetService indicates an expected call of GetService.
-func (mr *MockClientMockRecorder) GetService(data any) *gomock.Call {
+func (mr *MockClientMockRecorder) GetService(ctx, data any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetService", reflect.TypeOf((*MockClient)(nil).GetService), data)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetService", reflect.TypeOf((*MockClient)(nil).GetService), ctx, data)
 }
 
-// ToggleServiceOrchestration mocks base method.
-func (m *MockClient) ToggleServiceOrchestration(data *Data, active bool) error {
+// ResolvePendingIncidents mocks base method.
+func (m *MockClient) ResolvePendingIncidents(ctx context.Context, data *Data, summary string) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "ToggleServiceOrchestration", data, active)
+	ret := m.ctrl.Call(m, "ResolvePendingIncidents", ctx, data, summary)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
-// ToggleServiceOrchestration indicates an expected call of ToggleServiceOrchestration.
-func (mr *MockClientMockRecorder) ToggleServiceOrchestration(data, active any) *gomock.Call {
+// ResolvePendingIncidents indicates an expected call of ResolvePendingIncidents.
+func (mr *MockClientMockRecorder) ResolvePendingIncidents(ctx, data, summary any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ToggleServiceOrchestration", reflect.TypeOf((*MockClient)(nil).ToggleServiceOrchestration), data, active)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ResolvePendingIncidents", reflect.TypeOf((*MockClient)(nil).ResolvePendingIncidents), ctx, data, summary)
 }
 
-// UpdateAlertGrouping mocks base method.
-func (m *MockClient) UpdateAlertGrouping(data *Data) error {
+// RestoreService mocks base method.
+func (m *MockClient) RestoreService(ctx context.Context, data *Data) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "UpdateAlertGrouping", data)
+	ret := m.ctrl.Call(m, "RestoreService", ctx, data)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
-// UpdateAlertGrouping indicates an expected call of UpdateAlertGrouping.
-func (mr *MockClientMockRecorder) UpdateAlertGrouping(data any) *gomock.Call {
+// RestoreService indicates an expected call of RestoreService.
+func (mr *MockClientMockRecorder) RestoreService(ctx, data any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateAlertGrouping", reflect.TypeOf((*MockClient)(nil).UpdateAlertGrouping), data)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RestoreService", reflect.TypeOf((*MockClient)(nil).RestoreService), ctx, data)
+}
+
+// ToggleServiceOrchestration mocks base method.
+func (m *MockClient) ToggleServiceOrchestration(ctx context.Context, data *Data, active bool) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ToggleServiceOrchestration", ctx, data, active)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ToggleServiceOrchestration indicates an expected call of ToggleServiceOrchestration.
+func (mr *MockClientMockRecorder) ToggleServiceOrchestration(ctx, data, active any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ToggleServiceOrchestration", reflect.TypeOf((*MockClient)(nil).ToggleServiceOrchestration), ctx, data, active)
 }
 
 // UpdateEscalationPolicy mocks base method.
-func (m *MockClient) UpdateEscalationPolicy(data *Data) error {
+func (m *MockClient) UpdateEscalationPolicy(ctx context.Context, data *Data) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "UpdateEscalationPolicy", data)
+	ret := m.ctrl.Call(m, "UpdateEscalationPolicy", ctx, data)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
 // UpdateEscalationPolicy indicates an expected call of UpdateEscalationPolicy.
-func (mr *MockClientMockRecorder) UpdateEscalationPolicy(data any) *gomock.Call {
+func (mr *MockClientMockRecorder) UpdateEscalationPolicy(ctx, data any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateEscalationPolicy", reflect.TypeOf((*MockClient)(nil).UpdateEscalationPolicy), ctx, data)
+}
+
+// UpdateServiceSettings mocks base method.
+func (m *MockClient) UpdateServiceSettings(ctx context.Context, data *Data) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateServiceSettings", ctx, data)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateServiceSettings indicates an expected call of UpdateServiceSettings.
+func (mr *MockClientMockRecorder) UpdateServiceSettings(ctx, data any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateServiceSettings", reflect.TypeOf((*MockClient)(nil).UpdateServiceSettings), ctx, data)
+}
+
+// ValidateAPIKey mocks base method.
+func (m *MockClient) ValidateAPIKey(ctx context.Context) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ValidateAPIKey", ctx)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ValidateAPIKey indicates an expected call of ValidateAPIKey.
+func (mr *MockClientMockRecorder) ValidateAPIKey(ctx any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateEscalationPolicy", reflect.TypeOf((*MockClient)(nil).UpdateEscalationPolicy), data)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ValidateAPIKey", reflect.TypeOf((*MockClient)(nil).ValidateAPIKey), ctx)
 }
 
 // MockPdClient is a mock of PdClient interface.
 type MockPdClient struct {
 	ctrl     *gomock.Controller
 	recorder *MockPdClientMockRecorder
+	isgomock struct{}
 }
 
 // MockPdClientMockRecorder is the mock recorder for MockPdClient.
@@ -205,166 +279,181 @@ func (m *MockPdClient) EXPECT() *MockPdClientMockRecorder {
 	return m.recorder
 }
 
-// CreateIntegration mocks base method.
-func (m *MockPdClient) CreateIntegration(serviceID string, integration pagerduty.Integration) (*pagerduty.Integration, error) {
+// CreateIntegrationWithContext mocks base method.
+func (m *MockPdClient) CreateIntegrationWithContext(ctx context.Context, serviceID string, integration pagerduty.Integration) (*pagerduty.Integration, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "CreateIntegration", serviceID, integration)
+	ret := m.ctrl.Call(m, "CreateIntegrationWithContext", ctx, serviceID, integration)
 	ret0, _ := ret[0].(*pagerduty.Integration)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// CreateIntegration indicates an expected call of CreateIntegration.
-func (mr *MockPdClientMockRecorder) CreateIntegration(serviceID, integration any) *gomock.Call {
+// CreateIntegrationWithContext indicates an expected call of CreateIntegrationWithContext.
+func (mr *MockPdClientMockRecorder) CreateIntegrationWithContext(ctx, serviceID, integration any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateIntegration", reflect.TypeOf((*MockPdClient)(nil).CreateIntegration), serviceID, integration)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateIntegrationWithContext", reflect.TypeOf((*MockPdClient)(nil).CreateIntegrationWithContext), ctx, serviceID, integration)
 }
 
-// CreateService mocks base method.
-func (m *MockPdClient) CreateService(service pagerduty.Service) (*pagerduty.Service, error) {
+// CreateServiceWithContext mocks base method.
+func (m *MockPdClient) CreateServiceWithContext(ctx context.Context, service pagerduty.Service) (*pagerduty.Service, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "CreateService", service)
+	ret := m.ctrl.Call(m, "CreateServiceWithContext", ctx, service)
 	ret0, _ := ret[0].(*pagerduty.Service)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// CreateService indicates an expected call of CreateService.
-func (mr *MockPdClientMockRecorder) CreateService(service any) *gomock.Call {
+// CreateServiceWithContext indicates an expected call of CreateServiceWithContext.
+func (mr *MockPdClientMockRecorder) CreateServiceWithContext(ctx, service any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateService", reflect.TypeOf((*MockPdClient)(nil).CreateService), service)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateServiceWithContext", reflect.TypeOf((*MockPdClient)(nil).CreateServiceWithContext), ctx, service)
 }
 
-// DeleteService mocks base method.
-func (m *MockPdClient) DeleteService(id string) error {
+// DeleteServiceWithContext mocks base method.
+func (m *MockPdClient) DeleteServiceWithContext(ctx context.Context, id string) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "DeleteService", id)
+	ret := m.ctrl.Call(m, "DeleteServiceWithContext", ctx, id)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
-// DeleteService indicates an expected call of DeleteService.
-func (mr *MockPdClientMockRecorder) DeleteService(id any) *gomock.Call {
+// DeleteServiceWithContext indicates an expected call of DeleteServiceWithContext.
+func (mr *MockPdClientMockRecorder) DeleteServiceWithContext(ctx, id any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteService", reflect.TypeOf((*MockPdClient)(nil).DeleteService), id)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteServiceWithContext", reflect.TypeOf((*MockPdClient)(nil).DeleteServiceWithContext), ctx, id)
 }
 
-// GetEscalationPolicy mocks base method.
-func (m *MockPdClient) GetEscalationPolicy(arg0 string, arg1 *pagerduty.GetEscalationPolicyOptions) (*pagerduty.EscalationPolicy, error) {
+// GetEscalationPolicyWithContext mocks base method.
+func (m *MockPdClient) GetEscalationPolicyWithContext(ctx context.Context, id string, o *pagerduty.GetEscalationPolicyOptions) (*pagerduty.EscalationPolicy, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetEscalationPolicy", arg0, arg1)
+	ret := m.ctrl.Call(m, "GetEscalationPolicyWithContext", ctx, id, o)
 	ret0, _ := ret[0].(*pagerduty.EscalationPolicy)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// GetEscalationPolicy indicates an expected call of GetEscalationPolicy.
-func (mr *MockPdClientMockRecorder) GetEscalationPolicy(arg0, arg1 any) *gomock.Call {
+// GetEscalationPolicyWithContext indicates an expected call of GetEscalationPolicyWithContext.
+func (mr *MockPdClientMockRecorder) GetEscalationPolicyWithContext(ctx, id, o any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetEscalationPolicy", reflect.TypeOf((*MockPdClient)(nil).GetEscalationPolicy), arg0, arg1)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetEscalationPolicyWithContext", reflect.TypeOf((*MockPdClient)(nil).GetEscalationPolicyWithContext), ctx, id, o)
 }
 
-// GetIntegration mocks base method.
-func (m *MockPdClient) GetIntegration(arg0, arg1 string, arg2 pagerduty.GetIntegrationOptions) (*pagerduty.Integration, error) {
+// GetIntegrationWithContext mocks base method.
+func (m *MockPdClient) GetIntegrationWithContext(ctx context.Context, serviceID, integrationID string, o pagerduty.GetIntegrationOptions) (*pagerduty.Integration, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetIntegration", arg0, arg1, arg2)
+	ret := m.ctrl.Call(m, "GetIntegrationWithContext", ctx, serviceID, integrationID, o)
 	ret0, _ := ret[0].(*pagerduty.Integration)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// GetIntegration indicates an expected call of GetIntegration.
-func (mr *MockPdClientMockRecorder) GetIntegration(arg0, arg1, arg2 any) *gomock.Call {
+// GetIntegrationWithContext indicates an expected call of GetIntegrationWithContext.
+func (mr *MockPdClientMockRecorder) GetIntegrationWithContext(ctx, serviceID, integrationID, o any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetIntegration", reflect.TypeOf((*MockPdClient)(nil).GetIntegration), arg0, arg1, arg2)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetIntegrationWithContext", reflect.TypeOf((*MockPdClient)(nil).GetIntegrationWithContext), ctx, serviceID, integrationID, o)
 }
 
-// GetService mocks base method.
-func (m *MockPdClient) GetService(arg0 string, arg1 *pagerduty.GetServiceOptions) (*pagerduty.Service, error) {
+// GetServiceWithContext mocks base method.
+func (m *MockPdClient) GetServiceWithContext(ctx context.Context, id string, o *pagerduty.GetServiceOptions) (*pagerduty.Service, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetService", arg0, arg1)
+	ret := m.ctrl.Call(m, "GetServiceWithContext", ctx, id, o)
 	ret0, _ := ret[0].(*pagerduty.Service)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// GetService indicates an expected call of GetService.
-func (mr *MockPdClientMockRecorder) GetService(arg0, arg1 any) *gomock.Call {
+// GetServiceWithContext indicates an expected call of GetServiceWithContext.
+func (mr *MockPdClientMockRecorder) GetServiceWithContext(ctx, id, o any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetServiceWithContext", reflect.TypeOf((*MockPdClient)(nil).GetServiceWithContext), ctx, id, o)
+}
+
+// ListAbilitiesWithContext mocks base method.
+func (m *MockPdClient) ListAbilitiesWithContext(ctx context.Context) (*pagerduty.ListAbilityResponse, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListAbilitiesWithContext", ctx)
+	ret0, _ := ret[0].(*pagerduty.ListAbilityResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListAbilitiesWithContext indicates an expected call of ListAbilitiesWithContext.
+func (mr *MockPdClientMockRecorder) ListAbilitiesWithContext(ctx any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetService", reflect.TypeOf((*MockPdClient)(nil).GetService), arg0, arg1)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListAbilitiesWithContext", reflect.TypeOf((*MockPdClient)(nil).ListAbilitiesWithContext), ctx)
 }
 
-// ListIncidentAlertsWithOpts mocks base method.
-func (m *MockPdClient) ListIncidentAlertsWithOpts(incidentId string, o pagerduty.ListIncidentAlertsOptions) (*pagerduty.ListAlertsResponse, error) {
+// ListIncidentAlertsWithContext mocks base method.
+func (m *MockPdClient) ListIncidentAlertsWithContext(ctx context.Context, incidentId string, o pagerduty.ListIncidentAlertsOptions) (*pagerduty.ListAlertsResponse, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "ListIncidentAlertsWithOpts", incidentId, o)
+	ret := m.ctrl.Call(m, "ListIncidentAlertsWithContext", ctx, incidentId, o)
 	ret0, _ := ret[0].(*pagerduty.ListAlertsResponse)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// ListIncidentAlertsWithOpts indicates an expected call of ListIncidentAlertsWithOpts.
-func (mr *MockPdClientMockRecorder) ListIncidentAlertsWithOpts(incidentId, o any) *gomock.Call {
+// ListIncidentAlertsWithContext indicates an expected call of ListIncidentAlertsWithContext.
+func (mr *MockPdClientMockRecorder) ListIncidentAlertsWithContext(ctx, incidentId, o any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListIncidentAlertsWithOpts", reflect.TypeOf((*MockPdClient)(nil).ListIncidentAlertsWithOpts), incidentId, o)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListIncidentAlertsWithContext", reflect.TypeOf((*MockPdClient)(nil).ListIncidentAlertsWithContext), ctx, incidentId, o)
 }
 
-// ListIncidents mocks base method.
-func (m *MockPdClient) ListIncidents(arg0 pagerduty.ListIncidentsOptions) (*pagerduty.ListIncidentsResponse, error) {
+// ListIncidentsWithContext mocks base method.
+func (m *MockPdClient) ListIncidentsWithContext(ctx context.Context, o pagerduty.ListIncidentsOptions) (*pagerduty.ListIncidentsResponse, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "ListIncidents", arg0)
+	ret := m.ctrl.Call(m, "ListIncidentsWithContext", ctx, o)
 	ret0, _ := ret[0].(*pagerduty.ListIncidentsResponse)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// ListIncidents indicates an expected call of ListIncidents.
-func (mr *MockPdClientMockRecorder) ListIncidents(arg0 any) *gomock.Call {
+// ListIncidentsWithContext indicates an expected call of ListIncidentsWithContext.
+func (mr *MockPdClientMockRecorder) ListIncidentsWithContext(ctx, o any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListIncidents", reflect.TypeOf((*MockPdClient)(nil).ListIncidents), arg0)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListIncidentsWithContext", reflect.TypeOf((*MockPdClient)(nil).ListIncidentsWithContext), ctx, o)
 }
 
-// ListServices mocks base method.
-func (m *MockPdClient) ListServices(arg0 pagerduty.ListServiceOptions) (*pagerduty.ListServiceResponse, error) {
+// ListServicesWithContext mocks base method.
+func (m *MockPdClient) ListServicesWithContext(ctx context.Context, o pagerduty.ListServiceOptions) (*pagerduty.ListServiceResponse, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "ListServices", arg0)
+	ret := m.ctrl.Call(m, "ListServicesWithContext", ctx, o)
 	ret0, _ := ret[0].(*pagerduty.ListServiceResponse)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// ListServices indicates an expected call of ListServices.
-func (mr *MockPdClientMockRecorder) ListServices(arg0 any) *gomock.Call {
+// ListServicesWithContext indicates an expected call of ListServicesWithContext.
+func (mr *MockPdClientMockRecorder) ListServicesWithContext(ctx, o any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListServices", reflect.TypeOf((*MockPdClient)(nil).ListServices), arg0)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListServicesWithContext", reflect.TypeOf((*MockPdClient)(nil).ListServicesWithContext), ctx, o)
 }
 
-// ManageEvent mocks base method.
-func (m *MockPdClient) ManageEvent(e *pagerduty.V2Event) (*pagerduty.V2EventResponse, error) {
+// ManageEventWithContext mocks base method.
+func (m *MockPdClient) ManageEventWithContext(ctx context.Context, e *pagerduty.V2Event) (*pagerduty.V2EventResponse, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "ManageEvent", e)
+	ret := m.ctrl.Call(m, "ManageEventWithContext", ctx, e)
 	ret0, _ := ret[0].(*pagerduty.V2EventResponse)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// ManageEvent indicates an expected call of ManageEvent.
-func (mr *MockPdClientMockRecorder) ManageEvent(e any) *gomock.Call {
+// ManageEventWithContext indicates an expected call of ManageEventWithContext.
+func (mr *MockPdClientMockRecorder) ManageEventWithContext(ctx, e any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ManageEvent", reflect.TypeOf((*MockPdClient)(nil).ManageEvent), e)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ManageEventWithContext", reflect.TypeOf((*MockPdClient)(nil).ManageEventWithContext), ctx, e)
 }
 
-// UpdateService mocks base method.
-func (m *MockPdClient) UpdateService(service pagerduty.Service) (*pagerduty.Service, error) {
+// UpdateServiceWithContext mocks base method.
+func (m *MockPdClient) UpdateServiceWithContext(ctx context.Context, service pagerduty.Service) (*pagerduty.Service, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "UpdateService", service)
+	ret := m.ctrl.Call(m, "UpdateServiceWithContext", ctx, service)
 	ret0, _ := ret[0].(*pagerduty.Service)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// UpdateService indicates an expected call of UpdateService.
-func (mr *MockPdClientMockRecorder) UpdateService(service any) *gomock.Call {
+// UpdateServiceWithContext indicates an expected call of UpdateServiceWithContext.
+func (mr *MockPdClientMockRecorder) UpdateServiceWithContext(ctx, service any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateService", reflect.TypeOf((*MockPdClient)(nil).UpdateService), service)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateServiceWithContext", reflect.TypeOf((*MockPdClient)(nil).UpdateServiceWithContext), ctx, service)
 }
@@ -0,0 +1,127 @@
+// Copyright 2019 RedHat
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pagerduty
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	pdApi "github.com/PagerDuty/go-pagerduty"
+	"golang.org/x/oauth2"
+	"golang.org/x/oauth2/clientcredentials"
+)
+
+// tokenRefreshMargin is how long before it expires an OAuth access token is replaced,
+// so that a request held back by the rate limiter isn't sent with an expired token
+const tokenRefreshMargin = 5 * time.Minute
+
+// OAuthCredentials are the client credentials of a PagerDuty scoped OAuth app
+type OAuthCredentials struct {
+	ClientID     string
+	ClientSecret string
+	// Scope is requested for the access tokens. PD requires it to name the account,
+	// e.g. "as_account-us.example services.read services.write".
+	Scope string
+}
+
+// tokenSourceFunc turns a function into an oauth2.TokenSource
+type tokenSourceFunc func() (*oauth2.Token, error)
+
+func (f tokenSourceFunc) Token() (*oauth2.Token, error) {
+	return f()
+}
+
+// newOAuthTokenSource returns the access tokens of an OAuth app, exchanging its client
+// credentials at tokenURL. Tokens are cached until tokenRefreshMargin before they expire.
+func newOAuthTokenSource(credentials OAuthCredentials, tokenURL string) oauth2.TokenSource {
+	config := clientcredentials.Config{
+		ClientID:     credentials.ClientID,
+		ClientSecret: credentials.ClientSecret,
+		TokenURL:     tokenURL,
+		Scopes:       strings.Fields(credentials.Scope),
+		AuthStyle:    oauth2.AuthStyleInParams,
+	}
+	// config.Token exchanges the credentials on every call, unlike config.TokenSource
+	// whose cached tokens are only replaced a few seconds before they expire
+	exchange := tokenSourceFunc(func() (*oauth2.Token, error) {
+		return config.Token(context.Background())
+	})
+	return oauth2.ReuseTokenSourceWithExpiry(nil, exchange, tokenRefreshMargin)
+}
+
+// oauthHTTPClient sends each request with an access token of an OAuth app rather than
+// an API key
+type oauthHTTPClient struct {
+	pdApi.HTTPClient
+	tokens oauth2.TokenSource
+}
+
+// Do replaces the Authorization header of req with the current access token. When PD
+// refuses to issue a token, its answer is returned as the response to req, so refused
+// credentials are reported like a rejected API key.
+func (c oauthHTTPClient) Do(req *http.Request) (*http.Response, error) {
+	token, err := c.tokens.Token()
+	if err != nil {
+		var retrieveErr *oauth2.RetrieveError
+		if errors.As(err, &retrieveErr) && retrieveErr.Response != nil {
+			return tokenErrorResponse(req, retrieveErr), nil
+		}
+		return nil, fmt.Errorf("unable to get PagerDuty OAuth access token: %w", err)
+	}
+
+	req.Header.Set("Authorization", "Bearer "+token.AccessToken)
+	return c.HTTPClient.Do(req)
+}
+
+// tokenErrorResponse turns the answer of the token endpoint into a response to req. PD
+// refuses unknown clients and scopes with 400 or 401, both mean the credentials can't be
+// used.
+func tokenErrorResponse(req *http.Request, retrieveErr *oauth2.RetrieveError) *http.Response {
+	statusCode := retrieveErr.Response.StatusCode
+	if statusCode == http.StatusBadRequest {
+		statusCode = http.StatusUnauthorized
+	}
+	return &http.Response{
+		Status:     fmt.Sprintf("%d %s", statusCode, http.StatusText(statusCode)),
+		StatusCode: statusCode,
+		Header:     retrieveErr.Response.Header,
+		Body:       io.NopCloser(bytes.NewReader(retrieveErr.Body)),
+		Request:    req,
+	}
+}
+
+// withAuthentication wraps httpClient so that its requests are sent with the access
+// tokens of an OAuth app. Without tokens, i.e. with an API key, which the pdApi.Client
+// and pdHttpRequest already send, httpClient is returned as is.
+func withAuthentication(tokens oauth2.TokenSource, httpClient pdApi.HTTPClient) pdApi.HTTPClient {
+	if tokens == nil {
+		return httpClient
+	}
+	return oauthHTTPClient{HTTPClient: httpClient, tokens: tokens}
+}
+
+// withOAuth makes the pdApi.Client send its requests with the access tokens of an OAuth
+// app, when tokens is set
+func withOAuth(tokens oauth2.TokenSource) pdApi.ClientOptions {
+	return func(c *pdApi.Client) {
+		c.HTTPClient = withAuthentication(tokens, c.HTTPClient)
+	}
+}
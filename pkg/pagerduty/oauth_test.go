@@ -0,0 +1,145 @@
+package pagerduty
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// oauthMockApi is a PD API that only accepts the access tokens it issued
+type oauthMockApi struct {
+	server *httptest.Server
+	// exchanges counts the issued tokens
+	exchanges atomic.Int32
+	// authorized counts the API requests sent with an issued token
+	authorized atomic.Int32
+}
+
+// newOAuthMockApi issues tokens valid for expiresIn seconds to the client "client-id",
+// or answers the token requests with tokenStatus when it is set
+func newOAuthMockApi(t *testing.T, expiresIn int, tokenStatus int) *oauthMockApi {
+	m := &oauthMockApi{}
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /oauth/token", func(w http.ResponseWriter, r *http.Request) {
+		assert.NoError(t, r.ParseForm())
+		assert.Equal(t, "client_credentials", r.PostForm.Get("grant_type"))
+		assert.Equal(t, "as_account-us.example services.write", r.PostForm.Get("scope"))
+		w.Header().Set("Content-Type", "application/json")
+		if tokenStatus != 0 || r.PostForm.Get("client_id") != "client-id" || r.PostForm.Get("client_secret") != "client-secret" {
+			if tokenStatus == 0 {
+				tokenStatus = http.StatusUnauthorized
+			}
+			w.WriteHeader(tokenStatus)
+			_, _ = w.Write([]byte(`{"error": "invalid_client"}`))
+			return
+		}
+		n := m.exchanges.Add(1)
+		_ = json.NewEncoder(w).Encode(map[string]any{
+			"access_token": fmt.Sprintf("token-%d", n),
+			"token_type":   "bearer",
+			"expires_in":   expiresIn,
+		})
+	})
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != fmt.Sprintf("Bearer token-%d", m.exchanges.Load()) {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		m.authorized.Add(1)
+		w.Header().Set("Content-Type", "application/json")
+		switch {
+		case r.URL.Path == "/abilities":
+			_, _ = w.Write([]byte(`{"abilities": ["teams"]}`))
+		case r.URL.Path == "/services/"+testServiceID:
+			_, _ = w.Write([]byte(`{"service": {"id": "` + testServiceID + `"}}`))
+		default:
+			_, _ = w.Write([]byte(`{}`))
+		}
+	})
+	m.server = httptest.NewServer(mux)
+	t.Cleanup(m.server.Close)
+	return m
+}
+
+const testServiceID = "SVC123"
+
+func (m *oauthMockApi) account(clientSecret string) Account {
+	return Account{
+		OAuth: &OAuthCredentials{
+			ClientID:     "client-id",
+			ClientSecret: clientSecret,
+			Scope:        "as_account-us.example services.write",
+		},
+		Endpoint: Endpoint{APIURL: m.server.URL, EventsURL: m.server.URL, TokenURL: m.server.URL + "/oauth/token"},
+	}
+}
+
+func TestNewClient_OAuth(t *testing.T) {
+	api := newOAuthMockApi(t, 3600, 0)
+	client := newSvcClient(api.account("client-secret"), "test")
+
+	assert.NoError(t, client.ValidateAPIKey(context.TODO()))
+	// the raw HTTP calls are authenticated with the same token
+	assert.NoError(t, client.ToggleServiceOrchestration(context.TODO(), &Data{ServiceID: testServiceID}, true))
+
+	assert.Equal(t, int32(1), api.exchanges.Load())
+	assert.Equal(t, int32(3), api.authorized.Load())
+}
+
+func TestNewClient_OAuthTokenRefresh(t *testing.T) {
+	// a token expiring within tokenRefreshMargin is replaced before every request
+	api := newOAuthMockApi(t, 60, 0)
+	client := newSvcClient(api.account("client-secret"), "test")
+
+	assert.NoError(t, client.ValidateAPIKey(context.TODO()))
+	assert.NoError(t, client.ValidateAPIKey(context.TODO()))
+
+	assert.Equal(t, int32(2), api.exchanges.Load())
+	assert.Equal(t, int32(2), api.authorized.Load())
+}
+
+func TestNewClient_OAuthRejected(t *testing.T) {
+	tests := []struct {
+		name         string
+		clientSecret string
+		tokenStatus  int
+		expectKind   error
+	}{
+		{
+			name:         "Unknown client",
+			clientSecret: "wrong-secret",
+			expectKind:   ErrUnauthorized,
+		},
+		{
+			name:         "Invalid scope",
+			clientSecret: "client-secret",
+			tokenStatus:  http.StatusBadRequest,
+			expectKind:   ErrUnauthorized,
+		},
+		{
+			name:         "Identity service unavailable",
+			clientSecret: "client-secret",
+			tokenStatus:  http.StatusServiceUnavailable,
+			expectKind:   ErrTransient,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			api := newOAuthMockApi(t, 3600, test.tokenStatus)
+			client := newSvcClient(api.account(test.clientSecret), "test")
+
+			err := client.ValidateAPIKey(context.TODO())
+			assert.ErrorIs(t, err, test.expectKind)
+			err = client.ToggleServiceOrchestration(context.TODO(), &Data{ServiceID: testServiceID}, true)
+			assert.ErrorIs(t, err, test.expectKind)
+			assert.Zero(t, api.authorized.Load())
+		})
+	}
+}
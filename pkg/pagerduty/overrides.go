@@ -0,0 +1,121 @@
+// Copyright 2019 RedHat
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pagerduty
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+
+	hivev1 "github.com/openshift/hive/apis/hive/v1"
+	pagerdutyv1alpha1 "github.com/openshift/pagerduty-operator/api/v1alpha1"
+	"github.com/openshift/pagerduty-operator/config"
+)
+
+// maxAlertGroupingTimeout is the longest time based alert grouping window PD accepts, in minutes
+const maxAlertGroupingTimeout = 1440
+
+// escalationPolicyIDPattern matches the IDs of PD objects
+var escalationPolicyIDPattern = regexp.MustCompile(`^P[A-Z0-9]+$`)
+
+// clusterOverrides are the settings of the PagerDutyIntegration overridden by the
+// annotations of a ClusterDeployment. Unset fields aren't overridden.
+type clusterOverrides struct {
+	resolveTimeout       *uint
+	acknowledgeTimeout   *uint
+	alertGroupingTimeout *uint
+	escalationPolicy     string
+}
+
+// parseClusterOverrides validates the config.OverrideAnnotationPrefix annotations of cd. It
+// returns the accepted overrides, and the status of every override annotation sorted by
+// annotation. Unknown annotations with the prefix are rejected, so typos are reported.
+func parseClusterOverrides(cd *hivev1.ClusterDeployment) (clusterOverrides, []pagerdutyv1alpha1.ClusterOverride) {
+	var annotations []string
+	for annotation := range cd.Annotations {
+		if strings.HasPrefix(annotation, config.OverrideAnnotationPrefix) {
+			annotations = append(annotations, annotation)
+		}
+	}
+	slices.Sort(annotations)
+
+	var overrides clusterOverrides
+	var statuses []pagerdutyv1alpha1.ClusterOverride
+	for _, annotation := range annotations {
+		value := cd.Annotations[annotation]
+		var err error
+		switch annotation {
+		case config.ResolveTimeoutOverrideAnnotation:
+			overrides.resolveTimeout, err = parseTimeoutOverride(value, 0)
+		case config.AcknowledgeTimeoutOverrideAnnotation:
+			overrides.acknowledgeTimeout, err = parseTimeoutOverride(value, 0)
+		case config.AlertGroupingTimeoutOverrideAnnotation:
+			overrides.alertGroupingTimeout, err = parseTimeoutOverride(value, maxAlertGroupingTimeout)
+		case config.EscalationPolicyOverrideAnnotation:
+			if escalationPolicyIDPattern.MatchString(value) {
+				overrides.escalationPolicy = value
+			} else {
+				err = fmt.Errorf("%q is not a PagerDuty escalation policy ID", value)
+			}
+		default:
+			err = fmt.Errorf("unknown override")
+		}
+
+		status := pagerdutyv1alpha1.ClusterOverride{Annotation: annotation, Value: value, Accepted: err == nil}
+		if err != nil {
+			status.Message = err.Error()
+		}
+		statuses = append(statuses, status)
+	}
+
+	return overrides, statuses
+}
+
+// parseTimeoutOverride parses a timeout that must not be negative, nor greater than max if
+// max isn't 0
+func parseTimeoutOverride(value string, max uint) (*uint, error) {
+	timeout, err := strconv.ParseUint(value, 10, 0)
+	if err != nil {
+		return nil, fmt.Errorf("%q is not a timeout, it must be a whole number", value)
+	}
+	if max != 0 && uint(timeout) > max {
+		return nil, fmt.Errorf("%d exceeds the maximum of %d", timeout, max)
+	}
+	t := uint(timeout)
+	return &t, nil
+}
+
+// applyOverrides sets the settings in data overridden by the annotations of cd, and
+// records the status of the override annotations in data. The escalation policy
+// override is resolved by EscalationPolicyFor.
+func (data *Data) applyOverrides(cd *hivev1.ClusterDeployment) {
+	overrides, statuses := parseClusterOverrides(cd)
+	data.Overrides = statuses
+
+	if overrides.resolveTimeout != nil {
+		data.ResolveTimeout = *overrides.resolveTimeout
+	}
+	if overrides.acknowledgeTimeout != nil {
+		data.AcknowledgeTimeOut = *overrides.acknowledgeTimeout
+	}
+	if overrides.alertGroupingTimeout != nil {
+		if data.AlertGroupingType == "" {
+			data.AlertGroupingType = "time"
+		}
+		data.AlertGroupingTimeout = *overrides.alertGroupingTimeout
+	}
+}
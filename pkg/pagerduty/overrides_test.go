@@ -0,0 +1,122 @@
+package pagerduty
+
+import (
+	"testing"
+
+	pagerdutyv1alpha1 "github.com/openshift/pagerduty-operator/api/v1alpha1"
+	"github.com/openshift/pagerduty-operator/config"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewData_Overrides(t *testing.T) {
+	tests := []struct {
+		name                         string
+		annotations                  map[string]string
+		alertGrouping                *pagerdutyv1alpha1.AlertGroupingParametersSpec
+		expectedResolveTimeout       uint
+		expectedAcknowledgeTimeout   uint
+		expectedAlertGroupingType    string
+		expectedAlertGroupingTimeout uint
+		expectedEscalationPolicy     string
+		expectedAccepted             map[string]bool
+	}{
+		{
+			name:                       "No overrides",
+			expectedResolveTimeout:     300,
+			expectedAcknowledgeTimeout: 1800,
+			expectedEscalationPolicy:   mockEscalationPolicyId,
+		},
+		{
+			name: "Valid overrides",
+			annotations: map[string]string{
+				config.ResolveTimeoutOverrideAnnotation:       "0",
+				config.AcknowledgeTimeoutOverrideAnnotation:   "3600",
+				config.AlertGroupingTimeoutOverrideAnnotation: "15",
+				config.EscalationPolicyOverrideAnnotation:     "PA2GM5L",
+			},
+			expectedResolveTimeout:       0,
+			expectedAcknowledgeTimeout:   3600,
+			expectedAlertGroupingType:    "time",
+			expectedAlertGroupingTimeout: 15,
+			expectedEscalationPolicy:     "PA2GM5L",
+			expectedAccepted: map[string]bool{
+				config.ResolveTimeoutOverrideAnnotation:       true,
+				config.AcknowledgeTimeoutOverrideAnnotation:   true,
+				config.AlertGroupingTimeoutOverrideAnnotation: true,
+				config.EscalationPolicyOverrideAnnotation:     true,
+			},
+		},
+		{
+			name: "Alert grouping window keeps the grouping type",
+			annotations: map[string]string{
+				config.AlertGroupingTimeoutOverrideAnnotation: "30",
+			},
+			alertGrouping: &pagerdutyv1alpha1.AlertGroupingParametersSpec{
+				Type:   "intelligent",
+				Config: &pagerdutyv1alpha1.AlertGroupingParametersConfigSpec{Timeout: 5},
+			},
+			expectedResolveTimeout:       300,
+			expectedAcknowledgeTimeout:   1800,
+			expectedAlertGroupingType:    "intelligent",
+			expectedAlertGroupingTimeout: 30,
+			expectedEscalationPolicy:     mockEscalationPolicyId,
+			expectedAccepted: map[string]bool{
+				config.AlertGroupingTimeoutOverrideAnnotation: true,
+			},
+		},
+		{
+			name: "Invalid overrides are rejected",
+			annotations: map[string]string{
+				config.ResolveTimeoutOverrideAnnotation:            "-1",
+				config.AcknowledgeTimeoutOverrideAnnotation:        "1h",
+				config.AlertGroupingTimeoutOverrideAnnotation:      "1441",
+				config.EscalationPolicyOverrideAnnotation:          "silent",
+				config.OverrideAnnotationPrefix + "resolve-timout": "600",
+				"other.openshift.io/resolve-timeout":               "600",
+			},
+			expectedResolveTimeout:     300,
+			expectedAcknowledgeTimeout: 1800,
+			expectedEscalationPolicy:   mockEscalationPolicyId,
+			expectedAccepted: map[string]bool{
+				config.ResolveTimeoutOverrideAnnotation:            false,
+				config.AcknowledgeTimeoutOverrideAnnotation:        false,
+				config.AlertGroupingTimeoutOverrideAnnotation:      false,
+				config.EscalationPolicyOverrideAnnotation:          false,
+				config.OverrideAnnotationPrefix + "resolve-timout": false,
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			pdi := &pagerdutyv1alpha1.PagerDutyIntegration{
+				Spec: pagerdutyv1alpha1.PagerDutyIntegrationSpec{
+					EscalationPolicy:        mockEscalationPolicyId,
+					ResolveTimeout:          300,
+					AcknowledgeTimeout:      1800,
+					AlertGroupingParameters: test.alertGrouping,
+				},
+			}
+			cd := testClusterDeployment()
+			cd.Annotations = test.annotations
+
+			data, err := NewData(pdi, cd, mockClusterId, false)
+			assert.NoError(t, err)
+			assert.Equal(t, test.expectedResolveTimeout, data.ResolveTimeout)
+			assert.Equal(t, test.expectedAcknowledgeTimeout, data.AcknowledgeTimeOut)
+			assert.Equal(t, test.expectedAlertGroupingType, data.AlertGroupingType)
+			assert.Equal(t, test.expectedAlertGroupingTimeout, data.AlertGroupingTimeout)
+			assert.Equal(t, test.expectedEscalationPolicy, data.EscalationPolicyID)
+
+			accepted := map[string]bool{}
+			for _, override := range data.Overrides {
+				accepted[override.Annotation] = override.Accepted
+				assert.Equal(t, override.Accepted, override.Message == "", override.Annotation)
+			}
+			if test.expectedAccepted == nil {
+				test.expectedAccepted = map[string]bool{}
+			}
+			assert.Equal(t, test.expectedAccepted, accepted)
+		})
+	}
+}
@@ -0,0 +1,287 @@
+// Copyright 2019 RedHat
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pagerduty
+
+import (
+	"context"
+	"crypto/sha256"
+	"io"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	pdApi "github.com/PagerDuty/go-pagerduty"
+	"github.com/openshift/pagerduty-operator/pkg/localmetrics"
+	"golang.org/x/time/rate"
+)
+
+const (
+	// defaultRequestsPerSecond stays below the PD REST API limit of 960 requests per
+	// minute per API key, leaving room for other users of the same key
+	defaultRequestsPerSecond = 12
+
+	// maxRetries is how often a rate limited request is retried before the 429 is returned
+	maxRetries = 3
+
+	// maxRetryWait is the longest PD may ask us to wait for a retry. Requests that would
+	// have to wait longer fail with ErrRateLimited, so the reconcile is requeued instead
+	// of blocking a worker.
+	maxRetryWait = 30 * time.Second
+
+	// defaultRetryWait is used when PD doesn't say how long to wait
+	defaultRetryWait = 2 * time.Second
+)
+
+// apiKeyLimiter throttles the requests made with a single API key
+type apiKeyLimiter struct {
+	limiter *rate.Limiter
+
+	mu sync.Mutex
+	// pausedUntil is set when PD reports the rate limit is exhausted
+	pausedUntil time.Time
+	// quota is the rate limit budget PD last reported, if it did
+	quota *RateLimitQuota
+}
+
+// RateLimitQuota is the rate limit budget of an API key as last reported by PD
+type RateLimitQuota struct {
+	// Remaining is how many requests are left in the current window
+	Remaining int
+	// ResetTime is when the current window ends
+	ResetTime time.Time
+}
+
+var (
+	limitersMu sync.Mutex
+	// limiters are keyed by the hash of the API key, so every SvcClient using the
+	// same key shares the same budget
+	limiters = map[[sha256.Size]byte]*apiKeyLimiter{}
+)
+
+// limiterFor returns the limiter shared by every client using apiKey. The budget of the
+// key is set to requestsPerSecond, or defaultRequestsPerSecond when it is zero.
+func limiterFor(apiKey string, requestsPerSecond int) *apiKeyLimiter {
+	key := sha256.Sum256([]byte(apiKey))
+	if requestsPerSecond <= 0 {
+		requestsPerSecond = defaultRequestsPerSecond
+	}
+
+	limitersMu.Lock()
+	defer limitersMu.Unlock()
+	l, ok := limiters[key]
+	if !ok {
+		l = &apiKeyLimiter{limiter: rate.NewLimiter(rate.Limit(requestsPerSecond), requestsPerSecond)}
+		limiters[key] = l
+	} else if l.limiter.Burst() != requestsPerSecond {
+		// the budget of the PagerDutyAccount changed
+		l.limiter.SetLimit(rate.Limit(requestsPerSecond))
+		l.limiter.SetBurst(requestsPerSecond)
+	}
+	return l
+}
+
+// QuotaOf returns the rate limit budget PD last reported for account, false if no
+// request was sent for the account yet or PD didn't report it
+func QuotaOf(account Account) (RateLimitQuota, bool) {
+	limitersMu.Lock()
+	l, ok := limiters[sha256.Sum256([]byte(account.rateLimitKey()))]
+	limitersMu.Unlock()
+	if !ok {
+		return RateLimitQuota{}, false
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.quota == nil {
+		return RateLimitQuota{}, false
+	}
+	return *l.quota, true
+}
+
+// wait blocks until the request may be sent, returning true if it had to wait
+func (l *apiKeyLimiter) wait(ctx context.Context) (bool, error) {
+	throttled := false
+
+	if pause := l.remainingPause(); pause > 0 {
+		throttled = true
+		if err := sleep(ctx, pause); err != nil {
+			return throttled, err
+		}
+	}
+
+	r := l.limiter.Reserve()
+	if delay := r.Delay(); delay > 0 {
+		throttled = true
+		if err := sleep(ctx, delay); err != nil {
+			r.Cancel()
+			return throttled, err
+		}
+	}
+	return throttled, nil
+}
+
+// remainingPause returns how long requests are still held back, at most maxRetryWait. PD
+// may report a rate limit window resetting much later, a worker must not block that long.
+func (l *apiKeyLimiter) remainingPause() time.Duration {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return min(time.Until(l.pausedUntil), maxRetryWait)
+}
+
+// observe records the rate limit budget PD reports in the headers of a response
+func (l *apiKeyLimiter) observe(header http.Header) {
+	remaining, err := strconv.Atoi(header.Get("Ratelimit-Remaining"))
+	if err != nil {
+		return
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.quota = &RateLimitQuota{
+		Remaining: remaining,
+		ResetTime: time.Now().Add(headerSeconds(header, "Ratelimit-Reset")),
+	}
+}
+
+// pause holds back every request until PD's rate limit window resets
+func (l *apiKeyLimiter) pause(d time.Duration) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if until := time.Now().Add(d); until.After(l.pausedUntil) {
+		l.pausedUntil = until
+	}
+}
+
+// rateLimitedHTTPClient sends requests through the limiter of its API key and retries
+// the ones PD rejects with 429 Too Many Requests
+type rateLimitedHTTPClient struct {
+	pdApi.HTTPClient
+	limiter *apiKeyLimiter
+}
+
+// Do waits for the limiter, then sends the request, retrying it up to maxRetries times
+// when it is rate limited
+func (c rateLimitedHTTPClient) Do(req *http.Request) (*http.Response, error) {
+	for attempt := 0; ; attempt++ {
+		throttled, err := c.limiter.wait(req.Context())
+		if throttled {
+			localmetrics.AddMetricPagerDutyAPIThrottled()
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		resp, err := c.HTTPClient.Do(req)
+		if err != nil {
+			return resp, err
+		}
+
+		recordResponseHeader(req.Context(), resp.Header)
+
+		// PD sends ratelimit-remaining/ratelimit-reset with every response, stop
+		// before the budget is exhausted rather than after
+		c.limiter.observe(resp.Header)
+		if resp.Header.Get("Ratelimit-Remaining") == "0" {
+			c.limiter.pause(headerSeconds(resp.Header, "Ratelimit-Reset"))
+		}
+
+		if resp.StatusCode != http.StatusTooManyRequests {
+			return resp, nil
+		}
+
+		wait := parseRetryAfter(resp.Header)
+		if wait == 0 {
+			wait = defaultRetryWait << attempt
+		}
+		c.limiter.pause(wait)
+
+		if attempt >= maxRetries || wait > maxRetryWait || !rewindBody(req) {
+			return resp, nil
+		}
+
+		// drain the body so the connection can be reused
+		_, _ = io.Copy(io.Discard, resp.Body)
+		_ = resp.Body.Close()
+		localmetrics.AddMetricPagerDutyAPIRetried()
+	}
+}
+
+// responseHeaderKey is the context key of the headers of the last response of a call
+type responseHeaderKey struct{}
+
+// withResponseHeader returns a copy of ctx in which the rate limited HTTP client records
+// the headers of the responses it receives, so they can be attached to the error of a call
+func withResponseHeader(ctx context.Context) context.Context {
+	return context.WithValue(ctx, responseHeaderKey{}, new(http.Header))
+}
+
+func recordResponseHeader(ctx context.Context, header http.Header) {
+	if h, ok := ctx.Value(responseHeaderKey{}).(*http.Header); ok {
+		*h = header
+	}
+}
+
+// responseHeaderOf returns the headers of the last response received with ctx, nil if there
+// was none
+func responseHeaderOf(ctx context.Context) http.Header {
+	if h, ok := ctx.Value(responseHeaderKey{}).(*http.Header); ok {
+		return *h
+	}
+	return nil
+}
+
+// rewindBody resets the body of req so it can be sent again
+func rewindBody(req *http.Request) bool {
+	if req.Body == nil || req.Body == http.NoBody {
+		return true
+	}
+	if req.GetBody == nil {
+		return false
+	}
+	body, err := req.GetBody()
+	if err != nil {
+		return false
+	}
+	req.Body = body
+	return true
+}
+
+// WithRateLimit makes the pdApi.Client share the rate limit budget of apiKey with
+// every other client using the same key
+func WithRateLimit(apiKey string, requestsPerSecond int) pdApi.ClientOptions {
+	return func(c *pdApi.Client) {
+		c.HTTPClient = newRateLimitedHTTPClient(apiKey, requestsPerSecond, c.HTTPClient)
+	}
+}
+
+func newRateLimitedHTTPClient(apiKey string, requestsPerSecond int, httpClient pdApi.HTTPClient) pdApi.HTTPClient {
+	return rateLimitedHTTPClient{
+		HTTPClient: httpClient,
+		limiter:    limiterFor(apiKey, requestsPerSecond),
+	}
+}
+
+// sleep waits for d or until ctx is done
+func sleep(ctx context.Context, d time.Duration) error {
+	t := time.NewTimer(d)
+	defer t.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-t.C:
+		return nil
+	}
+}
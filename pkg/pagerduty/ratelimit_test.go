@@ -0,0 +1,149 @@
+package pagerduty
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/time/rate"
+)
+
+func TestLimiterFor(t *testing.T) {
+	assert.Same(t, limiterFor("key-a", 0), limiterFor("key-a", 0))
+	assert.NotSame(t, limiterFor("key-a", 0), limiterFor("key-b", 0))
+}
+
+func TestLimiterFor_RequestsPerSecond(t *testing.T) {
+	l := limiterFor(t.Name(), 0)
+	assert.Equal(t, defaultRequestsPerSecond, l.limiter.Burst())
+
+	// the budget follows the PagerDutyAccount
+	assert.Same(t, l, limiterFor(t.Name(), 4))
+	assert.Equal(t, 4, l.limiter.Burst())
+	assert.Equal(t, rate.Limit(4), l.limiter.Limit())
+}
+
+func TestQuotaOf(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Ratelimit-Remaining", "42")
+		w.Header().Set("Ratelimit-Reset", "30")
+	}))
+	defer server.Close()
+
+	_, ok := QuotaOf(Account{APIKey: t.Name()})
+	assert.False(t, ok)
+
+	c := newRateLimitedHTTPClient(t.Name(), 0, http.DefaultClient)
+	req, err := http.NewRequest("GET", server.URL, nil)
+	assert.NoError(t, err)
+	resp, err := c.Do(req)
+	assert.NoError(t, err)
+	resp.Body.Close()
+
+	quota, ok := QuotaOf(Account{APIKey: t.Name()})
+	assert.True(t, ok)
+	assert.Equal(t, 42, quota.Remaining)
+	assert.WithinDuration(t, time.Now().Add(30*time.Second), quota.ResetTime, 5*time.Second)
+}
+
+func TestRateLimitedHTTPClient_Do(t *testing.T) {
+	tests := []struct {
+		name             string
+		responses        []int
+		retryAfter       string
+		expectedStatus   int
+		expectedRequests int
+	}{
+		{
+			name:             "Not rate limited",
+			responses:        []int{http.StatusOK},
+			expectedStatus:   http.StatusOK,
+			expectedRequests: 1,
+		},
+		{
+			name:             "Rate limited, then retried",
+			responses:        []int{http.StatusTooManyRequests, http.StatusOK},
+			retryAfter:       "1",
+			expectedStatus:   http.StatusOK,
+			expectedRequests: 2,
+		},
+		{
+			name:             "Retry-After too long, not retried",
+			responses:        []int{http.StatusTooManyRequests, http.StatusOK},
+			retryAfter:       "120",
+			expectedStatus:   http.StatusTooManyRequests,
+			expectedRequests: 1,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var bodies []string
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				body, _ := io.ReadAll(r.Body)
+				bodies = append(bodies, string(body))
+				status := test.responses[len(bodies)-1]
+				if status == http.StatusTooManyRequests {
+					w.Header().Set("Retry-After", test.retryAfter)
+				}
+				w.WriteHeader(status)
+			}))
+			defer server.Close()
+
+			// every test uses its own key so the pauses don't leak into other tests
+			c := newRateLimitedHTTPClient(t.Name(), 0, http.DefaultClient)
+			req, err := http.NewRequest("PUT", server.URL, strings.NewReader("payload"))
+			assert.NoError(t, err)
+
+			resp, err := c.Do(req)
+			assert.NoError(t, err)
+			defer resp.Body.Close()
+
+			assert.Equal(t, test.expectedStatus, resp.StatusCode)
+			assert.Len(t, bodies, test.expectedRequests)
+			for _, body := range bodies {
+				assert.Equal(t, "payload", body)
+			}
+		})
+	}
+}
+
+func TestRateLimitedHTTPClient_PausesWhenBudgetExhausted(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Ratelimit-Remaining", "0")
+		w.Header().Set("Ratelimit-Reset", "60")
+	}))
+	defer server.Close()
+
+	c := newRateLimitedHTTPClient(t.Name(), 0, http.DefaultClient)
+	req, err := http.NewRequest("GET", server.URL, nil)
+	assert.NoError(t, err)
+	resp, err := c.Do(req)
+	assert.NoError(t, err)
+	resp.Body.Close()
+
+	// the next request has to wait for the window to reset, give up on it early
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	req, err = http.NewRequestWithContext(ctx, "GET", server.URL, nil)
+	assert.NoError(t, err)
+	_, err = c.Do(req)
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+}
+
+func TestAPIKeyLimiter_RemainingPause(t *testing.T) {
+	l := limiterFor(t.Name(), 0)
+	assert.LessOrEqual(t, l.remainingPause(), time.Duration(0))
+
+	l.pause(10 * time.Second)
+	assert.InDelta(t, 10*time.Second, l.remainingPause(), float64(time.Second))
+
+	// a rate limit window resetting in an hour doesn't block the caller for an hour
+	l.pause(time.Hour)
+	assert.Equal(t, maxRetryWait, l.remainingPause())
+}
@@ -18,6 +18,7 @@ package pagerduty
 
 import (
 	"context"
+	"crypto/sha256"
 	"fmt"
 	"net/http"
 	"strconv"
@@ -27,16 +28,17 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/log"
 
 	pdApi "github.com/PagerDuty/go-pagerduty"
+	hivev1 "github.com/openshift/hive/apis/hive/v1"
 	pagerdutyv1alpha1 "github.com/openshift/pagerduty-operator/api/v1alpha1"
-	"github.com/openshift/pagerduty-operator/config"
 	"github.com/openshift/pagerduty-operator/pkg/localmetrics"
+	"github.com/openshift/pagerduty-operator/pkg/utils"
+	"golang.org/x/oauth2"
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/types"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 )
 
 const (
-	apiEndpoint                        string = "https://api.pagerduty.com/"
 	AlertResolvedSummaryDeleted        string = "Cluster does not exist anymore"
 	AlertResolvedSummaryLimitedSupport string = "The cluster has been placed in limited support"
 	integrationName                    string = "V4 Alertmanager"
@@ -58,40 +60,45 @@ func getConfigMapKey(data map[string]string, key string) (string, error) {
 
 // Client is a wrapper interface for the SvcClient to allow for easier testing
 type Client interface {
-	GetService(data *Data) (*pdApi.Service, error)
-	GetIntegrationKey(data *Data) (string, error)
-	CreateService(data *Data) (string, error)
-	DeleteService(data *Data) error
-	EnableService(data *Data) error
-	DisableService(data *Data) error
-	UpdateEscalationPolicy(data *Data) error
-	UpdateAlertGrouping(data *Data) error
-	ToggleServiceOrchestration(data *Data, active bool) error
-	ApplyServiceOrchestrationRule(data *Data) error
+	GetService(ctx context.Context, data *Data) (*pdApi.Service, error)
+	GetIntegrationKey(ctx context.Context, data *Data) (string, error)
+	CreateService(ctx context.Context, data *Data) (string, error)
+	CreateIntegration(ctx context.Context, data *Data) error
+	ResolvePendingIncidents(ctx context.Context, data *Data, summary string) error
+	CountUnresolvedIncidents(ctx context.Context, data *Data) (int, error)
+	DeleteService(ctx context.Context, data *Data) error
+	EnableService(ctx context.Context, data *Data) error
+	DisableService(ctx context.Context, data *Data) error
+	UpdateEscalationPolicy(ctx context.Context, data *Data) error
+	UpdateServiceSettings(ctx context.Context, data *Data) error
+	RestoreService(ctx context.Context, data *Data) error
+	ToggleServiceOrchestration(ctx context.Context, data *Data, active bool) error
+	ApplyServiceOrchestrationRule(ctx context.Context, data *Data) error
+	ValidateAPIKey(ctx context.Context) error
 }
 
 type PdClient interface {
-	GetService(string, *pdApi.GetServiceOptions) (*pdApi.Service, error)
-	GetEscalationPolicy(string, *pdApi.GetEscalationPolicyOptions) (*pdApi.EscalationPolicy, error)
-	GetIntegration(string, string, pdApi.GetIntegrationOptions) (*pdApi.Integration, error)
-	CreateService(service pdApi.Service) (*pdApi.Service, error)
-	DeleteService(id string) error
-	CreateIntegration(serviceID string, integration pdApi.Integration) (*pdApi.Integration, error)
-	ListServices(pdApi.ListServiceOptions) (*pdApi.ListServiceResponse, error)
-	ListIncidents(pdApi.ListIncidentsOptions) (*pdApi.ListIncidentsResponse, error)
-	ListIncidentAlertsWithOpts(incidentId string, o pdApi.ListIncidentAlertsOptions) (*pdApi.ListAlertsResponse, error)
-	ManageEvent(e *pdApi.V2Event) (*pdApi.V2EventResponse, error)
-	UpdateService(service pdApi.Service) (*pdApi.Service, error)
+	GetServiceWithContext(ctx context.Context, id string, o *pdApi.GetServiceOptions) (*pdApi.Service, error)
+	GetEscalationPolicyWithContext(ctx context.Context, id string, o *pdApi.GetEscalationPolicyOptions) (*pdApi.EscalationPolicy, error)
+	GetIntegrationWithContext(ctx context.Context, serviceID, integrationID string, o pdApi.GetIntegrationOptions) (*pdApi.Integration, error)
+	CreateServiceWithContext(ctx context.Context, service pdApi.Service) (*pdApi.Service, error)
+	DeleteServiceWithContext(ctx context.Context, id string) error
+	CreateIntegrationWithContext(ctx context.Context, serviceID string, integration pdApi.Integration) (*pdApi.Integration, error)
+	ListServicesWithContext(ctx context.Context, o pdApi.ListServiceOptions) (*pdApi.ListServiceResponse, error)
+	ListIncidentsWithContext(ctx context.Context, o pdApi.ListIncidentsOptions) (*pdApi.ListIncidentsResponse, error)
+	ListIncidentAlertsWithContext(ctx context.Context, incidentId string, o pdApi.ListIncidentAlertsOptions) (*pdApi.ListAlertsResponse, error)
+	ManageEventWithContext(ctx context.Context, e *pdApi.V2Event) (*pdApi.V2EventResponse, error)
+	UpdateServiceWithContext(ctx context.Context, service pdApi.Service) (*pdApi.Service, error)
+	ListAbilitiesWithContext(ctx context.Context) (*pdApi.ListAbilityResponse, error)
 }
 
-type DelayFunc func(time.Duration)
-
 // SvcClient wraps pdApi.Client
 type SvcClient struct {
 	APIKey   string
 	PdClient PdClient
-	Delay    DelayFunc
 	BaseURL  string
+	// HTTPClient sends the requests the PdClient doesn't support, http.DefaultClient if nil
+	HTTPClient pdApi.HTTPClient
 }
 
 type customHTTPClient struct {
@@ -123,12 +130,51 @@ func WithCustomHTTPClient(controllerName string) pdApi.ClientOptions {
 }
 
 // NewClient creates out client wrapper object for the actual pdApi.Client we use.
-func NewClient(APIKey string, controllerName string) Client {
+// Every request, including events and the ones the pdApi.Client doesn't support, is
+// sent to the given endpoint.
+// Clients are shared by every caller using the same account and controller name.
+func NewClient(account Account, controllerName string) Client {
+	key := clientKey{
+		credentials:       sha256.Sum256([]byte(account.credentials())),
+		endpoint:          account.Endpoint,
+		requestsPerSecond: account.RequestsPerSecond,
+		controller:        controllerName,
+	}
+
+	clientsMu.Lock()
+	defer clientsMu.Unlock()
+	c, ok := clients[key]
+	if !ok {
+		c = newSvcClient(account, controllerName)
+		clients[key] = c
+	}
+	return c
+}
+
+func newSvcClient(account Account, controllerName string) *SvcClient {
+	// the access tokens of an OAuth app are shared by both HTTP clients
+	var tokens oauth2.TokenSource
+	if account.OAuth != nil {
+		tokens = newOAuthTokenSource(*account.OAuth, account.Endpoint.TokenURL)
+	}
+
+	// The rate limiter wraps the metrics client so every attempt is timed, and the
+	// access token is set last so a delayed attempt doesn't use an expired one
+	rateLimitKey := account.rateLimitKey()
 	return &SvcClient{
-		APIKey:   APIKey,
-		PdClient: pdApi.NewClient(APIKey, WithCustomHTTPClient(controllerName)),
-		Delay:    time.Sleep,
-		BaseURL:  apiEndpoint,
+		APIKey: account.APIKey,
+		PdClient: pdApi.NewClient(account.APIKey,
+			pdApi.WithAPIEndpoint(account.Endpoint.APIURL),
+			pdApi.WithV2EventsAPIEndpoint(account.Endpoint.EventsURL),
+			withOAuth(tokens),
+			WithCustomHTTPClient(controllerName),
+			WithRateLimit(rateLimitKey, account.RequestsPerSecond),
+		),
+		HTTPClient: newRateLimitedHTTPClient(rateLimitKey, account.RequestsPerSecond, customHTTPClient{
+			HTTPClient: withAuthentication(tokens, http.DefaultClient),
+			controller: controllerName,
+		}),
+		BaseURL: account.Endpoint.APIURL,
 	}
 }
 
@@ -144,8 +190,13 @@ type Data struct {
 	ClusterID  string
 	BaseDomain string
 
+	// ServiceName and ServiceDescription are rendered from the templates of the
+	// PagerDutyIntegration, they are empty when the default name and description are used
+	ServiceName        string
+	ServiceDescription string
+
 	// These fields are stored when the PagerDuty service is created and stored
-	// in a Configmap in the ClusterDeployment's namespace
+	// in a PagerDutyService in the ClusterDeployment's namespace
 	// There is also an EscalationPolicyID field which is parsed fron the PDI CR
 	ServiceID      string
 	IntegrationID  string
@@ -159,23 +210,37 @@ type Data struct {
 	AlertGroupingType    string `json:"alert_grouping_type,omitempty"`
 	AlertGroupingTimeout uint   `'json:"alert_grouping_timeout,omitempty"`
 
+	// IncidentUrgencyRule is nil when the default urgency rule is used
+	IncidentUrgencyRule *pagerdutyv1alpha1.IncidentUrgencyRuleSpec
+
+	// Overrides are the override annotations of the ClusterDeployment, the accepted ones
+	// are already applied to the other fields
+	Overrides []pagerdutyv1alpha1.ClusterOverride
+
 	IsFedramp bool
 }
 
-// NewData initializes a Data struct from a v1alpha1 PagerDutyIntegration spec
+// NewData initializes a Data struct from a v1alpha1 PagerDutyIntegration spec and the
+// ClusterDeployment the PD service is for. The override annotations of the ClusterDeployment
+// take precedence over the PagerDutyIntegration spec.
 // pdi.Spec.EscalationPolicy is required
-func NewData(pdi *pagerdutyv1alpha1.PagerDutyIntegration, clusterId string, baseDomain string, isFedramp bool) (*Data, error) {
+func NewData(pdi *pagerdutyv1alpha1.PagerDutyIntegration, cd *hivev1.ClusterDeployment, clusterId string, isFedramp bool) (*Data, error) {
 	if pdi.Spec.EscalationPolicy == "" {
 		return nil, fmt.Errorf("found empty escalation policy in the pagerdutyintegration spec")
 	}
 
+	escalationPolicyID, err := EscalationPolicyFor(pdi, cd)
+	if err != nil {
+		return nil, err
+	}
+
 	data := &Data{
-		EscalationPolicyID: pdi.Spec.EscalationPolicy,
+		EscalationPolicyID: escalationPolicyID,
 		ResolveTimeout:     pdi.Spec.ResolveTimeout,
 		AcknowledgeTimeOut: pdi.Spec.AcknowledgeTimeout,
 		ServicePrefix:      pdi.Spec.ServicePrefix,
 		ClusterID:          clusterId,
-		BaseDomain:         baseDomain,
+		BaseDomain:         cd.Spec.BaseDomain,
 		IsFedramp:          isFedramp,
 	}
 
@@ -184,101 +249,193 @@ func NewData(pdi *pagerdutyv1alpha1.PagerDutyIntegration, clusterId string, base
 		data.AlertGroupingTimeout = pdi.Spec.AlertGroupingParameters.Config.Timeout
 	}
 
+	data.applyOverrides(cd)
+
+	if err = validateUrgencyRule(pdi.Spec.IncidentUrgencyRule); err != nil {
+		return nil, err
+	}
+	data.IncidentUrgencyRule = pdi.Spec.IncidentUrgencyRule.DeepCopy()
+
+	if pdi.Spec.ServiceNameTemplate != "" || pdi.Spec.ServiceDescriptionTemplate != "" {
+		platform, region := utils.GetClusterPlatform(cd)
+		templateData := ServiceTemplateData{
+			ServicePrefix: data.ServicePrefix,
+			ClusterID:     data.ClusterID,
+			ClusterName:   cd.Spec.ClusterName,
+			BaseDomain:    data.BaseDomain,
+			Namespace:     cd.Namespace,
+			Platform:      platform,
+			Region:        region,
+			Labels:        cd.Labels,
+			Annotations:   cd.Annotations,
+		}
+
+		if pdi.Spec.ServiceNameTemplate != "" {
+			data.ServiceName, err = renderServiceName(pdi.Spec.ServiceNameTemplate, templateData)
+			if err != nil {
+				return nil, err
+			}
+		}
+		if pdi.Spec.ServiceDescriptionTemplate != "" {
+			data.ServiceDescription, err = renderServiceTemplate("serviceDescriptionTemplate", pdi.Spec.ServiceDescriptionTemplate, templateData)
+			if err != nil {
+				return nil, err
+			}
+		}
+	}
+
 	return data, nil
 }
 
-// ParseClusterConfig parses the cluster specific config map and stores the IDs in the data struct
-// SERVICE_ID and INTEGRATION_ID are required ConfigMap data fields
-// LIMITED_SUPPORT are optional.
-func (data *Data) ParseClusterConfig(osc client.Client, namespace string, cmName string) error {
-	pdAPIConfigMap := &corev1.ConfigMap{}
-	err := osc.Get(context.TODO(), types.NamespacedName{Namespace: namespace, Name: cmName}, pdAPIConfigMap)
+// ParseClusterConfig loads the cluster specific PagerDutyService and stores the IDs in the data struct
+func (data *Data) ParseClusterConfig(ctx context.Context, osc client.Client, namespace string, name string) error {
+	pdService := &pagerdutyv1alpha1.PagerDutyService{}
+	err := osc.Get(ctx, types.NamespacedName{Namespace: namespace, Name: name}, pdService)
 	if err != nil {
 		return err
 	}
 
-	data.ServiceID, err = getConfigMapKey(pdAPIConfigMap.Data, "SERVICE_ID")
-	if err != nil {
-		return err
-	}
+	data.ServiceID = pdService.Spec.ServiceID
+	data.IntegrationID = pdService.Spec.IntegrationID
+	data.EscalationPolicyID = pdService.Spec.EscalationPolicyID
+	data.LimitedSupport = pdService.Spec.LimitedSupport
+	data.ServiceOrchestrationEnabled = pdService.Spec.ServiceOrchestrationEnabled
+	data.ServiceOrchestrationRuleApplied = pdService.Spec.ServiceOrchestrationRuleApplied
 
-	data.IntegrationID, err = getConfigMapKey(pdAPIConfigMap.Data, "INTEGRATION_ID")
-	if err != nil {
+	// Don't parse the timeouts or alert grouping parameters from the PagerDutyService because we will always want to
+	// use the values from the pagerdutyintegration for configuration. Saving the values to the PagerDutyService is done
+	// as a way to avoid hitting the API rate limit
+
+	return nil
+}
+
+// SetClusterConfig updates a specific ClusterDeployment's PagerDutyService with the contents of the data struct
+func (data *Data) SetClusterConfig(ctx context.Context, osc client.Client, namespace string, name string) error {
+	pdService := &pagerdutyv1alpha1.PagerDutyService{}
+	if err := osc.Get(ctx, types.NamespacedName{Namespace: namespace, Name: name}, pdService); err != nil {
 		return err
 	}
 
-	data.EscalationPolicyID, err = getConfigMapKey(pdAPIConfigMap.Data, "ESCALATION_POLICY_ID")
-	// do not return error, allow EscalationPolicyID to be empty string
-	if err != nil {
-		data.EscalationPolicyID = ""
-	}
+	data.UpdatePagerDutyServiceSpec(&pdService.Spec)
 
-	isInLimitedSupport := pdAPIConfigMap.Data["LIMITED_SUPPORT"]
-	data.LimitedSupport = isInLimitedSupport == "true"
+	return osc.Update(ctx, pdService)
+}
 
-	serviceOrchestrationEnabled := pdAPIConfigMap.Data["SERVICE_ORCHESTRATION_ENABLED"]
-	data.ServiceOrchestrationEnabled = serviceOrchestrationEnabled == "true"
+// UpdatePagerDutyServiceSpec copies the IDs and the state of the data struct that are stored per cluster into spec.
+// The service settings are left alone, they are only recorded by RecordAppliedSettings once PD accepted them
+func (data *Data) UpdatePagerDutyServiceSpec(spec *pagerdutyv1alpha1.PagerDutyServiceSpec) {
+	spec.ServiceID = data.ServiceID
+	spec.IntegrationID = data.IntegrationID
+	spec.EscalationPolicyID = data.EscalationPolicyID
+	spec.LimitedSupport = data.LimitedSupport
+	spec.ServiceOrchestrationEnabled = data.ServiceOrchestrationEnabled
+	spec.ServiceOrchestrationRuleApplied = data.ServiceOrchestrationRuleApplied
+}
 
-	data.ServiceOrchestrationRuleApplied, err = getConfigMapKey(pdAPIConfigMap.Data, "SERVICE_ORCHESTRATION_RULE_APPLIED")
-	if err != nil {
-		data.ServiceOrchestrationRuleApplied = ""
+// RecordAppliedSettings records the service settings of the data struct in spec as the ones applied to the
+// PD service. It must only be called after the settings were sent to PD, when the service was created or
+// updated, because handleUpdate compares against them to decide whether PD needs to be called.
+// Alert grouping is only recorded when it is configured
+func (data *Data) RecordAppliedSettings(spec *pagerdutyv1alpha1.PagerDutyServiceSpec) {
+	spec.ResolveTimeout = data.ResolveTimeout
+	spec.AcknowledgeTimeout = data.AcknowledgeTimeOut
+	if data.AlertGroupingType != "" {
+		spec.AlertGroupingType = data.AlertGroupingType
+		spec.AlertGroupingTimeout = data.AlertGroupingTimeout
 	}
+	spec.IncidentUrgencyRule = data.IncidentUrgencyRule.DeepCopy()
+	spec.ServiceName = data.ServiceName
+	spec.ServiceDescription = data.ServiceDescription
+}
 
-	// Don't parse the alert grouping parameters from the configmap because we will always want to use the values from
-	// the pagerdutyintegration for configuration. Saving the values to the configmap is done as a way to avoid hitting
-	// the API rate limit
+// ParseLegacyClusterConfig parses the ConfigMap that stored the cluster config before the
+// PagerDutyService CR existed. It is only used to migrate clusters, so unlike
+// ParseClusterConfig the alert grouping parameters that were last applied are loaded too.
+// SERVICE_ID and INTEGRATION_ID are required ConfigMap data fields
+func (data *Data) ParseLegacyClusterConfig(cm *corev1.ConfigMap) error {
+	var err error
 
-	return nil
-}
+	data.ServiceID, err = getConfigMapKey(cm.Data, "SERVICE_ID")
+	if err != nil {
+		return err
+	}
 
-// SetClusterConfig updates a specific ClusterDeployment's PagerDuty Configmap with the contents of the data struct
-func (data *Data) SetClusterConfig(osc client.Client, namespace string, cmName string) error {
-	pdAPIConfigMap := &corev1.ConfigMap{}
-	if err := osc.Get(context.TODO(), types.NamespacedName{Namespace: namespace, Name: cmName}, pdAPIConfigMap); err != nil {
+	data.IntegrationID, err = getConfigMapKey(cm.Data, "INTEGRATION_ID")
+	if err != nil {
 		return err
 	}
 
-	pdAPIConfigMap.Data["SERVICE_ID"] = data.ServiceID
-	pdAPIConfigMap.Data["INTEGRATION_ID"] = data.IntegrationID
-	pdAPIConfigMap.Data["ESCALATION_POLICY_ID"] = data.EscalationPolicyID
-	pdAPIConfigMap.Data["LIMITED_SUPPORT"] = strconv.FormatBool(data.LimitedSupport)
-	pdAPIConfigMap.Data["SERVICE_ORCHESTRATION_ENABLED"] = strconv.FormatBool(data.ServiceOrchestrationEnabled)
-	pdAPIConfigMap.Data["SERVICE_ORCHESTRATION_RULE_APPLIED"] = data.ServiceOrchestrationRuleApplied
-	pdAPIConfigMap.Data["ALERT_GROUPING_TYPE"] = data.AlertGroupingType
-	pdAPIConfigMap.Data["ALERT_GROUPING_TIMEOUT"] = fmt.Sprintf("%d", data.AlertGroupingTimeout)
+	data.EscalationPolicyID, err = getConfigMapKey(cm.Data, "ESCALATION_POLICY_ID")
+	// do not return error, allow EscalationPolicyID to be empty string
+	if err != nil {
+		data.EscalationPolicyID = ""
+	}
 
-	if err := osc.Update(context.TODO(), pdAPIConfigMap); err != nil {
-		return err
+	data.LimitedSupport = cm.Data["LIMITED_SUPPORT"] == "true"
+	data.ServiceOrchestrationEnabled = cm.Data["SERVICE_ORCHESTRATION_ENABLED"] == "true"
+	data.ServiceOrchestrationRuleApplied = cm.Data["SERVICE_ORCHESTRATION_RULE_APPLIED"]
+
+	data.AlertGroupingType = cm.Data["ALERT_GROUPING_TYPE"]
+	data.AlertGroupingTimeout = 0
+	if timeout, err := strconv.ParseUint(cm.Data["ALERT_GROUPING_TIMEOUT"], 10, 0); err == nil {
+		data.AlertGroupingTimeout = uint(timeout)
 	}
 
+	// the default urgency rule, name and description were the only ones before they became configurable
+	data.IncidentUrgencyRule = nil
+	data.ServiceName = ""
+	data.ServiceDescription = ""
+
 	return nil
 }
 
 // GetService searches the PD API for an already existing service
-func (c *SvcClient) GetService(data *Data) (*pdApi.Service, error) {
-	service, err := c.PdClient.GetService(data.ServiceID, nil)
+func (c *SvcClient) GetService(ctx context.Context, data *Data) (*pdApi.Service, error) {
+	ctx = withResponseHeader(ctx)
+	service, err := c.PdClient.GetServiceWithContext(ctx, data.ServiceID, nil)
 	if err != nil {
-		return nil, fmt.Errorf("unable to get service with ID %v: %w", data.ServiceID, err)
+		return nil, fmt.Errorf("unable to get service with ID %v: %w", data.ServiceID, classifyError(ctx, err))
 	}
 	return service, nil
 }
 
 // GetIntegrationKey searches the PD API for an already existing service and returns the first integration key
-func (c *SvcClient) GetIntegrationKey(data *Data) (string, error) {
-	integration, err := c.PdClient.GetIntegration(data.ServiceID, data.IntegrationID, pdApi.GetIntegrationOptions{})
+func (c *SvcClient) GetIntegrationKey(ctx context.Context, data *Data) (string, error) {
+	ctx = withResponseHeader(ctx)
+	integration, err := c.PdClient.GetIntegrationWithContext(ctx, data.ServiceID, data.IntegrationID, pdApi.GetIntegrationOptions{})
 	if err != nil {
 		return "", fmt.Errorf("unable to get integration with service ID %v, integration ID %v: %w", data.ServiceID,
-			data.IntegrationID, err)
+			data.IntegrationID, classifyError(ctx, err))
 	}
 
 	return integration.IntegrationKey, nil
 }
 
+// ValidateAPIKey makes a cheap authenticated call to check that PD accepts the API key
+func (c *SvcClient) ValidateAPIKey(ctx context.Context) error {
+	ctx = withResponseHeader(ctx)
+	if _, err := c.PdClient.ListAbilitiesWithContext(ctx); err != nil {
+		return fmt.Errorf("unable to validate API key: %w", classifyError(ctx, err))
+	}
+	return nil
+}
+
+// HasIntegration returns true if the integration with the given ID is attached to the PD service
+func HasIntegration(service *pdApi.Service, integrationID string) bool {
+	for _, integration := range service.Integrations {
+		if integration.ID == integrationID {
+			return true
+		}
+	}
+	return false
+}
+
 // CreateService creates a service in pagerduty for the specified clusterid and returns the service key
-func (c *SvcClient) CreateService(data *Data) (string, error) {
-	escalationPolicy, err := c.PdClient.GetEscalationPolicy(data.EscalationPolicyID, nil)
+func (c *SvcClient) CreateService(ctx context.Context, data *Data) (string, error) {
+	ctx = withResponseHeader(ctx)
+	escalationPolicy, err := c.PdClient.GetEscalationPolicyWithContext(ctx, data.EscalationPolicyID, nil)
 	if err != nil {
-		return "", fmt.Errorf("escalation policy %v not found: %w", data.EscalationPolicyID, err)
+		return "", fmt.Errorf("escalation policy %v not found: %w", data.EscalationPolicyID, classifyError(ctx, err))
 	}
 
 	clusterService := pdApi.Service{
@@ -288,10 +445,6 @@ func (c *SvcClient) CreateService(data *Data) (string, error) {
 		AutoResolveTimeout:     &data.ResolveTimeout,
 		AcknowledgementTimeout: &data.AcknowledgeTimeOut,
 		AlertCreation:          "create_alerts_and_incidents",
-		IncidentUrgencyRule: &pdApi.IncidentUrgencyRule{
-			Type:    "constant",
-			Urgency: config.PagerDutyUrgencyRule,
-		},
 		AlertGroupingParameters: &pdApi.AlertGroupingParameters{
 			Type: data.AlertGroupingType,
 			Config: &pdApi.AlertGroupParamsConfig{
@@ -299,18 +452,20 @@ func (c *SvcClient) CreateService(data *Data) (string, error) {
 			},
 		},
 	}
+	applyUrgencyRule(&clusterService, data)
 
 	var newSvc *pdApi.Service
-	newSvc, err = c.PdClient.CreateService(clusterService)
+	newSvc, err = c.PdClient.CreateServiceWithContext(ctx, clusterService)
 	if err != nil {
-		if !strings.Contains(err.Error(), "Name has already been taken") {
+		err = classifyError(ctx, err)
+		if !IsConflict(err) {
 			return "", fmt.Errorf("unable to create service %v: %w", clusterService.Name, err)
 		}
 		lso := pdApi.ListServiceOptions{}
 		lso.Query = clusterService.Name
-		currentSvcs, newerr := c.PdClient.ListServices(lso)
+		currentSvcs, newerr := c.PdClient.ListServicesWithContext(ctx, lso)
 		if newerr != nil {
-			return "", fmt.Errorf("unable to list services with name %v: %w", clusterService.Name, newerr)
+			return "", fmt.Errorf("unable to list services with name %v: %w", clusterService.Name, classifyError(ctx, newerr))
 		}
 
 		if len(currentSvcs.Services) > 0 {
@@ -346,7 +501,7 @@ func (c *SvcClient) CreateService(data *Data) (string, error) {
 	}
 
 	if data.IntegrationID == "" {
-		data.IntegrationID, err = c.createIntegration(newSvc.ID, integrationName, integrationType)
+		data.IntegrationID, err = c.createIntegration(ctx, newSvc.ID, integrationName, integrationType)
 		if err != nil {
 			return "", fmt.Errorf("unable to create integration for service %v: %w", newSvc.ID, err)
 		}
@@ -357,7 +512,19 @@ func (c *SvcClient) CreateService(data *Data) (string, error) {
 	return data.IntegrationID, nil
 }
 
-func (c *SvcClient) createIntegration(serviceId, name, integrationType string) (string, error) {
+// CreateIntegration creates the Events API v2 integration on the PD service, e.g. after
+// it was deleted in PagerDuty, and stores its ID in data
+func (c *SvcClient) CreateIntegration(ctx context.Context, data *Data) error {
+	ctx = withResponseHeader(ctx)
+	integrationID, err := c.createIntegration(ctx, data.ServiceID, integrationName, integrationType)
+	if err != nil {
+		return err
+	}
+	data.IntegrationID = integrationID
+	return nil
+}
+
+func (c *SvcClient) createIntegration(ctx context.Context, serviceId, name, integrationType string) (string, error) {
 	newIntegration := pdApi.Integration{
 		Name: name,
 		APIObject: pdApi.APIObject{
@@ -365,69 +532,56 @@ func (c *SvcClient) createIntegration(serviceId, name, integrationType string) (
 		},
 	}
 
-	newInt, err := c.PdClient.CreateIntegration(serviceId, newIntegration)
+	newInt, err := c.PdClient.CreateIntegrationWithContext(ctx, serviceId, newIntegration)
 	if err != nil {
-		return "", fmt.Errorf("unable to create integration %v for service ID %v: %w", name, serviceId, err)
+		return "", fmt.Errorf("unable to create integration %v for service ID %v: %w", name, serviceId, classifyError(ctx, err))
 	}
 	return newInt.ID, nil
 }
 
-// DeleteService will get a service from the PD api and delete it
-func (c *SvcClient) DeleteService(data *Data) error {
-	err := c.resolvePendingIncidents(data, AlertResolvedSummaryDeleted)
-	if err != nil {
-		return fmt.Errorf("unable to resolve pending incidents for service ID %v: %w", data.ServiceID, err)
-	}
-
-	err = c.waitForIncidentsToResolve(data, 10*time.Second)
+// DeleteService deletes the PD service. Its incidents should be resolved with
+// ResolvePendingIncidents first, so that the alerts are cleared rather than dropped.
+func (c *SvcClient) DeleteService(ctx context.Context, data *Data) error {
+	ctx = withResponseHeader(ctx)
+	err := c.PdClient.DeleteServiceWithContext(ctx, data.ServiceID)
 	if err != nil {
-		return fmt.Errorf("error waiting for incidents to resolve for service ID %v: %w", data.ServiceID, err)
-	}
-
-	err = c.PdClient.DeleteService(data.ServiceID)
-	if err != nil {
-		return fmt.Errorf("unable to delete service ID %v: %w", data.ServiceID, err)
+		return fmt.Errorf("unable to delete service ID %v: %w", data.ServiceID, classifyError(ctx, err))
 	}
 	return nil
 }
 
 // EnableService will set the PD service active
-func (c *SvcClient) EnableService(data *Data) error {
-	service, err := c.PdClient.GetService(data.ServiceID, nil)
+func (c *SvcClient) EnableService(ctx context.Context, data *Data) error {
+	ctx = withResponseHeader(ctx)
+	service, err := c.PdClient.GetServiceWithContext(ctx, data.ServiceID, nil)
 	if err != nil {
-		return fmt.Errorf("unable to get service with ID %v: %w", data.ServiceID, err)
+		return fmt.Errorf("unable to get service with ID %v: %w", data.ServiceID, classifyError(ctx, err))
 	}
 
 	if service.Status != "active" {
 		service.Status = "active"
-		_, err = c.PdClient.UpdateService(*service)
+		_, err = c.PdClient.UpdateServiceWithContext(ctx, *service)
 		if err != nil {
-			return fmt.Errorf("failed to enable service: unable to update service ID %v: %w", data.ServiceID, err)
+			return fmt.Errorf("failed to enable service: unable to update service ID %v: %w", data.ServiceID, classifyError(ctx, err))
 		}
 	}
 
 	return nil
 }
 
-// DisableService will set the PD service disabled
-func (c *SvcClient) DisableService(data *Data) error {
-	service, err := c.PdClient.GetService(data.ServiceID, nil)
+// DisableService will set the PD service disabled. Its incidents should be resolved
+// with ResolvePendingIncidents first.
+func (c *SvcClient) DisableService(ctx context.Context, data *Data) error {
+	ctx = withResponseHeader(ctx)
+	service, err := c.PdClient.GetServiceWithContext(ctx, data.ServiceID, nil)
 	if err != nil {
-		return fmt.Errorf("unable to get service with ID %v: %w", data.ServiceID, err)
-	}
-
-	if err := c.resolvePendingIncidents(data, AlertResolvedSummaryLimitedSupport); err != nil {
-		return fmt.Errorf("unable to resolve pending incidents for service ID %v: %w", data.ServiceID, err)
-	}
-
-	if err = c.waitForIncidentsToResolve(data, 10*time.Second); err != nil {
-		return fmt.Errorf("error waiting for incidents to resolve for service ID %v: %w", data.ServiceID, err)
+		return fmt.Errorf("unable to get service with ID %v: %w", data.ServiceID, classifyError(ctx, err))
 	}
 
 	if service.Status != "disabled" {
 		service.Status = "disabled"
-		if _, err = c.PdClient.UpdateService(*service); err != nil {
-			return fmt.Errorf("failed to disable service: unable to update service ID %v: %w", data.ServiceID, err)
+		if _, err = c.PdClient.UpdateServiceWithContext(ctx, *service); err != nil {
+			return fmt.Errorf("failed to disable service: unable to update service ID %v: %w", data.ServiceID, classifyError(ctx, err))
 		}
 	}
 
@@ -435,16 +589,17 @@ func (c *SvcClient) DisableService(data *Data) error {
 }
 
 // ToggleServiceOrchestration enables/disables the service orchestration for a given PD service
-func (c *SvcClient) ToggleServiceOrchestration(data *Data, active bool) error {
-	service, err := c.PdClient.GetService(data.ServiceID, nil)
+func (c *SvcClient) ToggleServiceOrchestration(ctx context.Context, data *Data, active bool) error {
+	ctx = withResponseHeader(ctx)
+	service, err := c.PdClient.GetServiceWithContext(ctx, data.ServiceID, nil)
 	if err != nil {
-		return fmt.Errorf("unable to get service with ID %v: %w", data.ServiceID, err)
+		return fmt.Errorf("unable to get service with ID %v: %w", data.ServiceID, classifyError(ctx, err))
 	}
 
 	reqUrl := fmt.Sprintf("%s/event_orchestrations/services/%s/active", strings.TrimRight(c.BaseURL, "/"), service.ID)
 	payload := strings.NewReader(fmt.Sprintf("{\"active\": %t}", active))
 
-	err = c.pdHttpRequest("PUT", reqUrl, payload)
+	err = c.pdHttpRequest(ctx, "PUT", reqUrl, payload)
 	if err != nil {
 		return fmt.Errorf("unable to set service orchestration to %v for service ID %v: %w", active, data.ServiceID, err)
 	}
@@ -452,16 +607,17 @@ func (c *SvcClient) ToggleServiceOrchestration(data *Data, active bool) error {
 }
 
 // ApplyServiceOrchestrationRule applies the pre-defined orchestration rule to the service after enabled
-func (c *SvcClient) ApplyServiceOrchestrationRule(data *Data) error {
-	service, err := c.PdClient.GetService(data.ServiceID, nil)
+func (c *SvcClient) ApplyServiceOrchestrationRule(ctx context.Context, data *Data) error {
+	ctx = withResponseHeader(ctx)
+	service, err := c.PdClient.GetServiceWithContext(ctx, data.ServiceID, nil)
 	if err != nil {
-		return fmt.Errorf("unable to get service with ID %v: %w", data.ServiceID, err)
+		return fmt.Errorf("unable to get service with ID %v: %w", data.ServiceID, classifyError(ctx, err))
 	}
 
 	reqUrl := fmt.Sprintf("%s/event_orchestrations/services/%s", strings.TrimRight(c.BaseURL, "/"), service.ID)
 	payload := strings.NewReader(data.ServiceOrchestrationRuleApplied)
 
-	err = c.pdHttpRequest("PUT", reqUrl, payload)
+	err = c.pdHttpRequest(ctx, "PUT", reqUrl, payload)
 	if err != nil {
 		return fmt.Errorf("unable to apply service orchestration rule for service ID %v: %w", data.ServiceID, err)
 	}
@@ -469,94 +625,137 @@ func (c *SvcClient) ApplyServiceOrchestrationRule(data *Data) error {
 }
 
 // pdHttpRequest is a wrapper func to help send the PD http request
-func (c *SvcClient) pdHttpRequest(method string, reqUrl string, payload *strings.Reader) error {
-	req, err := http.NewRequest(method, reqUrl, payload)
+func (c *SvcClient) pdHttpRequest(ctx context.Context, method string, reqUrl string, payload *strings.Reader) error {
+	req, err := http.NewRequestWithContext(ctx, method, reqUrl, payload)
 	if err != nil {
 		return fmt.Errorf("unable to create new http request: %w", err)
 	}
 
 	req.Header.Add("Accept", "application/vnd.pagerduty+json;version=2")
 	req.Header.Add("Content-Type", "application/json")
-	req.Header.Add("Authorization", fmt.Sprintf("Token token=%s", c.APIKey))
+	// the HTTPClient authenticates the request itself with OAuth credentials
+	if c.APIKey != "" {
+		req.Header.Add("Authorization", fmt.Sprintf("Token token=%s", c.APIKey))
+	}
 
-	resp, err := http.DefaultClient.Do(req)
+	httpClient := c.HTTPClient
+	if httpClient == nil {
+		httpClient = http.DefaultClient
+	}
+	resp, err := httpClient.Do(req)
 	if err != nil {
-		return err
+		return classifyHTTPClientError(err)
 	}
+	defer resp.Body.Close()
 
 	statusOK := resp.StatusCode >= 200 && resp.StatusCode < 300
 	if !statusOK {
-		return fmt.Errorf("failed pdHttpRequest, returned status code is non 2xx: Status: %s", resp.Status)
+		return newHTTPError(resp)
 	}
 
 	return nil
 }
 
 // UpdateEscalationPolicy will update the PD service escalation policy
-func (c *SvcClient) UpdateEscalationPolicy(data *Data) error {
-	escalationPolicy, err := c.PdClient.GetEscalationPolicy(data.EscalationPolicyID, &pdApi.GetEscalationPolicyOptions{})
+func (c *SvcClient) UpdateEscalationPolicy(ctx context.Context, data *Data) error {
+	ctx = withResponseHeader(ctx)
+	escalationPolicy, err := c.PdClient.GetEscalationPolicyWithContext(ctx, data.EscalationPolicyID, &pdApi.GetEscalationPolicyOptions{})
 	if err != nil {
-		return fmt.Errorf("unable to get escalation policy with ID %v: %w", data.EscalationPolicyID, err)
+		return fmt.Errorf("unable to get escalation policy with ID %v: %w", data.EscalationPolicyID, classifyError(ctx, err))
 	}
 
-	service, err := c.PdClient.GetService(data.ServiceID, nil)
+	service, err := c.PdClient.GetServiceWithContext(ctx, data.ServiceID, nil)
 	if err != nil {
-		return fmt.Errorf("unable to get service with ID %v: %w", data.ServiceID, err)
+		return fmt.Errorf("unable to get service with ID %v: %w", data.ServiceID, classifyError(ctx, err))
 	}
 
 	service.EscalationPolicy.ID = escalationPolicy.ID
 
-	_, err = c.PdClient.UpdateService(*service)
+	_, err = c.PdClient.UpdateServiceWithContext(ctx, *service)
 	if err != nil {
-		return fmt.Errorf("failed to update escalation policy: unable to update service %v: %w", data.ServiceID, err)
+		return fmt.Errorf("failed to update escalation policy: unable to update service %v: %w", data.ServiceID, classifyError(ctx, err))
 	}
 
 	return nil
 }
 
-// UpdateAlertGrouping will update the PD service alert grouping
-func (c *SvcClient) UpdateAlertGrouping(data *Data) error {
-	service, err := c.PdClient.GetService(data.ServiceID, nil)
+// UpdateServiceSettings will update the PD service auto-resolve and acknowledgement
+// timeouts, and the alert grouping when one is configured
+func (c *SvcClient) UpdateServiceSettings(ctx context.Context, data *Data) error {
+	ctx = withResponseHeader(ctx)
+	service, err := c.PdClient.GetServiceWithContext(ctx, data.ServiceID, nil)
 	if err != nil {
-		return fmt.Errorf("unable to get service with ID %v: %w", data.ServiceID, err)
+		return fmt.Errorf("unable to get service with ID %v: %w", data.ServiceID, classifyError(ctx, err))
 	}
 
-	service.AlertGroupingParameters = &pdApi.AlertGroupingParameters{
-		Type: data.AlertGroupingType,
-		Config: &pdApi.AlertGroupParamsConfig{
-			Timeout: &data.AlertGroupingTimeout,
-		},
+	service.Name = generatePDServiceName(data)
+	service.Description = generatePDServiceDescription(data)
+	service.AutoResolveTimeout = &data.ResolveTimeout
+	service.AcknowledgementTimeout = &data.AcknowledgeTimeOut
+	applyUrgencyRule(service, data)
+
+	if data.AlertGroupingType != "" {
+		service.AlertGroupingParameters = &pdApi.AlertGroupingParameters{
+			Type: data.AlertGroupingType,
+			Config: &pdApi.AlertGroupParamsConfig{
+				Timeout: &data.AlertGroupingTimeout,
+			},
+		}
 	}
 
-	_, err = c.PdClient.UpdateService(*service)
+	_, err = c.PdClient.UpdateServiceWithContext(ctx, *service)
 	if err != nil {
-		return fmt.Errorf("failed to update alert grouping: unable to update service %v with config %v: %w", data.ServiceID, service.AlertGroupingParameters, err)
+		err = classifyError(ctx, err)
+		if IsConflict(err) {
+			return fmt.Errorf("failed to update service settings: PD service name %q is already taken: %w", service.Name, err)
+		}
+		return fmt.Errorf("failed to update service settings: unable to update service %v: %w", data.ServiceID, err)
 	}
 
 	return nil
 }
 
-// resolvePendingIncidents loops over all unresolved incidents to resolve all contained alerts
-func (c *SvcClient) resolvePendingIncidents(data *Data, summary string) error {
-	incidents, err := c.getUnresolvedIncidents(data)
+// RestoreService will set every PD service setting that can drift back to its desired value
+func (c *SvcClient) RestoreService(ctx context.Context, data *Data) error {
+	ctx = withResponseHeader(ctx)
+	service, err := c.PdClient.GetServiceWithContext(ctx, data.ServiceID, nil)
+	if err != nil {
+		return fmt.Errorf("unable to get service with ID %v: %w", data.ServiceID, classifyError(ctx, err))
+	}
+
+	applyDesiredSettings(service, data)
+
+	_, err = c.PdClient.UpdateServiceWithContext(ctx, *service)
+	if err != nil {
+		return fmt.Errorf("failed to restore service: unable to update service %v: %w", data.ServiceID, classifyError(ctx, err))
+	}
+
+	return nil
+}
+
+// ResolvePendingIncidents loops over all unresolved incidents to resolve all contained alerts.
+// PD resolves the incidents asynchronously, use CountUnresolvedIncidents to follow up on them.
+func (c *SvcClient) ResolvePendingIncidents(ctx context.Context, data *Data, summary string) error {
+	ctx = withResponseHeader(ctx)
+	incidents, err := c.getUnresolvedIncidents(ctx, data)
 	if err != nil {
 		return fmt.Errorf("unable to get unresolved incidents for service %v: %w", data.ServiceID, err)
 	}
 
 	for _, incident := range incidents {
-		alerts, err := c.getUnresolvedAlerts(incident.ID)
+		alerts, err := c.getUnresolvedAlerts(ctx, incident.ID)
 		if err != nil {
 			return fmt.Errorf("unable to get unresolved alerts for incident %v: %w", incident.ID, err)
 		}
 
 		for _, alert := range alerts {
-			integration, err := c.PdClient.GetIntegration(data.ServiceID, alert.Integration.ID, pdApi.GetIntegrationOptions{})
+			integration, err := c.PdClient.GetIntegrationWithContext(ctx, data.ServiceID, alert.Integration.ID, pdApi.GetIntegrationOptions{})
 			if err != nil {
 				return fmt.Errorf("unable to get integration %v for incident %v, service %v: %w",
-					alert.Integration.ID, incident.ID, data.ServiceID, err)
+					alert.Integration.ID, incident.ID, data.ServiceID, classifyError(ctx, err))
 			}
 
-			err = c.resolveAlert(integration.IntegrationKey, alert.AlertKey, summary)
+			err = c.resolveAlert(ctx, integration.IntegrationKey, alert.AlertKey, summary)
 			if err != nil {
 				return fmt.Errorf("unable to resolve alert %v for incident %v, service %v: %w",
 					alert.AlertKey, incident.ID, data.ServiceID, err)
@@ -568,75 +767,52 @@ func (c *SvcClient) resolvePendingIncidents(data *Data, summary string) error {
 }
 
 // getUnresolvedIncidents returns a slice of unresolved incidents for the provided Service ID
-func (c *SvcClient) getUnresolvedIncidents(data *Data) ([]pdApi.Incident, error) {
+func (c *SvcClient) getUnresolvedIncidents(ctx context.Context, data *Data) ([]pdApi.Incident, error) {
 	// Possible statuses are: "acknowledged", "triggered", and "resolved"
 	listServiceIncidentOptions := pdApi.ListIncidentsOptions{
 		ServiceIDs: []string{data.ServiceID},
 		Statuses:   []string{"acknowledged", "triggered"},
 	}
 
-	incidentsRes, err := c.PdClient.ListIncidents(listServiceIncidentOptions)
+	incidentsRes, err := c.PdClient.ListIncidentsWithContext(ctx, listServiceIncidentOptions)
 	if err != nil {
-		return []pdApi.Incident{}, fmt.Errorf("unable to list incidents for service %v: %w", data.ServiceID, err)
+		return []pdApi.Incident{}, fmt.Errorf("unable to list incidents for service %v: %w", data.ServiceID, classifyError(ctx, err))
 	}
 	return incidentsRes.Incidents, err
 }
 
 // getUnresolvedAlerts returns a slice of unresolved incidents for the provided Service ID
-func (c *SvcClient) getUnresolvedAlerts(incidentId string) ([]pdApi.IncidentAlert, error) {
+func (c *SvcClient) getUnresolvedAlerts(ctx context.Context, incidentId string) ([]pdApi.IncidentAlert, error) {
 	// Possible statuses are: "triggered" and "resolved"
 	listIncidentAlertsOptions := pdApi.ListIncidentAlertsOptions{
 		Statuses: []string{"triggered"},
 	}
 
-	alerts, err := c.PdClient.ListIncidentAlertsWithOpts(incidentId, listIncidentAlertsOptions)
+	alerts, err := c.PdClient.ListIncidentAlertsWithContext(ctx, incidentId, listIncidentAlertsOptions)
 	if err != nil {
 		return []pdApi.IncidentAlert{}, fmt.Errorf("unable to list incident alerts for incident %v: %w",
-			incidentId, err)
+			incidentId, classifyError(ctx, err))
 	}
 	return alerts.Alerts, err
 }
 
-// waitForIncidentsToResolve polls for unresolved incidents every waitStep,
-// returning nil when all are resolved or an error if maxWait is exceeded.
-func (c *SvcClient) waitForIncidentsToResolve(data *Data, maxWait time.Duration) error {
-	waitStep := 2 * time.Second
-	start := time.Now()
-
-	for {
-		incidents, err := c.getUnresolvedIncidents(data)
-		if err != nil {
-			return fmt.Errorf("unable to get unresolved incidents: %w", err)
-		}
-
-		if len(incidents) == 0 {
-			return nil
-		}
-
-		if time.Since(start) > maxWait {
-			return fmt.Errorf("timed out waiting for %d incidents to resolve: %v",
-				len(incidents),
-				parseIncidentNumbers(incidents),
-			)
-		}
-
-		c.Delay(waitStep)
-	}
-}
-
-// parseIncidentNumbers returns a slice of PagerDuty incident numbers
-func parseIncidentNumbers(incidents []pdApi.Incident) []uint {
-	var incidentNumbers []uint
-	for _, incident := range incidents {
-		incidentNumbers = append(incidentNumbers, incident.IncidentNumber)
+// CountUnresolvedIncidents returns the number of incidents of the PD service that are not resolved yet
+func (c *SvcClient) CountUnresolvedIncidents(ctx context.Context, data *Data) (int, error) {
+	ctx = withResponseHeader(ctx)
+	incidents, err := c.getUnresolvedIncidents(ctx, data)
+	if err != nil {
+		return 0, err
 	}
-
-	return incidentNumbers
+	return len(incidents), nil
 }
 
-// generateServiceName checks if FedRamp is enabled. If it is, it returns
+// generateServiceName returns the name rendered from the PagerDutyIntegration template.
+// Without a template it checks if FedRamp is enabled. If it is, it returns
 // an anonymized PD service name.
 func generatePDServiceName(data *Data) string {
+	if data.ServiceName != "" {
+		return data.ServiceName
+	}
 	if data.IsFedramp {
 		return data.ServicePrefix + "-" + data.ClusterID
 	} else {
@@ -644,9 +820,13 @@ func generatePDServiceName(data *Data) string {
 	}
 }
 
-// generateServiceDescription checks if FedRamp is enabled. If it is, it returns
+// generateServiceDescription returns the description rendered from the PagerDutyIntegration
+// template. Without a template it checks if FedRamp is enabled. If it is, it returns
 // an empty PD service description
 func generatePDServiceDescription(data *Data) string {
+	if data.ServiceDescription != "" {
+		return data.ServiceDescription
+	}
 	if data.IsFedramp {
 		return ""
 	} else {
@@ -659,7 +839,7 @@ func generatePDServiceDescription(data *Data) string {
 // enabled for a service. The integration key for the integration that generated the alert
 // identified by the alertKey must be used to successfully delete the alert. The summary passed
 // in will be the resolution message for the alert.
-func (c *SvcClient) resolveAlert(integrationKey, alertKey, summary string) error {
+func (c *SvcClient) resolveAlert(ctx context.Context, integrationKey, alertKey, summary string) error {
 	event := &pdApi.V2Event{
 		RoutingKey: integrationKey,
 		Action:     "resolve",
@@ -671,10 +851,10 @@ func (c *SvcClient) resolveAlert(integrationKey, alertKey, summary string) error
 		},
 	}
 
-	// TODO: If the response status is 429 (TooManyRequests) we should probably wait for longer
-	// Note: A 202 (StatusAccepted) is returned when the event is accepted by PagerDuty,
+	// Note: Rate limited events are retried by the rate limiter of the PdClient.
+	// A 202 (StatusAccepted) is returned when the event is accepted by PagerDuty,
 	// this does not mean the alert will be successfully resolved, i.e. if an incorrect
 	// integration key is provided.
-	_, err := c.PdClient.ManageEvent(event)
-	return err
+	_, err := c.PdClient.ManageEventWithContext(ctx, event)
+	return classifyError(ctx, err)
 }
@@ -10,7 +10,6 @@ import (
 	"fmt"
 	"net/http"
 	"net/http/httptest"
-	"time"
 
 	pd "github.com/PagerDuty/go-pagerduty"
 	"github.com/openshift/pagerduty-operator/pkg/utils"
@@ -175,6 +174,7 @@ func defaultMockApi() *mockApi {
 	mockApi.setupDefaultListIncidentAlertsHandler()
 	mockApi.setupOrchestrationHandlers()
 	mockApi.setupV2EventsHandler()
+	mockApi.setupAbilitiesHandler()
 
 	return mockApi
 }
@@ -200,7 +200,6 @@ func newMockClient(server *httptest.Server) *mockClient {
 				pd.WithAPIEndpoint(server.URL),
 				pd.WithV2EventsAPIEndpoint(server.URL),
 			),
-			Delay:   func(time.Duration) {},
 			BaseURL: server.URL,
 		},
 	}
@@ -242,6 +241,14 @@ func (m *mockApi) setupDefaultServiceHandlers() {
 					http.Error(w, "Could not find expected key: service", http.StatusBadRequest)
 					return
 				}
+				for _, other := range m.State.Services {
+					if other.ID != svc.ID && other.Name == service.Name {
+						w.Header().Set("Content-Type", "application/json")
+						w.WriteHeader(http.StatusBadRequest)
+						_, _ = w.Write([]byte("{\"error\":{\"message\":\"Name has already been taken\",\"code\":2100}}"))
+						return
+					}
+				}
 				service.ID = svc.ID
 				m.State.Services[svc.ID] = &service
 				processedService := map[string]pd.Service{
@@ -417,6 +424,22 @@ func (m *mockApi) setupDefaultGetEscalationPolicyHandler() {
 	}
 }
 
+// setupAbilitiesHandler sets up a handler listing the abilities of the account, which only
+// accepts the API key of the mock client
+func (m *mockApi) setupAbilitiesHandler() {
+	m.mux.HandleFunc("/abilities", func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Token token="+m.Client.APIKey {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+		_, err := w.Write([]byte(`{"abilities": ["teams"]}`))
+		if err != nil {
+			return
+		}
+	})
+}
+
 // setupCreateIntegrationHandler sets up a handler to create new integrations for a provided service ID
 func (m *mockApi) setupCreateIntegrationHandler(serviceId string) {
 	m.mux.HandleFunc(fmt.Sprintf("/services/%s/integrations", serviceId), func(w http.ResponseWriter, r *http.Request) {
@@ -1,15 +1,18 @@
 package pagerduty
 
 import (
+	"context"
 	"testing"
-	"time"
 
-	pdApi "github.com/PagerDuty/go-pagerduty"
+	pd "github.com/PagerDuty/go-pagerduty"
+	hivev1 "github.com/openshift/hive/apis/hive/v1"
+	"github.com/openshift/hive/apis/hive/v1/aws"
 	pagerdutyv1alpha1 "github.com/openshift/pagerduty-operator/api/v1alpha1"
 	"github.com/stretchr/testify/assert"
 	v1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
 	"sigs.k8s.io/controller-runtime/pkg/client/fake"
 )
 
@@ -96,11 +99,98 @@ func TestNewData(t *testing.T) {
 			},
 			expectErr: false,
 		},
+		{
+			name: "support hours urgency rule defined",
+			pdi: &pagerdutyv1alpha1.PagerDutyIntegration{
+				Spec: pagerdutyv1alpha1.PagerDutyIntegrationSpec{
+					EscalationPolicy:    mockEscalationPolicyId,
+					IncidentUrgencyRule: supportHoursData(&Data{}).IncidentUrgencyRule,
+				},
+			},
+			expectErr: false,
+		},
+		{
+			name: "support hours urgency rule without support hours",
+			pdi: &pagerdutyv1alpha1.PagerDutyIntegration{
+				Spec: pagerdutyv1alpha1.PagerDutyIntegrationSpec{
+					EscalationPolicy: mockEscalationPolicyId,
+					IncidentUrgencyRule: &pagerdutyv1alpha1.IncidentUrgencyRuleSpec{
+						Type:                pagerdutyv1alpha1.IncidentUrgencyRuleUseSupportHours,
+						DuringSupportHours:  "high",
+						OutsideSupportHours: "low",
+					},
+				},
+			},
+			expectErr: true,
+		},
+		{
+			name: "service name and description templates defined",
+			pdi: &pagerdutyv1alpha1.PagerDutyIntegration{
+				Spec: pagerdutyv1alpha1.PagerDutyIntegrationSpec{
+					EscalationPolicy:           mockEscalationPolicyId,
+					ServiceNameTemplate:        "{{.ServicePrefix}}-{{.ClusterID}}",
+					ServiceDescriptionTemplate: "{{.Platform}} cluster",
+				},
+			},
+			expectErr: false,
+		},
+		{
+			name: "invalid service name template",
+			pdi: &pagerdutyv1alpha1.PagerDutyIntegration{
+				Spec: pagerdutyv1alpha1.PagerDutyIntegrationSpec{
+					EscalationPolicy:    mockEscalationPolicyId,
+					ServiceNameTemplate: "{{.ClusterID",
+				},
+			},
+			expectErr: true,
+		},
+		{
+			name: "service name template not unique per cluster",
+			pdi: &pagerdutyv1alpha1.PagerDutyIntegration{
+				Spec: pagerdutyv1alpha1.PagerDutyIntegrationSpec{
+					EscalationPolicy:    mockEscalationPolicyId,
+					ServiceNameTemplate: "{{.ServicePrefix}}-{{.Region}}",
+				},
+			},
+			expectErr: true,
+		},
+		{
+			name: "service name template only unique per namespace",
+			pdi: &pagerdutyv1alpha1.PagerDutyIntegration{
+				Spec: pagerdutyv1alpha1.PagerDutyIntegrationSpec{
+					EscalationPolicy:    mockEscalationPolicyId,
+					ServiceNameTemplate: "{{.ServicePrefix}}-{{.ClusterName}}",
+				},
+			},
+			expectErr: true,
+		},
+		{
+			name: "service name template with namespace and cluster name",
+			pdi: &pagerdutyv1alpha1.PagerDutyIntegration{
+				Spec: pagerdutyv1alpha1.PagerDutyIntegrationSpec{
+					EscalationPolicy:    mockEscalationPolicyId,
+					ServiceNameTemplate: "{{.Namespace}}-{{.ClusterName}}",
+				},
+			},
+			expectErr: false,
+		},
+		{
+			name: "constant urgency rule without urgency",
+			pdi: &pagerdutyv1alpha1.PagerDutyIntegration{
+				Spec: pagerdutyv1alpha1.PagerDutyIntegrationSpec{
+					EscalationPolicy: mockEscalationPolicyId,
+					IncidentUrgencyRule: &pagerdutyv1alpha1.IncidentUrgencyRuleSpec{
+						Type: pagerdutyv1alpha1.IncidentUrgencyRuleConstant,
+					},
+				},
+			},
+			expectErr: true,
+		},
 	}
 
 	for _, test := range tests {
 		t.Run(test.name, func(t *testing.T) {
-			_, err := NewData(test.pdi, "clusterId", "baseDomain", false)
+			_, err := NewData(test.pdi, testClusterDeployment(), "clusterId", false)
 			if test.expectErr {
 				assert.NotNil(t, err)
 			} else {
@@ -110,6 +200,121 @@ func TestNewData(t *testing.T) {
 	}
 }
 
+// testClusterDeployment returns an AWS ClusterDeployment named after the mock cluster
+func testClusterDeployment() *hivev1.ClusterDeployment {
+	return &hivev1.ClusterDeployment{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      mockClusterId,
+			Namespace: "uhc-" + mockClusterId,
+			Labels: map[string]string{
+				"api.openshift.com/environment": "production",
+			},
+		},
+		Spec: hivev1.ClusterDeploymentSpec{
+			ClusterName: mockClusterId,
+			BaseDomain:  mockBaseDomain,
+			Platform: hivev1.Platform{
+				AWS: &aws.Platform{Region: "us-east-1"},
+			},
+		},
+	}
+}
+
+func TestNewData_Templates(t *testing.T) {
+	tests := []struct {
+		name                string
+		nameTemplate        string
+		descriptionTemplate string
+		isFedramp           bool
+		expectedName        string
+		expectedDescription string
+	}{
+		{
+			name:                "Defaults",
+			expectedName:        mockServiceName,
+			expectedDescription: mockClusterId + " - A managed hive created cluster",
+		},
+		{
+			name:                "FedRAMP defaults",
+			isFedramp:           true,
+			expectedName:        mockServicePrefix + "-" + mockClusterId,
+			expectedDescription: "",
+		},
+		{
+			name:                "Templates",
+			nameTemplate:        `{{.ServicePrefix}}-{{.ClusterID}}-{{index .Labels "api.openshift.com/environment"}}`,
+			descriptionTemplate: "{{.ClusterName}} on {{.Platform}} in {{.Region}} {{.Annotations.missing}}",
+			expectedName:        mockServicePrefix + "-" + mockClusterId + "-production",
+			expectedDescription: mockClusterId + " on aws in us-east-1",
+		},
+		{
+			name:                "Templates in FedRAMP",
+			nameTemplate:        "{{.ClusterID}}.{{.BaseDomain}}",
+			isFedramp:           true,
+			expectedName:        mockClusterId + "." + mockBaseDomain,
+			expectedDescription: "",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			pdi := &pagerdutyv1alpha1.PagerDutyIntegration{
+				Spec: pagerdutyv1alpha1.PagerDutyIntegrationSpec{
+					EscalationPolicy:           mockEscalationPolicyId,
+					ServicePrefix:              mockServicePrefix,
+					ServiceNameTemplate:        test.nameTemplate,
+					ServiceDescriptionTemplate: test.descriptionTemplate,
+				},
+			}
+
+			data, err := NewData(pdi, testClusterDeployment(), mockClusterId, test.isFedramp)
+			assert.NoError(t, err)
+			assert.Equal(t, test.expectedName, generatePDServiceName(data))
+			assert.Equal(t, test.expectedDescription, generatePDServiceDescription(data))
+		})
+	}
+}
+
+func TestValidateServiceTemplates(t *testing.T) {
+	tests := []struct {
+		name                string
+		nameTemplate        string
+		descriptionTemplate string
+		expectedErr         string
+	}{
+		{
+			name: "No templates",
+		},
+		{
+			name:                "Valid templates",
+			nameTemplate:        `{{.ServicePrefix}}-{{.ClusterID}}-{{index .Labels "api.openshift.com/environment"}}`,
+			descriptionTemplate: "{{.ClusterName}} in {{.Region}}",
+		},
+		{
+			name:         "Unclosed action in the name template",
+			nameTemplate: "{{.ClusterID",
+			expectedErr:  "invalid serviceNameTemplate",
+		},
+		{
+			name:                "Unknown function in the description template",
+			nameTemplate:        "{{.ClusterID}}",
+			descriptionTemplate: "{{lower .ClusterName}}",
+			expectedErr:         "invalid serviceDescriptionTemplate",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			err := ValidateServiceTemplates(test.nameTemplate, test.descriptionTemplate)
+			if test.expectedErr == "" {
+				assert.NoError(t, err)
+			} else {
+				assert.ErrorContains(t, err, test.expectedErr)
+			}
+		})
+	}
+}
+
 func TestNewData_IsFedramp(t *testing.T) {
 	pdi := &pagerdutyv1alpha1.PagerDutyIntegration{
 		Spec: pagerdutyv1alpha1.PagerDutyIntegrationSpec{
@@ -118,13 +323,13 @@ func TestNewData_IsFedramp(t *testing.T) {
 	}
 
 	t.Run("isFedramp false is stored in Data", func(t *testing.T) {
-		data, err := NewData(pdi, "cluster1", "example.com", false)
+		data, err := NewData(pdi, testClusterDeployment(), "cluster1", false)
 		assert.Nil(t, err)
 		assert.False(t, data.IsFedramp)
 	})
 
 	t.Run("isFedramp true is stored in Data", func(t *testing.T) {
-		data, err := NewData(pdi, "cluster1", "example.com", true)
+		data, err := NewData(pdi, testClusterDeployment(), "cluster1", true)
 		assert.Nil(t, err)
 		assert.True(t, data.IsFedramp)
 	})
@@ -173,39 +378,125 @@ func TestGeneratePDServiceDescription(t *testing.T) {
 func TestParseSetClusterConfig(t *testing.T) {
 	tests := []struct {
 		name                   string
-		cmName                 string
+		pdServiceName          string
 		namespace              string
-		data                   map[string]string
+		spec                   pagerdutyv1alpha1.PagerDutyServiceSpec
 		expectedLimitedSupport bool
 		expectErr              bool
 	}{
 		{
-			name:      "working",
-			cmName:    "cluster-pd-config",
-			namespace: "namespace",
-			data: map[string]string{
-				"SERVICE_ID":           "abcd",
-				"INTEGRATION_ID":       "abcd",
-				"ESCALATION_POLICY_ID": "abcd",
+			name:          "working",
+			pdServiceName: "cluster-pd-service",
+			namespace:     "namespace",
+			spec: pagerdutyv1alpha1.PagerDutyServiceSpec{
+				ServiceID:          "abcd",
+				IntegrationID:      "abcd",
+				EscalationPolicyID: "abcd",
 			},
 			expectedLimitedSupport: false,
 			expectErr:              false,
 		},
 		{
-			name:      "missing escalation policy id",
-			cmName:    "cluster-pd-config",
-			namespace: "namespace",
+			name:          "limited support",
+			pdServiceName: "cluster-pd-service",
+			namespace:     "namespace",
+			spec: pagerdutyv1alpha1.PagerDutyServiceSpec{
+				ServiceID:      "abcd",
+				IntegrationID:  "abcd",
+				LimitedSupport: true,
+			},
+			expectedLimitedSupport: true,
+			expectErr:              false,
+		},
+		{
+			name:          "missing PagerDutyService",
+			pdServiceName: "other-pd-service",
+			namespace:     "namespace",
+			expectErr:     true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			pdService := &pagerdutyv1alpha1.PagerDutyService{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "cluster-pd-service",
+					Namespace: test.namespace,
+				},
+				Spec: test.spec,
+			}
+
+			s := runtime.NewScheme()
+			s.AddKnownTypes(pagerdutyv1alpha1.GroupVersion, &pagerdutyv1alpha1.PagerDutyService{})
+			client := fake.NewClientBuilder().WithScheme(s).WithObjects(pdService).Build()
+
+			testData := Data{
+				EscalationPolicyID: mockEscalationPolicyId,
+				AlertGroupingType:  "time",
+			}
+			parseErr := testData.ParseClusterConfig(context.TODO(), client, test.namespace, test.pdServiceName)
+			setErr := testData.SetClusterConfig(context.TODO(), client, test.namespace, test.pdServiceName)
+
+			if test.expectErr {
+				assert.NotNil(t, parseErr)
+				assert.NotNil(t, setErr)
+				return
+			}
+
+			assert.Nil(t, parseErr)
+			assert.Equal(t, test.spec.ServiceID, testData.ServiceID)
+			assert.Equal(t, test.spec.IntegrationID, testData.IntegrationID)
+			assert.Equal(t, test.spec.EscalationPolicyID, testData.EscalationPolicyID)
+			assert.Equal(t, test.expectedLimitedSupport, testData.LimitedSupport)
+
+			assert.Nil(t, setErr)
+			updated := &pagerdutyv1alpha1.PagerDutyService{}
+			assert.Nil(t, client.Get(context.TODO(), types.NamespacedName{Namespace: test.namespace, Name: test.pdServiceName}, updated))
+			// the settings are only recorded once they were applied to the PD service
+			assert.Empty(t, updated.Spec.AlertGroupingType)
+			assert.Equal(t, test.expectedLimitedSupport, updated.Spec.LimitedSupport)
+		})
+	}
+}
+
+func TestParseLegacyClusterConfig(t *testing.T) {
+	tests := []struct {
+		name                         string
+		data                         map[string]string
+		expectedLimitedSupport       bool
+		expectedEscalationPolicyID   string
+		expectedAlertGroupingType    string
+		expectedAlertGroupingTimeout uint
+		expectErr                    bool
+	}{
+		{
+			name: "working",
+			data: map[string]string{
+				"SERVICE_ID":             "abcd",
+				"INTEGRATION_ID":         "abcd",
+				"ESCALATION_POLICY_ID":   "abcd",
+				"LIMITED_SUPPORT":        "true",
+				"ALERT_GROUPING_TYPE":    "time",
+				"ALERT_GROUPING_TIMEOUT": "300",
+			},
+			expectedLimitedSupport:       true,
+			expectedEscalationPolicyID:   "abcd",
+			expectedAlertGroupingType:    "time",
+			expectedAlertGroupingTimeout: 300,
+			expectErr:                    false,
+		},
+		{
+			name: "missing escalation policy id",
 			data: map[string]string{
 				"SERVICE_ID":     "abcd",
 				"INTEGRATION_ID": "abcd",
 			},
-			expectedLimitedSupport: false,
-			expectErr:              false,
+			expectedLimitedSupport:     false,
+			expectedEscalationPolicyID: "",
+			expectErr:                  false,
 		},
 		{
-			name:      "missing values",
-			cmName:    "cluster-pd-config",
-			namespace: "namespace",
+			name: "missing values",
 			data: map[string]string{
 				"SERVICE_ID": "abcd",
 			},
@@ -217,30 +508,29 @@ func TestParseSetClusterConfig(t *testing.T) {
 		t.Run(test.name, func(t *testing.T) {
 			cm := &v1.ConfigMap{
 				ObjectMeta: metav1.ObjectMeta{
-					Name:      test.cmName,
-					Namespace: test.namespace,
+					Name:      "cluster-pd-config",
+					Namespace: "namespace",
 				},
 				Data: test.data,
 			}
 
-			s := runtime.NewScheme()
-			s.AddKnownTypes(v1.SchemeGroupVersion, &v1.ConfigMap{})
-			client := fake.NewClientBuilder().WithScheme(s).WithObjects(cm).Build()
-
 			testData := Data{
 				EscalationPolicyID: mockEscalationPolicyId,
 			}
-			parseErr := testData.ParseClusterConfig(client, test.namespace, test.cmName)
+			err := testData.ParseLegacyClusterConfig(cm)
 
 			if test.expectErr {
-				assert.NotNil(t, parseErr)
-			} else {
-				assert.Nil(t, parseErr)
-				assert.Equal(t, test.expectedLimitedSupport, testData.LimitedSupport)
+				assert.NotNil(t, err)
+				return
 			}
 
-			setErr := testData.SetClusterConfig(client, test.namespace, test.cmName)
-			assert.Nil(t, setErr)
+			assert.Nil(t, err)
+			assert.Equal(t, "abcd", testData.ServiceID)
+			assert.Equal(t, "abcd", testData.IntegrationID)
+			assert.Equal(t, test.expectedEscalationPolicyID, testData.EscalationPolicyID)
+			assert.Equal(t, test.expectedLimitedSupport, testData.LimitedSupport)
+			assert.Equal(t, test.expectedAlertGroupingType, testData.AlertGroupingType)
+			assert.Equal(t, test.expectedAlertGroupingTimeout, testData.AlertGroupingTimeout)
 		})
 	}
 }
@@ -268,7 +558,7 @@ func TestSvcClient_GetService(t *testing.T) {
 
 	for _, test := range tests {
 		t.Run(test.name, func(t *testing.T) {
-			_, err := mock.Client.GetService(&Data{ServiceID: test.serviceId})
+			_, err := mock.Client.GetService(context.TODO(), &Data{ServiceID: test.serviceId})
 			if test.expectErr {
 				assert.NotNil(t, err)
 			} else {
@@ -306,7 +596,7 @@ func TestSvcClient_GetIntegrationKey(t *testing.T) {
 
 	for _, test := range tests {
 		t.Run(test.name, func(t *testing.T) {
-			actual, err := mock.Client.GetIntegrationKey(&Data{ServiceID: test.serviceId, IntegrationID: test.integrationId})
+			actual, err := mock.Client.GetIntegrationKey(context.TODO(), &Data{ServiceID: test.serviceId, IntegrationID: test.integrationId})
 			if test.expectErr {
 				assert.NotNil(t, err)
 				assert.Equal(t, test.expected, actual)
@@ -344,7 +634,7 @@ func TestSvcClient_CreateIntegration(t *testing.T) {
 
 	for _, test := range tests {
 		t.Run(test.name, func(t *testing.T) {
-			actual, err := mock.Client.createIntegration(test.serviceId, test.integrationName, test.integrationType)
+			actual, err := mock.Client.createIntegration(context.TODO(), test.serviceId, test.integrationName, test.integrationType)
 			if test.expectErr {
 				assert.NotNil(t, err)
 			} else {
@@ -407,7 +697,7 @@ func TestSvcClient_CreateService(t *testing.T) {
 
 	for _, test := range tests {
 		t.Run(test.name, func(t *testing.T) {
-			intID, err := mock.Client.CreateService(test.data)
+			intID, err := mock.Client.CreateService(context.TODO(), test.data)
 			if test.expectErr {
 				assert.NotNil(t, err)
 			} else {
@@ -445,7 +735,7 @@ func TestSvcClient_EnableService(t *testing.T) {
 
 	for _, test := range tests {
 		t.Run(test.name, func(t *testing.T) {
-			err := mock.Client.EnableService(test.data)
+			err := mock.Client.EnableService(context.TODO(), test.data)
 			if test.expectErr {
 				assert.NotNil(t, err)
 			} else {
@@ -491,7 +781,7 @@ func TestSvcClient_UpdateEscalationPolicy(t *testing.T) {
 
 	for _, test := range tests {
 		t.Run(test.name, func(t *testing.T) {
-			err := mock.Client.UpdateEscalationPolicy(test.data)
+			err := mock.Client.UpdateEscalationPolicy(context.TODO(), test.data)
 			if test.expectErr {
 				assert.NotNil(t, err)
 			} else {
@@ -501,7 +791,7 @@ func TestSvcClient_UpdateEscalationPolicy(t *testing.T) {
 	}
 }
 
-func TestSvcClient_UpdateAlertGrouping(t *testing.T) {
+func TestSvcClient_UpdateServiceSettings(t *testing.T) {
 	tests := []struct {
 		name      string
 		data      *Data
@@ -511,11 +801,37 @@ func TestSvcClient_UpdateAlertGrouping(t *testing.T) {
 			name: "normal",
 			data: &Data{
 				ServiceID:            mockServiceId,
+				ResolveTimeout:       300,
+				AcknowledgeTimeOut:   1800,
 				AlertGroupingType:    "time",
 				AlertGroupingTimeout: 3600,
 			},
 			expectErr: false,
 		},
+		{
+			name: "timeouts only",
+			data: &Data{
+				ServiceID:          mockServiceId,
+				ResolveTimeout:     0,
+				AcknowledgeTimeOut: 1800,
+			},
+			expectErr: false,
+		},
+		{
+			name: "support hours urgency rule",
+			data: supportHoursData(&Data{
+				ServiceID:          mockServiceId,
+				AcknowledgeTimeOut: 1800,
+			}),
+			expectErr: false,
+		},
+		{
+			name: "unknown service",
+			data: &Data{
+				ServiceID: "UNKNOWN",
+			},
+			expectErr: true,
+		},
 	}
 
 	mock := defaultMockApi()
@@ -523,7 +839,7 @@ func TestSvcClient_UpdateAlertGrouping(t *testing.T) {
 
 	for _, test := range tests {
 		t.Run(test.name, func(t *testing.T) {
-			err := mock.Client.UpdateAlertGrouping(test.data)
+			err := mock.Client.UpdateServiceSettings(context.TODO(), test.data)
 			if test.expectErr {
 				assert.NotNil(t, err)
 			} else {
@@ -533,6 +849,83 @@ func TestSvcClient_UpdateAlertGrouping(t *testing.T) {
 	}
 }
 
+func TestSvcClient_UpdateServiceSettingsRenames(t *testing.T) {
+	mock := defaultMockApi()
+	defer mock.cleanup()
+
+	_, err := mock.Client.CreateService(context.TODO(), &Data{
+		EscalationPolicyID: mockEscalationPolicyId,
+		ServiceName:        "taken",
+	})
+	assert.NoError(t, err)
+
+	err = mock.Client.UpdateServiceSettings(context.TODO(), &Data{ServiceID: mockServiceId, ServiceName: "taken"})
+	assert.True(t, IsConflict(err))
+	assert.Equal(t, mockServiceName, mock.State.Services[mockServiceId].Name)
+
+	err = mock.Client.UpdateServiceSettings(context.TODO(), &Data{ServiceID: mockServiceId, ServiceName: "renamed", ServiceDescription: "description"})
+	assert.NoError(t, err)
+	assert.Equal(t, "renamed", mock.State.Services[mockServiceId].Name)
+	assert.Equal(t, "description", mock.State.Services[mockServiceId].Description)
+}
+
+func TestSvcClient_CreateIntegrationStoresID(t *testing.T) {
+	mock := defaultMockApi()
+	defer mock.cleanup()
+
+	data := &Data{ServiceID: mockServiceId, IntegrationID: "DELETED"}
+	err := mock.Client.CreateIntegration(context.TODO(), data)
+	assert.Nil(t, err)
+	assert.Equal(t, mockIntegrationId3, data.IntegrationID)
+}
+
+func TestSvcClient_RestoreService(t *testing.T) {
+	tests := []struct {
+		name      string
+		data      *Data
+		expectErr bool
+	}{
+		{
+			name: "normal",
+			data: &Data{
+				ServiceID:            mockServiceId,
+				ServicePrefix:        "osd",
+				ClusterID:            "abc123",
+				BaseDomain:           "example.com",
+				EscalationPolicyID:   mockEscalationPolicyId2,
+				ResolveTimeout:       300,
+				AcknowledgeTimeOut:   1800,
+				AlertGroupingType:    "time",
+				AlertGroupingTimeout: 3600,
+				LimitedSupport:       true,
+			},
+			expectErr: false,
+		},
+		{
+			name: "unknown service",
+			data: &Data{
+				ServiceID: "UNKNOWN",
+			},
+			expectErr: true,
+		},
+	}
+
+	mock := defaultMockApi()
+	defer mock.cleanup()
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			err := mock.Client.RestoreService(context.TODO(), test.data)
+			if test.expectErr {
+				assert.NotNil(t, err)
+				return
+			}
+			assert.Nil(t, err)
+			assert.Empty(t, DetectServiceDrift(mock.State.Services[test.data.ServiceID], test.data))
+		})
+	}
+}
+
 func TestSvcClient_GetUnresolvedIncidents(t *testing.T) {
 	tests := []struct {
 		name              string
@@ -555,7 +948,7 @@ func TestSvcClient_GetUnresolvedIncidents(t *testing.T) {
 
 	for _, test := range tests {
 		t.Run(test.name, func(t *testing.T) {
-			actual, err := mock.Client.getUnresolvedIncidents(test.data)
+			actual, err := mock.Client.getUnresolvedIncidents(context.TODO(), test.data)
 			if test.expectErr {
 				assert.NotNil(t, err)
 			} else {
@@ -592,7 +985,7 @@ func TestSvcClient_GetUnresolvedAlerts(t *testing.T) {
 
 	for _, test := range tests {
 		t.Run(test.name, func(t *testing.T) {
-			actual, err := mock.Client.getUnresolvedAlerts(test.incidentId)
+			actual, err := mock.Client.getUnresolvedAlerts(context.TODO(), test.incidentId)
 			if test.expectErr {
 				assert.NotNil(t, err)
 			} else {
@@ -625,7 +1018,7 @@ func TestSvcClient_ResolvePendingIncidents(t *testing.T) {
 
 	for _, test := range tests {
 		t.Run(test.name, func(t *testing.T) {
-			err := mock.Client.resolvePendingIncidents(test.data, test.summary)
+			err := mock.Client.ResolvePendingIncidents(context.TODO(), test.data, test.summary)
 			if test.expectErr {
 				assert.NotNil(t, err)
 			} else {
@@ -662,7 +1055,7 @@ func TestSvcClient_DeleteService(t *testing.T) {
 			mock := defaultMockApi()
 			defer mock.cleanup()
 
-			err := mock.Client.DeleteService(test.data)
+			err := mock.Client.DeleteService(context.TODO(), test.data)
 			if test.expectErr {
 				assert.NotNil(t, err)
 			} else {
@@ -701,7 +1094,7 @@ func TestSvcClient_DisableService(t *testing.T) {
 			mock := defaultMockApi()
 			defer mock.cleanup()
 
-			err := mock.Client.DisableService(test.data)
+			err := mock.Client.DisableService(context.TODO(), test.data)
 			if test.expectErr {
 				assert.NotNil(t, err)
 			} else {
@@ -751,7 +1144,7 @@ func TestSvcClient_ToggleServiceOrchestration(t *testing.T) {
 			mock := defaultMockApi()
 			defer mock.cleanup()
 
-			err := mock.Client.ToggleServiceOrchestration(test.data, test.active)
+			err := mock.Client.ToggleServiceOrchestration(context.TODO(), test.data, test.active)
 			if test.expectErr {
 				assert.NotNil(t, err)
 			} else {
@@ -789,7 +1182,7 @@ func TestSvcClient_ApplyServiceOrchestrationRule(t *testing.T) {
 			mock := defaultMockApi()
 			defer mock.cleanup()
 
-			err := mock.Client.ApplyServiceOrchestrationRule(test.data)
+			err := mock.Client.ApplyServiceOrchestrationRule(context.TODO(), test.data)
 			if test.expectErr {
 				assert.NotNil(t, err)
 			} else {
@@ -799,76 +1192,34 @@ func TestSvcClient_ApplyServiceOrchestrationRule(t *testing.T) {
 	}
 }
 
-func TestSvcClient_WaitForIncidentsToResolve(t *testing.T) {
-	t.Run("0 incidents returns nil immediately", func(t *testing.T) {
-		mock := defaultMockApi()
-		defer mock.cleanup()
-
-		err := mock.Client.waitForIncidentsToResolve(&Data{ServiceID: mockServiceId2}, 1*time.Second)
-		assert.Nil(t, err)
-	})
-
-	t.Run("1 unresolved incident times out", func(t *testing.T) {
-		mock := defaultMockApi()
-		defer mock.cleanup()
-
-		mock.Client.Delay = func(d time.Duration) {
-			time.Sleep(10 * time.Millisecond)
-		}
-
-		err := mock.Client.waitForIncidentsToResolve(&Data{ServiceID: mockServiceId}, 30*time.Millisecond)
-		assert.NotNil(t, err)
-		assert.Contains(t, err.Error(), "timed out waiting for")
-	})
-
-	t.Run("3 unresolved incidents times out", func(t *testing.T) {
-		mock := defaultMockApi()
-		defer mock.cleanup()
-
-		mock.State.Incidents = append(mock.State.Incidents,
-			&pdApi.Incident{
-				APIObject: pdApi.APIObject{ID: "INC3"},
-				Service:   pdApi.APIObject{ID: mockServiceId},
-				Status:    "triggered",
-				AlertCounts: pdApi.AlertCounts{
-					Triggered: 1,
-				},
-			},
-			&pdApi.Incident{
-				APIObject: pdApi.APIObject{ID: "INC4"},
-				Service:   pdApi.APIObject{ID: mockServiceId},
-				Status:    "triggered",
-				AlertCounts: pdApi.AlertCounts{
-					Triggered: 1,
-				},
-			},
-		)
-
-		mock.Client.Delay = func(d time.Duration) {
-			time.Sleep(10 * time.Millisecond)
-		}
-
-		err := mock.Client.waitForIncidentsToResolve(&Data{ServiceID: mockServiceId}, 30*time.Millisecond)
-		assert.NotNil(t, err)
-		assert.Contains(t, err.Error(), "timed out waiting for")
-	})
-
-	t.Run("Incidents resolve during wait", func(t *testing.T) {
-		mock := defaultMockApi()
-		defer mock.cleanup()
+func TestSvcClient_CountUnresolvedIncidents(t *testing.T) {
+	tests := []struct {
+		name      string
+		serviceID string
+		expected  int
+	}{
+		{
+			name:      "Unresolved incident",
+			serviceID: mockServiceId,
+			expected:  1,
+		},
+		{
+			name:      "No incidents",
+			serviceID: mockServiceId2,
+			expected:  0,
+		},
+	}
 
-		callCount := 0
-		mock.Client.Delay = func(d time.Duration) {
-			callCount++
-			if callCount >= 2 {
-				mock.State.Incidents = nil
-			}
-		}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			mock := defaultMockApi()
+			defer mock.cleanup()
 
-		err := mock.Client.waitForIncidentsToResolve(&Data{ServiceID: mockServiceId}, 5*time.Second)
-		assert.Nil(t, err)
-		assert.GreaterOrEqual(t, callCount, 2)
-	})
+			actual, err := mock.Client.CountUnresolvedIncidents(context.TODO(), &Data{ServiceID: test.serviceID})
+			assert.NoError(t, err)
+			assert.Equal(t, test.expected, actual)
+		})
+	}
 }
 
 func TestSvcClient_ResolveAlert(t *testing.T) {
@@ -900,7 +1251,7 @@ func TestSvcClient_ResolveAlert(t *testing.T) {
 			mock := defaultMockApi()
 			defer mock.cleanup()
 
-			err := mock.Client.resolveAlert(test.integrationKey, test.alertKey, test.summary)
+			err := mock.Client.resolveAlert(context.TODO(), test.integrationKey, test.alertKey, test.summary)
 			if test.expectErr {
 				assert.NotNil(t, err)
 			} else {
@@ -910,39 +1261,42 @@ func TestSvcClient_ResolveAlert(t *testing.T) {
 	}
 }
 
-func TestParseIncidentNumbers(t *testing.T) {
+func TestSvcClient_ValidateAPIKey(t *testing.T) {
 	tests := []struct {
-		name      string
-		incidents []pdApi.Incident
-		expected  []uint
+		name           string
+		apiKey         string
+		expectErr      bool
+		expectRejected bool
 	}{
 		{
-			name:      "Empty slice",
-			incidents: []pdApi.Incident{},
-			expected:  nil,
+			name:   "Valid API key",
+			apiKey: "apiKey",
 		},
 		{
-			name: "Single incident",
-			incidents: []pdApi.Incident{
-				{IncidentNumber: 42},
-			},
-			expected: []uint{42},
-		},
-		{
-			name: "Multiple incidents",
-			incidents: []pdApi.Incident{
-				{IncidentNumber: 1},
-				{IncidentNumber: 2},
-				{IncidentNumber: 3},
-			},
-			expected: []uint{1, 2, 3},
+			name:           "Rejected API key",
+			apiKey:         "revoked",
+			expectErr:      true,
+			expectRejected: true,
 		},
 	}
 
 	for _, test := range tests {
 		t.Run(test.name, func(t *testing.T) {
-			result := parseIncidentNumbers(test.incidents)
-			assert.Equal(t, test.expected, result)
+			mock := defaultMockApi()
+			defer mock.cleanup()
+
+			client := &SvcClient{
+				APIKey:   test.apiKey,
+				PdClient: pd.NewClient(test.apiKey, withTestHttpClient(mock.server.Client()), pd.WithAPIEndpoint(mock.server.URL)),
+				BaseURL:  mock.server.URL,
+			}
+			err := client.ValidateAPIKey(context.TODO())
+			if test.expectErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+			assert.Equal(t, test.expectRejected, IsUnauthorized(err))
 		})
 	}
 }
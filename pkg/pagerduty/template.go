@@ -0,0 +1,109 @@
+// Copyright 2019 RedHat
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pagerduty
+
+import (
+	"fmt"
+	"strings"
+	"text/template"
+)
+
+// ServiceTemplateData is the data available to the PD service name and description templates
+type ServiceTemplateData struct {
+	ServicePrefix string
+	ClusterID     string
+	ClusterName   string
+	BaseDomain    string
+	Namespace     string
+	Platform      string
+	Region        string
+	Labels        map[string]string
+	Annotations   map[string]string
+}
+
+// ValidateServiceTemplates checks that the service name and description templates of a
+// PagerDutyIntegration parse, so that a broken template is reported once on the
+// PagerDutyIntegration instead of failing every ClusterDeployment. Admission already
+// rejects unclosed actions, this catches the errors only the template parser finds
+func ValidateServiceTemplates(nameTemplate, descriptionTemplate string) error {
+	if _, err := parseServiceTemplate("serviceNameTemplate", nameTemplate); err != nil {
+		return err
+	}
+	_, err := parseServiceTemplate("serviceDescriptionTemplate", descriptionTemplate)
+	return err
+}
+
+// parseServiceTemplate parses the named Go template text. Missing labels and annotations
+// render as empty strings.
+func parseServiceTemplate(name, text string) (*template.Template, error) {
+	tmpl, err := template.New(name).Option("missingkey=zero").Parse(text)
+	if err != nil {
+		return nil, fmt.Errorf("invalid %s: %w", name, err)
+	}
+	return tmpl, nil
+}
+
+// renderServiceTemplate executes the named Go template text against data
+func renderServiceTemplate(name, text string, data ServiceTemplateData) (string, error) {
+	tmpl, err := parseServiceTemplate(name, text)
+	if err != nil {
+		return "", err
+	}
+
+	var b strings.Builder
+	if err := tmpl.Execute(&b, data); err != nil {
+		return "", fmt.Errorf("unable to render %s: %w", name, err)
+	}
+	return strings.TrimSpace(b.String()), nil
+}
+
+// renderServiceName renders the PD service name template. The template must render a
+// different, non-empty name for every cluster: CreateService adopts an existing PD service
+// with the same name, so two clusters sharing a name would share a PD service.
+func renderServiceName(text string, data ServiceTemplateData) (string, error) {
+	name, err := renderServiceTemplate("serviceNameTemplate", text, data)
+	if err != nil {
+		return "", err
+	}
+	if name == "" {
+		return "", fmt.Errorf("serviceNameTemplate rendered an empty name")
+	}
+
+	// ClusterName and Namespace are only unique together, ClusterDeployments in different
+	// namespaces may have the same name
+	changesWith := func(change func(*ServiceTemplateData)) (bool, error) {
+		other := data
+		change(&other)
+		otherName, err := renderServiceTemplate("serviceNameTemplate", text, other)
+		return otherName != name, err
+	}
+	byClusterID, err := changesWith(func(d *ServiceTemplateData) { d.ClusterID += "-other" })
+	if err != nil {
+		return "", err
+	}
+	byNamespace, err := changesWith(func(d *ServiceTemplateData) { d.Namespace += "-other" })
+	if err != nil {
+		return "", err
+	}
+	byClusterName, err := changesWith(func(d *ServiceTemplateData) { d.ClusterName += "-other" })
+	if err != nil {
+		return "", err
+	}
+	if !byClusterID && !(byNamespace && byClusterName) {
+		return "", fmt.Errorf("serviceNameTemplate must render a different name for every cluster, use .ClusterID, or .Namespace and .ClusterName")
+	}
+
+	return name, nil
+}
@@ -0,0 +1,139 @@
+// Copyright 2019 RedHat
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pagerduty
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	pdApi "github.com/PagerDuty/go-pagerduty"
+	pagerdutyv1alpha1 "github.com/openshift/pagerduty-operator/api/v1alpha1"
+	"github.com/openshift/pagerduty-operator/config"
+)
+
+const (
+	supportHoursType          = "fixed_time_per_day"
+	scheduledActionType       = "urgency_change"
+	scheduledActionAtNameType = "named_time"
+)
+
+// validateUrgencyRule checks that rule sets the fields required by its type
+func validateUrgencyRule(rule *pagerdutyv1alpha1.IncidentUrgencyRuleSpec) error {
+	if rule == nil {
+		return nil
+	}
+
+	switch rule.Type {
+	case pagerdutyv1alpha1.IncidentUrgencyRuleConstant:
+		if rule.Urgency == "" {
+			return fmt.Errorf("incident urgency rule of type %s requires an urgency", rule.Type)
+		}
+	case pagerdutyv1alpha1.IncidentUrgencyRuleUseSupportHours:
+		if rule.DuringSupportHours == "" || rule.OutsideSupportHours == "" {
+			return fmt.Errorf("incident urgency rule of type %s requires an urgency during and outside support hours", rule.Type)
+		}
+		if rule.SupportHours == nil {
+			return fmt.Errorf("incident urgency rule of type %s requires support hours", rule.Type)
+		}
+	default:
+		return fmt.Errorf("unknown incident urgency rule type %q", rule.Type)
+	}
+
+	return nil
+}
+
+// applyUrgencyRule sets the incident urgency rule, support hours and scheduled actions
+// of service to the ones in data. Without a rule in data, incidents get the constant
+// config.PagerDutyUrgencyRule urgency.
+func applyUrgencyRule(service *pdApi.Service, data *Data) {
+	rule := data.IncidentUrgencyRule
+	service.SupportHours = nil
+	service.ScheduledActions = nil
+
+	if rule == nil {
+		service.IncidentUrgencyRule = &pdApi.IncidentUrgencyRule{
+			Type:    string(pagerdutyv1alpha1.IncidentUrgencyRuleConstant),
+			Urgency: config.PagerDutyUrgencyRule,
+		}
+		return
+	}
+
+	if rule.Type != pagerdutyv1alpha1.IncidentUrgencyRuleUseSupportHours {
+		service.IncidentUrgencyRule = &pdApi.IncidentUrgencyRule{
+			Type:    string(rule.Type),
+			Urgency: rule.Urgency,
+		}
+		return
+	}
+
+	service.IncidentUrgencyRule = &pdApi.IncidentUrgencyRule{
+		Type: string(rule.Type),
+		DuringSupportHours: &pdApi.IncidentUrgencyType{
+			Type:    string(pagerdutyv1alpha1.IncidentUrgencyRuleConstant),
+			Urgency: rule.DuringSupportHours,
+		},
+		OutsideSupportHours: &pdApi.IncidentUrgencyType{
+			Type:    string(pagerdutyv1alpha1.IncidentUrgencyRuleConstant),
+			Urgency: rule.OutsideSupportHours,
+		},
+	}
+	if rule.SupportHours != nil {
+		service.SupportHours = &pdApi.SupportHours{
+			Type:       supportHoursType,
+			Timezone:   rule.SupportHours.TimeZone,
+			StartTime:  rule.SupportHours.StartTime,
+			EndTime:    rule.SupportHours.EndTime,
+			DaysOfWeek: rule.SupportHours.DaysOfWeek,
+		}
+	}
+	for _, action := range rule.ScheduledActions {
+		service.ScheduledActions = append(service.ScheduledActions, pdApi.ScheduledAction{
+			Type:      scheduledActionType,
+			At:        pdApi.InlineModel{Type: scheduledActionAtNameType, Name: action.At},
+			ToUrgency: action.ToUrgency,
+		})
+	}
+}
+
+// formatUrgencyRule describes the incident urgency rule of service in a single line.
+// Support hours and scheduled actions are only included for rules that use them.
+func formatUrgencyRule(service *pdApi.Service) string {
+	rule := service.IncidentUrgencyRule
+	if rule == nil {
+		return ""
+	}
+	if rule.Type != string(pagerdutyv1alpha1.IncidentUrgencyRuleUseSupportHours) {
+		return rule.Type + "/" + rule.Urgency
+	}
+
+	parts := []string{rule.Type, formatUrgencyType(rule.DuringSupportHours), formatUrgencyType(rule.OutsideSupportHours)}
+	if hours := service.SupportHours; hours != nil {
+		days := slices.Clone(hours.DaysOfWeek)
+		slices.Sort(days)
+		parts = append(parts, fmt.Sprintf("%s %s-%s %v", hours.Timezone, hours.StartTime, hours.EndTime, days))
+	}
+	for _, action := range service.ScheduledActions {
+		parts = append(parts, fmt.Sprintf("%s@%s:%s", action.Type, action.At.Name, action.ToUrgency))
+	}
+	return strings.Join(parts, "/")
+}
+
+func formatUrgencyType(urgency *pdApi.IncidentUrgencyType) string {
+	if urgency == nil {
+		return ""
+	}
+	return urgency.Type + ":" + urgency.Urgency
+}
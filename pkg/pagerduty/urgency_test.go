@@ -0,0 +1,93 @@
+package pagerduty
+
+import (
+	"testing"
+
+	pdApi "github.com/PagerDuty/go-pagerduty"
+	pagerdutyv1alpha1 "github.com/openshift/pagerduty-operator/api/v1alpha1"
+	"github.com/openshift/pagerduty-operator/config"
+	"github.com/stretchr/testify/assert"
+)
+
+// supportHoursData sets a use_support_hours urgency rule on data
+func supportHoursData(data *Data) *Data {
+	data.IncidentUrgencyRule = &pagerdutyv1alpha1.IncidentUrgencyRuleSpec{
+		Type:                pagerdutyv1alpha1.IncidentUrgencyRuleUseSupportHours,
+		DuringSupportHours:  "high",
+		OutsideSupportHours: "low",
+		SupportHours: &pagerdutyv1alpha1.SupportHoursSpec{
+			TimeZone:   "America/New_York",
+			DaysOfWeek: []uint{1, 2, 3, 4, 5},
+			StartTime:  "09:00:00",
+			EndTime:    "17:00:00",
+		},
+		ScheduledActions: []pagerdutyv1alpha1.ScheduledActionSpec{
+			{At: "support_hours_start", ToUrgency: "high"},
+		},
+	}
+	return data
+}
+
+func TestApplyUrgencyRule(t *testing.T) {
+	tests := []struct {
+		name     string
+		data     *Data
+		expected *pdApi.Service
+	}{
+		{
+			name: "Default rule",
+			data: &Data{},
+			expected: &pdApi.Service{
+				IncidentUrgencyRule: &pdApi.IncidentUrgencyRule{Type: "constant", Urgency: config.PagerDutyUrgencyRule},
+			},
+		},
+		{
+			name: "Constant rule",
+			data: &Data{
+				IncidentUrgencyRule: &pagerdutyv1alpha1.IncidentUrgencyRuleSpec{
+					Type:    pagerdutyv1alpha1.IncidentUrgencyRuleConstant,
+					Urgency: "low",
+				},
+			},
+			expected: &pdApi.Service{
+				IncidentUrgencyRule: &pdApi.IncidentUrgencyRule{Type: "constant", Urgency: "low"},
+			},
+		},
+		{
+			name: "Support hours rule",
+			data: supportHoursData(&Data{}),
+			expected: &pdApi.Service{
+				IncidentUrgencyRule: &pdApi.IncidentUrgencyRule{
+					Type:                "use_support_hours",
+					DuringSupportHours:  &pdApi.IncidentUrgencyType{Type: "constant", Urgency: "high"},
+					OutsideSupportHours: &pdApi.IncidentUrgencyType{Type: "constant", Urgency: "low"},
+				},
+				SupportHours: &pdApi.SupportHours{
+					Type:       "fixed_time_per_day",
+					Timezone:   "America/New_York",
+					StartTime:  "09:00:00",
+					EndTime:    "17:00:00",
+					DaysOfWeek: []uint{1, 2, 3, 4, 5},
+				},
+				ScheduledActions: []pdApi.ScheduledAction{
+					{
+						Type:      "urgency_change",
+						At:        pdApi.InlineModel{Type: "named_time", Name: "support_hours_start"},
+						ToUrgency: "high",
+					},
+				},
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			// start from a service that used support hours, switching back must clear them
+			service := &pdApi.Service{}
+			applyUrgencyRule(service, supportHoursData(&Data{}))
+
+			applyUrgencyRule(service, test.data)
+			assert.Equal(t, test.expected, service)
+		})
+	}
+}
@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"reflect"
 	"strings"
 	"testing"
 	"text/template"
@@ -382,3 +383,35 @@ func TestAllGotmplsRenderWithDefaults(t *testing.T) {
 		})
 	}
 }
+
+// TestClusterRolesInSync checks that the ClusterRole of every deployment method grants the
+// same rules. They are generated from the kubebuilder RBAC markers by hack/generate-rbac.sh.
+func TestClusterRolesInSync(t *testing.T) {
+	repoRoot := filepath.Dir(deployPkoDir())
+	roles := []string{
+		filepath.Join(repoRoot, "deploy", "role.yaml"),
+		filepath.Join(repoRoot, "manifests", "02-role.yaml"),
+		filepath.Join(deployPkoDir(), "ClusterRole-pagerduty-operator.yaml"),
+	}
+	fixtures, err := filepath.Glob(filepath.Join(deployPkoDir(), ".test-fixtures", "*", "ClusterRole-pagerduty-operator.yaml"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	roles = append(roles, fixtures...)
+
+	var expected interface{}
+	for _, role := range roles {
+		content, err := os.ReadFile(role)
+		if err != nil {
+			t.Fatalf("failed to read %s: %v", role, err)
+		}
+		rules := parseYAMLDocument(t, string(content))["rules"]
+		if expected == nil {
+			expected = rules
+			continue
+		}
+		if !reflect.DeepEqual(rules, expected) {
+			t.Errorf("rules of %s differ from %s, run hack/generate-rbac.sh", role, roles[0])
+		}
+	}
+}
@@ -7,6 +7,7 @@ import (
 
 	"github.com/go-logr/logr"
 	hivev1 "github.com/openshift/hive/apis/hive/v1"
+	pagerdutyv1alpha1 "github.com/openshift/pagerduty-operator/api/v1alpha1"
 	v1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -70,6 +71,38 @@ func DeleteConfigMap(name string, namespace string, client client.Client, reqLog
 	return nil
 }
 
+// DeletePagerDutyService deletes a PagerDutyService
+func DeletePagerDutyService(name string, namespace string, client client.Client, reqLogger logr.Logger) error {
+	pdService := &pagerdutyv1alpha1.PagerDutyService{}
+	err := client.Get(context.TODO(), types.NamespacedName{Namespace: namespace, Name: name}, pdService)
+
+	if err != nil {
+		if errors.IsNotFound(err) {
+			// Request object not found, could have been deleted after reconcile request.
+			// Owned objects are automatically garbage collected. For additional cleanup logic use finalizers.
+			// Return and don't requeue
+			return nil
+		}
+		// Error finding the object, requeue
+		return err
+	}
+
+	reqLogger.Info("Deleting PagerDutyService", "ClusterDeployment.Namespace", namespace, "Name", name)
+	err = client.Delete(context.TODO(), pdService)
+	if err != nil {
+		if errors.IsNotFound(err) {
+			// Request object not found, could have been deleted after reconcile request.
+			// Owned objects are automatically garbage collected. For additional cleanup logic use finalizers.
+			// Return and don't requeue
+			return nil
+		}
+		// Error finding the object, requeue
+		return err
+	}
+
+	return nil
+}
+
 // DeleteSyncSet deletes a SyncSet
 func DeleteSyncSet(name string, namespace string, client client.Client, reqLogger logr.Logger) error {
 	syncset := &hivev1.SyncSet{}
@@ -145,6 +178,35 @@ func GetClusterID(cd *hivev1.ClusterDeployment, isFedramp bool) string {
 	}
 }
 
+// GetClusterPlatform returns the platform of a cluster (e.g. aws or gcp) and the region it
+// was installed in. The region is empty for platforms without regions.
+func GetClusterPlatform(cd *hivev1.ClusterDeployment) (platform string, region string) {
+	p := cd.Spec.Platform
+	switch {
+	case p.AWS != nil:
+		return "aws", p.AWS.Region
+	case p.GCP != nil:
+		return "gcp", p.GCP.Region
+	case p.Azure != nil:
+		return "azure", p.Azure.Region
+	case p.AlibabaCloud != nil:
+		return "alibabacloud", p.AlibabaCloud.Region
+	case p.IBMCloud != nil:
+		return "ibmcloud", p.IBMCloud.Region
+	case p.OpenStack != nil:
+		return "openstack", ""
+	case p.VSphere != nil:
+		return "vsphere", ""
+	case p.Ovirt != nil:
+		return "ovirt", ""
+	case p.BareMetal != nil, p.AgentBareMetal != nil:
+		return "baremetal", ""
+	case p.None != nil:
+		return "none", ""
+	}
+	return "", ""
+}
+
 // IsRedHatInfrastructure returns whether or not a cluster is part of the Red Hat infrastructure
 func IsRedHatInfrastructure(cd *hivev1.ClusterDeployment) bool {
 	// clusterRHInfraLabel is the annotation key for Red Hat infrastructure clusters
@@ -1,14 +1,21 @@
 package utils
 
 import (
+	"context"
 	"testing"
 
 	"github.com/go-logr/logr"
 	hivev1 "github.com/openshift/hive/apis/hive/v1"
+	"github.com/openshift/hive/apis/hive/v1/aws"
+	"github.com/openshift/hive/apis/hive/v1/gcp"
+	"github.com/openshift/hive/apis/hive/v1/none"
+	pagerdutyv1alpha1 "github.com/openshift/pagerduty-operator/api/v1alpha1"
 	"github.com/stretchr/testify/assert"
 	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
 	"sigs.k8s.io/controller-runtime/pkg/client/fake"
 )
 
@@ -173,6 +180,56 @@ func TestDeleteConfigMap(t *testing.T) {
 	}
 }
 
+func TestDeletePagerDutyService(t *testing.T) {
+	tests := []struct {
+		name      string
+		existing  bool
+		expectErr bool
+	}{
+		{
+			name:      "PagerDutyService exists",
+			existing:  true,
+			expectErr: false,
+		},
+		{
+			name:      "PagerDutyService does not exist",
+			existing:  false,
+			expectErr: false,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			s := runtime.NewScheme()
+			s.AddKnownTypes(pagerdutyv1alpha1.GroupVersion, &pagerdutyv1alpha1.PagerDutyService{})
+
+			builder := fake.NewClientBuilder().WithScheme(s)
+			if test.existing {
+				pdService := &pagerdutyv1alpha1.PagerDutyService{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:      "test-pd-service",
+						Namespace: testNamespace,
+					},
+				}
+				builder = builder.WithObjects(pdService)
+			}
+			client := builder.Build()
+
+			err := DeletePagerDutyService("test-pd-service", testNamespace, client, logr.Discard())
+			if test.expectErr {
+				assert.NotNil(t, err)
+			} else {
+				assert.Nil(t, err)
+			}
+
+			if test.existing {
+				err = client.Get(context.TODO(), types.NamespacedName{Name: "test-pd-service", Namespace: testNamespace}, &pagerdutyv1alpha1.PagerDutyService{})
+				assert.True(t, errors.IsNotFound(err))
+			}
+		})
+	}
+}
+
 func TestDeleteSyncSet(t *testing.T) {
 	tests := []struct {
 		name      string
@@ -286,6 +343,45 @@ func TestGetClusterID(t *testing.T) {
 	})
 }
 
+func TestGetClusterPlatform(t *testing.T) {
+	tests := []struct {
+		name             string
+		platform         hivev1.Platform
+		expectedPlatform string
+		expectedRegion   string
+	}{
+		{
+			name:             "AWS",
+			platform:         hivev1.Platform{AWS: &aws.Platform{Region: "us-east-1"}},
+			expectedPlatform: "aws",
+			expectedRegion:   "us-east-1",
+		},
+		{
+			name:             "GCP",
+			platform:         hivev1.Platform{GCP: &gcp.Platform{Region: "europe-west1"}},
+			expectedPlatform: "gcp",
+			expectedRegion:   "europe-west1",
+		},
+		{
+			name:             "Platform without regions",
+			platform:         hivev1.Platform{None: &none.Platform{}},
+			expectedPlatform: "none",
+		},
+		{
+			name: "No platform",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			cd := &hivev1.ClusterDeployment{Spec: hivev1.ClusterDeploymentSpec{Platform: test.platform}}
+			platform, region := GetClusterPlatform(cd)
+			assert.Equal(t, test.expectedPlatform, platform)
+			assert.Equal(t, test.expectedRegion, region)
+		})
+	}
+}
+
 func TestIsRedHatInfrastructure(t *testing.T) {
 	tests := []struct {
 		name     string